@@ -6,6 +6,10 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 )
 
 type addNode struct {
@@ -39,7 +43,10 @@ func (a *addNode) Set(value string) error {
 
 type flagContainer struct {
 	managerAddress     string
+	credentials        *auth.Credentials
+	tls                *secure.Config
 	createCluster      []string
+	erasureCoding      *common.ErasureCoding
 	deleteCluster      string
 	moveCluster        []string
 	balanceClusters    []string
@@ -50,6 +57,8 @@ type flagContainer struct {
 	createSnapshot     string
 	deleteSnapshot     string
 	restoreSnapshot    string
+	rotateKeys         []string
+	rotateKeysAll      bool
 	changeState        []string
 	changeStateAll     bool
 	stateOnline        bool
@@ -186,6 +195,11 @@ func (f *flagContainer) Define(v string) int {
 		f.active = "restoreSnapshot"
 	}
 
+	if len(f.rotateKeys) != 0 || f.rotateKeysAll {
+		activeCount++
+		f.active = "rotateKeys"
+	}
+
 	if len(f.changeState) != 0 || f.changeStateAll {
 		activeCount++
 		f.active = "changeState"
@@ -244,10 +258,26 @@ func defineFlags(v string) *flagContainer {
 	var t string
 	set.StringVar(&t, `t`, "localhost:9400", `The end point of manager to work with.`)
 
+	var credentials string
+	set.StringVar(&credentials, `credentials`, "", `The key to sign the requests to manager. Ex: keyId:secret`)
+
+	var tlsCa string
+	set.StringVar(&tlsCa, `tls-ca`, "", `The certificate authority file to verify manager and enable https.`)
+
+	var tlsCert string
+	set.StringVar(&tlsCert, `tls-cert`, "", `The client certificate file to present to manager.`)
+
+	var tlsKey string
+	set.StringVar(&tlsKey, `tls-key`, "", `The key file of the client certificate.`)
+
 	var createCluster string
 	set.StringVar(&createCluster, `create-cluster`, "", `Creates data nodes cluster. Provide data node binding addresses to create cluster. Node Manager will decide which data node will be master and which others are slave.
 Ex: 192.168.0.1:9430,192.168.0.2:9430`)
 
+	var erasureCoding string
+	set.StringVar(&erasureCoding, `erasure-coding`, "", `Creates the cluster as erasure coded instead of keeping the full copy of the data on every node. Provide data and parity shard counts. Node count should match the total shard count. (Can only be used with -create-cluster argument)
+Ex: 4+2`)
+
 	var deleteCluster string
 	set.StringVar(&deleteCluster, `delete-cluster`, "", `Deletes data nodes cluster. Provide cluster id to delete.`)
 
@@ -285,6 +315,10 @@ Ex: clusterId=snapshotIndex`)
 	set.StringVar(&restoreSnapshot, `restore-snapshot`, "", `Restores a snapshot in the cluster. Provide cluster id with snapshot index to be restored.
 Ex: clusterId=snapshotIndex`)
 
+	var rotateKeys string
+	set.StringVar(&rotateKeys, `rotate-keys`, "", `Re-encrypts the block files on the data nodes with their current encryption keys in the background. Provide at least one cluster id to rotate the keys or leave empty to apply all clusters in the setup.
+Ex: clusterId,clusterId`)
+
 	var changeState string
 	set.StringVar(&changeState, `change-state`, "", `Change the state of the cluster. Provide at least one cluster id to change the state or leave empty to apply all clusters in the setup.
 Ex: clusterId,clusterId`)
@@ -328,6 +362,18 @@ Ex: clusterId,clusterId`)
 		break
 	}
 
+	for i, arg := range args {
+		idx := strings.Index(arg, "-rotate-keys")
+		if idx == -1 {
+			continue
+		}
+		if len(args) > i+1 && !strings.HasPrefix(args[i+1], "-") {
+			break
+		}
+		args = insert(args, i, "*")
+		break
+	}
+
 	for i, arg := range args {
 		idx := strings.Index(arg, "-change-state")
 		if idx == -1 {
@@ -350,6 +396,39 @@ Ex: clusterId,clusterId`)
 		managerAddress = t
 	}
 
+	var c *auth.Credentials
+	if len(credentials) > 0 {
+		var err error
+		c, err = auth.ParseCredentials(credentials)
+		if err != nil {
+			fmt.Println("credentials should be in keyId:secret format")
+			fmt.Println()
+			os.Exit(2)
+		}
+	}
+
+	var tls *secure.Config
+	if len(tlsCa) > 0 || len(tlsCert) > 0 || len(tlsKey) > 0 {
+		var err error
+		tls, err = secure.NewConfig(tlsCert, tlsKey, tlsCa, false)
+		if err != nil {
+			fmt.Printf("tls setup is failed: %s\n", err.Error())
+			fmt.Println()
+			os.Exit(2)
+		}
+	}
+
+	var ec *common.ErasureCoding
+	if len(erasureCoding) > 0 {
+		var err error
+		ec, err = common.ParseErasureCoding(erasureCoding)
+		if err != nil {
+			fmt.Println("erasure coding should be in data+parity format with at least 2 data and 1 parity shards")
+			fmt.Println()
+			os.Exit(2)
+		}
+	}
+
 	cc := strings.Split(createCluster, ",")
 	if len(cc) > 0 && len(cc[0]) == 0 {
 		cc = []string{}
@@ -367,6 +446,13 @@ Ex: clusterId,clusterId`)
 		bc = []string{}
 	}
 
+	rka := false
+	rk := strings.Split(rotateKeys, ",")
+	if len(rk) > 0 && len(rk[0]) == 0 || strings.Compare(rk[0], "*") == 0 {
+		rka = strings.Compare(rk[0], "*") == 0
+		rk = []string{}
+	}
+
 	csa := false
 	cs := strings.Split(changeState, ",")
 	if len(cs) > 0 && len(cs[0]) == 0 || strings.Compare(cs[0], "*") == 0 {
@@ -378,7 +464,10 @@ Ex: clusterId,clusterId`)
 
 	fc := &flagContainer{
 		managerAddress:     managerAddress,
+		credentials:        c,
+		tls:                tls,
 		createCluster:      cc,
+		erasureCoding:      ec,
 		deleteCluster:      deleteCluster,
 		moveCluster:        mc,
 		balanceClusters:    bc,
@@ -389,6 +478,8 @@ Ex: clusterId,clusterId`)
 		createSnapshot:     createSnapshot,
 		deleteSnapshot:     deleteSnapshot,
 		restoreSnapshot:    restoreSnapshot,
+		rotateKeys:         rk,
+		rotateKeysAll:      rka,
 		changeState:        cs,
 		changeStateAll:     csa,
 		stateOnline:        strings.Contains(joinedArgs, "online"),
@@ -16,12 +16,14 @@ var version = "XX.X.XXXX"
 
 func main() {
 	fc := defineFlags(version)
+	manager.SetTls(fc.tls)
+	manager.SetCredentials(fc.credentials)
 
 	switch fc.active {
 	case "version", "v":
 		fmt.Println(version)
 	case "createCluster":
-		if err := manager.CreateCluster([]string{fc.managerAddress}, fc.createCluster); err != nil {
+		if err := manager.CreateCluster([]string{fc.managerAddress}, fc.createCluster, fc.erasureCoding); err != nil {
 			fmt.Printf("ERROR: %s\n", err.Error())
 			os.Exit(10)
 		}
@@ -293,6 +295,12 @@ func main() {
 		default:
 			fmt.Println("cluster balancing is canceled")
 		}
+	case "rotateKeys":
+		if err := manager.RotateKeys([]string{fc.managerAddress}, fc.rotateKeys); err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(85)
+		}
+		fmt.Println("key rotation is started on data nodes, follow the data node logs for the progress.")
 	case "getCluster":
 		if err := manager.GetClusters([]string{fc.managerAddress}, fc.getCluster); err != nil {
 			fmt.Printf("%s\n", err.Error())
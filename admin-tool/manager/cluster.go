@@ -9,20 +9,45 @@ import (
 	"strings"
 	"time"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
 	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 )
 
 const managerEndPoint = "/client/manager"
 
 var client = http.Client{Timeout: time.Hour * 24 * 7} // one week timeout
 
-func CreateCluster(managerAddr []string, addresses []string) error {
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+var credentials *auth.Credentials
+
+// SetCredentials makes the requests to manager to be signed with the credentials
+func SetCredentials(c *auth.Credentials) {
+	credentials = c
+	client.Transport = auth.NewTransport(credentials, secure.HttpTransport())
+}
+
+// SetTls makes the requests to manager over https using the tls configuration
+func SetTls(config *secure.Config) {
+	secure.CurrentConfig = config
+	client.Transport = auth.NewTransport(credentials, secure.HttpTransport())
+}
+
+func endPointUrl(managerAddr string) string {
+	return fmt.Sprintf("%s://%s%s", secure.CurrentConfig.Scheme(), managerAddr, managerEndPoint)
+}
+
+// CreateCluster registers the data nodes as a cluster. Cluster keeps the shards of the chunks when the erasure
+// coding is provided, otherwise every node keeps the full copy of them
+func CreateCluster(managerAddr []string, addresses []string, erasureCoding *common.ErasureCoding) error {
+	req, err := http.NewRequest(http.MethodPost, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
 	req.Header.Set("X-Action", "register")
 	req.Header.Set("X-Options", strings.Join(addresses, ","))
+	if erasureCoding != nil {
+		req.Header.Set("X-Erasure-Coding", erasureCoding.String())
+	}
 
 	res, err := client.Do(req)
 	if err != nil {
@@ -46,11 +71,17 @@ func CreateCluster(managerAddr []string, addresses []string) error {
 		return err
 	}
 	fmt.Printf("Cluster is created as offline: %s\n", c.Id)
+	if c.ErasureCoded() {
+		fmt.Printf("         Erasure:   %s\n", c.Erasure)
+	}
 	for _, n := range c.Nodes {
 		mode := "SLAVE"
 		if n.Master {
 			mode = "MASTER"
 		}
+		if c.ErasureCoded() {
+			mode = fmt.Sprintf("%s, SHARD %d", mode, n.Shard)
+		}
 		fmt.Printf("         Data Node: %s (%s) -> %s\n", n.Address, mode, n.Id)
 	}
 
@@ -58,7 +89,7 @@ func CreateCluster(managerAddr []string, addresses []string) error {
 }
 
 func DeleteCluster(managerAddr []string, clusterId string) error {
-	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodDelete, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -88,7 +119,7 @@ func DeleteCluster(managerAddr []string, clusterId string) error {
 }
 
 func MoveCluster(managerAddr []string, clusterIds []string) error {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -120,7 +151,7 @@ func MoveCluster(managerAddr []string, clusterIds []string) error {
 }
 
 func BalanceClusters(managerAddr []string, clusterIds []string) error {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -152,7 +183,7 @@ func BalanceClusters(managerAddr []string, clusterIds []string) error {
 }
 
 func AddNode(managerAddr []string, clusterId string, addresses []string) error {
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodPost, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -193,7 +224,7 @@ func AddNode(managerAddr []string, clusterId string, addresses []string) error {
 }
 
 func RemoveNode(managerAddr []string, nodeId string) error {
-	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodDelete, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -223,7 +254,7 @@ func RemoveNode(managerAddr []string, nodeId string) error {
 }
 
 func ChangeState(managerAddr []string, clusterIds []string, state common.States) error {
-	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodPut, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -273,7 +304,7 @@ func ChangeState(managerAddr []string, clusterIds []string, state common.States)
 }
 
 func CreateSnapshot(managerAddr []string, clusterId string) error {
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodPost, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -305,7 +336,7 @@ func CreateSnapshot(managerAddr []string, clusterId string) error {
 }
 
 func DeleteSnapshot(managerAddr []string, clusterId string, snapshotIndex uint64) error {
-	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodDelete, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -337,7 +368,7 @@ func DeleteSnapshot(managerAddr []string, clusterId string, snapshotIndex uint64
 }
 
 func RestoreSnapshot(managerAddr []string, clusterId string, snapshotIndex uint64) error {
-	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodPut, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -368,8 +399,40 @@ func RestoreSnapshot(managerAddr []string, clusterId string, snapshotIndex uint6
 	return nil
 }
 
+func RotateKeys(managerAddr []string, clusterIds []string) error {
+	req, err := http.NewRequest(http.MethodPost, endPointUrl(managerAddr[0]), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Action", "rotateKeys")
+	req.Header.Set("X-Options", strings.Join(clusterIds, ","))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: manager node is not reachable", managerAddr[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != 202 {
+		if res.StatusCode == 422 {
+			return fmt.Errorf("")
+		}
+
+		var e common.Error
+		if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				return fmt.Errorf("dfs manager returned with an unrecognisable status code: %d", res.StatusCode)
+			}
+			return err
+		}
+		return fmt.Errorf(e.Message)
+	}
+
+	return nil
+}
+
 func SyncClusters(managerAddr []string, clusterId string) error {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -397,7 +460,7 @@ func SyncClusters(managerAddr []string, clusterId string) error {
 }
 
 func RepairConsistency(managerAddr []string, repairModel string) error {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -425,7 +488,7 @@ func RepairConsistency(managerAddr []string, repairModel string) error {
 }
 
 func GetClusters(managerAddr []string, clusterId string) error {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
@@ -466,10 +529,20 @@ func GetClusters(managerAddr []string, clusterId string) error {
 			if n.Master {
 				mode = "(MASTER)"
 			}
+			if cluster.ErasureCoded() {
+				fmt.Printf("      Data Node: %s %s -> %s (shard %d)\n", n.Address, mode, n.Id, n.Shard)
+				continue
+			}
 			fmt.Printf("      Data Node: %s %s -> %s\n", n.Address, mode, n.Id)
 		}
+		if cluster.ErasureCoded() {
+			fmt.Printf("      Erasure:   %s\n", cluster.Erasure)
+		}
 		fmt.Printf("      Size:      %d (%d Gb)\n", cluster.Size, cluster.Size/(1024*1024*1024))
 		fmt.Printf("      Available: %d (%d Gb)\n", cluster.Available(), cluster.Available()/(1024*1024*1024))
+		if cluster.Logical > 0 {
+			fmt.Printf("      Logical:   %d (%d Gb)\n", cluster.Logical, cluster.Logical/(1024*1024*1024))
+		}
 		fmt.Printf("      Weight:    %.2f\n", cluster.Weight())
 		fmt.Printf("      Status:    %s\n", cluster.StateString())
 		if cluster.Maintain {
@@ -510,7 +583,7 @@ func GetClusters(managerAddr []string, clusterId string) error {
 }
 
 func GetReport(managerAddr []string) error {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", managerAddr[0], managerEndPoint), nil)
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(managerAddr[0]), nil)
 	if err != nil {
 		return err
 	}
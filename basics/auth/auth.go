@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+)
+
+// MaxTimeSkew is the accepted time difference between the signed request and the server
+const MaxTimeSkew = time.Minute * 5
+
+// Scope is the group of the operations that the key is allowed to request
+type Scope string
+
+const (
+	ScopeClient Scope = "client" // File storage manipulation requests on head node
+	ScopeAdmin  Scope = "admin"  // Cluster management requests on manager node
+)
+
+// Key struct is to hold the access key definition in the keys file
+type Key struct {
+	Id     string  `json:"id"`
+	Secret string  `json:"secret"`
+	Scopes []Scope `json:"scopes"`
+}
+
+// Has checks the key is allowed to request the operations of the scope
+func (k *Key) Has(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator validates the credentials of the requests
+type Authenticator interface {
+	Authenticate(r *http.Request, scope Scope) (*Key, error)
+	AuthenticateS3(r *http.Request, scope Scope) (*Key, error)
+	ChunkSigner(r *http.Request) (*ChunkSigner, error)
+	Key(keyId string, scope Scope) (*Key, error)
+}
+
+type authenticator struct {
+	keys map[string]*Key
+
+	nonceMutex sync.Mutex
+	nonces     map[string]time.Time
+	purged     time.Time
+}
+
+// NewAuthenticator creates the authenticator using the keys in the json formatted file at keysPath
+func NewAuthenticator(keysPath string) (Authenticator, error) {
+	content, err := os.ReadFile(keysPath)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0)
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return nil, err
+	}
+
+	return NewAuthenticatorWithKeys(keys)
+}
+
+// NewAuthenticatorWithKeys creates the authenticator using the provided keys
+func NewAuthenticatorWithKeys(keys []*Key) (Authenticator, error) {
+	a := &authenticator{
+		keys:       make(map[string]*Key),
+		nonceMutex: sync.Mutex{},
+		nonces:     make(map[string]time.Time),
+		purged:     time.Now(),
+	}
+
+	for _, key := range keys {
+		if len(key.Id) == 0 || len(key.Secret) == 0 || strings.Contains(key.Id, ":") {
+			return nil, os.ErrInvalid
+		}
+		if _, has := a.keys[key.Id]; has {
+			return nil, os.ErrExist
+		}
+		a.keys[key.Id] = key
+	}
+
+	return a, nil
+}
+
+// Authenticate validates the signed or the static credentials of the request and checks the
+// key is allowed for the scope. It returns ErrUnauthorized if the credentials are not valid
+// or absent and ErrForbidden if the key does not have the scope
+func (a *authenticator) Authenticate(r *http.Request, scope Scope) (*Key, error) {
+	key, err := a.signedKey(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		key = a.staticKey(r)
+		if key == nil {
+			return nil, errors.ErrUnauthorized
+		}
+	}
+
+	if !key.Has(scope) {
+		return nil, errors.ErrForbidden
+	}
+	return key, nil
+}
+
+// Key finds the key of the key id in the keys and checks the key is allowed for the scope. It is used for the
+// credentials that refer the key without its secret, such as the issuer of the presigned urls. It returns
+// ErrUnauthorized if the key does not exist and ErrForbidden if the key does not have the scope
+func (a *authenticator) Key(keyId string, scope Scope) (*Key, error) {
+	key, has := a.keys[keyId]
+	if !has {
+		return nil, errors.ErrUnauthorized
+	}
+
+	if !key.Has(scope) {
+		return nil, errors.ErrForbidden
+	}
+	return key, nil
+}
+
+func (a *authenticator) signedKey(r *http.Request) (*Key, error) {
+	signature := r.Header.Get(signatureHeader)
+	if len(signature) == 0 {
+		return nil, nil
+	}
+
+	key, has := a.keys[r.Header.Get(keyHeader)]
+	if !has {
+		return nil, errors.ErrUnauthorized
+	}
+
+	timestamp := r.Header.Get(timestampHeader)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+
+	skew := time.Since(time.Unix(unix, 0))
+	if skew > MaxTimeSkew || skew < -MaxTimeSkew {
+		return nil, errors.ErrUnauthorized
+	}
+
+	nonce := r.Header.Get(nonceHeader)
+	contentSha256 := r.Header.Get(contentSha256Header)
+	if len(nonce) == 0 || len(contentSha256) == 0 {
+		return nil, errors.ErrUnauthorized
+	}
+
+	expected := Sign(key.Secret, r.Method, r.URL.RequestURI(), r.Header)
+	provided, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, provided) {
+		return nil, errors.ErrUnauthorized
+	}
+
+	if !a.useNonce(key.Id, nonce, time.Unix(unix, 0).Add(MaxTimeSkew)) {
+		return nil, errors.ErrUnauthorized
+	}
+
+	if strings.Compare(contentSha256, UnsignedPayload) != 0 {
+		expectedSum, err := hex.DecodeString(contentSha256)
+		if err != nil || len(expectedSum) != sha256.Size {
+			return nil, errors.ErrUnauthorized
+		}
+		if r.Body == nil {
+			r.Body = http.NoBody
+		}
+		r.Body = &verifyingBody{body: r.Body, hash: sha256.New(), expected: expectedSum, remaining: r.ContentLength}
+	}
+
+	return key, nil
+}
+
+// useNonce records the nonce of the key until it expires and reports false if it is already used. The request
+// can not be replayed after the expiry because its timestamp is out of the accepted time skew
+func (a *authenticator) useNonce(keyId string, nonce string, expires time.Time) bool {
+	a.nonceMutex.Lock()
+	defer a.nonceMutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(a.purged) > time.Minute {
+		for n, e := range a.nonces {
+			if now.After(e) {
+				delete(a.nonces, n)
+			}
+		}
+		a.purged = now
+	}
+
+	nonce = keyId + ":" + nonce
+	if _, has := a.nonces[nonce]; has {
+		return false
+	}
+	a.nonces[nonce] = expires
+
+	return true
+}
+
+// verifyingBody compares the hash of the request body with the signed content hash when the body is read
+// completely and fails the read if it is not matching. Body is completed at the content length if it is known,
+// so the handlers that do not read until EOF are also covered
+type verifyingBody struct {
+	body      io.ReadCloser
+	hash      hash.Hash
+	expected  []byte
+	remaining int64
+}
+
+func (v *verifyingBody) Read(p []byte) (int, error) {
+	n, err := v.body.Read(p)
+	_, _ = v.hash.Write(p[:n])
+
+	completed := err == io.EOF
+	if v.remaining > -1 {
+		v.remaining -= int64(n)
+		completed = completed || v.remaining <= 0
+	}
+
+	if completed && !hmac.Equal(v.hash.Sum(nil), v.expected) {
+		// the last part of the body is not returned, so the readers that ignore the error of a complete read
+		// can not accept the content
+		return 0, errors.ErrUnauthorized
+	}
+	return n, err
+}
+
+func (v *verifyingBody) Close() error {
+	return v.body.Close()
+}
+
+func (a *authenticator) staticKey(r *http.Request) *Key {
+	keyId, secret, ok := r.BasicAuth()
+	if !ok {
+		apiKey := r.Header.Get(apiKeyHeader)
+
+		idx := strings.Index(apiKey, ":")
+		if idx == -1 {
+			return nil
+		}
+		keyId, secret = apiKey[:idx], apiKey[idx+1:]
+	}
+
+	key, has := a.keys[keyId]
+	if !has || subtle.ConstantTimeCompare([]byte(key.Secret), []byte(secret)) != 1 {
+		return nil
+	}
+	return key
+}
+
+var _ Authenticator = &authenticator{}
@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func testAuthenticator(t *testing.T) Authenticator {
+	a, err := NewAuthenticatorWithKeys([]*Key{
+		{Id: "tools", Secret: "s3cr3t", Scopes: []Scope{ScopeClient, ScopeAdmin}},
+		{Id: "app", Secret: "4pp", Scopes: []Scope{ScopeClient}},
+	})
+	assert.Nil(t, err)
+	return a
+}
+
+func TestNewAuthenticatorWithKeys(t *testing.T) {
+	_, err := NewAuthenticatorWithKeys([]*Key{{Id: "app", Secret: ""}})
+	assert.NotNil(t, err)
+
+	_, err = NewAuthenticatorWithKeys([]*Key{{Id: "a:b", Secret: "secret"}})
+	assert.NotNil(t, err)
+
+	_, err = NewAuthenticatorWithKeys([]*Key{{Id: "app", Secret: "1"}, {Id: "app", Secret: "2"}})
+	assert.NotNil(t, err)
+}
+
+func TestAuthenticator_Key(t *testing.T) {
+	a := testAuthenticator(t)
+
+	key, err := a.Key("app", ScopeClient)
+	assert.Nil(t, err)
+	assert.Equal(t, "app", key.Id)
+
+	_, err = a.Key("app", ScopeAdmin)
+	assert.Equal(t, errors.ErrForbidden, err)
+
+	_, err = a.Key("revoked", ScopeClient)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+}
+
+func TestParseCredentials(t *testing.T) {
+	c, err := ParseCredentials("tools:s3:cr3t")
+	assert.Nil(t, err)
+	assert.Equal(t, "tools", c.KeyId)
+	assert.Equal(t, "s3:cr3t", c.Secret)
+
+	_, err = ParseCredentials("tools")
+	assert.NotNil(t, err)
+
+	_, err = ParseCredentials(":s3cr3t")
+	assert.NotNil(t, err)
+
+	_, err = ParseCredentials("tools:")
+	assert.NotNil(t, err)
+}
+
+func TestAuthenticator_Authenticate_Signed(t *testing.T) {
+	a := testAuthenticator(t)
+	c := &Credentials{KeyId: "app", Secret: "4pp"}
+
+	r := httptest.NewRequest(http.MethodGet, "/client/dfs?x=1", nil)
+	r.Header.Set("X-Path", "/Foo")
+	assert.Nil(t, c.SignRequest(r))
+
+	key, err := a.Authenticate(r, ScopeClient)
+	assert.Nil(t, err)
+	assert.Equal(t, "app", key.Id)
+
+	assert.Nil(t, c.SignRequest(r))
+	_, err = a.Authenticate(r, ScopeAdmin)
+	assert.Equal(t, errors.ErrForbidden, err)
+
+	// forwarding headers are not covered by the signature
+	assert.Nil(t, c.SignRequest(r))
+	r.Header.Set("X-Forwarded-For", "10.0.0.1")
+	_, err = a.Authenticate(r, ScopeClient)
+	assert.Nil(t, err)
+
+	assert.Nil(t, c.SignRequest(r))
+	r.Header.Set("X-Path", "/Bar")
+	_, err = a.Authenticate(r, ScopeClient)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	r = httptest.NewRequest(http.MethodGet, "/client/dfs", nil)
+	assert.Nil(t, (&Credentials{KeyId: "app", Secret: "wrong"}).SignRequest(r))
+	_, err = a.Authenticate(r, ScopeClient)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	r = httptest.NewRequest(http.MethodGet, "/client/dfs", nil)
+	assert.Nil(t, (&Credentials{KeyId: "unknown", Secret: "4pp"}).SignRequest(r))
+	_, err = a.Authenticate(r, ScopeClient)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	r = httptest.NewRequest(http.MethodGet, "/client/dfs", nil)
+	assert.Nil(t, c.SignRequest(r))
+	r.Header.Del(nonceHeader)
+	_, err = a.Authenticate(r, ScopeClient)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+}
+
+func TestAuthenticator_Authenticate_Replay(t *testing.T) {
+	a := testAuthenticator(t)
+	c := &Credentials{KeyId: "app", Secret: "4pp"}
+
+	r := httptest.NewRequest(http.MethodDelete, "/client/dfs", nil)
+	r.Header.Set("X-Path", "/Foo")
+	assert.Nil(t, c.SignRequest(r))
+
+	_, err := a.Authenticate(r, ScopeClient)
+	assert.Nil(t, err)
+
+	_, err = a.Authenticate(r, ScopeClient)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	// the same nonce can be used by another key
+	nonce := r.Header.Get(nonceHeader)
+	r.Header.Set(keyHeader, "tools")
+	r.Header.Set(signatureHeader, hex.EncodeToString(Sign("s3cr3t", r.Method, r.URL.RequestURI(), r.Header)))
+	assert.Equal(t, nonce, r.Header.Get(nonceHeader))
+	_, err = a.Authenticate(r, ScopeClient)
+	assert.Nil(t, err)
+}
+
+func TestAuthenticator_Authenticate_Content(t *testing.T) {
+	a := testAuthenticator(t)
+	c := &Credentials{KeyId: "app", Secret: "4pp"}
+
+	req, err := http.NewRequest(http.MethodPost, "/client/dfs", strings.NewReader("content"))
+	assert.Nil(t, err)
+	assert.Nil(t, c.SignRequest(req))
+
+	r := httptest.NewRequest(http.MethodPost, "/client/dfs", strings.NewReader("content"))
+	r.Header = req.Header.Clone()
+	_, err = a.Authenticate(r, ScopeClient)
+	assert.Nil(t, err)
+	content, err := io.ReadAll(r.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "content", string(content))
+
+	// the body is failed when it is not matching with the signed content hash
+	assert.Nil(t, c.SignRequest(req))
+	r = httptest.NewRequest(http.MethodPost, "/client/dfs", strings.NewReader("changed"))
+	r.Header = req.Header.Clone()
+	_, err = a.Authenticate(r, ScopeClient)
+	assert.Nil(t, err)
+	_, err = io.ReadFull(r.Body, make([]byte, 7))
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	// the body that can not be read again is not hashed
+	req, err = http.NewRequest(http.MethodPost, "/client/dfs", io.NopCloser(strings.NewReader("content")))
+	assert.Nil(t, err)
+	assert.Nil(t, c.SignRequest(req))
+	assert.Equal(t, UnsignedPayload, req.Header.Get(contentSha256Header))
+
+	r = httptest.NewRequest(http.MethodPost, "/client/dfs", strings.NewReader("changed"))
+	r.Header = req.Header.Clone()
+	_, err = a.Authenticate(r, ScopeClient)
+	assert.Nil(t, err)
+	content, err = io.ReadAll(r.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "changed", string(content))
+}
+
+func TestAuthenticator_Authenticate_Expired(t *testing.T) {
+	a := testAuthenticator(t)
+
+	r := httptest.NewRequest(http.MethodDelete, "/client/manager", nil)
+	r.Header.Set(keyHeader, "tools")
+	r.Header.Set(timestampHeader, strconv.FormatInt(time.Now().Add(-MaxTimeSkew*2).Unix(), 10))
+	r.Header.Set(nonceHeader, "0123456789abcdef")
+	r.Header.Set(contentSha256Header, UnsignedPayload)
+	signature := Sign("s3cr3t", r.Method, r.URL.RequestURI(), r.Header)
+	r.Header.Set(signatureHeader, hex.EncodeToString(signature))
+
+	_, err := a.Authenticate(r, ScopeAdmin)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+}
+
+func TestAuthenticator_Authenticate_Static(t *testing.T) {
+	a := testAuthenticator(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/client/dfs", nil)
+	_, err := a.Authenticate(r, ScopeClient)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	r.Header.Set(apiKeyHeader, "tools:s3cr3t")
+	key, err := a.Authenticate(r, ScopeAdmin)
+	assert.Nil(t, err)
+	assert.Equal(t, "tools", key.Id)
+
+	r.Header.Set(apiKeyHeader, "tools:wrong")
+	_, err = a.Authenticate(r, ScopeAdmin)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	r = httptest.NewRequest(http.MethodGet, "/client/dav/", nil)
+	r.SetBasicAuth("app", "4pp")
+	key, err = a.Authenticate(r, ScopeClient)
+	assert.Nil(t, err)
+	assert.Equal(t, "app", key.Id)
+	_, err = a.Authenticate(r, ScopeAdmin)
+	assert.Equal(t, errors.ErrForbidden, err)
+}
+
+func TestNewTransport(t *testing.T) {
+	a := testAuthenticator(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := a.Authenticate(r, ScopeClient); err != nil {
+			w.WriteHeader(401)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/client/dfs", nil)
+	assert.Nil(t, err)
+	req.Header.Set("X-Path", "/Foo")
+
+	client := http.Client{Transport: NewTransport(nil, nil)}
+	res, err := client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 401, res.StatusCode)
+
+	client = http.Client{Transport: NewTransport(&Credentials{KeyId: "app", Secret: "4pp"}, nil)}
+	res, err = client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Empty(t, req.Header.Get(signatureHeader))
+}
+
+func TestFromContext(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/client/dfs", nil)
+	assert.Nil(t, FromContext(r.Context()))
+
+	key := &Key{Id: "app", Secret: "4pp"}
+	r = r.WithContext(NewContext(r.Context(), key))
+	assert.Equal(t, key, FromContext(r.Context()))
+	assert.False(t, IsPresigned(r.Context()))
+
+	r = r.WithContext(NewPresignedContext(r.Context()))
+	assert.True(t, IsPresigned(r.Context()))
+}
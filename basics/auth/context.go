@@ -0,0 +1,29 @@
+package auth
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext creates the context that carries the authenticated key of the request
+func NewContext(ctx context.Context, key *Key) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// FromContext gets the authenticated key of the request. It returns nil if the authentication is disabled
+func FromContext(ctx context.Context) *Key {
+	key, _ := ctx.Value(contextKey{}).(*Key)
+	return key
+}
+
+type presignedContextKey struct{}
+
+// NewPresignedContext creates the context that marks the request is authorized with a presigned url
+func NewPresignedContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, presignedContextKey{}, true)
+}
+
+// IsPresigned checks the request is authorized with a presigned url
+func IsPresigned(ctx context.Context) bool {
+	presigned, _ := ctx.Value(presignedContextKey{}).(bool)
+	return presigned
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyHeader        = "X-Api-Key"
+	keyHeader           = "X-Auth-Key"
+	timestampHeader     = "X-Auth-Timestamp"
+	nonceHeader         = "X-Auth-Nonce"
+	contentSha256Header = "X-Auth-Content-Sha256"
+	signatureHeader     = "X-Auth-Signature"
+)
+
+// UnsignedPayload is the content hash of the requests that stream their body, so the body is not covered by the
+// signature. The nonce still prevents the replay of the request
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// Credentials struct is to hold the key to sign the requests
+type Credentials struct {
+	KeyId  string
+	Secret string
+}
+
+// ParseCredentials creates the credentials from the value in [keyId]:[secret] format
+func ParseCredentials(value string) (*Credentials, error) {
+	idx := strings.Index(value, ":")
+	if idx < 1 || idx == len(value)-1 {
+		return nil, os.ErrInvalid
+	}
+
+	return &Credentials{
+		KeyId:  value[:idx],
+		Secret: value[idx+1:],
+	}, nil
+}
+
+// SignRequest adds the signature headers of the credentials to the request. Body of the request is hashed when it
+// can be read again (GetBody), otherwise it is sent as UnsignedPayload
+func (c *Credentials) SignRequest(req *http.Request) error {
+	contentSha256, err := requestContentSha256(req)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set(keyHeader, c.KeyId)
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(nonceHeader, hex.EncodeToString(nonce))
+	req.Header.Set(contentSha256Header, contentSha256)
+	req.Header.Set(signatureHeader, hex.EncodeToString(Sign(c.Secret, req.Method, req.URL.RequestURI(), req.Header)))
+
+	return nil
+}
+
+func requestContentSha256(req *http.Request) (string, error) {
+	body := io.Reader(req.Body)
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return UnsignedPayload, nil
+		}
+
+		reader, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = reader.Close() }()
+
+		body = reader
+	}
+
+	h := sha256.New()
+	if body != nil {
+		if _, err := io.Copy(h, body); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Sign calculates the HMAC-SHA256 signature of the request with the secret. The signature covers the method,
+// the request uri, the timestamp, the nonce, the content hash and the X- prefixed headers except the auth and
+// the forwarding headers
+func Sign(secret string, method string, requestUri string, header http.Header) []byte {
+	names := make([]string, 0)
+	for name := range header {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, "x-") || strings.HasPrefix(name, "x-auth-") || strings.HasPrefix(name, "x-forwarded-") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(method)
+	sb.WriteString("\n")
+	sb.WriteString(requestUri)
+	sb.WriteString("\n")
+	sb.WriteString(header.Get(timestampHeader))
+	sb.WriteString("\n")
+	sb.WriteString(header.Get(nonceHeader))
+	sb.WriteString("\n")
+	sb.WriteString(header.Get(contentSha256Header))
+	sb.WriteString("\n")
+	for _, name := range names {
+		sb.WriteString(name)
+		sb.WriteString(":")
+		sb.WriteString(strings.Join(header.Values(name), ","))
+		sb.WriteString("\n")
+	}
+
+	h := hmac.New(sha256.New, []byte(secret))
+	_, _ = h.Write([]byte(sb.String()))
+
+	return h.Sum(nil)
+}
+
+type transport struct {
+	credentials *Credentials
+	base        http.RoundTripper
+}
+
+// NewTransport creates the round tripper that signs the requests with the credentials before
+// passing them to the base. It returns the base as it is if there is no credentials
+func NewTransport(credentials *Credentials, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if credentials == nil {
+		return base
+	}
+
+	return &transport{
+		credentials: credentials,
+		base:        base,
+	}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// round tripper should not modify the request
+	signedReq := req.Clone(req.Context())
+	if err := t.credentials.SignRequest(signedReq); err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+
+	return t.base.RoundTrip(signedReq)
+}
+
+var _ http.RoundTripper = &transport{}
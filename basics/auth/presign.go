@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+)
+
+// MaxPresignExpiry is the longest duration that a presigned url can stay valid
+const MaxPresignExpiry = time.Hour * 24 * 7
+
+// MinPresignSecretLength is the shortest shared secret that the presigned urls can be signed with
+const MinPresignSecretLength = 16
+
+const (
+	presignPathParam      = "path"
+	presignIssuerParam    = "issuer"
+	presignExpiresParam   = "expires"
+	presignSignatureParam = "signature"
+)
+
+// Presigner creates and validates the urls that grant the access to a file storage path with the
+// http method until the expiry time without any other credentials. The issuer key id is bound to the
+// url to evaluate the access lists of the issuer when the url is used
+type Presigner struct {
+	secret string
+}
+
+// NewPresigner creates the presigner using the shared secret. It returns ErrInvalid if the secret is shorter
+// than MinPresignSecretLength
+func NewPresigner(secret string) (*Presigner, error) {
+	if len(secret) < MinPresignSecretLength {
+		return nil, os.ErrInvalid
+	}
+
+	return &Presigner{
+		secret: secret,
+	}, nil
+}
+
+// Presign creates the query of the url at urlPath that grants the method on the file storage path until expiresAt.
+// issuer is the key id that the access lists are evaluated for, empty issuer is not restricted by the access lists
+func (p *Presigner) Presign(method string, urlPath string, path string, issuer string, expiresAt time.Time) url.Values {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set(presignPathParam, path)
+	if len(issuer) > 0 {
+		query.Set(presignIssuerParam, issuer)
+	}
+	query.Set(presignExpiresParam, expires)
+	query.Set(presignSignatureParam, hex.EncodeToString(p.sign(method, urlPath, path, issuer, expires)))
+
+	return query
+}
+
+// Validate checks the presigned url of the request and returns the file storage path that the url grants the
+// access and the issuer key id. It returns empty path if the request is not presigned and ErrUnauthorized if the
+// signature is not valid or expired
+func (p *Presigner) Validate(r *http.Request) (string, string, error) {
+	query := r.URL.Query()
+
+	signature := query.Get(presignSignatureParam)
+	if len(signature) == 0 {
+		return "", "", nil
+	}
+
+	path := query.Get(presignPathParam)
+	issuer := query.Get(presignIssuerParam)
+	expires := query.Get(presignExpiresParam)
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || len(path) == 0 {
+		return "", "", errors.ErrUnauthorized
+	}
+
+	expiresAt := time.Unix(unix, 0)
+	if time.Now().After(expiresAt) || time.Until(expiresAt) > MaxPresignExpiry {
+		return "", "", errors.ErrUnauthorized
+	}
+
+	expected := p.sign(r.Method, r.URL.Path, path, issuer, expires)
+	provided, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, provided) {
+		return "", "", errors.ErrUnauthorized
+	}
+
+	return path, issuer, nil
+}
+
+// sign calculates the HMAC-SHA256 signature of the method, the url path, the file storage path, the issuer and
+// the expiry
+func (p *Presigner) sign(method string, urlPath string, path string, issuer string, expires string) []byte {
+	var sb strings.Builder
+	sb.WriteString(method)
+	sb.WriteString("\n")
+	sb.WriteString(urlPath)
+	sb.WriteString("\n")
+	sb.WriteString(path)
+	sb.WriteString("\n")
+	sb.WriteString(issuer)
+	sb.WriteString("\n")
+	sb.WriteString(expires)
+	sb.WriteString("\n")
+
+	h := hmac.New(sha256.New, []byte(p.secret))
+	_, _ = h.Write([]byte(sb.String()))
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPresigner(t *testing.T) {
+	_, err := NewPresigner("")
+	assert.NotNil(t, err)
+
+	_, err = NewPresigner("pr3s1gn")
+	assert.NotNil(t, err)
+}
+
+func TestPresigner_Validate(t *testing.T) {
+	p, err := NewPresigner("pr3s1gn-s3cr3t-k3y")
+	assert.Nil(t, err)
+
+	query := p.Presign(http.MethodGet, "/client/dfs", "/Foo/Bar 1.jpg", "", time.Now().Add(time.Hour))
+
+	r := httptest.NewRequest(http.MethodGet, "/client/dfs?"+query.Encode(), nil)
+	path, issuer, err := p.Validate(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "/Foo/Bar 1.jpg", path)
+	assert.Empty(t, issuer)
+
+	// method is bound to the signature
+	r = httptest.NewRequest(http.MethodPost, "/client/dfs?"+query.Encode(), nil)
+	_, _, err = p.Validate(r)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	// url path is bound to the signature
+	r = httptest.NewRequest(http.MethodGet, "/client/upload?"+query.Encode(), nil)
+	_, _, err = p.Validate(r)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	// file storage path is bound to the signature
+	query.Set(presignPathParam, "/Foo/Bar 2.jpg")
+	r = httptest.NewRequest(http.MethodGet, "/client/dfs?"+query.Encode(), nil)
+	_, _, err = p.Validate(r)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	other, err := NewPresigner("other-s3cr3t-k3y!")
+	assert.Nil(t, err)
+	query = other.Presign(http.MethodGet, "/client/dfs", "/Foo/Bar 1.jpg", "", time.Now().Add(time.Hour))
+	r = httptest.NewRequest(http.MethodGet, "/client/dfs?"+query.Encode(), nil)
+	_, _, err = p.Validate(r)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	r = httptest.NewRequest(http.MethodGet, "/client/dfs", nil)
+	path, _, err = p.Validate(r)
+	assert.Nil(t, err)
+	assert.Empty(t, path)
+}
+
+func TestPresigner_Validate_Issuer(t *testing.T) {
+	p, err := NewPresigner("pr3s1gn-s3cr3t-k3y")
+	assert.Nil(t, err)
+
+	query := p.Presign(http.MethodGet, "/client/dfs", "/Foo/Bar.jpg", "client-1", time.Now().Add(time.Hour))
+
+	r := httptest.NewRequest(http.MethodGet, "/client/dfs?"+query.Encode(), nil)
+	_, issuer, err := p.Validate(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "client-1", issuer)
+
+	// issuer is bound to the signature
+	query.Set(presignIssuerParam, "client-2")
+	r = httptest.NewRequest(http.MethodGet, "/client/dfs?"+query.Encode(), nil)
+	_, _, err = p.Validate(r)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	query.Del(presignIssuerParam)
+	r = httptest.NewRequest(http.MethodGet, "/client/dfs?"+query.Encode(), nil)
+	_, _, err = p.Validate(r)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+}
+
+func TestPresigner_Validate_Expiry(t *testing.T) {
+	p, err := NewPresigner("pr3s1gn-s3cr3t-k3y")
+	assert.Nil(t, err)
+
+	query := p.Presign(http.MethodPost, "/client/dfs", "/Foo/Bar.jpg", "", time.Now().Add(-time.Second))
+	r := httptest.NewRequest(http.MethodPost, "/client/dfs?"+query.Encode(), nil)
+	_, _, err = p.Validate(r)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+
+	query = p.Presign(http.MethodPost, "/client/dfs", "/Foo/Bar.jpg", "", time.Now().Add(MaxPresignExpiry*2))
+	r = httptest.NewRequest(http.MethodPost, "/client/dfs?"+query.Encode(), nil)
+	_, _, err = p.Validate(r)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+}
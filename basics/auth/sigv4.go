@@ -0,0 +1,294 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+)
+
+const (
+	sigV4Algorithm      = "AWS4-HMAC-SHA256"
+	sigV4ChunkAlgorithm = "AWS4-HMAC-SHA256-PAYLOAD"
+	sigV4Terminator     = "aws4_request"
+	sigV4Service        = "s3"
+	sigV4TimeFormat     = "20060102T150405Z"
+	sigV4DateFormat     = "20060102"
+
+	amzDateHeader          = "X-Amz-Date"
+	amzContentSha256Header = "X-Amz-Content-Sha256"
+)
+
+// StreamingSignedPayload is the content hash of the S3 requests that send their body in aws-chunked encoding with
+// the chained signatures of the chunks
+const StreamingSignedPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+// StreamingUnsignedPayload is the content hash of the S3 requests that send their body in aws-chunked encoding
+// without the signatures of the chunks, the body is not covered by the signature as in UnsignedPayload
+const StreamingUnsignedPayload = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+
+// sigV4Authorization is the parsed authorization header of the AWS signature version 4
+type sigV4Authorization struct {
+	keyId         string
+	scope         string
+	date          string
+	region        string
+	signedHeaders []string
+	signature     []byte
+}
+
+// AuthenticateS3 validates the AWS signature version 4 of the S3 request using the key id as the access key id
+// and the secret of the key as the secret access key. Payload is verified while the body is read when its hash
+// is signed. It returns ErrUnauthorized if the signature is not valid
+func (a *authenticator) AuthenticateS3(r *http.Request, scope Scope) (*Key, error) {
+	authorization, err := parseSigV4Authorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+
+	key, has := a.keys[authorization.keyId]
+	if !has {
+		return nil, errors.ErrUnauthorized
+	}
+
+	amzDate := r.Header.Get(amzDateHeader)
+	signedAt, err := time.Parse(sigV4TimeFormat, amzDate)
+	if err != nil || strings.Compare(signedAt.Format(sigV4DateFormat), authorization.date) != 0 {
+		return nil, errors.ErrUnauthorized
+	}
+
+	skew := time.Since(signedAt)
+	if skew > MaxTimeSkew || skew < -MaxTimeSkew {
+		return nil, errors.ErrUnauthorized
+	}
+
+	contentSha256 := r.Header.Get(amzContentSha256Header)
+	if len(contentSha256) == 0 {
+		return nil, errors.ErrUnauthorized
+	}
+
+	canonicalRequest := canonicalSigV4Request(r, authorization.signedHeaders, contentSha256)
+	if !hmac.Equal(signSigV4(key.Secret, authorization, amzDate, canonicalRequest), authorization.signature) {
+		return nil, errors.ErrUnauthorized
+	}
+
+	// streaming payloads are sent in aws-chunked encoding and their chunks are verified with ChunkSigner
+	if !strings.HasPrefix(contentSha256, "STREAMING-") && strings.Compare(contentSha256, UnsignedPayload) != 0 {
+		expectedSum, err := hex.DecodeString(contentSha256)
+		if err != nil || len(expectedSum) != sha256.Size {
+			return nil, errors.ErrUnauthorized
+		}
+		if r.Body == nil {
+			r.Body = http.NoBody
+		}
+		r.Body = &verifyingBody{body: r.Body, hash: sha256.New(), expected: expectedSum, remaining: r.ContentLength}
+	}
+
+	if !key.Has(scope) {
+		return nil, errors.ErrForbidden
+	}
+	return key, nil
+}
+
+// ChunkSigner creates the signer of the chunks of the streaming signed payload. The request should be already
+// authenticated with AuthenticateS3, the first chunk is chained to the signature of the request
+func (a *authenticator) ChunkSigner(r *http.Request) (*ChunkSigner, error) {
+	authorization, err := parseSigV4Authorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+
+	key, has := a.keys[authorization.keyId]
+	if !has {
+		return nil, errors.ErrUnauthorized
+	}
+
+	return &ChunkSigner{
+		signingKey: sigV4SigningKey(key.Secret, authorization),
+		amzDate:    r.Header.Get(amzDateHeader),
+		scope:      authorization.scope,
+		previous:   authorization.signature,
+	}, nil
+}
+
+// ChunkSigner verifies the chained signatures of the aws-chunked payload. Every chunk is signed with the signature
+// of the previous chunk and the payload is completed with the signature of the final empty chunk
+type ChunkSigner struct {
+	signingKey []byte
+	amzDate    string
+	scope      string
+	previous   []byte
+}
+
+// Verify checks the signature of the chunk with the sha256 sum of its content and chains the signature for the next
+// chunk. It returns false if the signature is not matching
+func (c *ChunkSigner) Verify(chunkSum []byte, signature []byte) bool {
+	emptySum := sha256.Sum256(nil)
+
+	stringToSign := fmt.Sprintf(
+		"%s\n%s\n%s\n%s\n%s\n%s",
+		sigV4ChunkAlgorithm, c.amzDate, c.scope, hex.EncodeToString(c.previous), hex.EncodeToString(emptySum[:]), hex.EncodeToString(chunkSum),
+	)
+
+	if !hmac.Equal(hmacSha256(c.signingKey, stringToSign), signature) {
+		return false
+	}
+	c.previous = signature
+
+	return true
+}
+
+// parseSigV4Authorization parses the authorization header in
+// "AWS4-HMAC-SHA256 Credential=[keyId]/[date]/[region]/s3/aws4_request, SignedHeaders=[h1;h2], Signature=[hex]" format
+func parseSigV4Authorization(value string) (*sigV4Authorization, error) {
+	if !strings.HasPrefix(value, fmt.Sprintf("%s ", sigV4Algorithm)) {
+		return nil, errors.ErrUnauthorized
+	}
+
+	fields := make(map[string]string)
+	for _, field := range strings.Split(value[len(sigV4Algorithm)+1:], ",") {
+		field = strings.TrimSpace(field)
+
+		equalIdx := strings.Index(field, "=")
+		if equalIdx < 1 {
+			return nil, errors.ErrUnauthorized
+		}
+		fields[field[:equalIdx]] = field[equalIdx+1:]
+	}
+
+	credential := strings.Split(fields["Credential"], "/")
+	if len(credential) != 5 || len(credential[0]) == 0 ||
+		strings.Compare(credential[3], sigV4Service) != 0 || strings.Compare(credential[4], sigV4Terminator) != 0 {
+		return nil, errors.ErrUnauthorized
+	}
+
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	hasHost := false
+	for _, signedHeader := range signedHeaders {
+		if strings.Compare(signedHeader, "host") == 0 {
+			hasHost = true
+		}
+	}
+	if !hasHost {
+		return nil, errors.ErrUnauthorized
+	}
+
+	signature, err := hex.DecodeString(fields["Signature"])
+	if err != nil || len(signature) != sha256.Size {
+		return nil, errors.ErrUnauthorized
+	}
+
+	return &sigV4Authorization{
+		keyId:         credential[0],
+		scope:         strings.Join(credential[1:], "/"),
+		date:          credential[1],
+		region:        credential[2],
+		signedHeaders: signedHeaders,
+		signature:     signature,
+	}, nil
+}
+
+func canonicalSigV4Request(r *http.Request, signedHeaders []string, contentSha256 string) string {
+	sb := strings.Builder{}
+
+	sb.WriteString(r.Method)
+	sb.WriteString("\n")
+
+	path := r.URL.Path
+	if len(path) == 0 {
+		path = "/"
+	}
+	sb.WriteString(sigV4Escape(path, false))
+	sb.WriteString("\n")
+
+	query, _ := url.ParseQuery(r.URL.RawQuery)
+	queryKeys := make([]string, 0)
+	for k := range query {
+		queryKeys = append(queryKeys, k)
+	}
+	sort.Strings(queryKeys)
+
+	queryPairs := make([]string, 0)
+	for _, k := range queryKeys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			queryPairs = append(queryPairs, fmt.Sprintf("%s=%s", sigV4Escape(k, true), sigV4Escape(v, true)))
+		}
+	}
+	sb.WriteString(strings.Join(queryPairs, "&"))
+	sb.WriteString("\n")
+
+	for _, name := range signedHeaders {
+		values := r.Header.Values(name)
+		if strings.Compare(name, "host") == 0 {
+			values = []string{r.Host}
+		}
+
+		trimmed := make([]string, 0)
+		for _, v := range values {
+			trimmed = append(trimmed, strings.Join(strings.Fields(v), " "))
+		}
+
+		sb.WriteString(name)
+		sb.WriteString(":")
+		sb.WriteString(strings.Join(trimmed, ","))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString(strings.Join(signedHeaders, ";"))
+	sb.WriteString("\n")
+	sb.WriteString(contentSha256)
+
+	return sb.String()
+}
+
+// sigV4Escape encodes the value as the AWS signature expects, the unreserved characters are kept as they are and
+// the others are percent encoded in upper case. Slash is kept in the paths
+func sigV4Escape(value string, encodeSlash bool) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !encodeSlash {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%%%02X", c))
+	}
+	return sb.String()
+}
+
+// signSigV4 calculates the signature of the canonical request with the signing key that is derived for the scope
+func signSigV4(secret string, authorization *sigV4Authorization, amzDate string, canonicalRequest string) []byte {
+	canonicalRequestSum := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := fmt.Sprintf(
+		"%s\n%s\n%s\n%s",
+		sigV4Algorithm, amzDate, authorization.scope, hex.EncodeToString(canonicalRequestSum[:]),
+	)
+
+	return hmacSha256(sigV4SigningKey(secret, authorization), stringToSign)
+}
+
+// sigV4SigningKey derives the signing key of the secret for the scope of the authorization
+func sigV4SigningKey(secret string, authorization *sigV4Authorization) []byte {
+	signingKey := hmacSha256([]byte(fmt.Sprintf("AWS4%s", secret)), authorization.date)
+	signingKey = hmacSha256(signingKey, authorization.region)
+	signingKey = hmacSha256(signingKey, sigV4Service)
+	return hmacSha256(signingKey, sigV4Terminator)
+}
+
+func hmacSha256(key []byte, value string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(value))
+	return h.Sum(nil)
+}
@@ -0,0 +1,83 @@
+package common
+
+import (
+	"os"
+	"strings"
+)
+
+// Permission is the group of the operations that the access entry allows in the folder tree
+type Permission string
+
+const (
+	PermissionRead   Permission = "read"   // Listing, searching and reading the folders, files and versions
+	PermissionWrite  Permission = "write"  // Creating, changing and restoring the folders and files
+	PermissionDelete Permission = "delete" // Deleting the folders, files, versions and trash entries
+	PermissionManage Permission = "manage" // Managing the hooks, quotas and versioning of the folders
+)
+
+// AnyIdentity matches all the identities in the access entry
+const AnyIdentity = "*"
+
+// AccessEntry struct is to hold the permissions of the identity in the folder tree
+// Identity is the key id of the authentication or AnyIdentity
+type AccessEntry struct {
+	Identity    string       `json:"identity"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// AccessList is the definition of the pointer array of AccessEntry struct
+// It is attached to the folder and inherited by the sub folders till another one is attached
+type AccessList []*AccessEntry
+
+// ParsePermission validates and creates the permission from the value
+func ParsePermission(value string) (Permission, error) {
+	permission := Permission(strings.ToLower(value))
+	switch permission {
+	case PermissionRead, PermissionWrite, PermissionDelete, PermissionManage:
+		return permission, nil
+	}
+	return "", os.ErrInvalid
+}
+
+// Validate checks the entries have identity and known permissions
+func (a AccessList) Validate() error {
+	identities := make(map[string]bool)
+
+	for _, entry := range a {
+		if entry == nil || len(entry.Identity) == 0 || identities[entry.Identity] {
+			return os.ErrInvalid
+		}
+		identities[entry.Identity] = true
+
+		for _, permission := range entry.Permissions {
+			if _, err := ParsePermission(string(permission)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Allows checks the identity has the permission in the access list. The entry of the identity
+// is used if it exists, otherwise the entry of AnyIdentity is used
+func (a AccessList) Allows(identity string, permission Permission) bool {
+	var anyEntry *AccessEntry
+	for _, entry := range a {
+		if strings.Compare(entry.Identity, identity) == 0 {
+			return entry.has(permission)
+		}
+		if strings.Compare(entry.Identity, AnyIdentity) == 0 {
+			anyEntry = entry
+		}
+	}
+	return anyEntry != nil && anyEntry.has(permission)
+}
+
+func (e *AccessEntry) has(permission Permission) bool {
+	for _, p := range e.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
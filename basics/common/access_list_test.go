@@ -0,0 +1,56 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePermission(t *testing.T) {
+	permission, err := ParsePermission("Write")
+	assert.Nil(t, err)
+	assert.Equal(t, PermissionWrite, permission)
+
+	_, err = ParsePermission("execute")
+	assert.NotNil(t, err)
+}
+
+func TestAccessList_Validate(t *testing.T) {
+	acl := AccessList{
+		{Identity: "app", Permissions: []Permission{PermissionRead, PermissionWrite}},
+		{Identity: AnyIdentity, Permissions: []Permission{PermissionRead}},
+	}
+	assert.Nil(t, acl.Validate())
+
+	acl = AccessList{
+		{Identity: "app", Permissions: []Permission{PermissionRead}},
+		{Identity: "app", Permissions: []Permission{PermissionWrite}},
+	}
+	assert.NotNil(t, acl.Validate())
+
+	acl = AccessList{{Identity: "", Permissions: []Permission{PermissionRead}}}
+	assert.NotNil(t, acl.Validate())
+
+	acl = AccessList{{Identity: "app", Permissions: []Permission{"execute"}}}
+	assert.NotNil(t, acl.Validate())
+}
+
+func TestAccessList_Allows(t *testing.T) {
+	acl := AccessList{
+		{Identity: "reader", Permissions: []Permission{PermissionRead}},
+		{Identity: "blocked", Permissions: []Permission{}},
+		{Identity: AnyIdentity, Permissions: []Permission{PermissionRead, PermissionWrite}},
+	}
+
+	assert.True(t, acl.Allows("reader", PermissionRead))
+	assert.False(t, acl.Allows("reader", PermissionWrite))
+	assert.False(t, acl.Allows("reader", PermissionDelete))
+
+	// identity entry overrides the any identity entry
+	assert.False(t, acl.Allows("blocked", PermissionRead))
+
+	assert.True(t, acl.Allows("other", PermissionWrite))
+	assert.False(t, acl.Allows("other", PermissionManage))
+
+	assert.False(t, AccessList{}.Allows("reader", PermissionRead))
+}
@@ -0,0 +1,21 @@
+package common
+
+import "os"
+
+// Limits of the block size. Files are cut into the chunks in the block size and the last chunk can be smaller
+const (
+	MinBlockSize     uint32 = 1024 * 1024       // 1Mb
+	DefaultBlockSize uint32 = 1024 * 1024 * 32  // 32Mb
+	MaxBlockSize     uint32 = 1024 * 1024 * 256 // 256Mb
+)
+
+// ValidateBlockSize checks the block size is in the limits. Zero is valid and it stands for the default block size
+func ValidateBlockSize(blockSize uint32) error {
+	if blockSize == 0 {
+		return nil
+	}
+	if blockSize < MinBlockSize || blockSize > MaxBlockSize {
+		return os.ErrInvalid
+	}
+	return nil
+}
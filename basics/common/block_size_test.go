@@ -0,0 +1,17 @@
+package common
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBlockSize(t *testing.T) {
+	assert.Nil(t, ValidateBlockSize(0))
+	assert.Nil(t, ValidateBlockSize(MinBlockSize))
+	assert.Nil(t, ValidateBlockSize(DefaultBlockSize))
+	assert.Nil(t, ValidateBlockSize(MaxBlockSize))
+	assert.Equal(t, os.ErrInvalid, ValidateBlockSize(MinBlockSize-1))
+	assert.Equal(t, os.ErrInvalid, ValidateBlockSize(MaxBlockSize+1))
+}
@@ -42,6 +42,14 @@ type Cluster struct {
 	Nodes        NodeList     `json:"nodes"`
 	Reservations Reservations `json:"reservations"`
 
+	// Uncompressed size of the stored blocks when the data nodes compress the blocks.
+	// It is reported by the master node on cluster sync
+	Logical uint64 `json:"logical"`
+
+	// Chunks are kept as the Reed-Solomon shards on the nodes instead of the full copies when it is set.
+	// Size and Used are the chunk capacity of the cluster that is the data shard count times of the node figures
+	Erasure *ErasureCoding `json:"erasure,omitempty"`
+
 	// If master node is unreachable and also unable to elect a new master in the cluster
 	Paralyzed bool `json:"paralyzed"`
 
@@ -209,6 +217,27 @@ func (c *Cluster) Master() *Node {
 	return nil
 }
 
+// ErasureCoded checks the chunks of the cluster are kept as the shards
+func (c *Cluster) ErasureCoded() bool {
+	return c.Erasure != nil
+}
+
+// ShardNodes returns the nodes in the shard order of the erasure coded cluster.
+// Entry of the shard is nil when the shard does not have a node in the cluster
+func (c *Cluster) ShardNodes() NodeList {
+	if !c.ErasureCoded() {
+		return nil
+	}
+
+	shardNodes := make(NodeList, c.Erasure.Shards())
+	for _, n := range c.Nodes {
+		if int(n.Shard) < len(shardNodes) {
+			shardNodes[n.Shard] = n
+		}
+	}
+	return shardNodes
+}
+
 // Slaves returns only the slave nodes other than the master node
 func (c *Cluster) Slaves() NodeList {
 	slaves := make(NodeList, 0)
@@ -5,8 +5,11 @@ package common
 // Id is the cluster id where the File Chunk is located
 // Address is the node address in the cluster that requester can reach and read the chunk
 // Chunk is the information of chunk to read
+// Erasure and Addresses are set when the cluster is erasure coded. Addresses are in the shard order
 type ClusterMap struct {
-	Id      string `json:"clusterId"`
-	Address string `json:"address"`
-	Chunk   Chunk  `json:"chunk"`
+	Id        string         `json:"clusterId"`
+	Address   string         `json:"address"`
+	Chunk     Chunk          `json:"chunk"`
+	Erasure   *ErasureCoding `json:"erasure,omitempty"`
+	Addresses []string       `json:"addresses,omitempty"`
 }
@@ -11,6 +11,9 @@ type Node struct {
 	Master   bool      `json:"master"`
 	LeadTill time.Time `json:"leadTill"`
 	Quality  int64     `json:"quality"`
+
+	// Shard is the index of the shard that the node keeps in the erasure coded cluster
+	Shard uint8 `json:"shard"`
 }
 
 func (n *Node) LeadershipExpired() bool {
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MaxShards is the limit of the total shard count of the erasure coded cluster. Every shard is kept on
+// a different node, so it is also the node limit of the cluster
+const MaxShards = 32
+
+// ErasureCoding struct is to hold the Reed-Solomon layout of the erasure coded cluster.
+// Every chunk is split into the Data shards and the Parity shards are calculated from them,
+// so the chunk survives the loss of the Parity count of the nodes
+type ErasureCoding struct {
+	Data   uint8 `json:"data"`
+	Parity uint8 `json:"parity"`
+}
+
+// ParseErasureCoding validates and creates the erasure coding from the value in the data+parity
+// format. E.g. 4+2
+func ParseErasureCoding(value string) (*ErasureCoding, error) {
+	plusIdx := strings.Index(value, "+")
+	if plusIdx == -1 {
+		return nil, os.ErrInvalid
+	}
+
+	data, err := strconv.ParseUint(strings.TrimSpace(value[:plusIdx]), 10, 8)
+	if err != nil {
+		return nil, os.ErrInvalid
+	}
+
+	parity, err := strconv.ParseUint(strings.TrimSpace(value[plusIdx+1:]), 10, 8)
+	if err != nil {
+		return nil, os.ErrInvalid
+	}
+
+	e := &ErasureCoding{
+		Data:   uint8(data),
+		Parity: uint8(parity),
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Validate checks the shard counts. There should be at least two data shards and one parity shard,
+// otherwise the full replication is the better choice
+func (e *ErasureCoding) Validate() error {
+	if e.Data < 2 || e.Parity < 1 || e.Shards() > MaxShards {
+		return os.ErrInvalid
+	}
+	return nil
+}
+
+// Shards returns the total shard count that is also the node count of the cluster
+func (e *ErasureCoding) Shards() int {
+	return int(e.Data) + int(e.Parity)
+}
+
+// ShardSize calculates the size of each shard of the chunk. The last data shard is padded with zeros
+// when the chunk size is not divisible by the data shard count
+func (e *ErasureCoding) ShardSize(chunkSize uint32) uint32 {
+	return (chunkSize + uint32(e.Data) - 1) / uint32(e.Data)
+}
+
+func (e *ErasureCoding) String() string {
+	return fmt.Sprintf("%d+%d", e.Data, e.Parity)
+}
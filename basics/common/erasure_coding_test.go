@@ -0,0 +1,36 @@
+package common
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErasureCoding(t *testing.T) {
+	e, err := ParseErasureCoding("4+2")
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(4), e.Data)
+	assert.Equal(t, uint8(2), e.Parity)
+	assert.Equal(t, 6, e.Shards())
+	assert.Equal(t, "4+2", e.String())
+
+	e, err = ParseErasureCoding(" 10 + 4 ")
+	assert.Nil(t, err)
+	assert.Equal(t, 14, e.Shards())
+
+	for _, value := range []string{"", "4", "4+", "+2", "a+b", "1+2", "4+0", "30+3", "300+1"} {
+		_, err = ParseErasureCoding(value)
+		assert.Equal(t, os.ErrInvalid, err, value)
+	}
+}
+
+func TestErasureCoding_ShardSize(t *testing.T) {
+	e := &ErasureCoding{Data: 4, Parity: 2}
+
+	assert.Equal(t, uint32(0), e.ShardSize(0))
+	assert.Equal(t, uint32(1), e.ShardSize(1))
+	assert.Equal(t, uint32(1), e.ShardSize(4))
+	assert.Equal(t, uint32(2), e.ShardSize(5))
+	assert.Equal(t, uint32(8388608), e.ShardSize(DefaultBlockSize))
+}
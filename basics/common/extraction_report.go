@@ -0,0 +1,64 @@
+package common
+
+// Extraction results of the archive entries
+const (
+	ERCreated     = "created"
+	EROverwritten = "overwritten"
+	ERSkipped     = "skipped"
+	ERFailed      = "failed"
+)
+
+// ExtractionReport struct is to hold the results of the archive entries that are extracted into the folder
+type ExtractionReport struct {
+	Created     int               `json:"created"`
+	Overwritten int               `json:"overwritten"`
+	Skipped     int               `json:"skipped"`
+	Failed      int               `json:"failed"`
+	Entries     ExtractionEntries `json:"entries"`
+}
+
+// ExtractionEntry struct is to hold the result of the archive entry
+// Type is the kind of the entry. Values: folder or file
+type ExtractionEntry struct {
+	Full   string `json:"full"`
+	Type   string `json:"type"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ExtractionEntries is the definition of the pointer array of ExtractionEntry struct
+type ExtractionEntries []*ExtractionEntry
+
+// NewExtractionReport creates the empty extraction report
+func NewExtractionReport() *ExtractionReport {
+	return &ExtractionReport{
+		Entries: make(ExtractionEntries, 0),
+	}
+}
+
+// Add appends the result of the archive entry to the report and counts it. err is the reason of the skipped or
+// failed entry
+func (e *ExtractionReport) Add(full string, entryType string, result string, err error) {
+	entry := &ExtractionEntry{
+		Full:   full,
+		Type:   entryType,
+		Result: result,
+	}
+
+	switch result {
+	case ERCreated:
+		e.Created++
+	case EROverwritten:
+		e.Overwritten++
+	case ERSkipped:
+		e.Skipped++
+	case ERFailed:
+		e.Failed++
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	e.Entries = append(e.Entries, entry)
+}
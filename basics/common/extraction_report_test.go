@@ -0,0 +1,26 @@
+package common
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractionReport_Add(t *testing.T) {
+	report := NewExtractionReport()
+
+	report.Add("/Foo", "folder", ERCreated, nil)
+	report.Add("/Foo/a.txt", "file", EROverwritten, nil)
+	report.Add("/Foo/b.txt", "file", ERSkipped, os.ErrExist)
+	report.Add("/Foo/c.txt", "file", ERFailed, os.ErrInvalid)
+
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Overwritten)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+	assert.Len(t, report.Entries, 4)
+	assert.Empty(t, report.Entries[1].Error)
+	assert.Equal(t, os.ErrExist.Error(), report.Entries[2].Error)
+	assert.Equal(t, os.ErrInvalid.Error(), report.Entries[3].Error)
+}
@@ -11,17 +11,20 @@ import (
 )
 
 // File struct is to hold the actual file details in dfs cluster
+// Expires is the date that the file will be deleted, it is not set if the file does not have time-to-live
 type File struct {
 	Name     string     `json:"name"`
 	Mime     string     `json:"mime"`
 	Size     uint64     `json:"size"`
 	Checksum string     `json:"checksum"`
+	Metadata Metadata   `json:"metadata,omitempty"`
 	Created  time.Time  `json:"created"`
 	Modified time.Time  `json:"modified"`
 	Chunks   DataChunks `json:"chunks"`
 	Missing  DataChunks `json:"missing"`
 	Lock     *FileLock  `json:"lock"`
 	Zombie   bool       `json:"zombie"`
+	Expires  *time.Time `json:"expires,omitempty"`
 }
 
 // Files is the definition of the pointer array of File struct
@@ -36,6 +39,7 @@ func CreateJoinedFile(files Files) (*File, error) {
 	hash := md5.New()
 
 	mime := ""
+	metadata := make(Metadata)
 	sequenceCount := uint16(0)
 	joinedFile := newFile("")
 	for _, f := range files {
@@ -60,6 +64,13 @@ func CreateJoinedFile(files Files) (*File, error) {
 			joinedFile.Chunks = append(joinedFile.Chunks, &shadow)
 		}
 
+		// the metadata of the preceding file has the priority on the same key
+		for k, v := range f.Metadata {
+			if _, has := metadata[k]; !has {
+				metadata[k] = v
+			}
+		}
+
 		if len(mime) == 0 {
 			mime = f.Mime
 			continue
@@ -71,6 +82,7 @@ func CreateJoinedFile(files Files) (*File, error) {
 		mime = joinedFile.Mime
 	}
 	joinedFile.Mime = mime
+	joinedFile.Metadata = metadata.Clone()
 	joinedFile.Name = hex.EncodeToString(hash.Sum(nil))
 
 	return joinedFile, nil
@@ -171,16 +183,23 @@ func (f *File) Resurrect() {
 	f.Zombie = false
 }
 
+// Expired checks if the time-to-live of the file is over
+func (f *File) Expired() bool {
+	return f.Expires != nil && !f.Expires.After(time.Now().UTC())
+}
+
 // Locked checks if file is locked for any other operation
 func (f *File) Locked() bool {
-	return f.Lock != nil && f.Lock.Till.After(time.Now().UTC())
+	return f.Lock != nil && f.Lock.Active()
 }
 
 // Reset resets the file struct fields to default values
+// Expires is kept as it is, it is decided when the file entry is prepared
 func (f *File) Reset(mime string, size uint64) {
 	f.Mime = mime
 	f.Size = size
 	f.Checksum = EmptyChecksum()
+	f.Metadata = nil
 	f.Created = time.Now().UTC()
 	f.Modified = time.Now().UTC()
 	f.Chunks = make(DataChunks, 0)
@@ -198,7 +217,13 @@ func (f *File) CloneInto(target *File) {
 	target.Mime = f.Mime
 	target.Size = f.Size
 	target.Checksum = f.Checksum
+	target.Metadata = f.Metadata.Clone()
 	target.Lock = f.Lock
+	target.Expires = nil
+	if f.Expires != nil {
+		expires := *f.Expires
+		target.Expires = &expires
+	}
 
 	target.Chunks = make(DataChunks, 0)
 	for _, c := range f.Chunks {
@@ -0,0 +1,50 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile_Expired(t *testing.T) {
+	file := newFile("a.txt")
+	assert.False(t, file.Expired())
+
+	expires := time.Now().UTC().Add(time.Hour)
+	file.Expires = &expires
+	assert.False(t, file.Expired())
+
+	expires = time.Now().UTC().Add(-time.Second)
+	file.Expires = &expires
+	assert.True(t, file.Expired())
+}
+
+func TestFile_CloneIntoKeepsExpiry(t *testing.T) {
+	file := newFile("a.txt")
+	expires := time.Now().UTC().Add(time.Hour)
+	file.Expires = &expires
+
+	clone := newFile("b.txt")
+	file.CloneInto(clone)
+	assert.Equal(t, expires, *clone.Expires)
+}
+
+func TestFolder_DropExpiredFiles(t *testing.T) {
+	folder := NewFolder("/")
+	expired := time.Now().UTC().Add(-time.Second)
+	alive := time.Now().UTC().Add(time.Hour)
+
+	a, _ := folder.NewFile("a.txt")
+	a.Expires = &expired
+	_, _ = folder.NewFile("b.txt")
+	c, _ := folder.NewFile("c.txt")
+	c.Expires = &expired
+	d, _ := folder.NewFile("d.txt")
+	d.Expires = &alive
+
+	folder.DropExpiredFiles()
+	assert.Len(t, folder.Files, 2)
+	assert.NotNil(t, folder.File("b.txt"))
+	assert.NotNil(t, folder.File("d.txt"))
+}
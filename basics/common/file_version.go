@@ -0,0 +1,165 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileVersion struct is to hold the previous state of the file in the versioning enabled folder
+// Id is the version identity that is shared with the client
+// Archived is the date of the overwrite or the deletion that has created the version
+// Deleted shows that the version is created by the deletion of the file
+type FileVersion struct {
+	Id       string    `json:"id"`
+	Archived time.Time `json:"archived"`
+	Deleted  bool      `json:"deleted"`
+	File     *File     `json:"file"`
+}
+
+// FileVersions is the definition of the pointer array of FileVersion struct
+// the versions are sorted from the newest to the oldest
+type FileVersions []*FileVersion
+
+func (f FileVersions) Len() int           { return len(f) }
+func (f FileVersions) Less(i, j int) bool { return f[i].Archived.After(f[j].Archived) }
+func (f FileVersions) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+
+// newFileVersion creates the version of the file using a copy of the file struct. Chunk references
+// are moved to the version, so they are kept in the data nodes till the version is deleted
+func newFileVersion(file *File, deleted bool) (*FileVersion, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	shadow := *file
+	shadow.Metadata = file.Metadata.Clone()
+	shadow.Lock = nil
+
+	shadow.Chunks = make(DataChunks, 0)
+	for _, c := range file.Chunks {
+		chunk := *c
+		shadow.Chunks = append(shadow.Chunks, &chunk)
+	}
+
+	shadow.Missing = make(DataChunks, 0)
+	for _, c := range file.Missing {
+		chunk := *c
+		shadow.Missing = append(shadow.Missing, &chunk)
+	}
+
+	return &FileVersion{
+		Id:       hex.EncodeToString(b),
+		Archived: time.Now().UTC(),
+		Deleted:  deleted,
+		File:     &shadow,
+	}, nil
+}
+
+// ArchiveFile keeps the current state of the file as a version. If the file is not deleted,
+// the chunks of the file are emptied to make it ready for the new content placement
+func (f *Folder) ArchiveFile(file *File, deleted bool) error {
+	version, err := newFileVersion(file, deleted)
+	if err != nil {
+		return err
+	}
+
+	f.Versions = append(f.Versions, version)
+	sort.Sort(f.Versions)
+
+	if !deleted {
+		file.Chunks = make(DataChunks, 0)
+		file.Missing = make(DataChunks, 0)
+	}
+
+	return nil
+}
+
+// FileVersions filters the versions of the file by name
+func (f *Folder) FileVersions(name string) FileVersions {
+	versions := make(FileVersions, 0)
+	for _, v := range f.Versions {
+		if strings.Compare(v.File.Name, name) == 0 {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// FileVersion searches the version of the file by name and version id
+func (f *Folder) FileVersion(name string, versionId string) *FileVersion {
+	for _, v := range f.Versions {
+		if strings.Compare(v.File.Name, name) == 0 && strings.Compare(v.Id, versionId) == 0 {
+			return v
+		}
+	}
+	return nil
+}
+
+// RestoreFileVersion places the version of the file as the current file. If the file exists,
+// its current state is archived as a new version
+// if version isn't being found, it will return ErrNotExists error
+func (f *Folder) RestoreFileVersion(name string, versionId string) (*File, error) {
+	version := f.FileVersion(name, versionId)
+	if version == nil {
+		return nil, os.ErrNotExist
+	}
+
+	if current := f.File(name); current != nil {
+		if err := f.ArchiveFile(current, false); err != nil {
+			return nil, err
+		}
+	}
+
+	f.removeVersion(version)
+
+	restored := version.File
+	restored.Modified = time.Now().UTC()
+	f.ReplaceFile(name, restored)
+
+	return restored, nil
+}
+
+// DeleteFileVersions removes the versions of the file that are out of the newest keep count or archived
+// before the provided date. If both are not provided, all versions of the file are removed.
+// if deleteVersionHandler raises error, deletion of that version will be canceled
+func (f *Folder) DeleteFileVersions(name string, keep *int, before *time.Time, deleteVersionHandler func(*FileVersion) error) error {
+	for i, version := range f.FileVersions(name) {
+		expired := keep == nil && before == nil ||
+			keep != nil && i >= *keep ||
+			before != nil && version.Archived.Before(*before)
+		if !expired {
+			continue
+		}
+
+		if err := deleteVersionHandler(version); err != nil {
+			return err
+		}
+		f.removeVersion(version)
+	}
+	return nil
+}
+
+// FilesWithVersions collects the current files and the files of the versions
+// to be able to reach all the chunks that are referenced by the folder
+func (f *Folder) FilesWithVersions() Files {
+	files := make(Files, 0)
+	files = append(files, f.Files...)
+	for _, v := range f.Versions {
+		files = append(files, v.File)
+	}
+	return files
+}
+
+func (f *Folder) removeVersion(version *FileVersion) {
+	for i, v := range f.Versions {
+		if v == version {
+			f.Versions = append(f.Versions[:i], f.Versions[i+1:]...)
+			return
+		}
+	}
+}
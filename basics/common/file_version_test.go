@@ -0,0 +1,108 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createVersionedFolder() (*Folder, *File) {
+	folder := NewFolder("/")
+	folder.Versioning = true
+
+	file, _ := folder.NewFile("a.txt")
+	file.Checksum = "v1"
+	file.Chunks = DataChunks{NewDataChunk(0, 10, "hash-v1")}
+
+	return folder, file
+}
+
+func TestFolder_ArchiveFile(t *testing.T) {
+	folder, file := createVersionedFolder()
+
+	err := folder.ArchiveFile(file, false)
+	assert.Nil(t, err)
+	assert.Len(t, file.Chunks, 0)
+	assert.Len(t, folder.Versions, 1)
+
+	version := folder.Versions[0]
+	assert.NotEmpty(t, version.Id)
+	assert.False(t, version.Deleted)
+	assert.Equal(t, "v1", version.File.Checksum)
+	assert.Equal(t, "hash-v1", version.File.Chunks[0].Hash)
+	assert.Nil(t, version.File.Lock)
+
+	file.Checksum = "v2"
+	file.Chunks = DataChunks{NewDataChunk(0, 10, "hash-v2")}
+
+	err = folder.ArchiveFile(file, true)
+	assert.Nil(t, err)
+	assert.Len(t, file.Chunks, 1)
+
+	versions := folder.FileVersions("a.txt")
+	assert.Len(t, versions, 2)
+	assert.True(t, versions[0].Deleted)
+	assert.Equal(t, "v2", versions[0].File.Checksum)
+	assert.Equal(t, "v1", versions[1].File.Checksum)
+	assert.Len(t, folder.FileVersions("b.txt"), 0)
+}
+
+func TestFolder_RestoreFileVersion(t *testing.T) {
+	folder, file := createVersionedFolder()
+
+	_ = folder.ArchiveFile(file, false)
+	versionId := folder.Versions[0].Id
+
+	file.Checksum = "v2"
+	file.Chunks = DataChunks{NewDataChunk(0, 10, "hash-v2")}
+
+	_, err := folder.RestoreFileVersion("a.txt", "unknown")
+	assert.NotNil(t, err)
+
+	restored, err := folder.RestoreFileVersion("a.txt", versionId)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", restored.Checksum)
+	assert.Equal(t, "v1", folder.File("a.txt").Checksum)
+	assert.Len(t, folder.Files, 1)
+
+	versions := folder.FileVersions("a.txt")
+	assert.Len(t, versions, 1)
+	assert.Equal(t, "v2", versions[0].File.Checksum)
+	assert.Equal(t, "hash-v2", versions[0].File.Chunks[0].Hash)
+	assert.Len(t, folder.FilesWithVersions(), 2)
+}
+
+func TestFolder_DeleteFileVersions(t *testing.T) {
+	folder, file := createVersionedFolder()
+
+	for i := 0; i < 4; i++ {
+		_ = folder.ArchiveFile(file, false)
+	}
+	for i, version := range folder.Versions {
+		version.Archived = time.Date(2020, 1, 4-i, 0, 0, 0, 0, time.UTC)
+	}
+
+	deleted := 0
+	handler := func(version *FileVersion) error {
+		deleted++
+		return nil
+	}
+
+	keep := 3
+	err := folder.DeleteFileVersions("a.txt", &keep, nil, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Len(t, folder.Versions, 3)
+
+	before := time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)
+	err = folder.DeleteFileVersions("a.txt", nil, &before, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.Len(t, folder.Versions, 2)
+
+	err = folder.DeleteFileVersions("a.txt", nil, nil, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, deleted)
+	assert.Len(t, folder.Versions, 0)
+}
@@ -13,15 +13,29 @@ import (
 )
 
 // Folder struct is to hold the virtual folder details associated in dfs cluster
+// Versioning keeps the previous states of the overwritten and deleted files in Versions
+// Ttl is the default time-to-live of the files in the folder in seconds, zero means no expiry
+// Quota limits the usage of the folder including its sub folders
+// Acl restricts the access of the identities in the folder tree, nil inherits the access list of the parent folder
+// BlockSize is the default block size of the files in the folder, zero means the farm default
+// Locks are the WebDAV locks of the folder itself when it is the root folder and of the files and the sub folders
+// in it, the other folders keep their locks in their parent folder. Locks are not shared with the clients
 type Folder struct {
-	Full     string        `json:"full"`
-	Name     string        `json:"name"`
-	Created  time.Time     `json:"created"`
-	Modified time.Time     `json:"modified"`
-	Size     uint64        `json:"size" bson:"-"`
-	Folders  FolderShadows `json:"folders"`
-	Files    Files         `json:"files"`
-	Hooks    hooks.Hooks   `json:"hooks,omitempty"`
+	Full       string        `json:"full"`
+	Name       string        `json:"name"`
+	Created    time.Time     `json:"created"`
+	Modified   time.Time     `json:"modified"`
+	Size       uint64        `json:"size" bson:"-"`
+	Folders    FolderShadows `json:"folders"`
+	Files      Files         `json:"files"`
+	Hooks      hooks.Hooks   `json:"hooks,omitempty"`
+	Versioning bool          `json:"versioning"`
+	Ttl        uint64        `json:"ttl,omitempty"`
+	Quota      *Quota        `json:"quota,omitempty"`
+	Acl        AccessList    `json:"acl,omitempty"`
+	BlockSize  uint32        `json:"blockSize,omitempty"`
+	Locks      ResourceLocks `json:"-"`
+	Versions   FileVersions  `json:"-"`
 }
 
 // NewFolder creates a new empty Folder struct with folderPath
@@ -249,6 +263,18 @@ func (f *Folder) CloneInto(target *Folder) {
 	}
 }
 
+// DropExpiredFiles removes the files that are out of their time-to-live from the Folder struct
+// to keep them invisible till they are deleted from the dfs
+func (f *Folder) DropExpiredFiles() {
+	for i := 0; i < len(f.Files); i++ {
+		if !f.Files[i].Expired() {
+			continue
+		}
+		f.Files = append(f.Files[:i], f.Files[i+1:]...)
+		i--
+	}
+}
+
 // Locked checks if the folder has any locked File
 func (f *Folder) Locked() bool {
 	for _, file := range f.Files {
@@ -0,0 +1,185 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FolderListing struct is to hold the sorting, filtering and paging requirements of the folder listing
+// Sort is the field of the ordering. Values: name, size or modified. Default: name
+// Only is the entry type filter. Values: folders, files or empty for both
+// Limit is the maximum entry count of the page, zero means no limit
+type FolderListing struct {
+	Sort         string
+	Descending   bool
+	Only         string
+	Continuation string
+	Limit        int
+}
+
+// listingEntry is the sortable representation of the sub folder or the file in the listing
+// sub folders do not have modification tracking so the creation date is used
+type listingEntry struct {
+	Folder   bool      `json:"f"`
+	Name     string    `json:"n"`
+	Size     uint64    `json:"z"`
+	Modified time.Time `json:"m"`
+	Sort     string    `json:"s"`
+	Desc     bool      `json:"d"`
+
+	index int
+}
+
+// Valid checks if the listing requirements are supported
+func (l FolderListing) Valid() bool {
+	switch l.Sort {
+	case "", "name", "size", "modified":
+	default:
+		return false
+	}
+	switch l.Only {
+	case "", "folders", "files":
+	default:
+		return false
+	}
+	return l.Limit >= 0
+}
+
+// Total calculates the entry count of the folder that is matching with the entry type filter
+func (l FolderListing) Total(folder *Folder) int {
+	switch l.Only {
+	case "folders":
+		return len(folder.Folders)
+	case "files":
+		return len(folder.Files)
+	}
+	return len(folder.Folders) + len(folder.Files)
+}
+
+// less orders the sub folders before the files and uses the name when the sort field values are the same
+func (l FolderListing) less(a listingEntry, b listingEntry) bool {
+	if a.Folder != b.Folder {
+		return a.Folder
+	}
+
+	c := 0
+	switch l.Sort {
+	case "size":
+		if a.Size < b.Size {
+			c = -1
+		} else if a.Size > b.Size {
+			c = 1
+		}
+	case "modified":
+		if a.Modified.Before(b.Modified) {
+			c = -1
+		} else if a.Modified.After(b.Modified) {
+			c = 1
+		}
+	}
+	if c == 0 {
+		c = strings.Compare(a.Name, b.Name)
+	}
+
+	if l.Descending {
+		return c > 0
+	}
+	return c < 0
+}
+
+func (l FolderListing) token(entry listingEntry) string {
+	entry.Sort = l.Sort
+	entry.Desc = l.Descending
+
+	b, _ := json.Marshal(entry)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (l FolderListing) parseToken() (*listingEntry, error) {
+	if len(l.Continuation) == 0 {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(l.Continuation)
+	if err != nil {
+		return nil, os.ErrInvalid
+	}
+
+	var entry *listingEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, os.ErrInvalid
+	}
+
+	// continuation token can only be used with the same ordering
+	if strings.Compare(entry.Sort, l.Sort) != 0 || entry.Desc != l.Descending {
+		return nil, os.ErrInvalid
+	}
+	return entry, nil
+}
+
+// Page creates a Folder struct that holds the requested page of the sub folders and files and returns
+// the continuation token of the next page. Continuation token is empty for the last page
+func (f *Folder) Page(listing FolderListing) (*Folder, string, error) {
+	if !listing.Valid() {
+		return nil, "", os.ErrInvalid
+	}
+
+	after, err := listing.parseToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries := make([]listingEntry, 0)
+	if strings.Compare(listing.Only, "files") != 0 {
+		for i, shadow := range f.Folders {
+			entries = append(entries, listingEntry{Folder: true, Name: shadow.Name, Size: shadow.Size, Modified: shadow.Created, index: i})
+		}
+	}
+	if strings.Compare(listing.Only, "folders") != 0 {
+		for i, file := range f.Files {
+			entries = append(entries, listingEntry{Name: file.Name, Size: file.Size, Modified: file.Modified, index: i})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return listing.less(entries[i], entries[j]) })
+
+	page := &Folder{
+		Full:       f.Full,
+		Name:       f.Name,
+		Created:    f.Created,
+		Modified:   f.Modified,
+		Size:       f.Size,
+		Folders:    make(FolderShadows, 0),
+		Files:      make(Files, 0),
+		Hooks:      f.Hooks,
+		Versioning: f.Versioning,
+		Ttl:        f.Ttl,
+		Quota:      f.Quota,
+		Acl:        f.Acl,
+		BlockSize:  f.BlockSize,
+	}
+
+	count := 0
+	for i, entry := range entries {
+		if after != nil && !listing.less(*after, entry) {
+			continue
+		}
+
+		if listing.Limit > 0 && count == listing.Limit {
+			return page, listing.token(entries[i-1]), nil
+		}
+		count++
+
+		if entry.Folder {
+			page.Folders = append(page.Folders, f.Folders[entry.index])
+			continue
+		}
+		page.Files = append(page.Files, f.Files[entry.index])
+	}
+
+	return page, "", nil
+}
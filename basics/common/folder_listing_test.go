@@ -0,0 +1,86 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createListingFolder() *Folder {
+	modified := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	folder := NewFolder("/")
+	_, _ = folder.NewFolder("beta")
+	_, _ = folder.NewFolder("alpha")
+	folder.Folders[0].Created = modified
+	folder.Folders[1].Created = modified.Add(time.Minute)
+	for i, name := range []string{"c.txt", "a.txt", "d.txt", "b.txt"} {
+		file, _ := folder.NewFile(name)
+		file.Size = uint64(len(name) * (4 - i))
+		file.Modified = modified.Add(time.Hour * time.Duration(i))
+	}
+	return folder
+}
+
+func listingNames(folder *Folder) []string {
+	result := make([]string, 0)
+	for _, f := range folder.Folders {
+		result = append(result, f.Name)
+	}
+	for _, f := range folder.Files {
+		result = append(result, f.Name)
+	}
+	return result
+}
+
+func TestFolder_Page(t *testing.T) {
+	folder := createListingFolder()
+
+	page, next, err := folder.Page(FolderListing{})
+	assert.Nil(t, err)
+	assert.Empty(t, next)
+	assert.Equal(t, []string{"alpha", "beta", "a.txt", "b.txt", "c.txt", "d.txt"}, listingNames(page))
+
+	page, _, err = folder.Page(FolderListing{Sort: "size", Only: "files"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b.txt", "d.txt", "a.txt", "c.txt"}, listingNames(page))
+
+	page, _, err = folder.Page(FolderListing{Sort: "modified", Descending: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"beta", "alpha", "b.txt", "d.txt", "a.txt", "c.txt"}, listingNames(page))
+
+	page, _, err = folder.Page(FolderListing{Only: "folders"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"alpha", "beta"}, listingNames(page))
+
+	_, _, err = folder.Page(FolderListing{Sort: "checksum"})
+	assert.NotNil(t, err)
+}
+
+func TestFolder_PageContinuation(t *testing.T) {
+	folder := createListingFolder()
+
+	listing := FolderListing{Sort: "size", Descending: true, Limit: 4}
+
+	page, next, err := folder.Page(listing)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, next)
+	assert.Equal(t, []string{"beta", "alpha", "c.txt", "a.txt"}, listingNames(page))
+	assert.Equal(t, 6, listing.Total(folder))
+
+	// the listed entry is removed and a new entry is added before the next page is requested
+	folder.ReplaceFile("a.txt", nil)
+	file, _ := folder.NewFile("e.txt")
+	file.Size = 1
+
+	listing.Continuation = next
+	page, next, err = folder.Page(listing)
+	assert.Nil(t, err)
+	assert.Empty(t, next)
+	assert.Equal(t, []string{"d.txt", "b.txt", "e.txt"}, listingNames(page))
+
+	listing.Sort = "name"
+	_, _, err = folder.Page(listing)
+	assert.NotNil(t, err)
+}
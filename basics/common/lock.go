@@ -27,6 +27,11 @@ func NewFileLockForSize(size uint64) *FileLock {
 	return NewFileLock(time.Second * time.Duration(size))
 }
 
+// Active checks if the lock is still effective
+func (f *FileLock) Active() bool {
+	return f.Till.After(time.Now().UTC())
+}
+
 // Cancel cancels or expires the file lock and release it
 func (f *FileLock) Cancel() {
 	f.Till = time.Now().UTC()
@@ -0,0 +1,56 @@
+package common
+
+// has the http header size relation, metadata is served in the response headers
+const maxMetadataSize = 8 * 1024
+
+// Metadata is the definition of the user-defined key/value details of the file
+// keys are kept in lowercase to have the same behaviour with the case-insensitive http headers
+type Metadata map[string]string
+
+// Valid checks if the keys are formed by lowercase letters, digits, dash and underscore
+// and the total size of the metadata is in the limit
+func (m Metadata) Valid() bool {
+	size := 0
+	for k, v := range m {
+		if len(k) == 0 {
+			return false
+		}
+		for _, c := range k {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+				return false
+			}
+		}
+		size += len(k) + len(v)
+	}
+	return size <= maxMetadataSize
+}
+
+// Clone creates a copy of the metadata. Empty metadata is cloned as nil
+func (m Metadata) Clone() Metadata {
+	if len(m) == 0 {
+		return nil
+	}
+
+	clone := make(Metadata)
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// Merge creates a new metadata by applying the changes on the current metadata.
+// The key that has an empty value in changes is removed
+func (m Metadata) Merge(changes Metadata) Metadata {
+	merged := make(Metadata)
+	for k, v := range m {
+		merged[k] = v
+	}
+	for k, v := range changes {
+		if len(v) == 0 {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged.Clone()
+}
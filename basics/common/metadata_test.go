@@ -0,0 +1,59 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadata_Valid(t *testing.T) {
+	assert.True(t, Metadata{}.Valid())
+	assert.True(t, Metadata{"owner": "tuncay", "source-system": "crm", "content_language": "tr"}.Valid())
+	assert.False(t, Metadata{"Owner": "tuncay"}.Valid())
+	assert.False(t, Metadata{"owner.name": "tuncay"}.Valid())
+	assert.False(t, Metadata{"": "empty"}.Valid())
+	assert.False(t, Metadata{"big": strings.Repeat("x", maxMetadataSize)}.Valid())
+}
+
+func TestMetadata_Merge(t *testing.T) {
+	metadata := Metadata{"owner": "tuncay", "language": "tr"}
+
+	merged := metadata.Merge(Metadata{"language": "en", "source": "crm"})
+	assert.Equal(t, Metadata{"owner": "tuncay", "language": "en", "source": "crm"}, merged)
+	assert.Equal(t, "tr", metadata["language"])
+
+	merged = merged.Merge(Metadata{"owner": "", "source": ""})
+	assert.Equal(t, Metadata{"language": "en"}, merged)
+
+	assert.Nil(t, merged.Merge(Metadata{"language": ""}))
+	assert.Nil(t, Metadata{}.Clone())
+}
+
+func TestFile_Metadata(t *testing.T) {
+	first := newFile("first")
+	first.Chunks = DataChunks{NewDataChunk(0, 10, "hash0")}
+	first.Metadata = Metadata{"owner": "tuncay", "language": "tr"}
+	first.Zombie = false
+	first.Lock = nil
+
+	second := newFile("second")
+	second.Chunks = DataChunks{NewDataChunk(0, 10, "hash1")}
+	second.Metadata = Metadata{"language": "en", "source": "crm"}
+	second.Zombie = false
+	second.Lock = nil
+
+	joined, err := CreateJoinedFile(Files{first, second})
+	assert.Nil(t, err)
+	assert.Equal(t, Metadata{"owner": "tuncay", "language": "tr", "source": "crm"}, joined.Metadata)
+
+	target := newFile("target")
+	first.CloneInto(target)
+	assert.Equal(t, first.Metadata, target.Metadata)
+
+	target.Metadata["owner"] = "someone"
+	assert.Equal(t, "tuncay", first.Metadata["owner"])
+
+	target.Reset("text/plain", 0)
+	assert.Nil(t, target.Metadata)
+}
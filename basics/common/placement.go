@@ -0,0 +1,24 @@
+package common
+
+// Placement struct is to hold the node addresses of the file chunk in the cluster.
+// Addresses are in the shard order when the cluster is erasure coded and the address
+// of the shard is empty if the shard is not available
+type Placement struct {
+	ClusterId string         `json:"clusterId"`
+	Erasure   *ErasureCoding `json:"erasure,omitempty"`
+	Addresses []string       `json:"addresses"`
+}
+
+// NewPlacement initialises a new Placement struct for the cluster
+func NewPlacement(cluster *Cluster, addresses []string) *Placement {
+	return &Placement{
+		ClusterId: cluster.Id,
+		Erasure:   cluster.Erasure,
+		Addresses: addresses,
+	}
+}
+
+// ErasureCoded checks the chunk is kept as the shards
+func (p *Placement) ErasureCoded() bool {
+	return p.Erasure != nil
+}
@@ -0,0 +1,89 @@
+package common
+
+import "os"
+
+// Quota struct is to limit the usage of the folder including its sub folders
+// Size and Files are the hard limits of the total file size and the file count, zero means no limit
+// SoftSize and SoftFiles are the warning limits to execute the quota hooks when the usage passes them
+// Usage is kept up to date with the changes in the folder tree instead of calculating it on every request
+type Quota struct {
+	Size      uint64     `json:"size"`
+	Files     uint64     `json:"files"`
+	SoftSize  uint64     `json:"softSize"`
+	SoftFiles uint64     `json:"softFiles"`
+	Usage     QuotaUsage `json:"usage"`
+}
+
+// QuotaUsage struct holds the total file size and the file count of the folder tree
+type QuotaUsage struct {
+	Size  uint64 `json:"size"`
+	Files uint64 `json:"files"`
+}
+
+// NewQuota creates a Quota struct with the limits. At least one of the limits should be set and
+// the soft limits can not be more than the hard limits
+func NewQuota(size uint64, files uint64, softSize uint64, softFiles uint64) (*Quota, error) {
+	if size == 0 && files == 0 && softSize == 0 && softFiles == 0 {
+		return nil, os.ErrInvalid
+	}
+
+	if size > 0 && softSize > size || files > 0 && softFiles > files {
+		return nil, os.ErrInvalid
+	}
+
+	return &Quota{
+		Size:      size,
+		Files:     files,
+		SoftSize:  softSize,
+		SoftFiles: softFiles,
+	}, nil
+}
+
+// NewQuotaUsage calculates the usage of the provided folders using their current files.
+// File versions are not included
+func NewQuotaUsage(folders []*Folder) QuotaUsage {
+	usage := QuotaUsage{}
+	for _, folder := range folders {
+		for _, file := range folder.Files {
+			usage.Size += file.Size
+			usage.Files++
+		}
+	}
+	return usage
+}
+
+// Exceeds checks if the usage changes pass the hard limits. Decreasing changes never exceed
+func (q *Quota) Exceeds(size int64, files int64) bool {
+	if size > 0 && q.Size > 0 && q.Usage.Size+uint64(size) > q.Size {
+		return true
+	}
+	return files > 0 && q.Files > 0 && q.Usage.Files+uint64(files) > q.Files
+}
+
+// Change applies the changes to the usage and returns true if the usage has just passed the soft limits
+func (q *Quota) Change(size int64, files int64) bool {
+	softExceeded := q.SoftExceeded()
+
+	q.Usage.Size = changeUsage(q.Usage.Size, size)
+	q.Usage.Files = changeUsage(q.Usage.Files, files)
+
+	return !softExceeded && q.SoftExceeded()
+}
+
+// SoftExceeded checks if the usage is over the soft limits
+func (q *Quota) SoftExceeded() bool {
+	return q.SoftSize > 0 && q.Usage.Size > q.SoftSize ||
+		q.SoftFiles > 0 && q.Usage.Files > q.SoftFiles
+}
+
+func changeUsage(usage uint64, change int64) uint64 {
+	if change >= 0 {
+		return usage + uint64(change)
+	}
+
+	decrease := uint64(-change)
+	if decrease > usage {
+		return 0
+	}
+	return usage - decrease
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQuota(t *testing.T) {
+	_, err := NewQuota(0, 0, 0, 0)
+	assert.Equal(t, os.ErrInvalid, err)
+
+	_, err = NewQuota(100, 0, 200, 0)
+	assert.Equal(t, os.ErrInvalid, err)
+
+	_, err = NewQuota(0, 10, 0, 20)
+	assert.Equal(t, os.ErrInvalid, err)
+
+	quota, err := NewQuota(0, 0, 200, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(200), quota.SoftSize)
+
+	quota, err = NewQuota(100, 10, 80, 8)
+	assert.Nil(t, err)
+	assert.Equal(t, QuotaUsage{}, quota.Usage)
+}
+
+func TestNewQuotaUsage(t *testing.T) {
+	folder := NewFolder("/a")
+	a, _ := folder.NewFile("a.txt")
+	a.Size = 10
+	subFolder, _ := folder.NewFolder("b")
+	b, _ := subFolder.NewFile("b.txt")
+	b.Size = 5
+
+	subFolder.Versioning = true
+	_ = subFolder.ArchiveFile(b, false)
+
+	usage := NewQuotaUsage([]*Folder{folder, subFolder})
+	assert.Equal(t, uint64(15), usage.Size)
+	assert.Equal(t, uint64(2), usage.Files)
+}
+
+func TestQuota_Exceeds(t *testing.T) {
+	quota, _ := NewQuota(100, 2, 0, 0)
+	quota.Usage = QuotaUsage{Size: 90, Files: 2}
+
+	assert.False(t, quota.Exceeds(10, 0))
+	assert.True(t, quota.Exceeds(11, 0))
+	assert.True(t, quota.Exceeds(0, 1))
+	assert.False(t, quota.Exceeds(-50, -1))
+
+	quota, _ = NewQuota(0, 0, 10, 1)
+	quota.Usage = QuotaUsage{Size: 90, Files: 2}
+	assert.False(t, quota.Exceeds(100, 100))
+}
+
+func TestQuota_Change(t *testing.T) {
+	quota, _ := NewQuota(100, 0, 80, 0)
+
+	assert.False(t, quota.Change(80, 1))
+	assert.True(t, quota.Change(1, 1))
+	assert.True(t, quota.SoftExceeded())
+
+	// already passed, it should not be reported again
+	assert.False(t, quota.Change(10, 1))
+	assert.Equal(t, QuotaUsage{Size: 91, Files: 3}, quota.Usage)
+
+	assert.False(t, quota.Change(-200, -5))
+	assert.Equal(t, QuotaUsage{}, quota.Usage)
+	assert.False(t, quota.SoftExceeded())
+}
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ResourceLock struct is to hold the WebDAV lock that is placed on a folder or a file in the folder
+// Token is the opaque lock token that is shared with the client
+// Root is the full path of the locked folder or file, infinite locks also cover the folders and the files under it
+// Owner is the raw owner information that the client provides
+// Timeout is the duration of the lock in seconds and it is refreshed by the client before Till
+type ResourceLock struct {
+	Token     string    `json:"token"`
+	Root      string    `json:"root"`
+	Infinite  bool      `json:"infinite"`
+	Exclusive bool      `json:"exclusive"`
+	Owner     string    `json:"owner,omitempty"`
+	Timeout   uint64    `json:"timeout"`
+	Till      time.Time `json:"till"`
+}
+
+// NewResourceLock creates the lock of the root path that is effective for the timeout duration
+func NewResourceLock(root string, infinite bool, exclusive bool, owner string, timeout time.Duration) (*ResourceLock, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	h := hex.EncodeToString(b)
+
+	lock := &ResourceLock{
+		Token:     fmt.Sprintf("opaquelocktoken:%s-%s-%s-%s-%s", h[:8], h[8:12], h[12:16], h[16:20], h[20:]),
+		Root:      CorrectPath(root),
+		Infinite:  infinite,
+		Exclusive: exclusive,
+		Owner:     owner,
+	}
+	lock.Refresh(timeout)
+
+	return lock, nil
+}
+
+// Refresh extends the lock for the timeout duration
+func (r *ResourceLock) Refresh(timeout time.Duration) {
+	r.Timeout = uint64(timeout.Seconds())
+	r.Till = time.Now().UTC().Add(timeout)
+}
+
+// Active checks if the lock is still effective
+func (r *ResourceLock) Active() bool {
+	return r.Till.After(time.Now().UTC())
+}
+
+// Covers checks if the lock is effective on the path
+func (r *ResourceLock) Covers(path string) bool {
+	if strings.Compare(r.Root, path) == 0 {
+		return true
+	}
+	return r.Infinite && under(r.Root, path)
+}
+
+// under checks if the path is placed under the folder path
+func under(folderPath string, path string) bool {
+	if strings.Compare(folderPath, pathSeparator) == 0 {
+		return strings.Compare(path, pathSeparator) != 0
+	}
+	return strings.HasPrefix(path, fmt.Sprintf("%s%s", folderPath, pathSeparator))
+}
+
+// ResourceLocks is the definition of the pointer array of ResourceLock struct
+type ResourceLocks []*ResourceLock
+
+// Active returns the locks that are still effective
+func (r ResourceLocks) Active() ResourceLocks {
+	locks := make(ResourceLocks, 0)
+	for _, lock := range r {
+		if lock.Active() {
+			locks = append(locks, lock)
+		}
+	}
+	return locks
+}
+
+// Related finds the active locks that cover the path. If children is true, the locks that
+// are placed under the path are also returned
+func (r ResourceLocks) Related(path string, children bool) ResourceLocks {
+	locks := make(ResourceLocks, 0)
+	for _, lock := range r.Active() {
+		if lock.Covers(path) || children && under(path, lock.Root) {
+			locks = append(locks, lock)
+		}
+	}
+	return locks
+}
+
+// Find finds the active lock of the token that covers the path
+func (r ResourceLocks) Find(path string, token string) *ResourceLock {
+	for _, lock := range r.Related(path, false) {
+		if strings.Compare(lock.Token, token) == 0 {
+			return lock
+		}
+	}
+	return nil
+}
+
+// Conflicts checks if the lock can not be placed because of the active locks. Exclusive locks can not share
+// the path with any other lock
+func (r ResourceLocks) Conflicts(lock *ResourceLock) bool {
+	for _, related := range r.Related(lock.Root, lock.Infinite) {
+		if related.Exclusive || lock.Exclusive {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed checks if the provided tokens are sufficient to change the path. If children is true, the locks
+// that are placed under the path are also evaluated
+func (r ResourceLocks) Allowed(path string, children bool, tokens []string) bool {
+	for _, lock := range r.Related(path, children) {
+		submitted := false
+		for _, token := range tokens {
+			if strings.Compare(lock.Token, token) == 0 {
+				submitted = true
+				break
+			}
+		}
+		if !submitted && (lock.Exclusive || len(tokens) == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Drop removes the locks of the path and the locks under it
+func (r ResourceLocks) Drop(path string) ResourceLocks {
+	locks := make(ResourceLocks, 0)
+	for _, lock := range r.Active() {
+		if strings.Compare(lock.Root, path) == 0 || under(path, lock.Root) {
+			continue
+		}
+		locks = append(locks, lock)
+	}
+	return locks
+}
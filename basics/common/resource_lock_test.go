@@ -0,0 +1,80 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceLock_Covers(t *testing.T) {
+	lock, err := NewResourceLock("/Foo", false, true, "", time.Minute)
+	assert.Nil(t, err)
+	assert.Len(t, lock.Token, 52)
+	assert.True(t, lock.Active())
+	assert.Equal(t, uint64(60), lock.Timeout)
+
+	assert.True(t, lock.Covers("/Foo"))
+	assert.False(t, lock.Covers("/Foo/Bar"))
+	assert.False(t, lock.Covers("/FooBar"))
+
+	lock.Infinite = true
+	assert.True(t, lock.Covers("/Foo/Bar"))
+	assert.True(t, lock.Covers("/Foo/Bar/Baz.jpg"))
+	assert.False(t, lock.Covers("/FooBar"))
+
+	root, err := NewResourceLock("/", true, true, "", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, root.Covers("/"))
+	assert.True(t, root.Covers("/Foo"))
+}
+
+func TestResourceLocks_Allowed(t *testing.T) {
+	exclusive, _ := NewResourceLock("/Foo/Bar.jpg", false, true, "", time.Minute)
+	shared, _ := NewResourceLock("/Baz", true, false, "", time.Minute)
+	expired, _ := NewResourceLock("/Qux", true, true, "", time.Minute)
+	expired.Till = time.Now().UTC().Add(-time.Second)
+
+	locks := ResourceLocks{exclusive, shared, expired}
+
+	assert.False(t, locks.Allowed("/Foo/Bar.jpg", false, nil))
+	assert.False(t, locks.Allowed("/Foo/Bar.jpg", false, []string{shared.Token}))
+	assert.True(t, locks.Allowed("/Foo/Bar.jpg", false, []string{exclusive.Token}))
+	assert.True(t, locks.Allowed("/Foo", false, nil))
+	assert.False(t, locks.Allowed("/Foo", true, nil))
+
+	assert.False(t, locks.Allowed("/Baz/File.txt", false, nil))
+	assert.True(t, locks.Allowed("/Baz/File.txt", false, []string{shared.Token}))
+
+	assert.True(t, locks.Allowed("/Qux/File.txt", false, nil))
+}
+
+func TestResourceLocks_Conflicts(t *testing.T) {
+	shared, _ := NewResourceLock("/Foo", true, false, "", time.Minute)
+	locks := ResourceLocks{shared}
+
+	other, _ := NewResourceLock("/Foo/Bar.jpg", false, false, "", time.Minute)
+	assert.False(t, locks.Conflicts(other))
+
+	other.Exclusive = true
+	assert.True(t, locks.Conflicts(other))
+
+	parent, _ := NewResourceLock("/", true, true, "", time.Minute)
+	assert.True(t, locks.Conflicts(parent))
+
+	parent.Infinite = false
+	assert.False(t, locks.Conflicts(parent))
+}
+
+func TestResourceLocks_Find(t *testing.T) {
+	lock, _ := NewResourceLock("/Foo", true, true, "", time.Minute)
+	locks := ResourceLocks{lock}
+
+	assert.Equal(t, lock, locks.Find("/Foo/Bar.jpg", lock.Token))
+	assert.Nil(t, locks.Find("/Baz", lock.Token))
+	assert.Nil(t, locks.Find("/Foo", "opaquelocktoken:other"))
+
+	assert.Len(t, locks.Drop("/Foo"), 0)
+	assert.Len(t, locks.Drop("/"), 0)
+	assert.Len(t, locks.Drop("/Foo/Bar.jpg"), 1)
+}
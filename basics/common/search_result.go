@@ -0,0 +1,54 @@
+package common
+
+import "time"
+
+// SearchResult struct is to hold a page of the search in dfs
+// Next is the continuation token of the following page and it is empty for the last page
+type SearchResult struct {
+	Entries SearchEntries `json:"entries"`
+	Next    string        `json:"next,omitempty"`
+}
+
+// SearchEntry struct is to hold the folder or file that is matching with the search filters
+// Type is the kind of the entry. Values: folder or file
+type SearchEntry struct {
+	Full     string    `json:"full"`
+	Type     string    `json:"type"`
+	Mime     string    `json:"mime,omitempty"`
+	Size     uint64    `json:"size"`
+	Checksum string    `json:"checksum,omitempty"`
+	Metadata Metadata  `json:"metadata,omitempty"`
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+	Locked   bool      `json:"locked"`
+	Zombie   bool      `json:"zombie"`
+}
+
+// SearchEntries is the definition of the pointer array of SearchEntry struct
+type SearchEntries []*SearchEntry
+
+// NewSearchEntryForFolder creates the search entry of the folder
+func NewSearchEntryForFolder(folder *Folder) *SearchEntry {
+	return &SearchEntry{
+		Full:     folder.Full,
+		Type:     "folder",
+		Created:  folder.Created,
+		Modified: folder.Modified,
+	}
+}
+
+// NewSearchEntryForFile creates the search entry of the file in the folder
+func NewSearchEntryForFile(folderPath string, file *File) *SearchEntry {
+	return &SearchEntry{
+		Full:     Join(folderPath, file.Name),
+		Type:     "file",
+		Mime:     file.Mime,
+		Size:     file.Size,
+		Checksum: file.Checksum,
+		Metadata: file.Metadata,
+		Created:  file.Created,
+		Modified: file.Modified,
+		Locked:   file.Locked(),
+		Zombie:   file.ZombieCheck(),
+	}
+}
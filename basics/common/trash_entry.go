@@ -0,0 +1,98 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// TrashEntry struct is to hold the deleted folder or file till the retention period expires
+// Path is the original location of the entry in dfs
+// File is the deleted file, it is only set for the file entries
+// Folders are the deleted folder and its sub folders with their content, they are only set for the folder entries
+type TrashEntry struct {
+	Id      string    `json:"id"`
+	Path    string    `json:"path"`
+	Folder  bool      `json:"folder"`
+	Size    uint64    `json:"size"`
+	Deleted time.Time `json:"deleted"`
+	Expires time.Time `json:"expires"`
+	File    *File     `json:"-"`
+	Folders []*Folder `json:"-"`
+}
+
+// TrashEntries is the definition of the pointer array of TrashEntry struct
+type TrashEntries []*TrashEntry
+
+func (t TrashEntries) Len() int           { return len(t) }
+func (t TrashEntries) Less(i, j int) bool { return t[i].Deleted.After(t[j].Deleted) }
+func (t TrashEntries) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+// NewTrashEntryForFile creates a TrashEntry struct for the deleted file in the path
+func NewTrashEntryForFile(path string, file *File, retention time.Duration) (*TrashEntry, error) {
+	entry, err := newTrashEntry(path, false, retention)
+	if err != nil {
+		return nil, err
+	}
+	entry.File = file
+	entry.Size = file.Size
+
+	return entry, nil
+}
+
+// NewTrashEntryForFolder creates a TrashEntry struct for the deleted folder in the path using the folder
+// and its sub folders
+func NewTrashEntryForFolder(path string, folders []*Folder, retention time.Duration) (*TrashEntry, error) {
+	entry, err := newTrashEntry(path, true, retention)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Folders = make([]*Folder, len(folders))
+	copy(entry.Folders, folders)
+
+	// sorting by the full path keeps the deleted folder at the top
+	sort.Slice(entry.Folders, func(i, j int) bool { return entry.Folders[i].Full < entry.Folders[j].Full })
+
+	for _, folder := range entry.Folders {
+		for _, file := range folder.Files {
+			entry.Size += file.Size
+		}
+	}
+
+	return entry, nil
+}
+
+func newTrashEntry(path string, folder bool, retention time.Duration) (*TrashEntry, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	deleted := time.Now().UTC()
+	return &TrashEntry{
+		Id:      hex.EncodeToString(b),
+		Path:    path,
+		Folder:  folder,
+		Deleted: deleted,
+		Expires: deleted.Add(retention),
+	}, nil
+}
+
+// Files collects the files including the versions in the entry to be able to reach all the chunks
+// that are referenced by the entry
+func (t *TrashEntry) Files() Files {
+	if !t.Folder {
+		if t.File == nil {
+			return make(Files, 0)
+		}
+		return Files{t.File}
+	}
+
+	files := make(Files, 0)
+	for _, folder := range t.Folders {
+		files = append(files, folder.FilesWithVersions()...)
+	}
+	return files
+}
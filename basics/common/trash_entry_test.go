@@ -0,0 +1,58 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTrashEntryForFile(t *testing.T) {
+	folder := NewFolder("/")
+	file, _ := folder.NewFile("a.txt")
+	file.Size = 10
+	file.Chunks = DataChunks{NewDataChunk(0, 10, "hash-a")}
+
+	entry, err := NewTrashEntryForFile("/a.txt", file, time.Hour)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, entry.Id)
+	assert.False(t, entry.Folder)
+	assert.Equal(t, "/a.txt", entry.Path)
+	assert.Equal(t, uint64(10), entry.Size)
+	assert.Equal(t, time.Hour, entry.Expires.Sub(entry.Deleted))
+
+	files := entry.Files()
+	assert.Len(t, files, 1)
+	assert.Equal(t, "hash-a", files[0].Chunks[0].Hash)
+}
+
+func TestNewTrashEntryForFolder(t *testing.T) {
+	folder := NewFolder("/a")
+	subFolder, _ := folder.NewFolder("b")
+
+	file, _ := folder.NewFile("x.txt")
+	file.Size = 10
+	subFile, _ := subFolder.NewFile("y.txt")
+	subFile.Size = 5
+
+	subFolder.Versioning = true
+	_ = subFolder.ArchiveFile(subFile, true)
+
+	entry, err := NewTrashEntryForFolder("/a", []*Folder{subFolder, folder}, time.Hour)
+	assert.Nil(t, err)
+	assert.True(t, entry.Folder)
+	assert.Equal(t, uint64(15), entry.Size)
+	assert.Equal(t, "/a", entry.Folders[0].Full)
+	assert.Equal(t, "/a/b", entry.Folders[1].Full)
+	assert.Len(t, entry.Files(), 3)
+}
+
+func TestTrashEntries_Sort(t *testing.T) {
+	older, _ := NewTrashEntryForFile("/a.txt", &File{}, time.Hour)
+	older.Deleted = older.Deleted.Add(-time.Minute)
+	newer, _ := NewTrashEntryForFile("/b.txt", &File{}, time.Hour)
+
+	entries := TrashEntries{older, newer}
+	assert.True(t, entries.Less(1, 0))
+	assert.False(t, entries.Less(0, 1))
+}
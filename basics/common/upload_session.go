@@ -0,0 +1,147 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// has Reservation (reservationDuration) Expire relation
+// the value should be strictly less than reservationDuration value
+const uploadSessionDuration = time.Hour * 12
+
+// UploadSession struct is to hold the resumable file upload details in the dfs farm
+// Id is the upload session identity that is shared with the client
+// Path is the target file location in dfs
+// Parts are the particles of the file that client should upload
+// Reservation is the cluster reservation that is made for the file creation
+// HashState and HashOffset keep the whole file checksum calculation progress
+// Ttl is the time-to-live of the file that will be created by the commit, folder default is used if it is not set
+type UploadSession struct {
+	Id          string          `json:"id"`
+	Path        string          `json:"path"`
+	Mime        string          `json:"mime"`
+	Metadata    Metadata        `json:"metadata,omitempty"`
+	Size        uint64          `json:"size"`
+	Overwrite   bool            `json:"overwrite"`
+	Ttl         *time.Duration  `json:"-"`
+	Parts       UploadParts     `json:"parts"`
+	Reservation *ReservationMap `json:"-"`
+	HashState   []byte          `json:"-"`
+	HashOffset  uint64          `json:"-"`
+	Created     time.Time       `json:"created"`
+	Expires     time.Time       `json:"expires"`
+}
+
+// UploadPart struct is to hold the particle of the resumable file upload
+// Index is the particle starting point in the whole file
+// Hash is the uploaded particle hash and it is empty till the particle is received
+// Usage is the cluster space usage of the uploaded particle
+type UploadPart struct {
+	Sequence uint16            `json:"sequence"`
+	Index    uint64            `json:"index"`
+	Size     uint32            `json:"size"`
+	Received bool              `json:"received"`
+	Hash     string            `json:"hash,omitempty"`
+	Usage    map[string]uint64 `json:"-"`
+}
+
+// UploadParts is the definition of the pointer array of UploadPart struct
+type UploadParts []*UploadPart
+
+func (u UploadParts) Len() int           { return len(u) }
+func (u UploadParts) Less(i, j int) bool { return u[i].Sequence < u[j].Sequence }
+func (u UploadParts) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+
+// NewUploadSession initialises a new UploadSession struct using the reservation of the file
+func NewUploadSession(path string, mime string, metadata Metadata, size uint64, overwrite bool, reservation *ReservationMap) (*UploadSession, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	parts := make(UploadParts, 0)
+	for _, clusterMap := range reservation.Clusters {
+		parts = append(parts, &UploadPart{
+			Sequence: clusterMap.Chunk.Sequence,
+			Index:    clusterMap.Chunk.Index,
+			Size:     clusterMap.Chunk.Size,
+			Received: false,
+			Usage:    make(map[string]uint64),
+		})
+	}
+
+	created := time.Now().UTC()
+	return &UploadSession{
+		Id:          hex.EncodeToString(b),
+		Path:        path,
+		Mime:        mime,
+		Metadata:    metadata.Clone(),
+		Size:        size,
+		Overwrite:   overwrite,
+		Parts:       parts,
+		Reservation: reservation,
+		HashState:   nil,
+		HashOffset:  0,
+		Created:     created,
+		Expires:     created.Add(uploadSessionDuration),
+	}, nil
+}
+
+// Part finds the particle that starts at the offset
+func (u *UploadSession) Part(offset uint64) *UploadPart {
+	for _, part := range u.Parts {
+		if part.Index == offset {
+			return part
+		}
+	}
+	return nil
+}
+
+// ClusterMap finds the reserved cluster of the particle
+func (u *UploadSession) ClusterMap(sequence uint16) *ClusterMap {
+	for i := range u.Reservation.Clusters {
+		if u.Reservation.Clusters[i].Chunk.Sequence == sequence {
+			return &u.Reservation.Clusters[i]
+		}
+	}
+	return nil
+}
+
+// Completed checks if all the particles of the file are received
+func (u *UploadSession) Completed() bool {
+	for _, part := range u.Parts {
+		if !part.Received {
+			return false
+		}
+	}
+	return true
+}
+
+// Expired checks if the upload session is still usable
+func (u *UploadSession) Expired() bool {
+	return !u.Expires.After(time.Now().UTC())
+}
+
+// Chunks creates the data chunk list of the received particles
+func (u *UploadSession) Chunks() DataChunks {
+	chunks := make(DataChunks, 0)
+	for _, part := range u.Parts {
+		if !part.Received {
+			continue
+		}
+		chunks = append(chunks, NewDataChunk(part.Sequence, part.Size, part.Hash))
+	}
+	return chunks
+}
+
+// Usage calculates the cluster space usage of the received particles
+func (u *UploadSession) Usage() map[string]uint64 {
+	usage := make(map[string]uint64)
+	for _, part := range u.Parts {
+		for clusterId, size := range part.Usage {
+			usage[clusterId] += size
+		}
+	}
+	return usage
+}
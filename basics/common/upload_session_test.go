@@ -0,0 +1,47 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadSession_Parts(t *testing.T) {
+	reservation := &ReservationMap{
+		Id: "reservation",
+		Clusters: []ClusterMap{
+			{Id: "c1", Address: "127.0.0.1:9430", Chunk: Chunk{Sequence: 0, Index: 0, Size: 10}},
+			{Id: "c2", Address: "127.0.0.1:9431", Chunk: Chunk{Sequence: 1, Index: 10, Size: 5}},
+		},
+	}
+
+	session, err := NewUploadSession("/test.bin", "application/octet-stream", nil, 15, false, reservation)
+	assert.Nil(t, err)
+	assert.Len(t, session.Id, 32)
+	assert.Len(t, session.Parts, 2)
+	assert.False(t, session.Expired())
+	assert.False(t, session.Completed())
+
+	assert.Nil(t, session.Part(5))
+
+	part := session.Part(10)
+	assert.NotNil(t, part)
+	assert.Equal(t, uint16(1), part.Sequence)
+	assert.Equal(t, "c2", session.ClusterMap(part.Sequence).Id)
+
+	part.Received = true
+	part.Hash = "hash1"
+	part.Usage["c2"] = 5
+
+	assert.False(t, session.Completed())
+	assert.Len(t, session.Chunks(), 1)
+
+	part = session.Part(0)
+	part.Received = true
+	part.Hash = "hash0"
+	part.Usage["c1"] = 10
+
+	assert.True(t, session.Completed())
+	assert.Len(t, session.Chunks(), 2)
+	assert.Equal(t, map[string]uint64{"c1": 10, "c2": 5}, session.Usage())
+}
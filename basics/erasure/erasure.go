@@ -0,0 +1,101 @@
+package erasure
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/klauspost/reedsolomon"
+)
+
+var encodersMutex sync.Mutex
+var encoders = make(map[common.ErasureCoding]reedsolomon.Encoder)
+
+// encoder keeps the Reed-Solomon encoders per erasure coding because they cache their matrices
+func encoder(coding *common.ErasureCoding) (reedsolomon.Encoder, error) {
+	encodersMutex.Lock()
+	defer encodersMutex.Unlock()
+
+	enc, has := encoders[*coding]
+	if !has {
+		var err error
+		enc, err = reedsolomon.New(int(coding.Data), int(coding.Parity))
+		if err != nil {
+			return nil, err
+		}
+		encoders[*coding] = enc
+	}
+
+	return enc, nil
+}
+
+// Encode splits the chunk into the data shards and calculates the parity shards. The shards are returned in
+// the shard order and all of them are in the shard size of the chunk
+func Encode(coding *common.ErasureCoding, data []byte) ([][]byte, error) {
+	if len(data) == 0 {
+		return make([][]byte, coding.Shards()), nil
+	}
+
+	enc, err := encoder(coding)
+	if err != nil {
+		return nil, err
+	}
+
+	// capacity is limited, so the padding of the last shard does not touch the content after the data
+	shards, err := enc.Split(data[:len(data):len(data)])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(shards); err != nil {
+		return nil, err
+	}
+
+	return shards, nil
+}
+
+// Reconstruct joins the data shards into the chunk in the size. Missing shards should be nil and they are
+// reconstructed when at least the data shard count of the shards are available, otherwise ErrShards returns
+func Reconstruct(coding *common.ErasureCoding, shards [][]byte, size uint32) ([]byte, error) {
+	if size == 0 {
+		return []byte{}, nil
+	}
+
+	enc, err := encoder(coding)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enc.ReconstructData(shards); err != nil {
+		if err == reedsolomon.ErrTooFewShards {
+			return nil, errors.ErrShards
+		}
+		return nil, err
+	}
+
+	buffer := bytes.NewBuffer(make([]byte, 0, size))
+	if err := enc.Join(buffer, shards, int(size)); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Rebuild reconstructs all the missing shards including the parity ones. It is used to recover the shards of
+// the lost nodes. Missing shards should be nil and ErrShards returns if there is not enough shards to rebuild
+func Rebuild(coding *common.ErasureCoding, shards [][]byte) error {
+	enc, err := encoder(coding)
+	if err != nil {
+		return err
+	}
+
+	if err := enc.Reconstruct(shards); err != nil {
+		if err == reedsolomon.ErrTooFewShards {
+			return errors.ErrShards
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package erasure
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	coding := &common.ErasureCoding{Data: 4, Parity: 2}
+	data := bytes.Repeat([]byte("kertish-dfs"), 1001) // 11011 bytes, not divisible by 4
+
+	buffer := make([]byte, len(data), len(data)+100)
+	copy(buffer, data)
+
+	shards, err := Encode(coding, buffer)
+	assert.Nil(t, err)
+	assert.Len(t, shards, 6)
+	for _, shard := range shards {
+		assert.Equal(t, int(coding.ShardSize(uint32(len(data)))), len(shard))
+	}
+	assert.Equal(t, data, buffer)
+
+	shards, err = Encode(coding, []byte{})
+	assert.Nil(t, err)
+	assert.Len(t, shards, 6)
+}
+
+func TestReconstruct(t *testing.T) {
+	coding := &common.ErasureCoding{Data: 4, Parity: 2}
+	data := bytes.Repeat([]byte("kertish-dfs"), 1001)
+
+	shards, err := Encode(coding, data)
+	assert.Nil(t, err)
+
+	result, err := Reconstruct(coding, shards, uint32(len(data)))
+	assert.Nil(t, err)
+	assert.Equal(t, data, result)
+
+	// parity count of the shards can be lost
+	shards[0] = nil
+	shards[3] = nil
+	result, err = Reconstruct(coding, shards, uint32(len(data)))
+	assert.Nil(t, err)
+	assert.Equal(t, data, result)
+
+	shards[0] = nil
+	shards[1] = nil
+	shards[3] = nil
+	_, err = Reconstruct(coding, shards, uint32(len(data)))
+	assert.Equal(t, errors.ErrShards, err)
+
+	result, err = Reconstruct(coding, make([][]byte, 6), 0)
+	assert.Nil(t, err)
+	assert.Empty(t, result)
+}
+
+func TestRebuild(t *testing.T) {
+	coding := &common.ErasureCoding{Data: 3, Parity: 2}
+	data := bytes.Repeat([]byte("rebuild"), 500)
+
+	shards, err := Encode(coding, data)
+	assert.Nil(t, err)
+
+	expected := make([][]byte, len(shards))
+	for i, shard := range shards {
+		expected[i] = append([]byte{}, shard...)
+	}
+
+	shards[1] = nil
+	shards[4] = nil
+	assert.Nil(t, Rebuild(coding, shards))
+	assert.Equal(t, expected, shards)
+
+	shards[0] = nil
+	shards[1] = nil
+	shards[2] = nil
+	assert.Equal(t, errors.ErrShards, Rebuild(coding, shards))
+}
@@ -20,6 +20,15 @@ var (
 	ErrSync                  = errors.New("syncing is failed")
 	ErrTooManyErrors         = errors.New("too many error occurred, operation is canceled")
 	ErrSnapshot              = errors.New("snapshot operation is failed")
+	ErrIncomplete            = errors.New("upload is not completed")
+	ErrPrecondition          = errors.New("precondition failed")
+	ErrQuota                 = errors.New("quota is exceeded")
+	ErrUnauthorized          = errors.New("credentials are not valid or absent")
+	ErrForbidden             = errors.New("credentials do not have the scope")
+	ErrArchive               = errors.New("archive is not readable")
+	ErrUnsupported           = errors.New("archive entry type is not supported")
+	ErrKeyNotFound           = errors.New("encryption key is not found")
+	ErrChunking              = errors.New("upload session requires the block size in content defined chunking")
 
 	ErrExists                       = errors.New("cluster is already exists")
 	ErrPing                         = errors.New("node is not reachable")
@@ -31,6 +40,9 @@ var (
 	ErrNotAvailableForClusterAction = errors.New("cluster is not available for cluster wide actions")
 	ErrNoDiskSpace                  = errors.New("no available disk space for this operation")
 	ErrNotFound                     = errors.New("cluster/node not found")
+	ErrErasureCoded                 = errors.New("operation is not supported on erasure coded cluster")
+	ErrShards                       = errors.New("not enough shards to reconstruct the chunk")
+	ErrRotation                     = errors.New("key rotation is failed")
 
 	ErrShowUsage  = errors.New("show usage")
 	ErrProcessing = errors.New("another operation in progress")
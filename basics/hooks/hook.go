@@ -15,6 +15,7 @@ const (
 	Created RunOn = 2 // Folder/File or SubFolder/SubFile (if recursive) is newly Created
 	Updated RunOn = 3 // Folder/File or SubFolder/File is Copied or Moved (Renamed)
 	Deleted RunOn = 4 // Folder/File or SubFolder/SubFile (if recursive) is completely Deleted
+	Quota   RunOn = 5 // Folder or SubFolder (if recursive) usage passes the soft limit of the quota
 )
 
 // SetupMap is the simplified type name for underlying map
@@ -0,0 +1,86 @@
+package secure
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// Config holds the tls configurations of the node for the listeners and the outgoing connections.
+// nil Config keeps the traffic in plaintext
+type Config struct {
+	server *tls.Config
+	client *tls.Config
+}
+
+// CurrentConfig is the tls configuration of the running node for all its listeners and outgoing connections
+var CurrentConfig *Config
+
+// NewConfig creates the tls configuration using the PEM encoded files. certPath and keyPath are the
+// certificate of the node that is served on the listeners and presented as the client certificate on the
+// outgoing connections. They can be empty for the client only configuration. caPath is the certificate
+// authority to verify the peers, system roots are used if it is empty. verifyClient makes the listeners
+// to accept only the connections that present a client certificate signed by the certificate authority
+func NewConfig(certPath string, keyPath string, caPath string, verifyClient bool) (*Config, error) {
+	if (len(certPath) == 0) != (len(keyPath) == 0) {
+		return nil, fmt.Errorf("certificate and key should be defined together")
+	}
+	if verifyClient && (len(certPath) == 0 || len(caPath) == 0) {
+		return nil, fmt.Errorf("client verification requires certificate, key and certificate authority")
+	}
+
+	c := &Config{
+		client: &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	if len(caPath) > 0 {
+		pool, err := loadPool(caPath)
+		if err != nil {
+			return nil, err
+		}
+		c.client.RootCAs = pool
+	}
+
+	if len(certPath) == 0 {
+		return c, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	c.client.Certificates = []tls.Certificate{certificate}
+
+	c.server = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+	}
+	if verifyClient {
+		c.server.ClientAuth = tls.RequireAndVerifyClientCert
+		c.server.ClientCAs = c.client.RootCAs
+	}
+
+	return c, nil
+}
+
+func loadPool(caPath string) (*x509.CertPool, error) {
+	content, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("certificate authority file does not have any PEM encoded certificate")
+	}
+	return pool, nil
+}
+
+// Scheme returns the url scheme of the http requests
+func (c *Config) Scheme() string {
+	if c == nil {
+		return "http"
+	}
+	return "https"
+}
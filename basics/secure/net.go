@@ -0,0 +1,78 @@
+package secure
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Listen announces on the local network address. Accepted connections are tls encrypted when the
+// configuration is defined
+func (c *Config) Listen(network string, address string) (net.Listener, error) {
+	if c != nil && c.server == nil {
+		return nil, fmt.Errorf("listening with tls requires certificate and key")
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if c == nil {
+		return listener, nil
+	}
+	return tls.NewListener(listener, c.server), nil
+}
+
+// DialTimeout connects to the network address and completes the tls handshake in the timeout when the
+// configuration is defined
+func (c *Config) DialTimeout(network string, address string, timeout time.Duration) (net.Conn, error) {
+	if c == nil {
+		return net.DialTimeout(network, address, timeout)
+	}
+	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, network, address, c.client)
+}
+
+// HttpTransport creates the transport of the http clients. It returns nil to use the default transport
+// when the configuration is not defined
+func (c *Config) HttpTransport() http.RoundTripper {
+	if c == nil {
+		return nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = c.client
+
+	return transport
+}
+
+// ListenAndServe serves the http handler on the address over tls when the configuration is defined
+func (c *Config) ListenAndServe(address string, handler http.Handler) error {
+	listener, err := c.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return http.Serve(listener, handler)
+}
+
+// Listen announces on the local network address using the current configuration
+func Listen(network string, address string) (net.Listener, error) {
+	return CurrentConfig.Listen(network, address)
+}
+
+// DialTimeout connects to the network address using the current configuration
+func DialTimeout(network string, address string, timeout time.Duration) (net.Conn, error) {
+	return CurrentConfig.DialTimeout(network, address, timeout)
+}
+
+// HttpTransport creates the transport of the http clients using the current configuration
+func HttpTransport() http.RoundTripper {
+	return CurrentConfig.HttpTransport()
+}
+
+// ListenAndServe serves the http handler on the address using the current configuration
+func ListenAndServe(address string, handler http.Handler) error {
+	return CurrentConfig.ListenAndServe(address, handler)
+}
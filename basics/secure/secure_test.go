@@ -0,0 +1,252 @@
+package secure
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAuthority struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	path        string
+}
+
+func writePem(t *testing.T, path string, blockType string, content []byte) {
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: content}), 0600)
+	assert.Nil(t, err)
+}
+
+func newTestAuthority(t *testing.T, dir string, name string) *testAuthority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	content, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+
+	certificate, err := x509.ParseCertificate(content)
+	assert.Nil(t, err)
+
+	path := filepath.Join(dir, name+"-ca.pem")
+	writePem(t, path, "CERTIFICATE", content)
+
+	return &testAuthority{certificate: certificate, key: key, path: path}
+}
+
+// issue creates the node certificate for both server and client usage and returns the certificate and key paths
+func (a *testAuthority) issue(t *testing.T, dir string, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	content, err := x509.CreateCertificate(rand.Reader, template, a.certificate, &key.PublicKey, a.key)
+	assert.Nil(t, err)
+
+	keyContent, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certPath := filepath.Join(dir, name+".pem")
+	keyPath := filepath.Join(dir, name+"-key.pem")
+	writePem(t, certPath, "CERTIFICATE", content)
+	writePem(t, keyPath, "EC PRIVATE KEY", keyContent)
+
+	return certPath, keyPath
+}
+
+// echo accepts the connections and writes back the received data
+func echo(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer func() { _ = conn.Close() }()
+			_, _ = io.Copy(conn, conn)
+		}(conn)
+	}
+}
+
+func roundTrip(c *Config, address string) error {
+	conn, err := c.DialTimeout("tcp", address, time.Second*5)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := conn.SetDeadline(time.Now().Add(time.Second * 2)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		return err
+	}
+	b := make([]byte, 4)
+	_, err = io.ReadFull(conn, b)
+	return err
+}
+
+func TestNewConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestAuthority(t, dir, "test")
+	certPath, keyPath := ca.issue(t, dir, "node")
+
+	_, err := NewConfig(certPath, "", "", false)
+	assert.NotNil(t, err)
+
+	_, err = NewConfig(certPath, keyPath, "", true)
+	assert.NotNil(t, err)
+
+	_, err = NewConfig(certPath, keyPath, keyPath, false)
+	assert.NotNil(t, err)
+
+	c, err := NewConfig("", "", ca.path, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "https", c.Scheme())
+	_, err = c.Listen("tcp", "127.0.0.1:0")
+	assert.NotNil(t, err)
+
+	var plain *Config
+	assert.Equal(t, "http", plain.Scheme())
+	assert.Nil(t, plain.HttpTransport())
+}
+
+func TestConfig_Plaintext(t *testing.T) {
+	var c *Config
+
+	listener, err := c.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() { _ = listener.Close() }()
+	go echo(listener)
+
+	assert.Nil(t, roundTrip(c, listener.Addr().String()))
+}
+
+func TestConfig_Tls(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestAuthority(t, dir, "test")
+	certPath, keyPath := ca.issue(t, dir, "node")
+
+	server, err := NewConfig(certPath, keyPath, ca.path, false)
+	assert.Nil(t, err)
+
+	listener, err := server.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() { _ = listener.Close() }()
+	go echo(listener)
+
+	address := listener.Addr().String()
+
+	client, err := NewConfig("", "", ca.path, false)
+	assert.Nil(t, err)
+	assert.Nil(t, roundTrip(client, address))
+
+	// unknown certificate authority
+	other := newTestAuthority(t, dir, "other")
+	client, err = NewConfig("", "", other.path, false)
+	assert.Nil(t, err)
+	assert.NotNil(t, roundTrip(client, address))
+
+	// plaintext client
+	var plain *Config
+	assert.NotNil(t, roundTrip(plain, address))
+}
+
+func TestConfig_MutualTls(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestAuthority(t, dir, "test")
+	certPath, keyPath := ca.issue(t, dir, "data")
+
+	server, err := NewConfig(certPath, keyPath, ca.path, true)
+	assert.Nil(t, err)
+
+	listener, err := server.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() { _ = listener.Close() }()
+	go echo(listener)
+
+	address := listener.Addr().String()
+
+	headCertPath, headKeyPath := ca.issue(t, dir, "head")
+	client, err := NewConfig(headCertPath, headKeyPath, ca.path, false)
+	assert.Nil(t, err)
+	assert.Nil(t, roundTrip(client, address))
+
+	// without client certificate
+	client, err = NewConfig("", "", ca.path, false)
+	assert.Nil(t, err)
+	assert.NotNil(t, roundTrip(client, address))
+
+	// client certificate of unknown certificate authority
+	other := newTestAuthority(t, dir, "other")
+	otherCertPath, otherKeyPath := other.issue(t, dir, "intruder")
+	client, err = NewConfig(otherCertPath, otherKeyPath, ca.path, false)
+	assert.Nil(t, err)
+	assert.NotNil(t, roundTrip(client, address))
+}
+
+func TestConfig_Http(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestAuthority(t, dir, "test")
+	certPath, keyPath := ca.issue(t, dir, "manager")
+
+	server, err := NewConfig(certPath, keyPath, ca.path, true)
+	assert.Nil(t, err)
+
+	listener, err := server.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+				w.WriteHeader(500)
+				return
+			}
+			w.WriteHeader(200)
+		}))
+	}()
+
+	client, err := NewConfig(certPath, keyPath, ca.path, false)
+	assert.Nil(t, err)
+
+	httpClient := http.Client{Transport: client.HttpTransport()}
+	res, err := httpClient.Get(client.Scheme() + "://" + listener.Addr().String() + "/")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	_ = res.Body.Close()
+
+	httpClient = http.Client{}
+	_, err = httpClient.Get("https://" + listener.Addr().String() + "/")
+	assert.NotNil(t, err)
+}
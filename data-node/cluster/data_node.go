@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 )
 
 const dialTimeout = time.Second * 30
@@ -42,7 +43,7 @@ func NewDataNode(address string) (DataNode, error) {
 }
 
 func (d *dataNode) connect(connectionHandler func(conn net.Conn) error) error {
-	conn, err := net.DialTimeout(d.address.Network(), d.address.String(), dialTimeout)
+	conn, err := secure.DialTimeout(d.address.Network(), d.address.String(), dialTimeout)
 	if err != nil {
 		return err
 	}
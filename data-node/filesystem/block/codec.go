@@ -0,0 +1,68 @@
+package block
+
+import (
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec is the compression algorithm of the block file content
+type Codec uint8
+
+const (
+	CodecNone Codec = iota
+	CodecZstd
+	CodecSnappy
+)
+
+// CurrentCodec is the compression algorithm of the block files that are created on this data node.
+// Existent block files keep the codec that is recorded in their header
+var CurrentCodec = CodecNone
+
+var zstdEncoder, _ = zstd.NewWriter(nil)
+var zstdDecoder, _ = zstd.NewReader(nil)
+
+// ParseCodec validates and creates the codec from the value. Empty value or none disables the compression
+func ParseCodec(value string) (Codec, error) {
+	switch strings.ToLower(value) {
+	case "", "none":
+		return CodecNone, nil
+	case "zstd":
+		return CodecZstd, nil
+	case "snappy":
+		return CodecSnappy, nil
+	}
+	return CodecNone, os.ErrInvalid
+}
+
+func (c Codec) String() string {
+	switch c {
+	case CodecZstd:
+		return "zstd"
+	case CodecSnappy:
+		return "snappy"
+	}
+	return "none"
+}
+
+func (c Codec) encode(data []byte) []byte {
+	switch c {
+	case CodecZstd:
+		return zstdEncoder.EncodeAll(data, nil)
+	case CodecSnappy:
+		return snappy.Encode(nil, data)
+	}
+	return data
+}
+
+func (c Codec) decode(data []byte) ([]byte, error) {
+	switch c {
+	case CodecZstd:
+		return zstdDecoder.DecodeAll(data, nil)
+	case CodecSnappy:
+		return snappy.Decode(nil, data)
+	}
+	return data, nil
+}
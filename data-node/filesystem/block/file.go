@@ -2,25 +2,34 @@ package block
 
 import (
 	"crypto/sha512"
+	"encoding/binary"
 	"encoding/hex"
 	"hash"
 	"io"
 	"os"
 	"path"
 	"strings"
+	"sync"
 
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 const chunkSize uint32 = 1024 * 1024 // 1mb
 
+// frameLock keeps the frame reads of the encrypted block files away from the frames that are rewritten by the key
+// rotation. It is shared by all block files because the block files of the snapshots are linked to the root and
+// they are opened through the different managers
+var frameLock = sync.RWMutex{}
+
 // File handler for block file operations
 type File interface {
 	Temporary() bool
 
 	Write(data []byte) error
 	Verify() bool
+	VerifyAs(sha512Hex string) bool
 	VerifyForce() bool
 
 	Seek(offset int64) error
@@ -35,6 +44,7 @@ type File interface {
 	Delete() error
 	Wipe() error
 	Truncate(blockSize uint32) error
+	Rotate() (bool, error)
 
 	Cancel()
 	Close()
@@ -45,6 +55,8 @@ type file struct {
 	header *FileHeader
 
 	sha512   hash.Hash
+	pending  []byte
+	written  int64
 	tempPath string
 	verified bool
 	canceled bool
@@ -98,17 +110,83 @@ func (f *file) Write(data []byte) error {
 	if _, err := f.sha512.Write(data); err != nil {
 		return err
 	}
-	_, err := f.inner.Write(data)
-	return err
+
+	if !f.header.Framed() {
+		_, err := f.inner.Write(data)
+		return err
+	}
+
+	f.pending = append(f.pending, data...)
+	for uint32(len(f.pending)) >= chunkSize {
+		if err := f.writeFrame(f.pending[:chunkSize]); err != nil {
+			return err
+		}
+		f.pending = f.pending[chunkSize:]
+	}
+	return nil
+}
+
+// writeFrame compresses and encrypts the data and appends it to the content with the length prefix.
+// Each frame holds chunkSize of logical data except the last one, so the frames can be skipped on ranged read
+func (f *file) writeFrame(data []byte) error {
+	frame := f.header.Codec().encode(data)
+
+	if f.header.Encrypted() {
+		if CurrentKeyring == nil {
+			return errors.ErrKeyNotFound
+		}
+
+		var err error
+		frame, err = CurrentKeyring.seal(f.header.KeyId(), frame)
+		if err != nil {
+			return err
+		}
+	}
+
+	lengthPrefix := make([]byte, 4)
+	binary.LittleEndian.PutUint32(lengthPrefix, uint32(len(frame)))
+
+	offset := f.header.Size() + f.written
+	if _, err := f.inner.WriteAt(append(lengthPrefix, frame...), offset); err != nil {
+		return err
+	}
+	f.written += int64(len(lengthPrefix) + len(frame))
+
+	return f.header.SetContentSize(f.header.ContentSize() + uint32(len(data)))
+}
+
+// flush writes the remaining data of the framed content
+func (f *file) flush() error {
+	if len(f.pending) == 0 {
+		return nil
+	}
+
+	if err := f.writeFrame(f.pending); err != nil {
+		return err
+	}
+	f.pending = nil
+
+	return nil
 }
 
 func (f *file) Verify() bool {
+	return f.VerifyAs(f.sha512Hex)
+}
+
+// VerifyAs checks the written content with the hash instead of the name of the block file. Shards of the
+// erasure coded chunks are kept with the name of their chunk, so they are verified with their own hash
+func (f *file) VerifyAs(sha512Hex string) bool {
 	if f.verified {
 		return f.verified
 	}
 
+	if err := f.flush(); err != nil {
+		f.logger.Error("Framed block file write is failed", zap.String("sha512Hex", f.sha512Hex), zap.Error(err))
+		return false
+	}
+
 	result := hex.EncodeToString(f.sha512.Sum(nil))
-	f.verified = strings.Compare(result, f.sha512Hex) == 0
+	f.verified = strings.Compare(result, sha512Hex) == 0
 	return f.verified
 }
 
@@ -137,6 +215,10 @@ func (f *file) Seek(offset int64) error {
 }
 
 func (f *file) Read(begins uint32, ends uint32, readHandler func(data []byte) error, completedHandler func(inconsistency bool) error) error {
+	if f.header.Framed() {
+		return f.readFrames(begins, ends, readHandler, completedHandler)
+	}
+
 	if begins > 0 {
 		if err := f.Seek(int64(begins)); err != nil {
 			return err
@@ -172,6 +254,108 @@ func (f *file) Read(begins uint32, ends uint32, readHandler func(data []byte) er
 	return completedHandler(ends > 0 && total != 0)
 }
 
+// readFrames decrypts and decompresses the frames of the framed content. Frames before the beginning of the range
+// are skipped without decryption and decompression
+func (f *file) readFrames(begins uint32, ends uint32, readHandler func(data []byte) error, completedHandler func(inconsistency bool) error) error {
+	if err := f.Seek(0); err != nil {
+		return err
+	}
+
+	for i := begins / chunkSize; i > 0; i-- {
+		length, err := f.readFrameLength()
+		if err != nil {
+			if err == io.EOF {
+				return completedHandler(ends > 0)
+			}
+			return err
+		}
+
+		if _, err := f.inner.Seek(int64(length), io.SeekCurrent); err != nil {
+			return err
+		}
+	}
+
+	total := ^uint32(0) >> 1
+	if ends > 0 {
+		total = ends - begins
+	}
+
+	skip := begins % chunkSize
+	for total > 0 {
+		length, err := f.readFrameLength()
+		if err != nil {
+			if err == io.EOF {
+				return completedHandler(ends > 0 && total != 0)
+			}
+			return err
+		}
+
+		frame, err := f.readFrame(length)
+		if err != nil {
+			return err
+		}
+
+		frame, err = f.openFrame(frame)
+		if err != nil {
+			return err
+		}
+
+		data, err := f.header.Codec().decode(frame)
+		if err != nil {
+			return err
+		}
+
+		if skip > uint32(len(data)) {
+			skip = uint32(len(data))
+		}
+		data = data[skip:]
+		skip = 0
+
+		if total < uint32(len(data)) {
+			data = data[:total]
+		}
+
+		if err := readHandler(data); err != nil {
+			return err
+		}
+
+		total -= uint32(len(data))
+	}
+
+	return completedHandler(ends > 0 && total != 0)
+}
+
+func (f *file) readFrameLength() (uint32, error) {
+	var length uint32
+	err := binary.Read(f.inner, binary.LittleEndian, &length)
+	return length, err
+}
+
+// readFrame reads the frame in the length. Frame of the encrypted content is read under the frame lock, so it is not
+// torn by the key rotation
+func (f *file) readFrame(length uint32) ([]byte, error) {
+	if f.header.Encrypted() {
+		frameLock.RLock()
+		defer frameLock.RUnlock()
+	}
+
+	frame := make([]byte, length)
+	if _, err := io.ReadFull(f.inner, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+func (f *file) openFrame(frame []byte) ([]byte, error) {
+	if !f.header.Encrypted() {
+		return frame, nil
+	}
+	if CurrentKeyring == nil {
+		return nil, errors.ErrKeyNotFound
+	}
+	return CurrentKeyring.open(f.header.KeyId(), frame)
+}
+
 func (f *file) Id() string {
 	return f.sha512Hex
 }
@@ -188,7 +372,12 @@ func (f *file) ResetUsage(usage uint16) error {
 	return f.header.ResetUsage(usage)
 }
 
+// Size returns the logical size of the block file content
 func (f *file) Size() (uint32, error) {
+	if f.header.Framed() {
+		return f.header.ContentSize(), nil
+	}
+
 	info, err := f.inner.Stat()
 	if err != nil {
 		return 0, err
@@ -213,11 +402,99 @@ func (f *file) Wipe() error {
 	return os.Remove(f.targetPath)
 }
 
+// Truncate prepares the block file to be rewritten with the current codec and the current key. Framed content is
+// dropped completely because its size is not known before it is written
 func (f *file) Truncate(blockSize uint32) error {
-	if err := os.Truncate(f.targetPath, int64(blockSize)+f.header.Size()); err != nil {
+	if err := f.header.Reset(CurrentCodec, CurrentKeyring); err != nil {
+		return err
+	}
+
+	size := f.header.Size()
+	if !f.header.Framed() {
+		size += int64(blockSize)
+	}
+	if err := os.Truncate(f.targetPath, size); err != nil {
 		return err
 	}
-	return f.ResetUsage(1)
+
+	f.sha512.Reset()
+	f.pending = nil
+	f.written = 0
+
+	if err := f.ResetUsage(1); err != nil {
+		return err
+	}
+	return f.Seek(0)
+}
+
+// Rotate re-encrypts the frames of the encrypted content with the current key in place and reports if the block file
+// is rotated. Sealed frames keep their length, so the block files that are linked to the snapshots are rotated
+// together. Every frame is replaced under the frame lock that the readers use. Header is saved at last, frames of an
+// interrupted rotation or the frames that are rotated during the read are opened with the other keys of the keyring
+func (f *file) Rotate() (bool, error) {
+	if f.Temporary() || !f.header.Encrypted() || CurrentKeyring == nil {
+		return false, nil
+	}
+
+	keyId := CurrentKeyring.Current()
+	if f.header.KeyId() == keyId {
+		return false, nil
+	}
+
+	lengthPrefix := make([]byte, 4)
+
+	offset := f.header.Size()
+	for {
+		if _, err := f.inner.ReadAt(lengthPrefix, offset); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return false, err
+		}
+		offset += int64(len(lengthPrefix))
+
+		frame := make([]byte, binary.LittleEndian.Uint32(lengthPrefix))
+		if _, err := f.inner.ReadAt(frame, offset); err != nil {
+			return false, err
+		}
+
+		data, err := CurrentKeyring.open(f.header.KeyId(), frame)
+		if err != nil {
+			return false, err
+		}
+
+		sealed, err := CurrentKeyring.seal(keyId, data)
+		if err != nil {
+			return false, err
+		}
+		if len(sealed) != len(frame) {
+			return false, os.ErrInvalid
+		}
+
+		if err := f.writeFrameAt(sealed, offset); err != nil {
+			return false, err
+		}
+		offset += int64(len(frame))
+	}
+
+	if err := f.inner.Sync(); err != nil {
+		return false, err
+	}
+
+	if err := f.header.SetKeyId(keyId); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeFrameAt replaces the frame under the frame lock, so the readers get the frame either sealed with the previous
+// key or with the current key
+func (f *file) writeFrameAt(frame []byte, offset int64) error {
+	frameLock.Lock()
+	defer frameLock.Unlock()
+
+	_, err := f.inner.WriteAt(frame, offset)
+	return err
 }
 
 func (f *file) Cancel() {
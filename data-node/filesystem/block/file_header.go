@@ -3,25 +3,55 @@ package block
 import (
 	"encoding/binary"
 	"io"
+	"math"
 	"os"
 )
 
 const headerSize int64 = 2
+const framedHeaderSize int64 = headerSize + 2 + 1 + 4
+const encryptedHeaderSize int64 = framedHeaderSize + 4
+
+// framedMarker is placed in the usage bytes of the plain header to mark the block file that keeps its content in
+// frames, the usage follows the marker. Plain headers never keep the zero usage, block file is deleted when its
+// usage drops to zero
+const framedMarker uint16 = 0
+
+// maxUsage keeps the usage from wrapping around to the framedMarker
+const maxUsage uint16 = math.MaxUint16
+
+// encryptedFlag marks the encrypted content in the codec byte of the framed header
+const encryptedFlag uint8 = 1 << 7
 
 type FileHeader struct {
 	inner *os.File
 
-	usage uint16 // 2 bytes
+	usage uint16 // 2 bytes, follows the framedMarker when the block file is framed
+
+	// available only when the block file is framed
+	codec     Codec  // 1 byte, the highest bit is the encryptedFlag
+	size      uint32 // 4 bytes, logical size of the content
+	encrypted bool
+
+	// available only when the block file is encrypted
+	keyId uint32 // 4 bytes
 }
 
 func NewFileHeader(file *os.File) *FileHeader {
-	return &FileHeader{
+	h := &FileHeader{
 		inner: file,
 		usage: 1,
 	}
+	h.reset(CurrentCodec, CurrentKeyring)
+	return h
 }
 
 func (h *FileHeader) Size() int64 {
+	if h.Encrypted() {
+		return encryptedHeaderSize
+	}
+	if h.Framed() {
+		return framedHeaderSize
+	}
 	return headerSize
 }
 
@@ -38,8 +68,34 @@ func (h *FileHeader) Load() error {
 		return err
 	}
 	h.usage = usage
+	h.codec = CodecNone
+	h.size = 0
+	h.encrypted = false
+	h.keyId = 0
+
+	if usage != framedMarker {
+		return nil
+	}
+
+	if err := binary.Read(h.inner, binary.LittleEndian, &h.usage); err != nil {
+		return err
+	}
+
+	var codec uint8
+	if err := binary.Read(h.inner, binary.LittleEndian, &codec); err != nil {
+		return err
+	}
+	h.codec = Codec(codec &^ encryptedFlag)
+	h.encrypted = codec&encryptedFlag > 0
 
-	return nil
+	if err := binary.Read(h.inner, binary.LittleEndian, &h.size); err != nil {
+		return err
+	}
+
+	if !h.encrypted {
+		return nil
+	}
+	return binary.Read(h.inner, binary.LittleEndian, &h.keyId)
 }
 
 func (h *FileHeader) Usage() uint16 {
@@ -47,6 +103,10 @@ func (h *FileHeader) Usage() uint16 {
 }
 
 func (h *FileHeader) IncreaseUsage() error {
+	if h.usage == maxUsage {
+		return os.ErrInvalid
+	}
+
 	h.usage++
 	return h.save()
 }
@@ -64,19 +124,102 @@ func (h *FileHeader) ResetUsage(usage uint16) error {
 	if usage < 1 {
 		usage = 1
 	}
+	if usage > maxUsage {
+		usage = maxUsage
+	}
 
 	h.usage = usage
 	return h.save()
 }
 
+// Framed checks the content of the block file is kept in frames
+func (h *FileHeader) Framed() bool {
+	return h.Compressed() || h.Encrypted()
+}
+
+// Compressed checks the content of the block file is kept in compressed frames
+func (h *FileHeader) Compressed() bool {
+	return h.codec != CodecNone
+}
+
+// Encrypted checks the content of the block file is kept in encrypted frames
+func (h *FileHeader) Encrypted() bool {
+	return h.encrypted
+}
+
+// KeyId returns the id of the key that the content is encrypted with
+func (h *FileHeader) KeyId() uint32 {
+	return h.keyId
+}
+
+// Codec returns the compression algorithm of the content
+func (h *FileHeader) Codec() Codec {
+	return h.codec
+}
+
+// ContentSize returns the logical size of the framed content
+func (h *FileHeader) ContentSize() uint32 {
+	return h.size
+}
+
+// Reset prepares the header for the new content that will be compressed with the codec and encrypted with the
+// current key of the keyring. Content is not encrypted when the keyring is nil
+func (h *FileHeader) Reset(codec Codec, keyring *Keyring) error {
+	h.reset(codec, keyring)
+	return h.save()
+}
+
+func (h *FileHeader) reset(codec Codec, keyring *Keyring) {
+	h.codec = codec
+	h.size = 0
+	h.encrypted = keyring != nil
+	h.keyId = 0
+	if h.encrypted {
+		h.keyId = keyring.Current()
+	}
+}
+
+// SetContentSize saves the logical size of the framed content
+func (h *FileHeader) SetContentSize(size uint32) error {
+	h.size = size
+	return h.save()
+}
+
+// SetKeyId saves the id of the key that the content is encrypted with
+func (h *FileHeader) SetKeyId(keyId uint32) error {
+	h.keyId = keyId
+	return h.save()
+}
+
 func (h *FileHeader) save() error {
 	if _, err := h.inner.Seek(0, io.SeekStart); err != nil {
 		return err
 	}
 
+	if !h.Framed() {
+		return binary.Write(h.inner, binary.LittleEndian, h.usage)
+	}
+
+	if err := binary.Write(h.inner, binary.LittleEndian, framedMarker); err != nil {
+		return err
+	}
 	if err := binary.Write(h.inner, binary.LittleEndian, h.usage); err != nil {
 		return err
 	}
 
-	return nil
+	codec := uint8(h.codec)
+	if h.encrypted {
+		codec |= encryptedFlag
+	}
+	if err := binary.Write(h.inner, binary.LittleEndian, codec); err != nil {
+		return err
+	}
+	if err := binary.Write(h.inner, binary.LittleEndian, h.size); err != nil {
+		return err
+	}
+
+	if !h.encrypted {
+		return nil
+	}
+	return binary.Write(h.inner, binary.LittleEndian, h.keyId)
 }
@@ -0,0 +1,313 @@
+package block
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func createTestFile(t *testing.T, root string, codec Codec, content []byte) string {
+	CurrentCodec = codec
+	defer func() { CurrentCodec = CodecNone }()
+
+	sum := sha512.Sum512_256(content)
+	sha512Hex := hex.EncodeToString(sum[:])
+
+	f, err := NewFile(root, sha512Hex, zap.NewNop())
+	assert.Nil(t, err)
+
+	// written in pieces to cover the frames that are collected from several writes
+	for i := 0; i < len(content); i += 300 * 1024 {
+		end := i + 300*1024
+		if end > len(content) {
+			end = len(content)
+		}
+		assert.Nil(t, f.Write(content[i:end]))
+	}
+	assert.True(t, f.Verify())
+	f.Close()
+
+	return sha512Hex
+}
+
+func readTestFile(t *testing.T, root string, sha512Hex string, begins uint32, ends uint32) []byte {
+	f, err := NewFile(root, sha512Hex, zap.NewNop())
+	assert.Nil(t, err)
+	defer f.Close()
+
+	result := make([]byte, 0)
+	assert.Nil(t, f.Read(begins, ends,
+		func(data []byte) error {
+			result = append(result, data...)
+			return nil
+		},
+		func(inconsistency bool) error {
+			assert.False(t, inconsistency)
+			return nil
+		}))
+	return result
+}
+
+func TestFile_Compression(t *testing.T) {
+	content := bytes.Repeat([]byte("kertish-dfs block compression "), 100000) // ~2.9mb
+
+	for _, codec := range []Codec{CodecNone, CodecZstd, CodecSnappy} {
+		root, err := os.MkdirTemp("", "block")
+		assert.Nil(t, err)
+
+		sha512Hex := createTestFile(t, root, codec, content)
+
+		f, err := NewFile(root, sha512Hex, zap.NewNop())
+		assert.Nil(t, err)
+		assert.False(t, f.Temporary())
+		size, err := f.Size()
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(len(content)), size)
+		assert.True(t, f.VerifyForce())
+		assert.Nil(t, f.IncreaseUsage())
+		f.Close()
+
+		info, err := os.Stat(root + "/" + sha512Hex)
+		assert.Nil(t, err)
+		if codec == CodecNone {
+			assert.Equal(t, int64(len(content))+headerSize, info.Size())
+		} else {
+			assert.Less(t, info.Size(), int64(len(content)))
+		}
+
+		assert.Equal(t, content, readTestFile(t, root, sha512Hex, 0, 0))
+		assert.Equal(t, content[100:200], readTestFile(t, root, sha512Hex, 100, 200))
+		assert.Equal(t, content[chunkSize-10:chunkSize+10], readTestFile(t, root, sha512Hex, chunkSize-10, chunkSize+10))
+		assert.Equal(t, content[2*chunkSize+5:], readTestFile(t, root, sha512Hex, 2*chunkSize+5, uint32(len(content))))
+
+		f, err = NewFile(root, sha512Hex, zap.NewNop())
+		assert.Nil(t, err)
+		assert.Equal(t, uint16(2), f.Usage())
+		f.Close()
+
+		_ = os.RemoveAll(root)
+	}
+}
+
+func TestFile_LegacyHeader(t *testing.T) {
+	content := bytes.Repeat([]byte("legacy "), 1000)
+
+	root, err := os.MkdirTemp("", "block")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	sum := sha512.Sum512_256(content)
+	sha512Hex := hex.EncodeToString(sum[:])
+
+	// plain header of the block file that is shared more than 32767 times
+	header := make([]byte, headerSize)
+	binary.LittleEndian.PutUint16(header, 40000)
+	assert.Nil(t, os.WriteFile(path.Join(root, sha512Hex), append(header, content...), 0644))
+
+	f, err := NewFile(root, sha512Hex, zap.NewNop())
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(40000), f.Usage())
+	size, err := f.Size()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(len(content)), size)
+	assert.True(t, f.VerifyForce())
+	assert.Nil(t, f.ResetUsage(maxUsage))
+	assert.Equal(t, os.ErrInvalid, f.IncreaseUsage())
+	f.Close()
+
+	assert.Equal(t, content, readTestFile(t, root, sha512Hex, 0, 0))
+}
+
+func TestFile_Truncate(t *testing.T) {
+	content := bytes.Repeat([]byte("truncate "), 1000)
+
+	root, err := os.MkdirTemp("", "block")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	sha512Hex := createTestFile(t, root, CodecNone, content)
+
+	// the uncompressed block file is rewritten with the compression
+	CurrentCodec = CodecZstd
+	defer func() { CurrentCodec = CodecNone }()
+
+	f, err := NewFile(root, sha512Hex, zap.NewNop())
+	assert.Nil(t, err)
+	assert.Nil(t, f.Truncate(uint32(len(content))))
+	assert.Nil(t, f.Write(content))
+	assert.True(t, f.Verify())
+	f.Close()
+
+	f, err = NewFile(root, sha512Hex, zap.NewNop())
+	assert.Nil(t, err)
+	assert.True(t, f.VerifyForce())
+	f.Close()
+
+	assert.Equal(t, content, readTestFile(t, root, sha512Hex, 0, 0))
+}
+
+func TestFile_VerifyAs(t *testing.T) {
+	chunk := bytes.Repeat([]byte("chunk "), 1000)
+	shard := chunk[:len(chunk)/2]
+
+	root, err := os.MkdirTemp("", "block")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	chunkSum := sha512.Sum512_256(chunk)
+	chunkSha512Hex := hex.EncodeToString(chunkSum[:])
+	shardSum := sha512.Sum512_256(shard)
+	shardSha512Hex := hex.EncodeToString(shardSum[:])
+
+	// the shard is kept with the name of its chunk
+	f, err := NewFile(root, chunkSha512Hex, zap.NewNop())
+	assert.Nil(t, err)
+	assert.Nil(t, f.Write(shard))
+	assert.False(t, f.VerifyAs(chunkSha512Hex))
+	f.Close()
+
+	f, err = NewFile(root, chunkSha512Hex, zap.NewNop())
+	assert.Nil(t, err)
+	assert.True(t, f.Temporary())
+	assert.Nil(t, f.Write(shard))
+	assert.True(t, f.VerifyAs(shardSha512Hex))
+	f.Close()
+
+	assert.Equal(t, shard, readTestFile(t, root, chunkSha512Hex, 0, 0))
+}
+
+func TestFile_Encryption(t *testing.T) {
+	content := bytes.Repeat([]byte("kertish-dfs block encryption "), 100000) // ~2.9mb
+
+	keyring, err := ParseKeyring(strings.NewReader(testKeyfile))
+	assert.Nil(t, err)
+
+	for _, codec := range []Codec{CodecNone, CodecZstd} {
+		root, err := os.MkdirTemp("", "block")
+		assert.Nil(t, err)
+
+		CurrentKeyring = keyring
+		sha512Hex := createTestFile(t, root, codec, content)
+
+		raw, err := os.ReadFile(path.Join(root, sha512Hex))
+		assert.Nil(t, err)
+		assert.False(t, bytes.Contains(raw, []byte("kertish-dfs block encryption")))
+
+		f, err := NewFile(root, sha512Hex, zap.NewNop())
+		assert.Nil(t, err)
+		size, err := f.Size()
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(len(content)), size)
+		assert.True(t, f.VerifyForce())
+		f.Close()
+
+		assert.Equal(t, content, readTestFile(t, root, sha512Hex, 0, 0))
+		assert.Equal(t, content[chunkSize-10:chunkSize+10], readTestFile(t, root, sha512Hex, chunkSize-10, chunkSize+10))
+
+		// the encrypted block file is not readable without the keyring
+		CurrentKeyring = nil
+		f, err = NewFile(root, sha512Hex, zap.NewNop())
+		assert.Nil(t, err)
+		assert.False(t, f.VerifyForce())
+		f.Close()
+
+		_ = os.RemoveAll(root)
+	}
+}
+
+func TestFile_Rotate(t *testing.T) {
+	content := bytes.Repeat([]byte("rotate "), 300000) // ~2mb
+
+	root, err := os.MkdirTemp("", "block")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	defer func() { CurrentKeyring = nil }()
+
+	CurrentKeyring, err = ParseKeyring(strings.NewReader(testKeyfile))
+	assert.Nil(t, err)
+	sha512Hex := createTestFile(t, root, CodecSnappy, content)
+
+	// the snapshot shares the block file with the root
+	snapshotPath := path.Join(root, "snapshot")
+	assert.Nil(t, os.Mkdir(snapshotPath, 0777))
+	assert.Nil(t, os.Link(path.Join(root, sha512Hex), path.Join(snapshotPath, sha512Hex)))
+
+	info, err := os.Stat(path.Join(root, sha512Hex))
+	assert.Nil(t, err)
+
+	CurrentKeyring, err = ParseKeyring(strings.NewReader(testKeyfile + testRotatedKey))
+	assert.Nil(t, err)
+
+	f, err := NewFile(root, sha512Hex, zap.NewNop())
+	assert.Nil(t, err)
+	rotated, err := f.Rotate()
+	assert.Nil(t, err)
+	assert.True(t, rotated)
+	rotated, err = f.Rotate()
+	assert.Nil(t, err)
+	assert.False(t, rotated)
+	f.Close()
+
+	rotatedInfo, err := os.Stat(path.Join(root, sha512Hex))
+	assert.Nil(t, err)
+	assert.Equal(t, info.Size(), rotatedInfo.Size())
+
+	// the old key is not needed anymore
+	CurrentKeyring, err = ParseKeyring(strings.NewReader(testRotatedKey))
+	assert.Nil(t, err)
+
+	assert.Equal(t, content, readTestFile(t, root, sha512Hex, 0, 0))
+	assert.Equal(t, content, readTestFile(t, snapshotPath, sha512Hex, 0, 0))
+
+	f, err = NewFile(snapshotPath, sha512Hex, zap.NewNop())
+	assert.Nil(t, err)
+	rotated, err = f.Rotate()
+	assert.Nil(t, err)
+	assert.False(t, rotated)
+	f.Close()
+}
+
+func TestFile_RotateWhileReading(t *testing.T) {
+	content := bytes.Repeat([]byte("rotate while reading "), 200000) // ~4mb
+
+	root, err := os.MkdirTemp("", "block")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	defer func() { CurrentKeyring = nil }()
+
+	CurrentKeyring, err = ParseKeyring(strings.NewReader(testKeyfile))
+	assert.Nil(t, err)
+	sha512Hex := createTestFile(t, root, CodecNone, content)
+
+	CurrentKeyring, err = ParseKeyring(strings.NewReader(testKeyfile + testRotatedKey))
+	assert.Nil(t, err)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				assert.Equal(t, content, readTestFile(t, root, sha512Hex, 0, 0))
+			}
+		}()
+	}
+
+	f, err := NewFile(root, sha512Hex, zap.NewNop())
+	assert.Nil(t, err)
+	rotated, err := f.Rotate()
+	assert.Nil(t, err)
+	assert.True(t, rotated)
+	f.Close()
+
+	wg.Wait()
+}
@@ -0,0 +1,147 @@
+package block
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+)
+
+// Keyring keeps the keys of the block file encryption by their ids
+type Keyring struct {
+	current uint32
+	order   []uint32
+	keys    map[uint32]cipher.AEAD
+}
+
+// CurrentKeyring is the keyring of the block files that are created on this data node. Block files are encrypted
+// with the current key of the keyring when it is set. Existent block files keep the key id that is recorded in
+// their header
+var CurrentKeyring *Keyring
+
+// LoadKeyring reads the keyring from the keyfile
+func LoadKeyring(keyfilePath string) (*Keyring, error) {
+	keyfile, err := os.Open(keyfilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = keyfile.Close() }()
+
+	return ParseKeyring(keyfile)
+}
+
+// ParseKeyring creates the keyring from the keyfile content. Every line of the content should be in [keyId]:[key]
+// format where the key id is a positive numeric value and the key is 16, 24 or 32 bytes in hex. Empty lines and the
+// lines start with # are skipped. The key in the last line is the current key of the keyring
+func ParseKeyring(reader io.Reader) (*Keyring, error) {
+	k := &Keyring{
+		order: make([]uint32, 0),
+		keys:  make(map[uint32]cipher.AEAD),
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if len(entry) == 0 || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		colonIdx := strings.Index(entry, ":")
+		if colonIdx == -1 {
+			return nil, fmt.Errorf("keyfile line %d should be in keyId:key format", line)
+		}
+
+		keyId, err := strconv.ParseUint(entry[:colonIdx], 10, 32)
+		if err != nil || keyId == 0 {
+			return nil, fmt.Errorf("keyfile line %d should have a positive numeric key id", line)
+		}
+		if _, has := k.keys[uint32(keyId)]; has {
+			return nil, fmt.Errorf("keyfile line %d has a duplicate key id", line)
+		}
+
+		key, err := hex.DecodeString(entry[colonIdx+1:])
+		if err != nil {
+			return nil, fmt.Errorf("keyfile line %d should have the key in hex", line)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("keyfile line %d should have 16, 24 or 32 bytes key", line)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		k.current = uint32(keyId)
+		k.order = append(k.order, k.current)
+		k.keys[k.current] = aead
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(k.keys) == 0 {
+		return nil, fmt.Errorf("keyfile does not have any key")
+	}
+
+	return k, nil
+}
+
+// Current returns the id of the key that the new content is encrypted with
+func (k *Keyring) Current() uint32 {
+	return k.current
+}
+
+// seal encrypts the data with the key and prepends the random nonce to the result. Result is always longer than
+// the data in the same size, so the sealed frames can be replaced in place
+func (k *Keyring) seal(keyId uint32, data []byte) ([]byte, error) {
+	aead, has := k.keys[keyId]
+	if !has {
+		return nil, errors.ErrKeyNotFound
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// open decrypts the sealed data with the key. The other keys of the keyring are tried when the key fails, because
+// the frames of a block file can be sealed with the next key when its rotation is interrupted
+func (k *Keyring) open(keyId uint32, sealed []byte) ([]byte, error) {
+	if aead, has := k.keys[keyId]; has {
+		if data, err := k.openWith(aead, sealed); err == nil {
+			return data, nil
+		}
+	}
+
+	for _, id := range k.order {
+		if id == keyId {
+			continue
+		}
+		if data, err := k.openWith(k.keys[id], sealed); err == nil {
+			return data, nil
+		}
+	}
+
+	return nil, errors.ErrKeyNotFound
+}
+
+func (k *Keyring) openWith(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+}
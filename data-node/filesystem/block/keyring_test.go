@@ -0,0 +1,44 @@
+package block
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKeyfile = `# keys of the block file encryption
+1:000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
+`
+const testRotatedKey = `2:202122232425262728292a2b2c2d2e2f
+`
+
+func TestParseKeyring(t *testing.T) {
+	k, err := ParseKeyring(strings.NewReader(testKeyfile + "\n" + testRotatedKey))
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), k.Current())
+
+	sealed, err := k.seal(1, []byte("content"))
+	assert.Nil(t, err)
+	data, err := k.open(1, sealed)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("content"), data)
+
+	// the frames of an interrupted rotation are opened with the other keys
+	data, err = k.open(2, sealed)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("content"), data)
+
+	sealed[len(sealed)-1]++
+	_, err = k.open(1, sealed)
+	assert.NotNil(t, err)
+
+	_, err = ParseKeyring(strings.NewReader(""))
+	assert.NotNil(t, err)
+	_, err = ParseKeyring(strings.NewReader("0:000102030405060708090a0b0c0d0e0f"))
+	assert.NotNil(t, err)
+	_, err = ParseKeyring(strings.NewReader("1:0001020304"))
+	assert.NotNil(t, err)
+	_, err = ParseKeyring(strings.NewReader(testKeyfile + testKeyfile))
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"sync"
@@ -25,6 +26,8 @@ type Manager interface {
 
 	Wipe() error
 	Used() (uint64, error)
+	UsedLogical() (uint64, uint64, error)
+	RotateKeys() error
 }
 
 type manager struct {
@@ -36,6 +39,12 @@ type manager struct {
 	synchronize Synchronize
 
 	managerMutex sync.Mutex
+
+	logicalMutex sync.Mutex
+	logicalSizes map[string]uint64
+
+	rotationMutex sync.Mutex
+	rotating      bool
 }
 
 // NewManager creates the instance of data node operations manager
@@ -58,6 +67,12 @@ func NewManager(rootPath string, logger *zap.Logger) (Manager, error) {
 		snapshot:     ss,
 		synchronize:  s,
 		managerMutex: sync.Mutex{},
+
+		logicalMutex: sync.Mutex{},
+		logicalSizes: make(map[string]uint64),
+
+		rotationMutex: sync.Mutex{},
+		rotating:      false,
 	}, nil
 }
 
@@ -118,43 +133,181 @@ func (m *manager) Wipe() error {
 	return nil
 }
 
+// Used returns the physical size of the block files in the root and the snapshots. Block files that are shared
+// between the root and the snapshots are counted once
 func (m *manager) Used() (uint64, error) {
 	sha512HexMap := make(map[string]uint64)
 
-	// fill for root
-	if err := m.block.Traverse(func(sha512Hex string, size uint64) error {
-		sha512HexMap[sha512Hex] = size
-		return nil
+	if err := m.traverseAll(func(_ block.Manager) func(sha512Hex string, size uint64) error {
+		return func(sha512Hex string, size uint64) error {
+			sha512HexMap[sha512Hex] = size
+			return nil
+		}
 	}); err != nil {
 		return 0, err
 	}
 
+	used := uint64(0)
+	for _, v := range sha512HexMap {
+		used += v
+	}
+
+	return used, nil
+}
+
+// UsedLogical returns the physical and the logical (uncompressed) sizes of the block files in the root and
+// the snapshots. Logical size of the block file is read from its header once and kept with its name because
+// the block files are named with the hash of their content
+func (m *manager) UsedLogical() (uint64, uint64, error) {
+	m.logicalMutex.Lock()
+	defer m.logicalMutex.Unlock()
+
+	sha512HexMap := make(map[string]uint64)
+	logicalSizes := make(map[string]uint64)
+
+	if err := m.traverseAll(func(b block.Manager) func(sha512Hex string, size uint64) error {
+		return func(sha512Hex string, size uint64) error {
+			sha512HexMap[sha512Hex] = size
+
+			if _, has := logicalSizes[sha512Hex]; has {
+				return nil
+			}
+			if logicalSize, has := m.logicalSizes[sha512Hex]; has {
+				logicalSizes[sha512Hex] = logicalSize
+				return nil
+			}
+
+			return b.File(sha512Hex, func(file block.File) error {
+				logicalSize, err := file.Size()
+				if err != nil {
+					return err
+				}
+				logicalSizes[sha512Hex] = uint64(logicalSize)
+				return nil
+			})
+		}
+	}); err != nil {
+		return 0, 0, err
+	}
+	m.logicalSizes = logicalSizes
+
+	used := uint64(0)
+	for _, v := range sha512HexMap {
+		used += v
+	}
+
+	logical := uint64(0)
+	for _, v := range logicalSizes {
+		logical += v
+	}
+
+	return used, logical, nil
+}
+
+// traverseAll traverses the block files of the root and the snapshots
+func (m *manager) traverseAll(handler func(b block.Manager) func(sha512Hex string, size uint64) error) error {
+	// fill for root
+	if err := m.block.Traverse(handler(m.block)); err != nil {
+		return err
+	}
+
 	// fill for snapshotDates
 	snapshotDates, err := m.snapshot.Dates()
 	if err != nil {
-		return 0, err
+		return err
 	}
 
 	for _, snapshotDate := range snapshotDates {
 		snapshotBlock, err := m.snapshot.Block(snapshotDate)
 		if err != nil {
-			return 0, err
+			return err
 		}
 
-		if err := snapshotBlock.Traverse(func(sha512Hex string, size uint64) error {
-			sha512HexMap[sha512Hex] = size
+		if err := snapshotBlock.Traverse(handler(snapshotBlock)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RotateKeys re-encrypts the block files of the root and the snapshots with the current key of the keyring in
+// the background. Request is ignored when the rotation is already running or the encryption is not enabled
+func (m *manager) RotateKeys() error {
+	if block.CurrentKeyring == nil {
+		return nil
+	}
+
+	m.rotationMutex.Lock()
+	defer m.rotationMutex.Unlock()
+
+	if m.rotating {
+		return nil
+	}
+	m.rotating = true
+
+	go m.rotateKeys()
+
+	return nil
+}
+
+func (m *manager) rotateKeys() {
+	defer func() {
+		m.rotationMutex.Lock()
+		defer m.rotationMutex.Unlock()
+
+		m.rotating = false
+	}()
+
+	m.logger.Info("Key rotation is started")
+
+	rotated := 0
+	failed := 0
+	rotateHandler := func(b block.Manager) func(sha512Hex string, size uint64) error {
+		return func(sha512Hex string, _ uint64) error {
+			if err := b.File(sha512Hex, func(file block.File) error {
+				fileRotated, err := file.Rotate()
+				if fileRotated {
+					rotated++
+				}
+				return err
+			}); err != nil {
+				failed++
+				m.logger.Error("Key rotation of block file is failed", zap.String("sha512Hex", sha512Hex), zap.Error(err))
+			}
 			return nil
-		}); err != nil {
-			return 0, err
 		}
 	}
 
-	used := uint64(0)
-	for _, v := range sha512HexMap {
-		used += v
+	// block files of the snapshots are linked to the root, so most of them are rotated with the root
+	if err := m.block.Traverse(rotateHandler(m.block)); err != nil {
+		m.logger.Error("Key rotation is failed", zap.Error(err))
+		return
 	}
 
-	return used, nil
+	snapshotDates, err := m.snapshot.Dates()
+	if err != nil {
+		m.logger.Error("Key rotation is failed", zap.Error(err))
+		return
+	}
+
+	for _, snapshotDate := range snapshotDates {
+		snapshotBlock, err := m.snapshot.Block(snapshotDate)
+		if err != nil {
+			m.logger.Error("Key rotation of snapshot is failed", zap.Time("snapshot", snapshotDate), zap.Error(err))
+			continue
+		}
+
+		if err := snapshotBlock.Traverse(rotateHandler(snapshotBlock)); err != nil {
+			m.logger.Error("Key rotation of snapshot is failed", zap.Time("snapshot", snapshotDate), zap.Error(err))
+		}
+	}
+
+	m.logger.Info(
+		fmt.Sprintf("Key rotation is completed, %d block file(s) are rotated", rotated),
+		zap.Uint32("keyId", block.CurrentKeyring.Current()),
+		zap.Int("failed", failed),
+	)
 }
 
 var _ Manager = &manager{}
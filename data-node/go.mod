@@ -5,6 +5,7 @@ go 1.17
 require (
 	github.com/freakmaxi/kertish-dfs/basics v0.0.0-00010101000000-000000000000
 	github.com/google/uuid v1.2.0
+	github.com/klauspost/compress v1.9.5
 	github.com/stretchr/testify v1.7.0
 	go.uber.org/zap v1.16.0
 )
@@ -19,7 +20,7 @@ require (
 	github.com/rivo/uniseg v0.1.0 // indirect
 	go.uber.org/atomic v1.6.0 // indirect
 	go.uber.org/multierr v1.5.0 // indirect
-	golang.org/x/sys v0.0.0-20190626150813-e07cf5db2756 // indirect
+	golang.org/x/sys v0.7.0 // indirect
 	golang.org/x/text v0.3.5 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
@@ -9,9 +9,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
 	"github.com/freakmaxi/kertish-dfs/basics/logging"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 	"github.com/freakmaxi/kertish-dfs/data-node/cache"
 	"github.com/freakmaxi/kertish-dfs/data-node/filesystem"
+	"github.com/freakmaxi/kertish-dfs/data-node/filesystem/block"
 	"github.com/freakmaxi/kertish-dfs/data-node/manager"
 	"github.com/freakmaxi/kertish-dfs/data-node/service"
 	"go.uber.org/zap"
@@ -53,6 +56,31 @@ func main() {
 	}
 	logger.Info(fmt.Sprintf("MANAGER_ADDRESS: %s", managerAddress))
 
+	var managerCredentials *auth.Credentials
+	managerCredentialsString := os.Getenv("MANAGER_CREDENTIALS")
+	if len(managerCredentialsString) > 0 {
+		managerCredentials, err = auth.ParseCredentials(managerCredentialsString)
+		if err != nil {
+			logger.Error("Manager Credentials should be in [keyId]:[secret] format")
+			os.Exit(11)
+		}
+	}
+	logger.Info(fmt.Sprintf("MANAGER_CREDENTIALS: %t", managerCredentials != nil))
+
+	tlsCertPath := os.Getenv("TLS_CERT_PATH")
+	if len(tlsCertPath) > 0 {
+		tlsVerifyClient := len(os.Getenv("TLS_VERIFY_CLIENT")) > 0
+		secure.CurrentConfig, err = secure.NewConfig(tlsCertPath, os.Getenv("TLS_KEY_PATH"), os.Getenv("TLS_CA_PATH"), tlsVerifyClient)
+		if err != nil {
+			logger.Error("TLS setup is failed", zap.Error(err))
+			os.Exit(12)
+		}
+		logger.Info(fmt.Sprintf("TLS_CERT_PATH: %s", tlsCertPath))
+		logger.Info(fmt.Sprintf("TLS_VERIFY_CLIENT: %t", tlsVerifyClient))
+	} else {
+		logger.Info("TLS_CERT_PATH: disabled")
+	}
+
 	sizeString := os.Getenv("SIZE")
 	if len(sizeString) == 0 {
 		logger.Error("SIZE have to be specified")
@@ -75,12 +103,31 @@ func main() {
 	}
 	logger.Info(fmt.Sprintf("ROOT_PATH: %s", rootPath))
 
+	block.CurrentCodec, err = block.ParseCodec(os.Getenv("COMPRESSION"))
+	if err != nil {
+		logger.Error("Compression should be one of none, zstd or snappy")
+		os.Exit(70)
+	}
+	logger.Info(fmt.Sprintf("COMPRESSION: %s", block.CurrentCodec))
+
+	encryptionKeyfile := os.Getenv("ENCRYPTION_KEYFILE")
+	if len(encryptionKeyfile) > 0 {
+		block.CurrentKeyring, err = block.LoadKeyring(encryptionKeyfile)
+		if err != nil {
+			logger.Error("Encryption keyfile is not readable", zap.Error(err))
+			os.Exit(71)
+		}
+		logger.Info(fmt.Sprintf("ENCRYPTION_KEYFILE: %s (key id: %d)", encryptionKeyfile, block.CurrentKeyring.Current()))
+	} else {
+		logger.Info("ENCRYPTION_KEYFILE: disabled")
+	}
+
 	m, err := filesystem.NewManager(rootPath, logger)
 	if err != nil {
 		logger.Error("File System Manager creation is failed", zap.Error(err))
 		os.Exit(80)
 	}
-	n := manager.NewNode(hardwareAddr, bindAddr, size, strings.Split(managerAddress, ","), logger)
+	n := manager.NewNode(hardwareAddr, bindAddr, size, strings.Split(managerAddress, ","), managerCredentials, logger)
 
 	cacheLifetime := 360
 	cacheLimitString := os.Getenv("CACHE_LIMIT")
@@ -12,7 +12,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
 	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 	"go.uber.org/zap"
 )
 
@@ -58,7 +60,7 @@ type node struct {
 	nextProcessList map[string]*common.NotificationContainer
 }
 
-func NewNode(hardwareAddr string, bindAddr string, nodeSize uint64, managerAddresses []string, logger *zap.Logger) Node {
+func NewNode(hardwareAddr string, bindAddr string, nodeSize uint64, managerAddresses []string, managerCredentials *auth.Credentials, logger *zap.Logger) Node {
 	node := &node{
 		hardwareAddr: hardwareAddr,
 		bindAddr:     bindAddr,
@@ -66,7 +68,7 @@ func NewNode(hardwareAddr string, bindAddr string, nodeSize uint64, managerAddre
 
 		nodeId: calculateNodeId(hardwareAddr, bindAddr, nodeSize),
 
-		client:      http.Client{},
+		client:      http.Client{Transport: auth.NewTransport(managerCredentials, secure.HttpTransport())},
 		managerAddr: managerAddresses,
 		logger:      logger,
 
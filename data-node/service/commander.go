@@ -118,6 +118,8 @@ func (c *commander) process(command string, conn net.Conn) error {
 	switch command {
 	case "CREA":
 		return c.crea(conn)
+	case "SHRD":
+		return c.shrd(conn)
 	case "READ":
 		return c.read(conn)
 	case "DELE":
@@ -152,10 +154,14 @@ func (c *commander) process(command string, conn net.Conn) error {
 		return c.ssrs(conn)
 	case "WIPE":
 		return c.wipe()
+	case "ROTK":
+		return c.rotk()
 	case "SIZE":
 		return c.size(conn)
 	case "USED":
 		return c.used(conn)
+	case "USDL":
+		return c.usdl(conn)
 	case "RQHS":
 		return c.rqhs(conn)
 	case "PING":
@@ -189,10 +195,30 @@ func (c *commander) crea(conn net.Conn) error {
 		return err
 	}
 
+	return c.create(conn, sha512Hex, sha512Hex)
+}
+
+// shrd creates the shard of the erasure coded chunk. Shard is kept with the name of its chunk,
+// so the content is verified with the hash of the shard
+func (c *commander) shrd(conn net.Conn) error {
+	sha512Hex, err := c.hashAsHex(conn)
+	if err != nil {
+		return err
+	}
+
+	shardSha512Hex, err := c.hashAsHex(conn)
+	if err != nil {
+		return err
+	}
+
+	return c.create(conn, sha512Hex, shardSha512Hex)
+}
+
+func (c *commander) create(conn net.Conn, sha512Hex string, verifySha512Hex string) error {
 	var blockUsage uint16 = 1
 	var blockSize uint32
 
-	err = c.fs.Block(filesystem.Create).LockFile(sha512Hex, func(blockFile block.File) error {
+	err := c.fs.Block(filesystem.Create).LockFile(sha512Hex, func(blockFile block.File) error {
 		if !blockFile.Temporary() {
 			if err := blockFile.IncreaseUsage(); err != nil {
 				return err
@@ -215,6 +241,11 @@ func (c *commander) crea(conn net.Conn) error {
 			return err
 		}
 
+		// the whole block is kept in memory, so the size is limited
+		if blockSize > common.MaxBlockSize {
+			return fmt.Errorf("block size is over the limit, size: %d", blockSize)
+		}
+
 		chunkBuffer := make([]byte, blockSize)
 		if err := c.readWithTimeout(conn, chunkBuffer, len(chunkBuffer)); err != nil {
 			return err
@@ -224,7 +255,7 @@ func (c *commander) crea(conn net.Conn) error {
 			return err
 		}
 
-		if !blockFile.Verify() {
+		if !blockFile.VerifyAs(verifySha512Hex) {
 			return fmt.Errorf("file is not verified")
 		}
 
@@ -831,6 +862,10 @@ func (c *commander) wipe() error {
 	return nil
 }
 
+func (c *commander) rotk() error {
+	return c.fs.RotateKeys()
+}
+
 func (c *commander) size(conn net.Conn) error {
 	if err := c.writeWithTimeout(conn, []byte{'+'}); err != nil {
 		return err
@@ -852,6 +887,25 @@ func (c *commander) used(conn net.Conn) error {
 	return c.writeBinaryWithTimeout(conn, used)
 }
 
+// usdl replies the physical and the logical used sizes. It is a separate command to keep the reply of USED
+// as it is for the manager nodes that do not know the logical size
+func (c *commander) usdl(conn net.Conn) error {
+	used, logical, err := c.fs.UsedLogical()
+	if err != nil {
+		return err
+	}
+
+	if err := c.writeWithTimeout(conn, []byte{'+'}); err != nil {
+		return err
+	}
+
+	if err := c.writeBinaryWithTimeout(conn, used); err != nil {
+		return err
+	}
+
+	return c.writeBinaryWithTimeout(conn, logical)
+}
+
 func (c *commander) rqhs(conn net.Conn) error {
 	if err := c.node.Handshake(); err != nil {
 		return err
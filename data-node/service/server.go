@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 	"go.uber.org/zap"
 )
 
@@ -17,7 +18,7 @@ type server struct {
 	commander Commander
 	logger    *zap.Logger
 
-	listener *net.TCPListener
+	listener net.Listener
 	quiting  bool
 }
 
@@ -36,7 +37,7 @@ func NewServer(address string, c Commander, logger *zap.Logger) (Server, error)
 
 func (s *server) Listen() error {
 	var err error
-	s.listener, err = net.ListenTCP("tcp4", s.address)
+	s.listener, err = secure.Listen("tcp4", s.address.String())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,351 @@
+package dfs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+const encryptionAlgorithm = "aes-256-gcm"
+const encryptionSegmentSize int64 = 64 * 1024 // 64kb
+const maxEncryptionSegmentSize int64 = 16 * 1024 * 1024 // 16mb
+const encryptionSaltSize = 16
+const encryptionKeySize = 32
+const aeadOverhead = 16 // authentication tag size of each sealed segment
+const minKeyfileSize = 32
+
+const (
+	kdfScrypt = "scrypt"
+	kdfHkdf   = "hkdf"
+)
+
+// metadata keys of the encryption parameters, they are sent and received as X-Meta-* headers
+const (
+	metaEncryption        = "X-Meta-Encryption"
+	metaEncryptionKdf     = "X-Meta-Encryption-Kdf"
+	metaEncryptionSalt    = "X-Meta-Encryption-Salt"
+	metaEncryptionSegment = "X-Meta-Encryption-Segment"
+)
+
+// Encryption keeps the secret to encrypt the files before they are uploaded and to decrypt them after they are
+// downloaded, so the plain content never reaches the head node. Every file is encrypted with its own key that is
+// derived from the secret with a random salt
+type Encryption struct {
+	kdf    string
+	secret []byte
+}
+
+// NewPassphraseEncryption creates the encryption that derives the file keys from the passphrase using scrypt
+func NewPassphraseEncryption(passphrase string) (*Encryption, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase can not be empty")
+	}
+
+	return &Encryption{
+		kdf:    kdfScrypt,
+		secret: []byte(passphrase),
+	}, nil
+}
+
+// NewKeyfileEncryption creates the encryption that derives the file keys from the content of the keyfile using hkdf
+func NewKeyfileEncryption(keyfilePath string) (*Encryption, error) {
+	secret, err := os.ReadFile(keyfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read keyfile %s", keyfilePath)
+	}
+
+	if len(secret) < minKeyfileSize {
+		return nil, fmt.Errorf("keyfile %s should have at least %d bytes", keyfilePath, minKeyfileSize)
+	}
+
+	return &Encryption{
+		kdf:    kdfHkdf,
+		secret: secret,
+	}, nil
+}
+
+// encryptionParameters is the details of the file encryption that are stored in the file metadata. The content is
+// sealed in segments, so the ranges of the file can be downloaded and decrypted without the rest of the file
+type encryptionParameters struct {
+	kdf         string
+	salt        []byte
+	segmentSize int64
+}
+
+func newEncryptionParameters(kdf string) (*encryptionParameters, error) {
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return &encryptionParameters{
+		kdf:         kdf,
+		salt:        salt,
+		segmentSize: encryptionSegmentSize,
+	}, nil
+}
+
+// describeEncryptionParameters reads the encryption parameters from the metadata headers of the file.
+// It returns nil when the file is not encrypted
+func describeEncryptionParameters(header http.Header) (*encryptionParameters, error) {
+	algorithm := header.Get(metaEncryption)
+	if len(algorithm) == 0 {
+		return nil, nil
+	}
+	if strings.Compare(algorithm, encryptionAlgorithm) != 0 {
+		return nil, fmt.Errorf("encryption algorithm %s is not supported", algorithm)
+	}
+
+	kdf := header.Get(metaEncryptionKdf)
+	if strings.Compare(kdf, kdfScrypt) != 0 && strings.Compare(kdf, kdfHkdf) != 0 {
+		return nil, fmt.Errorf("key derivation %s is not supported", kdf)
+	}
+
+	salt, err := hex.DecodeString(header.Get(metaEncryptionSalt))
+	if err != nil || len(salt) == 0 {
+		return nil, fmt.Errorf("encryption salt is not valid")
+	}
+
+	segmentSize, err := strconv.ParseInt(header.Get(metaEncryptionSegment), 10, 64)
+	if err != nil || segmentSize <= 0 || segmentSize > maxEncryptionSegmentSize {
+		return nil, fmt.Errorf("encryption segment size is not valid")
+	}
+
+	return &encryptionParameters{
+		kdf:         kdf,
+		salt:        salt,
+		segmentSize: segmentSize,
+	}, nil
+}
+
+func (p *encryptionParameters) setHeaders(header http.Header) {
+	header.Set(metaEncryption, encryptionAlgorithm)
+	header.Set(metaEncryptionKdf, p.kdf)
+	header.Set(metaEncryptionSalt, hex.EncodeToString(p.salt))
+	header.Set(metaEncryptionSegment, strconv.FormatInt(p.segmentSize, 10))
+}
+
+func (p *encryptionParameters) sealedSegmentSize() int64 {
+	return p.segmentSize + aeadOverhead
+}
+
+// segments returns the segment count of the plain content. Empty content has a single empty segment to
+// authenticate the end of the content
+func (p *encryptionParameters) segments(plainSize int64) int64 {
+	if plainSize == 0 {
+		return 1
+	}
+	return (plainSize + p.segmentSize - 1) / p.segmentSize
+}
+
+func (p *encryptionParameters) encryptedSize(plainSize int64) int64 {
+	return plainSize + p.segments(plainSize)*aeadOverhead
+}
+
+func (p *encryptionParameters) plainSize(encryptedSize int64) int64 {
+	segments := (encryptedSize + p.sealedSegmentSize() - 1) / p.sealedSegmentSize()
+	return encryptedSize - segments*aeadOverhead
+}
+
+func (e *Encryption) aead(p *encryptionParameters) (cipher.AEAD, error) {
+	if strings.Compare(e.kdf, p.kdf) != 0 {
+		if strings.Compare(p.kdf, kdfScrypt) == 0 {
+			return nil, fmt.Errorf("file is encrypted with a passphrase")
+		}
+		return nil, fmt.Errorf("file is encrypted with a keyfile")
+	}
+
+	var key []byte
+	switch p.kdf {
+	case kdfScrypt:
+		var err error
+		key, err = scrypt.Key(e.secret, p.salt, 1<<15, 8, 1, encryptionKeySize)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		key = make([]byte, encryptionKeySize)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, e.secret, p.salt, []byte(encryptionAlgorithm)), key); err != nil {
+			return nil, err
+		}
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// segmentNonce creates the nonce of the segment from its index. Every file has its own key, so the index is
+// unique for the key. The last segment is marked to detect the truncated content
+func segmentNonce(index int64, final bool) []byte {
+	nonce := make([]byte, 12)
+	if final {
+		nonce[0] = 1
+	}
+	binary.BigEndian.PutUint64(nonce[4:], uint64(index))
+	return nonce
+}
+
+// encryptReader seals the plain content of the source in segments while it is read
+type encryptReader struct {
+	source      io.Reader
+	aead        cipher.AEAD
+	segmentSize int64
+	segments    int64
+
+	index   int64
+	pending []byte
+}
+
+func newEncryptReader(source io.Reader, aead cipher.AEAD, p *encryptionParameters, plainSize int64) io.Reader {
+	return &encryptReader{
+		source:      source,
+		aead:        aead,
+		segmentSize: p.segmentSize,
+		segments:    p.segments(plainSize),
+	}
+}
+
+func (r *encryptReader) Read(b []byte) (int, error) {
+	if len(r.pending) == 0 {
+		if r.index == r.segments {
+			return 0, io.EOF
+		}
+
+		segment := make([]byte, r.segmentSize)
+		n, err := io.ReadFull(r.source, segment)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+
+		final := r.index == r.segments-1
+		if !final && int64(n) != r.segmentSize {
+			return 0, io.ErrUnexpectedEOF
+		}
+
+		r.pending = r.aead.Seal(nil, segmentNonce(r.index, final), segment[:n], nil)
+		r.index++
+	}
+
+	n := copy(b, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// encryptedRange is the range of the sealed segments that covers the requested range of the plain content
+type encryptedRange struct {
+	parameters *encryptionParameters
+
+	begins      int64
+	ends        int64
+	firstIndex  int64
+	finalIndex  int64
+	skip        int64
+	plainLength int64
+}
+
+// newFullEncryptedRange creates the range that covers the whole content
+func newFullEncryptedRange(p *encryptionParameters, encryptedSize int64) (*encryptedRange, error) {
+	if encryptedSize < aeadOverhead {
+		return nil, fmt.Errorf("encrypted content is corrupted")
+	}
+
+	plainSize := p.plainSize(encryptedSize)
+	return &encryptedRange{
+		parameters:  p,
+		begins:      0,
+		ends:        encryptedSize - 1,
+		firstIndex:  0,
+		finalIndex:  p.segments(plainSize) - 1,
+		skip:        0,
+		plainLength: plainSize,
+	}, nil
+}
+
+// newEncryptedRange creates the range of the segments for the inclusive range of the plain content. Negative end
+// points the end of the content
+func newEncryptedRange(p *encryptionParameters, encryptedSize int64, begins int64, ends int64) (*encryptedRange, error) {
+	plainSize := p.plainSize(encryptedSize)
+	if begins >= plainSize {
+		return nil, fmt.Errorf("range is out of the file size")
+	}
+	if ends < 0 || ends >= plainSize {
+		ends = plainSize - 1
+	}
+
+	firstIndex := begins / p.segmentSize
+	lastIndex := ends / p.segmentSize
+
+	encryptedEnds := (lastIndex + 1) * p.sealedSegmentSize()
+	if encryptedEnds > encryptedSize {
+		encryptedEnds = encryptedSize
+	}
+
+	return &encryptedRange{
+		parameters:  p,
+		begins:      firstIndex * p.sealedSegmentSize(),
+		ends:        encryptedEnds - 1,
+		firstIndex:  firstIndex,
+		finalIndex:  p.segments(plainSize) - 1,
+		skip:        begins - firstIndex*p.segmentSize,
+		plainLength: ends - begins + 1,
+	}, nil
+}
+
+// decrypt opens the sealed segments of the range from the reader and writes the plain content to the writer
+func (e *Encryption) decrypt(reader io.Reader, writer io.Writer, r *encryptedRange) error {
+	aead, err := e.aead(r.parameters)
+	if err != nil {
+		return err
+	}
+
+	skip := r.skip
+	remaining := r.plainLength
+
+	sealed := make([]byte, r.parameters.sealedSegmentSize())
+	for index := r.firstIndex; remaining > 0 || index == r.firstIndex; index++ {
+		if index > r.finalIndex {
+			return fmt.Errorf("encrypted content is corrupted")
+		}
+
+		n, err := io.ReadFull(reader, sealed)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			if err == io.EOF {
+				return fmt.Errorf("encrypted content is truncated")
+			}
+			return err
+		}
+
+		segment, err := aead.Open(nil, segmentNonce(index, index == r.finalIndex), sealed[:n], nil)
+		if err != nil {
+			return fmt.Errorf("unable to decrypt, the key is not matching or the content is corrupted")
+		}
+
+		segment = segment[skip:]
+		skip = 0
+
+		if int64(len(segment)) > remaining {
+			segment = segment[:remaining]
+		}
+		if _, err := writer.Write(segment); err != nil {
+			return err
+		}
+		remaining -= int64(len(segment))
+	}
+
+	return nil
+}
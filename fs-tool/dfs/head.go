@@ -1,6 +1,7 @@
 package dfs
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,50 +13,118 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
 	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 )
 
 const headEndPoint = "/client/dfs"
+const searchEndPoint = "/client/search"
+const versionEndPoint = "/client/version"
+const trashEndPoint = "/client/trash"
+const quotaEndPoint = "/client/quota"
+const aclEndPoint = "/client/acl"
 
 var client = http.Client{}
+var credentials *auth.Credentials
 
+// SetCredentials makes the requests to head node to be signed with the credentials
+func SetCredentials(c *auth.Credentials) {
+	credentials = c
+	client.Transport = auth.NewTransport(credentials, secure.HttpTransport())
+}
+
+// SetTls makes the requests to head node over https using the tls configuration
+func SetTls(config *secure.Config) {
+	secure.CurrentConfig = config
+	client.Transport = auth.NewTransport(credentials, secure.HttpTransport())
+}
+
+func endPointUrl(headAddress string, endPoint string) string {
+	return fmt.Sprintf("%s://%s%s", secure.CurrentConfig.Scheme(), headAddress, endPoint)
+}
+
+const listPageLimit = 1000
+
+// List gets the folder content of the source by walking through all the pages of the folder listing
 func List(headAddresses []string, source string, usage bool) (*common.Folder, error) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", headAddresses[0], headEndPoint), nil)
+	var folder *common.Folder
+
+	continuation := ""
+	for {
+		page, _, next, err := ListPage(headAddresses, source, usage, nil, continuation)
+		if err != nil {
+			return nil, err
+		}
+
+		if folder == nil {
+			folder = page
+		} else {
+			folder.Folders = append(folder.Folders, page.Folders...)
+			folder.Files = append(folder.Files, page.Files...)
+		}
+
+		if len(next) == 0 {
+			return folder, nil
+		}
+		continuation = next
+	}
+}
+
+// ListPage gets a page of the folder content of the source. options are the sorting and filtering
+// request headers and continuation is the token of the page. It returns the page, the total entry
+// count of the folder and the continuation token of the next page
+func ListPage(headAddresses []string, source string, usage bool, options map[string]string, continuation string) (*common.Folder, int, string, error) {
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], headEndPoint), nil)
 	if err != nil {
-		return nil, err
+		return nil, 0, "", err
 	}
 	req.Header.Set("X-Path", createXPath([]string{source}))
 	req.Header.Set("X-Calculate-Usage", strconv.FormatBool(usage))
+	req.Header.Set("X-Limit", strconv.Itoa(listPageLimit))
+	for k, v := range options {
+		req.Header.Set(k, v)
+	}
+	if len(continuation) > 0 {
+		req.Header.Set("X-Continue", continuation)
+	}
 
 	res, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+		return nil, 0, "", fmt.Errorf("%s: head node is not reachable", headAddresses[0])
 	}
 	defer func() { _ = res.Body.Close() }()
 
 	switch res.StatusCode {
+	case 403:
+		return nil, 0, "", fmt.Errorf("%s is not accessible with the credentials", source)
 	case 404:
-		return nil, fmt.Errorf("%s is not exists", source)
+		return nil, 0, "", fmt.Errorf("%s is not exists", source)
 	case 422:
-		return nil, fmt.Errorf("%s should be an absolute path", source)
+		return nil, 0, "", fmt.Errorf("%s should be an absolute path and listing arguments should be valid", source)
 	case 500:
-		return nil, fmt.Errorf("unable to list %s", source)
+		return nil, 0, "", fmt.Errorf("unable to list %s", source)
 	default:
 		if res.StatusCode != 200 {
-			return nil, fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+			return nil, 0, "", fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
 		}
 	}
 
 	var folder *common.Folder
 	if err := json.NewDecoder(res.Body).Decode(&folder); err != nil {
-		return nil, fmt.Errorf("unable to list %s", source)
+		return nil, 0, "", fmt.Errorf("unable to list %s", source)
+	}
+
+	total, err := strconv.Atoi(res.Header.Get("X-Total"))
+	if err != nil {
+		total = len(folder.Folders) + len(folder.Files)
 	}
 
-	return folder, nil
+	return folder, total, res.Header.Get("X-Continue"), nil
 }
 
 func Tree(headAddresses []string, source string, usage bool) (*common.TreeShadow, error) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", headAddresses[0], headEndPoint), nil)
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], headEndPoint), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +139,8 @@ func Tree(headAddresses []string, source string, usage bool) (*common.TreeShadow
 	defer func() { _ = res.Body.Close() }()
 
 	switch res.StatusCode {
+	case 403:
+		return nil, fmt.Errorf("%s is not accessible with the credentials", source)
 	case 404:
 		return nil, fmt.Errorf("%s is not exists", source)
 	case 422:
@@ -90,8 +161,554 @@ func Tree(headAddresses []string, source string, usage bool) (*common.TreeShadow
 	return tree, nil
 }
 
+// Search queries the folder tree of the source using the filters and returns a page of the matching
+// folders and files. filters are the search request headers and continuation is the token of the page
+func Search(headAddresses []string, source string, filters map[string]string, continuation string) (*common.SearchResult, error) {
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], searchEndPoint), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Path", createXPath([]string{source}))
+	for k, v := range filters {
+		req.Header.Set(k, v)
+	}
+	if len(continuation) > 0 {
+		req.Header.Set("X-Continue", continuation)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return nil, fmt.Errorf("%s is not accessible with the credentials", source)
+	case 404:
+		return nil, fmt.Errorf("%s is not exists", source)
+	case 422:
+		return nil, fmt.Errorf("%s should be an absolute path and search arguments should be valid", source)
+	case 500:
+		return nil, fmt.Errorf("unable to search in %s", source)
+	default:
+		if res.StatusCode != 200 {
+			return nil, fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+		}
+	}
+
+	var result *common.SearchResult
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unable to search in %s", source)
+	}
+
+	return result, nil
+}
+
+// Versions gets the versions of the file from the newest to the oldest
+func Versions(headAddresses []string, source string) (common.FileVersions, error) {
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], versionEndPoint), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Path", createXPath([]string{source}))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return nil, fmt.Errorf("%s is not accessible with the credentials", source)
+	case 404:
+		return nil, fmt.Errorf("%s is not exists", source)
+	case 422:
+		return nil, fmt.Errorf("%s should be an absolute file path", source)
+	case 500:
+		return nil, fmt.Errorf("unable to get versions of %s", source)
+	default:
+		if res.StatusCode != 200 {
+			return nil, fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+		}
+	}
+
+	var versions common.FileVersions
+	if err := json.NewDecoder(res.Body).Decode(&versions); err != nil {
+		return nil, fmt.Errorf("unable to get versions of %s", source)
+	}
+
+	return versions, nil
+}
+
+// SetVersioning enables or disables the file versioning of the target folder
+func SetVersioning(headAddresses []string, target string, enabled bool) error {
+	req, err := http.NewRequest(http.MethodPut, endPointUrl(headAddresses[0], versionEndPoint), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Path", createXPath([]string{target}))
+	req.Header.Set("X-Versioning", strconv.FormatBool(enabled))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is not accessible with the credentials", target)
+	case 404:
+		return fmt.Errorf("%s is not exists", target)
+	case 422:
+		return fmt.Errorf("%s should be an absolute folder path", target)
+	case 500:
+		return fmt.Errorf("unable to change versioning of %s", target)
+	case 202:
+		return nil
+	default:
+		return fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+	}
+}
+
+// RestoreVersion places the version of the target file as the current file
+func RestoreVersion(headAddresses []string, target string, versionId string) error {
+	req, err := http.NewRequest(http.MethodPost, endPointUrl(headAddresses[0], versionEndPoint), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Path", createXPath([]string{target}))
+	req.Header.Set("X-Version", versionId)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is not accessible with the credentials", target)
+	case 404:
+		return fmt.Errorf("%s version of %s is not exists", versionId, target)
+	case 422:
+		return fmt.Errorf("%s should be an absolute file path", target)
+	case 500:
+		return fmt.Errorf("unable to restore %s version of %s", versionId, target)
+	case 523:
+		return fmt.Errorf("%s is locked", target)
+	case 524:
+		return fmt.Errorf("%s version of %s is zombie", versionId, target)
+	case 527:
+		return fmt.Errorf("quota of %s is exceeded", target)
+	case 202:
+		return nil
+	default:
+		return fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+	}
+}
+
+// PurgeVersions deletes the versions of the target file that are out of the newest keep count or older
+// than the duration. Empty values are not taken into account and all versions are deleted if both are empty
+func PurgeVersions(headAddresses []string, target string, keep string, olderThan string) error {
+	req, err := http.NewRequest(http.MethodDelete, endPointUrl(headAddresses[0], versionEndPoint), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Path", createXPath([]string{target}))
+	if len(keep) > 0 {
+		req.Header.Set("X-Keep", keep)
+	}
+	if len(olderThan) > 0 {
+		req.Header.Set("X-Older-Than", olderThan)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is not accessible with the credentials", target)
+	case 404:
+		return fmt.Errorf("%s does not have versions", target)
+	case 422:
+		return fmt.Errorf("%s should be an absolute file path and purge arguments should be valid", target)
+	case 500:
+		return fmt.Errorf("unable to purge versions of %s", target)
+	case 524:
+		return fmt.Errorf("%s has zombie versions", target)
+	case 525:
+		return fmt.Errorf("%s has still alive zombie versions, try again to kill", target)
+	case 200:
+		return nil
+	default:
+		return fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+	}
+}
+
+// PullVersion downloads the version of the source file to the local target. Encrypted version is decrypted with
+// the encryption
+func PullVersion(headAddresses []string, source string, versionId string, target string, encryption *Encryption) error {
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], headEndPoint), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Path", createXPath([]string{source}))
+	req.Header.Set("X-Version", versionId)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is not accessible with the credentials", source)
+	case 404:
+		return fmt.Errorf("%s version of %s is not exists", versionId, source)
+	case 422:
+		return fmt.Errorf("%s should be an absolute file path", source)
+	case 500:
+		return fmt.Errorf("unable to get %s version of %s", versionId, source)
+	case 503:
+		return fmt.Errorf("cluster(s) is/are unavailable to get %s", source)
+	case 524:
+		return fmt.Errorf("%s version of %s is zombie", versionId, source)
+	default:
+		if res.StatusCode != 200 {
+			return fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+		}
+	}
+
+	return writeContent(res, source, target, encryption, nil)
+}
+
+// PullArchive downloads the folder tree as a single archive file in the format
+func PullArchive(headAddresses []string, source string, target string, format string, include string, exclude string) error {
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], headEndPoint), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Path", createXPath([]string{source}))
+	req.Header.Set("X-Archive", format)
+	if len(include) > 0 {
+		req.Header.Set("X-Archive-Include", include)
+	}
+	if len(exclude) > 0 {
+		req.Header.Set("X-Archive-Exclude", exclude)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is not accessible with the credentials", source)
+	case 404:
+		return fmt.Errorf("%s is not exists", source)
+	case 422:
+		return fmt.Errorf("%s should be an absolute folder path and the patterns should be valid", source)
+	case 500:
+		return fmt.Errorf("unable to archive %s", source)
+	case 503:
+		return fmt.Errorf("cluster(s) is/are unavailable to get %s", source)
+	default:
+		if res.StatusCode != 200 {
+			return fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+		}
+	}
+
+	if strings.Compare(res.Header.Get("X-Type"), "folder") != 0 {
+		return fmt.Errorf("%s should be a folder to archive", source)
+	}
+
+	file, err := os.OpenFile(target, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return fmt.Errorf("unable to create %s", target)
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := io.Copy(file, res.Body); err != nil {
+		return fmt.Errorf("unsuccessful operation")
+	}
+
+	return nil
+}
+
+// ListTrash gets the deleted folders and files that are kept in the trash
+func ListTrash(headAddresses []string) (common.TrashEntries, error) {
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], trashEndPoint), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 500:
+		return nil, fmt.Errorf("unable to get trash entries")
+	default:
+		if res.StatusCode != 200 {
+			return nil, fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+		}
+	}
+
+	var entries common.TrashEntries
+	if err := json.NewDecoder(res.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("unable to get trash entries")
+	}
+
+	return entries, nil
+}
+
+// RestoreTrash places the deleted folder or file of the trash entry back to its original path
+func RestoreTrash(headAddresses []string, entryId string) error {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s", endPointUrl(headAddresses[0], trashEndPoint), url.PathEscape(entryId)), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s trash entry is not accessible with the credentials", entryId)
+	case 404:
+		return fmt.Errorf("%s trash entry is not exists", entryId)
+	case 409:
+		return fmt.Errorf("original path of %s trash entry is already in use", entryId)
+	case 500:
+		return fmt.Errorf("unable to restore %s trash entry", entryId)
+	case 526:
+		return fmt.Errorf("%s trash entry requires repair", entryId)
+	case 527:
+		return fmt.Errorf("quota of the original path of %s trash entry is exceeded", entryId)
+	case 202:
+		return nil
+	default:
+		return fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+	}
+}
+
+// PurgeTrash deletes the trash entry permanently. If entry id is empty, all the trash entries are deleted
+func PurgeTrash(headAddresses []string, entryId string) error {
+	endPoint := trashEndPoint
+	if len(entryId) > 0 {
+		endPoint = fmt.Sprintf("%s/%s", trashEndPoint, url.PathEscape(entryId))
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, endPointUrl(headAddresses[0], endPoint), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s trash entry is not accessible with the credentials", entryId)
+	case 404:
+		return fmt.Errorf("%s trash entry is not exists", entryId)
+	case 500:
+		return fmt.Errorf("unable to purge trash")
+	case 503:
+		return fmt.Errorf("cluster(s) is/are unavailable to purge trash")
+	case 525:
+		return fmt.Errorf("trash has zombie file(s) that is/are still alive, try again later")
+	case 200:
+		return nil
+	default:
+		return fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+	}
+}
+
+// Quota gets the quota of the target folder with its usage
+func Quota(headAddresses []string, target string) (*common.Quota, error) {
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], quotaEndPoint), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Path", createXPath([]string{target}))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return nil, fmt.Errorf("%s is not accessible with the credentials", target)
+	case 404:
+		return nil, fmt.Errorf("%s is not exists or does not have quota", target)
+	case 422:
+		return nil, fmt.Errorf("%s should be an absolute folder path", target)
+	case 500:
+		return nil, fmt.Errorf("unable to get quota of %s", target)
+	default:
+		if res.StatusCode != 200 {
+			return nil, fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+		}
+	}
+
+	var quota *common.Quota
+	if err := json.NewDecoder(res.Body).Decode(&quota); err != nil {
+		return nil, fmt.Errorf("unable to get quota of %s", target)
+	}
+
+	return quota, nil
+}
+
+// SetQuota sets the limits of the quota of the target folder. nil quota removes the quota
+func SetQuota(headAddresses []string, target string, quota *common.Quota) error {
+	method := http.MethodPut
+	if quota == nil {
+		method = http.MethodDelete
+	}
+
+	req, err := http.NewRequest(method, endPointUrl(headAddresses[0], quotaEndPoint), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Path", createXPath([]string{target}))
+
+	if quota != nil {
+		req.Header.Set("X-Quota-Size", strconv.FormatUint(quota.Size, 10))
+		req.Header.Set("X-Quota-Files", strconv.FormatUint(quota.Files, 10))
+		req.Header.Set("X-Quota-Soft-Size", strconv.FormatUint(quota.SoftSize, 10))
+		req.Header.Set("X-Quota-Soft-Files", strconv.FormatUint(quota.SoftFiles, 10))
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is not accessible with the credentials", target)
+	case 404:
+		if quota == nil {
+			return fmt.Errorf("%s is not exists or does not have quota", target)
+		}
+		return fmt.Errorf("%s is not exists", target)
+	case 422:
+		return fmt.Errorf("%s should be an absolute folder path and soft limits can not be more than the limits", target)
+	case 500:
+		return fmt.Errorf("unable to change quota of %s", target)
+	case 200, 202:
+		return nil
+	default:
+		return fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+	}
+}
+
+// Acl gets the access list that is effective on the target folder and the path of the folder that
+// the access list is attached to
+func Acl(headAddresses []string, target string) (string, common.AccessList, error) {
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], aclEndPoint), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("X-Path", createXPath([]string{target}))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return "", nil, fmt.Errorf("access lists can only be managed with the admin credentials")
+	case 404:
+		return "", nil, fmt.Errorf("%s is not exists or does not have access list", target)
+	case 422:
+		return "", nil, fmt.Errorf("%s should be an absolute folder path", target)
+	case 500:
+		return "", nil, fmt.Errorf("unable to get access list of %s", target)
+	default:
+		if res.StatusCode != 200 {
+			return "", nil, fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+		}
+	}
+
+	acl := make(common.AccessList, 0)
+	if err := json.NewDecoder(res.Body).Decode(&acl); err != nil {
+		return "", nil, fmt.Errorf("unable to get access list of %s", target)
+	}
+
+	return res.Header.Get("X-Acl-Path"), acl, nil
+}
+
+// SetAcl attaches the access list to the target folder. nil access list removes the access list
+func SetAcl(headAddresses []string, target string, acl common.AccessList) error {
+	method := http.MethodPut
+	if acl == nil {
+		method = http.MethodDelete
+	}
+
+	body, err := json.Marshal(acl)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, endPointUrl(headAddresses[0], aclEndPoint), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Path", createXPath([]string{target}))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("access lists can only be managed with the admin credentials")
+	case 404:
+		if acl == nil {
+			return fmt.Errorf("%s is not exists or does not have access list", target)
+		}
+		return fmt.Errorf("%s is not exists", target)
+	case 422:
+		return fmt.Errorf("%s should be an absolute folder path and the access list should be valid", target)
+	case 500:
+		return fmt.Errorf("unable to change access list of %s", target)
+	case 200, 202:
+		return nil
+	default:
+		return fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+	}
+}
+
 func MakeFolder(headAddresses []string, target string) error {
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", headAddresses[0], headEndPoint), nil)
+	req, err := http.NewRequest(http.MethodPost, endPointUrl(headAddresses[0], headEndPoint), nil)
 	if err != nil {
 		return err
 	}
@@ -105,6 +722,8 @@ func MakeFolder(headAddresses []string, target string) error {
 	defer func() { _ = res.Body.Close() }()
 
 	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is not accessible with the credentials", target)
 	case 409:
 		return fmt.Errorf("%s is already exists", target)
 	case 422:
@@ -119,7 +738,7 @@ func MakeFolder(headAddresses []string, target string) error {
 }
 
 func Change(headAddresses []string, sources []string, target string, overwrite bool, copy bool) error {
-	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://%s%s", headAddresses[0], headEndPoint), nil)
+	req, err := http.NewRequest(http.MethodPut, endPointUrl(headAddresses[0], headEndPoint), nil)
 	if err != nil {
 		return err
 	}
@@ -138,6 +757,8 @@ func Change(headAddresses []string, sources []string, target string, overwrite b
 	defer func() { _ = res.Body.Close() }()
 
 	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is/are not accessible with the credentials", sourcesErrorString(sources))
 	case 404:
 		return fmt.Errorf("%s is/are not exists", sourcesErrorString(sources))
 	case 406:
@@ -150,6 +771,8 @@ func Change(headAddresses []string, sources []string, target string, overwrite b
 		return fmt.Errorf("%s and %s should be full and absolute paths", sourcesErrorString(sources), target)
 	case 524:
 		return fmt.Errorf("%s is zombie or has zombie", sourcesErrorString(sources))
+	case 527:
+		return fmt.Errorf("quota of %s is exceeded", target)
 	case 500:
 		if strings.Compare(action, "m") == 0 {
 			action = "move"
@@ -165,7 +788,7 @@ func Change(headAddresses []string, sources []string, target string, overwrite b
 }
 
 func Delete(headAddresses []string, target string, killZombies bool) error {
-	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s%s", headAddresses[0], headEndPoint), nil)
+	req, err := http.NewRequest(http.MethodDelete, endPointUrl(headAddresses[0], headEndPoint), nil)
 	if err != nil {
 		return err
 	}
@@ -182,6 +805,8 @@ func Delete(headAddresses []string, target string, killZombies bool) error {
 	defer func() { _ = res.Body.Close() }()
 
 	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is not accessible with the credentials", target)
 	case 404:
 		return fmt.Errorf("%s is not exists", target)
 	case 422:
@@ -203,14 +828,16 @@ func Delete(headAddresses []string, target string, killZombies bool) error {
 	}
 }
 
-func Put(headAddresses []string, source string, target string, overwrite bool) error {
+// Put uploads the local file or the folder tree to the target. Files are encrypted before the upload when the
+// encryption is defined
+func Put(headAddresses []string, source string, target string, overwrite bool, encryption *Encryption) error {
 	info, err := os.Stat(source)
 	if err != nil {
 		return fmt.Errorf("unable to read %s", source)
 	}
 
 	if !info.IsDir() {
-		return PutFile(headAddresses, source, target, overwrite)
+		return PutFile(headAddresses, source, target, overwrite, encryption)
 	}
 
 	return filepath.Walk(source, func(p string, info os.FileInfo, err error) error {
@@ -227,7 +854,7 @@ func Put(headAddresses []string, source string, target string, overwrite bool) e
 		targetPath := path.Join(target, parent, child)
 
 		if !info.IsDir() {
-			return PutFile(headAddresses, p, targetPath, overwrite)
+			return PutFile(headAddresses, p, targetPath, overwrite, encryption)
 		}
 		if overwrite {
 			if err := Delete(headAddresses, targetPath, false); err != nil {
@@ -238,7 +865,9 @@ func Put(headAddresses []string, source string, target string, overwrite bool) e
 	})
 }
 
-func PutFile(headAddresses []string, source string, target string, overwrite bool) error {
+// PutFile uploads the local file to the target. File is encrypted locally with its own key that is derived from
+// the secret of the encryption and the encryption parameters are kept in the file metadata
+func PutFile(headAddresses []string, source string, target string, overwrite bool, encryption *Encryption) error {
 	contentType, size, err := contentDetails(source)
 	if err != nil {
 		return fmt.Errorf("unable to read %s", source)
@@ -250,11 +879,32 @@ func PutFile(headAddresses []string, source string, target string, overwrite boo
 	}
 	defer func() { _ = file.Close() }()
 
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", headAddresses[0], headEndPoint), file)
+	var body io.Reader = file
+	var parameters *encryptionParameters
+	if encryption != nil {
+		parameters, err = newEncryptionParameters(encryption.kdf)
+		if err != nil {
+			return err
+		}
+
+		aead, err := encryption.aead(parameters)
+		if err != nil {
+			return err
+		}
+
+		body = newEncryptReader(file, aead, parameters, size)
+		contentType = "application/octet-stream"
+		size = parameters.encryptedSize(size)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endPointUrl(headAddresses[0], headEndPoint), body)
 	if err != nil {
 		return err
 	}
 
+	if parameters != nil {
+		parameters.setHeaders(req.Header)
+	}
 	req.Header.Set("X-Apply-To", "file")
 	req.Header.Set("X-Path", createXPath([]string{target}))
 	req.Header.Set("X-Overwrite", strconv.FormatBool(overwrite))
@@ -269,6 +919,8 @@ func PutFile(headAddresses []string, source string, target string, overwrite boo
 	defer func() { _ = res.Body.Close() }()
 
 	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is not accessible with the credentials", target)
 	case 409:
 		return fmt.Errorf("%s is already exists", target)
 	case 411:
@@ -277,6 +929,8 @@ func PutFile(headAddresses []string, source string, target string, overwrite boo
 		return fmt.Errorf("filename must be specified for %s", target)
 	case 507:
 		return fmt.Errorf("insufficient space")
+	case 527:
+		return fmt.Errorf("quota of %s is exceeded", target)
 	case 500:
 		return fmt.Errorf("unable to create %s", target)
 	case 202:
@@ -286,6 +940,51 @@ func PutFile(headAddresses []string, source string, target string, overwrite boo
 	}
 }
 
+// PutArchive extracts the local archive file into the target folder and returns the results of the archive entries
+func PutArchive(headAddresses []string, source string, target string, format string, overwrite bool) (*common.ExtractionReport, error) {
+	file, err := os.OpenFile(source, os.O_RDONLY, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s", source)
+	}
+	defer func() { _ = file.Close() }()
+
+	req, err := http.NewRequest(http.MethodPost, endPointUrl(headAddresses[0], headEndPoint), file)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Apply-To", "archive")
+	req.Header.Set("X-Path", createXPath([]string{target}))
+	req.Header.Set("X-Archive", format)
+	req.Header.Set("X-Overwrite", strconv.FormatBool(overwrite))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: head node is not reachable", headAddresses[0])
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	switch res.StatusCode {
+	case 403:
+		return nil, fmt.Errorf("%s is not accessible with the credentials", target)
+	case 422:
+		if strings.Compare(res.Header.Get("Content-Type"), "application/json") == 0 {
+			return nil, fmt.Errorf("%s is not a readable %s archive", source, format)
+		}
+		return nil, fmt.Errorf("%s should be an absolute folder path", target)
+	case 500:
+		return nil, fmt.Errorf("unable to extract to %s", target)
+	case 200:
+		var report *common.ExtractionReport
+		if err := json.NewDecoder(res.Body).Decode(&report); err != nil {
+			return nil, fmt.Errorf("unsuccessful operation")
+		}
+		return report, nil
+	default:
+		return nil, fmt.Errorf("dfs head returned with an unrecognisable status code: %d", res.StatusCode)
+	}
+}
+
 func contentDetails(source string) (string, int64, error) {
 	file, err := os.OpenFile(source, os.O_RDONLY, 0666)
 	if err != nil {
@@ -311,14 +1010,27 @@ func contentDetails(source string) (string, int64, error) {
 	return http.DetectContentType(buf), info.Size(), nil
 }
 
-func Pull(headAddresses []string, sources []string, target string, readRange *common.ReadRange) error {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", headAddresses[0], headEndPoint), nil)
+// Pull downloads the sources to the local target. Encrypted files are detected from their metadata and decrypted
+// with the encryption. Range of the encrypted file is served from the segments that cover the range
+func Pull(headAddresses []string, sources []string, target string, readRange *common.ReadRange, encryption *Encryption) error {
+	var encrypted *encryptedRange
+	if readRange != nil && encryption != nil && len(sources) == 1 {
+		var err error
+		encrypted, err = describeEncryptedRange(headAddresses, sources[0], readRange)
+		if err != nil {
+			return fmt.Errorf("%s: %s", sources[0], err)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], headEndPoint), nil)
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("X-Path", createXPath(sources))
-	if readRange != nil {
+	if encrypted != nil {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", encrypted.begins, encrypted.ends))
+	} else if readRange != nil {
 		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", readRange.Begins, readRange.Ends))
 	}
 
@@ -331,6 +1043,8 @@ func Pull(headAddresses []string, sources []string, target string, readRange *co
 	isFile := strings.Compare(res.Header.Get("X-Type"), "file") == 0
 
 	switch res.StatusCode {
+	case 403:
+		return fmt.Errorf("%s is/are not accessible with the credentials", sourcesErrorString(sources))
 	case 404:
 		return fmt.Errorf("%s is/are not exists", sourcesErrorString(sources))
 	case 422:
@@ -353,17 +1067,14 @@ func Pull(headAddresses []string, sources []string, target string, readRange *co
 	}
 
 	if isFile {
-		file, err := os.OpenFile(target, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
-		if err != nil {
-			return fmt.Errorf("unable to create %s", target)
+		if len(sources) > 1 && len(res.Header.Get(metaEncryption)) > 0 {
+			return fmt.Errorf("encrypted files can not be joined")
 		}
-		defer func() { _ = file.Close() }()
-
-		if _, err := io.Copy(file, res.Body); err != nil {
-			return fmt.Errorf("unsuccessful operation")
+		// the file is replaced with an encrypted one after its range is described
+		if readRange != nil && encrypted == nil && len(res.Header.Get(metaEncryption)) > 0 && encryption != nil {
+			return fmt.Errorf("%s is changed during the download", sources[0])
 		}
-
-		return nil
+		return writeContent(res, sources[0], target, encryption, encrypted)
 	}
 
 	if strings.Compare(res.Header.Get("X-Type"), "folder") == 0 && readRange != nil {
@@ -383,7 +1094,7 @@ func Pull(headAddresses []string, sources []string, target string, readRange *co
 		sourcePath := path.Join(sources[0], file.Name)
 		targetPath := path.Join(target, file.Name)
 
-		if err := Pull(headAddresses, []string{sourcePath}, targetPath, nil); err != nil {
+		if err := Pull(headAddresses, []string{sourcePath}, targetPath, nil, encryption); err != nil {
 			return fmt.Errorf("unsuccessful operation")
 		}
 	}
@@ -392,11 +1103,84 @@ func Pull(headAddresses []string, sources []string, target string, readRange *co
 		sourcePath := path.Join(sources[0], f.Name)
 		targetPath := path.Join(target, f.Name)
 
-		if err := Pull(headAddresses, []string{sourcePath}, targetPath, nil); err != nil {
+		if err := Pull(headAddresses, []string{sourcePath}, targetPath, nil, encryption); err != nil {
+			return fmt.Errorf("unsuccessful operation")
+		}
+	}
+
+	return nil
+}
+
+// describeEncryptedRange reads the encryption parameters and the size of the source with the smallest range
+// request to find the segments of the requested range. It returns nil when the source is not an encrypted file
+func describeEncryptedRange(headAddresses []string, source string, readRange *common.ReadRange) (*encryptedRange, error) {
+	req, err := http.NewRequest(http.MethodGet, endPointUrl(headAddresses[0], headEndPoint), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Path", createXPath([]string{source}))
+	req.Header.Set("Range", "bytes=0-0")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("head node is not reachable")
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	// the failures are reported by the actual request
+	if res.StatusCode != 206 || strings.Compare(res.Header.Get("X-Type"), "file") != 0 {
+		return nil, nil
+	}
+
+	parameters, err := describeEncryptionParameters(res.Header)
+	if err != nil || parameters == nil {
+		return nil, err
+	}
+
+	contentRange := res.Header.Get("Content-Range")
+	encryptedSize, err := strconv.ParseInt(contentRange[strings.LastIndex(contentRange, "/")+1:], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("size of the encrypted file is not readable")
+	}
+
+	return newEncryptedRange(parameters, encryptedSize, readRange.Begins, readRange.Ends)
+}
+
+// writeContent writes the file content of the response to the local target. Encrypted content is decrypted in
+// the range or completely when the range is not defined
+func writeContent(res *http.Response, source string, target string, encryption *Encryption, encrypted *encryptedRange) error {
+	parameters, err := describeEncryptionParameters(res.Header)
+	if err != nil {
+		return fmt.Errorf("%s: %s", source, err)
+	}
+	if parameters != nil && encryption == nil {
+		return fmt.Errorf("%s is encrypted, provide the passphrase or the keyfile to decrypt", source)
+	}
+
+	if parameters != nil && encrypted == nil {
+		encrypted, err = newFullEncryptedRange(parameters, res.ContentLength)
+		if err != nil {
+			return fmt.Errorf("%s: %s", source, err)
+		}
+	}
+
+	file, err := os.OpenFile(target, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return fmt.Errorf("unable to create %s", target)
+	}
+	defer func() { _ = file.Close() }()
+
+	if parameters == nil {
+		if _, err := io.Copy(file, res.Body); err != nil {
 			return fmt.Errorf("unsuccessful operation")
 		}
+		return nil
 	}
 
+	if err := encryption.decrypt(res.Body, file, encrypted); err != nil {
+		return fmt.Errorf("%s: %s", source, err)
+	}
 	return nil
 }
 
@@ -0,0 +1,186 @@
+package flags
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/terminal"
+	"github.com/freakmaxi/kertish-dfs/fs-tool/dfs"
+)
+
+type aclCommand struct {
+	headAddresses []string
+	output        terminal.Output
+	basePath      string
+	args          []string
+
+	acl    common.AccessList
+	remove bool
+	target string
+}
+
+// NewAcl creates the execution of the folder access list operations
+func NewAcl(headAddresses []string, output terminal.Output, basePath string, args []string) Execution {
+	return &aclCommand{
+		headAddresses: headAddresses,
+		output:        output,
+		basePath:      basePath,
+		args:          args,
+	}
+}
+
+func (a *aclCommand) Parse() error {
+	for len(a.args) > 0 {
+		arg := a.args[0]
+		switch arg {
+		case "-s":
+			a.args = a.args[1:]
+			if len(a.args) == 0 {
+				return fmt.Errorf("-s argument needs value")
+			}
+			entry, err := a.describeEntry(a.args[0])
+			if err != nil {
+				return err
+			}
+			a.acl = append(a.acl, entry)
+			a.args = a.args[1:]
+			continue
+		case "-r":
+			a.args = a.args[1:]
+			a.remove = true
+			continue
+		case "-h":
+			return errors.ErrShowUsage
+		default:
+			if strings.Index(arg, "-") == 0 {
+				return fmt.Errorf("unsupported argument for acl command")
+			}
+		}
+		break
+	}
+
+	if a.remove && len(a.acl) > 0 {
+		return fmt.Errorf("acl command can only do one operation at a time")
+	}
+
+	if err := a.acl.Validate(); err != nil {
+		return fmt.Errorf("identities should be defined only once in the access list")
+	}
+
+	a.args = sourceTargetArguments(a.args)
+	a.args = cleanEmptyArguments(a.args)
+
+	a.target = a.basePath
+	if len(a.args) > 0 {
+		if !filepath.IsAbs(a.args[0]) {
+			a.target = path.Join(a.basePath, a.args[0])
+		} else {
+			a.target = a.args[0]
+		}
+	}
+
+	return nil
+}
+
+// describeEntry parses the identity=permission,permission formatted access list entry
+func (a *aclCommand) describeEntry(value string) (*common.AccessEntry, error) {
+	idx := strings.Index(value, "=")
+	if idx < 1 {
+		return nil, fmt.Errorf("-s argument value should be in identity=permission,permission format")
+	}
+
+	entry := &common.AccessEntry{
+		Identity:    value[:idx],
+		Permissions: make([]common.Permission, 0),
+	}
+
+	permissions := value[idx+1:]
+	if len(permissions) == 0 {
+		return entry, nil
+	}
+
+	for _, p := range strings.Split(permissions, ",") {
+		permission, err := common.ParsePermission(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid permission, use read, write, delete or manage", p)
+		}
+		entry.Permissions = append(entry.Permissions, permission)
+	}
+
+	return entry, nil
+}
+
+func (a *aclCommand) PrintUsage() {
+	a.output.Println("  acl         Show, set and remove the access list that is inherited by the whole folder tree.")
+	a.output.Println("              Ex: acl [arguments] [target]")
+	a.output.Println("")
+	a.output.Println("arguments:")
+	a.output.Println("  -s entry    sets the access list entry in identity=permission,permission format")
+	a.output.Println("              permissions are read, write, delete and manage. * identity matches all identities")
+	a.output.Println("  -r          removes the access list of the target folder")
+	a.output.Println("")
+	a.output.Println("              Multiple -s arguments replace the whole access list of the target folder")
+	a.output.Println("")
+	a.output.Refresh()
+}
+
+func (a *aclCommand) Name() string {
+	return "acl"
+}
+
+func (a *aclCommand) Execute() error {
+	if strings.Index(a.target, local) == 0 {
+		return fmt.Errorf("acl command works only with dfs folders")
+	}
+
+	anim := common.NewAnimation(a.output, "processing...")
+	anim.Start()
+
+	var err error
+	var attachedPath string
+	var acl common.AccessList
+
+	switch {
+	case a.remove:
+		err = dfs.SetAcl(a.headAddresses, a.target, nil)
+	case len(a.acl) > 0:
+		err = dfs.SetAcl(a.headAddresses, a.target, a.acl)
+	default:
+		attachedPath, acl, err = dfs.Acl(a.headAddresses, a.target)
+	}
+
+	if err != nil {
+		anim.Cancel()
+		return err
+	}
+	anim.Stop()
+
+	if acl != nil {
+		a.print(attachedPath, acl)
+	}
+	return nil
+}
+
+func (a *aclCommand) print(attachedPath string, acl common.AccessList) {
+	if strings.Compare(attachedPath, a.target) != 0 {
+		a.output.Printf("inherited from %s\n", attachedPath)
+	}
+
+	for _, entry := range acl {
+		permissions := make([]string, 0)
+		for _, permission := range entry.Permissions {
+			permissions = append(permissions, string(permission))
+		}
+		if len(permissions) == 0 {
+			permissions = append(permissions, "none")
+		}
+		a.output.Printf("%-16s %s\n", entry.Identity, strings.Join(permissions, ","))
+	}
+	a.output.Refresh()
+}
+
+var _ Execution = &aclCommand{}
@@ -5,8 +5,11 @@ import (
 	"os"
 	"path"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
 	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 	"github.com/freakmaxi/kertish-dfs/basics/terminal"
+	"github.com/freakmaxi/kertish-dfs/fs-tool/dfs"
 )
 
 const local = "local:"
@@ -17,6 +20,9 @@ type Command struct {
 	filename    string
 	args        []string
 	headAddress string
+	tlsCa       string
+	tlsCert     string
+	tlsKey      string
 	command     Execution
 }
 
@@ -48,6 +54,10 @@ func (c *Command) printUsage() {
 	fmt.Println("options:")
 	fmt.Println("  --head-address   (DEPRECATED) The end point of head node to work with. Default: localhost:4000")
 	fmt.Println("  -t --target      The end point of head node to work with. Default: localhost:4000")
+	fmt.Println("  -c --credentials The key to sign the requests to head node. Ex: [keyId]:[secret]")
+	fmt.Println("  --tls-ca         The certificate authority file to verify head node and enable https")
+	fmt.Println("  --tls-cert       The client certificate file to present to head node")
+	fmt.Println("  --tls-key        The key file of the client certificate")
 	fmt.Println("  -h --help        Prints this usage documentation")
 	fmt.Println("  -v --version     Prints release version")
 	fmt.Println()
@@ -58,6 +68,11 @@ func (c *Command) printUsage() {
 	fmt.Println("  mv      Move file or folder.")
 	fmt.Println("  rm      Remove files and/or folders.")
 	fmt.Println("  tree    Print folders tree.")
+	fmt.Println("  find    Search files and folders.")
+	fmt.Println("  versions Manage file versions.")
+	fmt.Println("  trash   Manage deleted files and folders.")
+	fmt.Println("  quota   Manage folder quotas.")
+	fmt.Println("  acl     Manage folder access lists.")
 	fmt.Println("  sh      Enter shell mode of fs-tool.")
 	fmt.Println()
 }
@@ -83,6 +98,42 @@ func (c *Command) Parse() bool {
 			i++
 			c.headAddress = c.args[i]
 			continue
+		case "--credentials", "-c":
+			if i+1 == len(c.args) {
+				fmt.Printf("%s requires value\n", arg)
+				fmt.Println()
+				c.printUsage()
+				return false
+			}
+
+			i++
+			credentials, err := auth.ParseCredentials(c.args[i])
+			if err != nil {
+				fmt.Printf("%s should be in [keyId]:[secret] format\n", arg)
+				fmt.Println()
+				c.printUsage()
+				return false
+			}
+			dfs.SetCredentials(credentials)
+			continue
+		case "--tls-ca", "--tls-cert", "--tls-key":
+			if i+1 == len(c.args) {
+				fmt.Printf("%s requires value\n", arg)
+				fmt.Println()
+				c.printUsage()
+				return false
+			}
+
+			i++
+			switch arg {
+			case "--tls-ca":
+				c.tlsCa = c.args[i]
+			case "--tls-cert":
+				c.tlsCert = c.args[i]
+			case "--tls-key":
+				c.tlsKey = c.args[i]
+			}
+			continue
 		case "--help", "-h":
 			c.printUsage()
 			return false
@@ -92,7 +143,11 @@ func (c *Command) Parse() bool {
 		}
 
 		switch arg {
-		case "mkdir", "ls", "cp", "mv", "rm", "tree", "sh":
+		case "mkdir", "ls", "cp", "mv", "rm", "tree", "find", "versions", "trash", "quota", "acl", "sh":
+			if !c.setupTls() {
+				return false
+			}
+
 			mrArgs := make([]string, 0)
 			if i+1 < len(c.args) {
 				mrArgs = c.args[i+1:]
@@ -126,6 +181,24 @@ func (c *Command) Parse() bool {
 	return false
 }
 
+// setupTls makes the requests to head node over https when any of the tls options is defined
+func (c *Command) setupTls() bool {
+	if len(c.tlsCa) == 0 && len(c.tlsCert) == 0 && len(c.tlsKey) == 0 {
+		return true
+	}
+
+	config, err := secure.NewConfig(c.tlsCert, c.tlsKey, c.tlsCa, false)
+	if err != nil {
+		fmt.Printf("tls setup is failed: %s\n", err.Error())
+		fmt.Println()
+		c.printUsage()
+		return false
+	}
+	dfs.SetTls(config)
+
+	return true
+}
+
 func (c *Command) Execute() error {
 	return c.command.Execute()
 }
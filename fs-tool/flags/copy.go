@@ -24,6 +24,12 @@ type copyCommand struct {
 	join      bool
 	overwrite bool
 	readRange *common.ReadRange
+	versionId string
+	archive   string
+	include   string
+	exclude   string
+	keyfile   string
+	encrypt   bool
 	sources   []string
 	target    string
 }
@@ -62,6 +68,47 @@ func (c *copyCommand) Parse() error {
 			c.args = c.args[1:]
 			c.readRange = readRange
 			continue
+		case "-v":
+			c.args = c.args[1:]
+			if len(c.args) == 0 {
+				return fmt.Errorf("version argument needs value")
+			}
+			c.versionId = c.args[0]
+			c.args = c.args[1:]
+			continue
+		case "-a", "-i", "-e":
+			c.args = c.args[1:]
+			if len(c.args) == 0 {
+				return fmt.Errorf("%s argument needs value", arg)
+			}
+			switch arg {
+			case "-a":
+				switch strings.ToLower(c.args[0]) {
+				case "zip", "tar", "tar.gz", "tgz":
+				default:
+					return fmt.Errorf("archive argument should be zip, tar or tar.gz")
+				}
+				c.archive = strings.ToLower(c.args[0])
+			case "-i":
+				c.include = c.args[0]
+			case "-e":
+				c.exclude = c.args[0]
+			}
+			c.args = c.args[1:]
+			continue
+		case "-p":
+			c.args = c.args[1:]
+			c.encrypt = true
+			continue
+		case "-k":
+			c.args = c.args[1:]
+			if len(c.args) == 0 {
+				return fmt.Errorf("keyfile argument needs value")
+			}
+			c.keyfile = c.args[0]
+			c.args = c.args[1:]
+			c.encrypt = true
+			continue
 		case "-h":
 			return errors.ErrShowUsage
 		default:
@@ -86,6 +133,24 @@ func (c *copyCommand) Parse() error {
 	c.sources = c.args[:len(c.args)-1]
 	c.target = c.args[len(c.args)-1]
 
+	if len(c.versionId) > 0 && (c.join || c.readRange != nil || strings.Index(c.target, local) != 0) {
+		return fmt.Errorf("version argument works only for a single file copy from dfs to local")
+	}
+
+	if len(c.archive) > 0 && (c.join || c.readRange != nil || len(c.versionId) > 0 ||
+		strings.Index(c.target, local) != 0 && strings.Index(c.sources[0], local) != 0) {
+		return fmt.Errorf("archive argument works only for a single folder copy between dfs and local")
+	}
+
+	if (len(c.archive) == 0 || strings.Index(c.target, local) != 0) && (len(c.include) > 0 || len(c.exclude) > 0) {
+		return fmt.Errorf("include and exclude arguments work only with archive argument from dfs to local")
+	}
+
+	if c.encrypt && (c.join || len(c.archive) > 0 ||
+		strings.Index(c.target, local) != 0 && strings.Index(c.sources[0], local) != 0) {
+		return fmt.Errorf("encryption arguments work only for copies between dfs and local without join and archive")
+	}
+
 	return nil
 }
 
@@ -102,6 +167,19 @@ func (c *copyCommand) PrintUsage() {
 	c.output.Println("              Ex: cp -r [byteBegins]->[byteEnds] [source] local:[target]")
 	c.output.Println("")
 	c.output.Println("              WARNING: range works only from dfs to local copy operations")
+	c.output.Println("  -v id       copies the version of the file.")
+	c.output.Println("              Ex: cp -v [versionId] [source] local:[target]")
+	c.output.Println("  -a format   copies the folder as a single archive file. Values: zip, tar or tar.gz")
+	c.output.Println("              Ex: cp -a zip [source] local:[target]")
+	c.output.Println("              Ex: cp -a zip local:[source] [target]      # Extracts the archive into target")
+	c.output.Println("  -i pattern  includes only the matching files to the archive. Ex: \"*.jpg\"")
+	c.output.Println("  -e pattern  excludes the matching folders and files from the archive")
+	c.output.Println("  -p          encrypts the files with the passphrase before the upload and decrypts them")
+	c.output.Println("              after the download. Passphrase is read from KRTFS_PASSPHRASE environment")
+	c.output.Println("              variable or asked when it is absent")
+	c.output.Println("  -k keyfile  encrypts and decrypts the files with the key derived from the keyfile")
+	c.output.Println("")
+	c.output.Println("              Encrypted files are detected from their metadata on download")
 	c.output.Println("")
 	c.output.Refresh()
 }
@@ -172,6 +250,12 @@ func (c *copyCommand) remoteToLocal() error {
 		}
 
 		_, sourceFileName := path.Split(c.sources[0])
+		if len(c.archive) > 0 {
+			if len(sourceFileName) == 0 {
+				sourceFileName = "root"
+			}
+			sourceFileName = fmt.Sprintf("%s.%s", sourceFileName, c.archive)
+		}
 		c.target = path.Join(c.target, sourceFileName)
 
 		info, err = os.Stat(c.target)
@@ -207,6 +291,11 @@ func (c *copyCommand) remoteToLocal() error {
 		}
 	}
 
+	encryption, err := c.encryption()
+	if err != nil {
+		return err
+	}
+
 	anim := common.NewAnimation(c.output, "processing...")
 	anim.Start()
 
@@ -224,7 +313,25 @@ func (c *copyCommand) remoteToLocal() error {
 		}
 	}
 
-	if err := dfs.Pull(c.headAddresses, c.sources, c.target, c.readRange); err != nil {
+	if len(c.versionId) > 0 {
+		if err := dfs.PullVersion(c.headAddresses, c.sources[0], c.versionId, c.target, encryption); err != nil {
+			anim.Cancel()
+			return err
+		}
+		anim.Stop()
+		return nil
+	}
+
+	if len(c.archive) > 0 {
+		if err := dfs.PullArchive(c.headAddresses, c.sources[0], c.target, c.archive, c.include, c.exclude); err != nil {
+			anim.Cancel()
+			return err
+		}
+		anim.Stop()
+		return nil
+	}
+
+	if err := dfs.Pull(c.headAddresses, c.sources, c.target, c.readRange, encryption); err != nil {
 		anim.Cancel()
 		return err
 	}
@@ -250,6 +357,11 @@ func (c *copyCommand) localToRemote() error {
 		}
 	}
 
+	encryption, err := c.encryption()
+	if err != nil {
+		return err
+	}
+
 	anim := common.NewAnimation(c.output, "processing...")
 	anim.Start()
 
@@ -267,7 +379,27 @@ func (c *copyCommand) localToRemote() error {
 		c.target = common.Join(c.basePath, c.target)
 	}
 
-	if err := dfs.Put(c.headAddresses, sourceTemp, c.target, c.overwrite); err != nil {
+	if len(c.archive) > 0 {
+		report, err := dfs.PutArchive(c.headAddresses, c.sources[0], c.target, c.archive, c.overwrite)
+		if err != nil {
+			anim.Cancel()
+			return err
+		}
+		anim.Stop()
+
+		for _, entry := range report.Entries {
+			if strings.Compare(entry.Result, common.ERFailed) != 0 {
+				continue
+			}
+			c.output.Printf("%s: %s\n", entry.Full, entry.Error)
+		}
+		c.output.Printf("%d created, %d overwritten, %d skipped, %d failed\n", report.Created, report.Overwritten, report.Skipped, report.Failed)
+		c.output.Refresh()
+
+		return nil
+	}
+
+	if err := dfs.Put(c.headAddresses, sourceTemp, c.target, c.overwrite, encryption); err != nil {
 		anim.Cancel()
 		return fmt.Errorf(err.Error())
 	}
@@ -275,4 +407,27 @@ func (c *copyCommand) localToRemote() error {
 	return nil
 }
 
+// encryption creates the client-side encryption from the keyfile or the passphrase when it is requested
+func (c *copyCommand) encryption() (*dfs.Encryption, error) {
+	if !c.encrypt {
+		return nil, nil
+	}
+
+	if len(c.keyfile) > 0 {
+		return dfs.NewKeyfileEncryption(c.keyfile)
+	}
+
+	passphrase := os.Getenv("KRTFS_PASSPHRASE")
+	if len(passphrase) == 0 {
+		c.output.Print("Passphrase: ")
+		c.output.Refresh()
+
+		if !c.output.Scan(&passphrase) {
+			return nil, fmt.Errorf("unable to get the passphrase")
+		}
+	}
+
+	return dfs.NewPassphraseEncryption(passphrase)
+}
+
 var _ Execution = &copyCommand{}
@@ -34,6 +34,16 @@ func newExecution(headAddresses []string, output terminal.Output, command string
 		return NewRemove(headAddresses, output, basePath, args), nil
 	case "tree":
 		return NewTree(headAddresses, output, basePath, args), nil
+	case "find":
+		return NewFind(headAddresses, output, basePath, args), nil
+	case "versions":
+		return NewVersions(headAddresses, output, basePath, args), nil
+	case "trash":
+		return NewTrash(headAddresses, output, args), nil
+	case "quota":
+		return NewQuota(headAddresses, output, basePath, args), nil
+	case "acl":
+		return NewAcl(headAddresses, output, basePath, args), nil
 	case "sh":
 		return NewShell(headAddresses, version), nil
 	}
@@ -0,0 +1,234 @@
+package flags
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/terminal"
+	"github.com/freakmaxi/kertish-dfs/fs-tool/dfs"
+)
+
+type findCommand struct {
+	headAddresses []string
+	output        terminal.Output
+	basePath      string
+	args          []string
+
+	listing bool
+	filters map[string]string
+	source  string
+}
+
+// NewFind creates the execution of find operation
+func NewFind(headAddresses []string, output terminal.Output, basePath string, args []string) Execution {
+	return &findCommand{
+		headAddresses: headAddresses,
+		output:        output,
+		basePath:      basePath,
+		args:          args,
+	}
+}
+
+func (f *findCommand) Parse() error {
+	f.filters = map[string]string{
+		"X-Recursive": "true",
+	}
+
+	valueArguments := map[string]string{
+		"-name":     "X-Name",
+		"-regex":    "X-Name-Regex",
+		"-mime":     "X-Mime",
+		"-size":     "X-Size",
+		"-created":  "X-Created",
+		"-modified": "X-Modified",
+	}
+
+	for len(f.args) > 0 {
+		arg := f.args[0]
+		switch arg {
+		case "-name", "-regex", "-mime", "-size", "-created", "-modified":
+			f.args = f.args[1:]
+			if len(f.args) == 0 {
+				return fmt.Errorf("%s argument needs value", arg)
+			}
+			f.filters[valueArguments[arg]] = strings.Trim(f.args[0], "\"")
+			f.args = f.args[1:]
+			continue
+		case "-type":
+			f.args = f.args[1:]
+			if len(f.args) == 0 {
+				return fmt.Errorf("type argument needs value")
+			}
+			switch f.args[0] {
+			case "d":
+				f.filters["X-Type"] = "folder"
+			case "f":
+				f.filters["X-Type"] = "file"
+			default:
+				return fmt.Errorf("type argument value should be d or f")
+			}
+			f.args = f.args[1:]
+			continue
+		case "-zombie":
+			f.args = f.args[1:]
+			f.filters["X-Zombie"] = "true"
+			continue
+		case "-locked":
+			f.args = f.args[1:]
+			f.filters["X-Locked"] = "true"
+			continue
+		case "-flat":
+			f.args = f.args[1:]
+			delete(f.filters, "X-Recursive")
+			continue
+		case "-l":
+			f.args = f.args[1:]
+			f.listing = true
+			continue
+		case "-h":
+			return errors.ErrShowUsage
+		default:
+			if strings.Index(arg, "-") == 0 {
+				return fmt.Errorf("unsupported argument for find command")
+			}
+		}
+		break
+	}
+
+	f.args = sourceTargetArguments(f.args)
+	f.args = cleanEmptyArguments(f.args)
+
+	f.source = f.basePath
+	if len(f.args) > 0 {
+		if !filepath.IsAbs(f.args[0]) {
+			f.source = path.Join(f.basePath, f.args[0])
+		} else {
+			f.source = f.args[0]
+		}
+	}
+
+	return nil
+}
+
+func (f *findCommand) PrintUsage() {
+	f.output.Println("  find        Search files and folders in the folder tree.")
+	f.output.Println("              Ex: find [arguments] [target]")
+	f.output.Println("")
+	f.output.Println("arguments:")
+	f.output.Println("  -name pattern       matches the name with the glob pattern. Ex: \"*.jpg\"")
+	f.output.Println("  -regex expression   matches the name with the regular expression")
+	f.output.Println("  -type d|f           matches only folders (d) or files (f)")
+	f.output.Println("  -mime pattern       matches the file mime type with the glob pattern. Ex: \"image/*\"")
+	f.output.Println("  -size min,max       matches the file size in bytes. Ex: 1024, or ,1048576")
+	f.output.Println("  -created from,to    matches the creation date (RFC3339). Ex: 2020-01-01T00:00:00Z,")
+	f.output.Println("  -modified from,to   matches the modification date (RFC3339)")
+	f.output.Println("  -zombie             matches only zombie files")
+	f.output.Println("  -locked             matches only locked files")
+	f.output.Println("  -flat               searches only in the target folder without sub folders")
+	f.output.Println("  -l                  shows in a listing format")
+	f.output.Println("")
+	f.output.Println("marking:")
+	f.output.Println("  d           folder")
+	f.output.Println("  -           file")
+	f.output.Println("  •           locked")
+	f.output.Println("  ↯           zombie")
+	f.output.Println("")
+	f.output.Refresh()
+}
+
+func (f *findCommand) Name() string {
+	return "find"
+}
+
+func (f *findCommand) Execute() error {
+	if strings.Index(f.source, local) == 0 {
+		return fmt.Errorf("please use O/S native commands to find files/folders")
+	}
+
+	total := 0
+	continuation := ""
+	for {
+		anim := common.NewAnimation(f.output, "processing...")
+		anim.Start()
+
+		result, err := dfs.Search(f.headAddresses, f.source, f.filters, continuation)
+		if err != nil {
+			anim.Cancel()
+			return err
+		}
+		anim.Stop()
+
+		f.print(result.Entries)
+		total += len(result.Entries)
+
+		if len(result.Next) == 0 {
+			break
+		}
+		continuation = result.Next
+	}
+
+	if f.listing {
+		f.output.Printf("total %d\n", total)
+	}
+	f.output.Refresh()
+
+	return nil
+}
+
+func (f *findCommand) print(entries common.SearchEntries) {
+	for _, entry := range entries {
+		if !f.listing {
+			f.output.Println(entry.Full)
+			continue
+		}
+
+		if strings.Compare(entry.Type, "folder") == 0 {
+			f.output.Printf("d %7v %s %s\n", "-", entry.Created.Local().Format(common.FriendlyTimeFormat), entry.Full)
+			continue
+		}
+
+		fileChar := "-"
+		if entry.Locked {
+			fileChar = "•"
+		} else if entry.Zombie {
+			fileChar = "↯"
+		}
+		f.output.Printf("%s %7v %s %s\n", fileChar, f.sizeToString(entry.Size), entry.Modified.Local().Format(common.FriendlyTimeFormat), entry.Full)
+	}
+	f.output.Refresh()
+}
+
+func (f *findCommand) sizeToString(size uint64) string {
+	calculatedSize := size
+	divideCount := 0
+	for {
+		calculatedSizeString := strconv.FormatUint(calculatedSize, 10)
+		if len(calculatedSizeString) < 6 {
+			break
+		}
+		calculatedSize /= 1024
+		divideCount++
+	}
+
+	switch divideCount {
+	case 0:
+		return fmt.Sprintf("%sb", strconv.FormatUint(calculatedSize, 10))
+	case 1:
+		return fmt.Sprintf("%skb", strconv.FormatUint(calculatedSize, 10))
+	case 2:
+		return fmt.Sprintf("%smb", strconv.FormatUint(calculatedSize, 10))
+	case 3:
+		return fmt.Sprintf("%sgb", strconv.FormatUint(calculatedSize, 10))
+	case 4:
+		return fmt.Sprintf("%stb", strconv.FormatUint(calculatedSize, 10))
+	}
+
+	return "N/A"
+}
+
+var _ Execution = &findCommand{}
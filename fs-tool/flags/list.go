@@ -21,6 +21,7 @@ type listCommand struct {
 
 	listing bool
 	usage   bool
+	options map[string]string
 	source  string
 }
 
@@ -35,9 +36,38 @@ func NewList(headAddresses []string, output terminal.Output, basePath string, ar
 }
 
 func (l *listCommand) Parse() error {
+	l.options = make(map[string]string)
+
 	for len(l.args) > 0 {
 		arg := l.args[0]
 		switch arg {
+		case "-o":
+			l.args = l.args[1:]
+			if len(l.args) == 0 {
+				return fmt.Errorf("order argument needs value")
+			}
+			switch l.args[0] {
+			case "name", "size", "modified":
+				l.options["X-Sort"] = l.args[0]
+			default:
+				return fmt.Errorf("order argument value should be name, size or modified")
+			}
+			l.args = l.args[1:]
+			continue
+		case "-r":
+			l.args = l.args[1:]
+			l.options["X-Sort-Order"] = "desc"
+			continue
+		case "-d", "-f":
+			l.args = l.args[1:]
+			if _, has := l.options["X-Only"]; has {
+				return fmt.Errorf("-d and -f arguments can not be used together")
+			}
+			l.options["X-Only"] = "folders"
+			if strings.Compare(arg, "-f") == 0 {
+				l.options["X-Only"] = "files"
+			}
+			continue
 		case "-l":
 			l.args = l.args[1:]
 			l.listing = true
@@ -78,6 +108,10 @@ func (l *listCommand) PrintUsage() {
 	l.output.Println("arguments:")
 	l.output.Println("  -l          shows in a listing format")
 	l.output.Println("  -u          calculate the size of folders")
+	l.output.Println("  -o field    orders the entries by name, size or modified. Default: name")
+	l.output.Println("  -r          reverses the order")
+	l.output.Println("  -d          lists only folders")
+	l.output.Println("  -f          lists only files")
 	l.output.Println("")
 	l.output.Println("marking:")
 	l.output.Println("  d           folder")
@@ -97,20 +131,36 @@ func (l *listCommand) Execute() error {
 		return fmt.Errorf("please use O/S native commands to list files/folders")
 	}
 
-	anim := common.NewAnimation(l.output, "processing...")
-	anim.Start()
+	continuation := ""
+	for {
+		anim := common.NewAnimation(l.output, "processing...")
+		anim.Start()
 
-	folder, err := dfs.List(l.headAddresses, l.source, l.usage)
-	if err != nil {
-		anim.Cancel()
-		return err
+		folder, total, next, err := dfs.ListPage(l.headAddresses, l.source, l.usage, l.options, continuation)
+		if err != nil {
+			anim.Cancel()
+			return err
+		}
+		anim.Stop()
+
+		if l.listing {
+			if len(continuation) == 0 {
+				l.printTotal(folder, total)
+			}
+			l.printAsList(folder)
+		} else {
+			l.printAsSummary(folder)
+		}
+
+		if len(next) == 0 {
+			break
+		}
+		continuation = next
 	}
-	anim.Stop()
 
-	if l.listing {
-		l.printAsList(folder)
-	} else {
-		l.printAsSummary(folder)
+	if !l.listing {
+		l.output.Println("")
+		l.output.Refresh()
 	}
 	return nil
 }
@@ -126,19 +176,18 @@ func (l *listCommand) printAsSummary(folder *common.Folder) {
 	for _, f := range folder.Files {
 		l.output.Printf("%s   ", f.Name)
 	}
-	l.output.Println("")
 	l.output.Refresh()
 }
 
-func (l *listCommand) printAsList(folder *common.Folder) {
-	total := len(folder.Folders) + len(folder.Files)
-
+func (l *listCommand) printTotal(folder *common.Folder, total int) {
 	if l.usage && total > 1 {
 		l.output.Printf("total %d (%s)\n", total, l.sizeToString(folder.Size))
 	} else {
 		l.output.Printf("total %d\n", total)
 	}
+}
 
+func (l *listCommand) printAsList(folder *common.Folder) {
 	for _, f := range folder.Folders {
 		l.output.Printf("d %7v %s %s\n", l.sizeToString(f.Size), f.Created.Format(common.FriendlyTimeFormat), f.Name)
 	}
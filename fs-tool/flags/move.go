@@ -207,7 +207,7 @@ func (m *moveCommand) remoteToLocal() error {
 		}
 	}
 
-	if err := dfs.Pull(m.headAddresses, m.sources, m.target, nil); err != nil {
+	if err := dfs.Pull(m.headAddresses, m.sources, m.target, nil, nil); err != nil {
 		anim.Cancel()
 		return err
 	}
@@ -257,7 +257,7 @@ func (m *moveCommand) localToRemote() error {
 		m.target = common.Join(m.basePath, m.target)
 	}
 
-	if err := dfs.Put(m.headAddresses, sourceTemp, m.target, m.overwrite); err != nil {
+	if err := dfs.Put(m.headAddresses, sourceTemp, m.target, m.overwrite, nil); err != nil {
 		anim.Cancel()
 		return fmt.Errorf(err.Error())
 	}
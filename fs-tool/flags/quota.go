@@ -0,0 +1,199 @@
+package flags
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/terminal"
+	"github.com/freakmaxi/kertish-dfs/fs-tool/dfs"
+)
+
+type quotaCommand struct {
+	headAddresses []string
+	output        terminal.Output
+	basePath      string
+	args          []string
+
+	limits map[string]uint64
+	remove bool
+	target string
+}
+
+// NewQuota creates the execution of the folder quota operations
+func NewQuota(headAddresses []string, output terminal.Output, basePath string, args []string) Execution {
+	return &quotaCommand{
+		headAddresses: headAddresses,
+		output:        output,
+		basePath:      basePath,
+		args:          args,
+		limits:        make(map[string]uint64),
+	}
+}
+
+func (q *quotaCommand) Parse() error {
+	for len(q.args) > 0 {
+		arg := q.args[0]
+		switch arg {
+		case "-s", "-f", "-ss", "-sf":
+			q.args = q.args[1:]
+			if len(q.args) == 0 {
+				return fmt.Errorf("%s argument needs value", arg)
+			}
+			limit, err := strconv.ParseUint(q.args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s argument value should be a positive number", arg)
+			}
+			q.limits[arg] = limit
+			q.args = q.args[1:]
+			continue
+		case "-r":
+			q.args = q.args[1:]
+			q.remove = true
+			continue
+		case "-h":
+			return errors.ErrShowUsage
+		default:
+			if strings.Index(arg, "-") == 0 {
+				return fmt.Errorf("unsupported argument for quota command")
+			}
+		}
+		break
+	}
+
+	if q.remove && len(q.limits) > 0 {
+		return fmt.Errorf("quota command can only do one operation at a time")
+	}
+
+	q.args = sourceTargetArguments(q.args)
+	q.args = cleanEmptyArguments(q.args)
+
+	q.target = q.basePath
+	if len(q.args) > 0 {
+		if !filepath.IsAbs(q.args[0]) {
+			q.target = path.Join(q.basePath, q.args[0])
+		} else {
+			q.target = q.args[0]
+		}
+	}
+
+	return nil
+}
+
+func (q *quotaCommand) PrintUsage() {
+	q.output.Println("  quota       Show, set and remove the folder quota that covers the whole folder tree.")
+	q.output.Println("              Ex: quota [arguments] [target]")
+	q.output.Println("")
+	q.output.Println("arguments:")
+	q.output.Println("  -s size     sets the total file size limit in bytes")
+	q.output.Println("  -f count    sets the file count limit")
+	q.output.Println("  -ss size    sets the soft limit of the total file size in bytes to execute the quota hooks")
+	q.output.Println("  -sf count   sets the soft limit of the file count to execute the quota hooks")
+	q.output.Println("  -r          removes the quota of the target folder")
+	q.output.Println("")
+	q.output.Println("              Absent limits are not applied while setting the quota")
+	q.output.Println("")
+	q.output.Refresh()
+}
+
+func (q *quotaCommand) Name() string {
+	return "quota"
+}
+
+func (q *quotaCommand) Execute() error {
+	if strings.Index(q.target, local) == 0 {
+		return fmt.Errorf("quota command works only with dfs folders")
+	}
+
+	anim := common.NewAnimation(q.output, "processing...")
+	anim.Start()
+
+	var err error
+	var quota *common.Quota
+
+	switch {
+	case q.remove:
+		err = dfs.SetQuota(q.headAddresses, q.target, nil)
+	case len(q.limits) > 0:
+		quota, err = common.NewQuota(q.limits["-s"], q.limits["-f"], q.limits["-ss"], q.limits["-sf"])
+		if err != nil {
+			err = fmt.Errorf("soft limits can not be more than the limits")
+			break
+		}
+		err = dfs.SetQuota(q.headAddresses, q.target, quota)
+		quota = nil
+	default:
+		quota, err = dfs.Quota(q.headAddresses, q.target)
+	}
+
+	if err != nil {
+		anim.Cancel()
+		return err
+	}
+	anim.Stop()
+
+	if quota != nil {
+		q.print(quota)
+	}
+	return nil
+}
+
+func (q *quotaCommand) print(quota *common.Quota) {
+	q.output.Printf("size  %s\n", q.usageToString(quota.Usage.Size, quota.Size, quota.SoftSize, true))
+	q.output.Printf("files %s\n", q.usageToString(quota.Usage.Files, quota.Files, quota.SoftFiles, false))
+	q.output.Refresh()
+}
+
+func (q *quotaCommand) usageToString(usage uint64, limit uint64, softLimit uint64, size bool) string {
+	valueToString := func(value uint64) string {
+		if size {
+			return q.sizeToString(value)
+		}
+		return strconv.FormatUint(value, 10)
+	}
+
+	limitString := "unlimited"
+	if limit > 0 {
+		limitString = valueToString(limit)
+	}
+
+	usageString := fmt.Sprintf("%s / %s", valueToString(usage), limitString)
+	if softLimit > 0 {
+		usageString = fmt.Sprintf("%s (soft %s)", usageString, valueToString(softLimit))
+	}
+	return usageString
+}
+
+func (q *quotaCommand) sizeToString(size uint64) string {
+	calculatedSize := size
+	divideCount := 0
+	for {
+		calculatedSizeString := strconv.FormatUint(calculatedSize, 10)
+		if len(calculatedSizeString) < 6 {
+			break
+		}
+		calculatedSize /= 1024
+		divideCount++
+	}
+
+	switch divideCount {
+	case 0:
+		return fmt.Sprintf("%sb", strconv.FormatUint(calculatedSize, 10))
+	case 1:
+		return fmt.Sprintf("%skb", strconv.FormatUint(calculatedSize, 10))
+	case 2:
+		return fmt.Sprintf("%smb", strconv.FormatUint(calculatedSize, 10))
+	case 3:
+		return fmt.Sprintf("%sgb", strconv.FormatUint(calculatedSize, 10))
+	case 4:
+		return fmt.Sprintf("%stb", strconv.FormatUint(calculatedSize, 10))
+	}
+
+	return "N/A"
+}
+
+var _ Execution = &quotaCommand{}
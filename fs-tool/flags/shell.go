@@ -78,6 +78,11 @@ func (s *shellCommand) printHelp() {
 	s.output.Println("  mv      Move file or folder.")
 	s.output.Println("  rm      Remove files and/or folders.")
 	s.output.Println("  tree    Print folders tree.")
+	s.output.Println("  find    Search files and folders.")
+	s.output.Println("  versions Manage file versions.")
+	s.output.Println("  trash   Manage deleted files and folders.")
+	s.output.Println("  quota   Manage folder quotas.")
+	s.output.Println("  acl     Manage folder access lists.")
 	s.output.Println("  help    Show this screen.")
 	s.output.Println("          Ex: help [command] or help shortcuts")
 	s.output.Println("  exit    Exit from shell.")
@@ -438,7 +443,7 @@ func (s *shellCommand) parse(args []string) (bool, bool, Execution) {
 		return true, false, nil
 	case "exit":
 		return true, true, nil
-	case "mkdir", "ls", "cp", "mv", "rm", "tree":
+	case "mkdir", "ls", "cp", "mv", "rm", "tree", "find", "versions", "trash", "quota", "acl":
 		mrArgs := make([]string, 0)
 		if len(args) > 1 {
 			mrArgs = args[1:]
@@ -0,0 +1,174 @@
+package flags
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/terminal"
+	"github.com/freakmaxi/kertish-dfs/fs-tool/dfs"
+)
+
+type trashCommand struct {
+	headAddresses []string
+	output        terminal.Output
+	args          []string
+
+	operation string
+	entryId   string
+}
+
+// NewTrash creates the execution of the deleted folders and files operations
+func NewTrash(headAddresses []string, output terminal.Output, args []string) Execution {
+	return &trashCommand{
+		headAddresses: headAddresses,
+		output:        output,
+		args:          args,
+	}
+}
+
+func (t *trashCommand) Parse() error {
+	t.args = cleanEmptyArguments(t.args)
+
+	if len(t.args) == 0 {
+		t.operation = "ls"
+		return nil
+	}
+
+	t.operation = t.args[0]
+	t.args = t.args[1:]
+
+	switch t.operation {
+	case "-h":
+		return errors.ErrShowUsage
+	case "ls":
+		if len(t.args) > 0 {
+			return fmt.Errorf("trash ls does not accept any parameter")
+		}
+	case "restore":
+		if len(t.args) != 1 {
+			return fmt.Errorf("trash restore needs entry id parameter")
+		}
+		t.entryId = t.args[0]
+	case "empty":
+		if len(t.args) > 1 {
+			return fmt.Errorf("trash empty accepts only entry id parameter")
+		}
+		if len(t.args) == 1 {
+			t.entryId = t.args[0]
+		}
+	default:
+		return fmt.Errorf("unsupported operation for trash command")
+	}
+
+	return nil
+}
+
+func (t *trashCommand) PrintUsage() {
+	t.output.Println("  trash       List, restore and empty the deleted folders and files.")
+	t.output.Println("              Ex: trash [operation] [entryId]")
+	t.output.Println("")
+	t.output.Println("operations:")
+	t.output.Println("  ls          lists the trash entries from the newest deletion to the oldest (default)")
+	t.output.Println("  restore id  restores the trash entry to its original path")
+	t.output.Println("  empty [id]  deletes the trash entry permanently. All of them if there is no entry id")
+	t.output.Println("")
+	t.output.Println("marking:")
+	t.output.Println("  d           folder")
+	t.output.Println("  -           file")
+	t.output.Println("")
+	t.output.Refresh()
+}
+
+func (t *trashCommand) Name() string {
+	return "trash"
+}
+
+func (t *trashCommand) Execute() error {
+	anim := common.NewAnimation(t.output, "processing...")
+	anim.Start()
+
+	var err error
+	var entries common.TrashEntries
+
+	switch t.operation {
+	case "restore":
+		err = dfs.RestoreTrash(t.headAddresses, t.entryId)
+	case "empty":
+		err = dfs.PurgeTrash(t.headAddresses, t.entryId)
+	default:
+		entries, err = dfs.ListTrash(t.headAddresses)
+	}
+
+	if err != nil {
+		anim.Cancel()
+		return err
+	}
+	anim.Stop()
+
+	if entries != nil {
+		t.print(entries)
+	}
+	return nil
+}
+
+func (t *trashCommand) print(entries common.TrashEntries) {
+	t.output.Printf("total %d\n", len(entries))
+
+	for _, entry := range entries {
+		entryChar := "-"
+		if entry.Folder {
+			entryChar = "d"
+		}
+		t.output.Printf(
+			"%s %s %7v %s %s %s\n",
+			entryChar,
+			entry.Id,
+			t.sizeToString(entry.Size),
+			entry.Deleted.Local().Format(common.FriendlyTimeFormat),
+			entry.Expires.Local().Format(common.FriendlyTimeFormat),
+			t.pathToString(entry.Path),
+		)
+	}
+
+	t.output.Refresh()
+}
+
+func (t *trashCommand) pathToString(path string) string {
+	if strings.Contains(path, " ") {
+		return fmt.Sprintf("\"%s\"", path)
+	}
+	return path
+}
+
+func (t *trashCommand) sizeToString(size uint64) string {
+	calculatedSize := size
+	divideCount := 0
+	for {
+		calculatedSizeString := strconv.FormatUint(calculatedSize, 10)
+		if len(calculatedSizeString) < 6 {
+			break
+		}
+		calculatedSize /= 1024
+		divideCount++
+	}
+
+	switch divideCount {
+	case 0:
+		return fmt.Sprintf("%sb", strconv.FormatUint(calculatedSize, 10))
+	case 1:
+		return fmt.Sprintf("%skb", strconv.FormatUint(calculatedSize, 10))
+	case 2:
+		return fmt.Sprintf("%smb", strconv.FormatUint(calculatedSize, 10))
+	case 3:
+		return fmt.Sprintf("%sgb", strconv.FormatUint(calculatedSize, 10))
+	case 4:
+		return fmt.Sprintf("%stb", strconv.FormatUint(calculatedSize, 10))
+	}
+
+	return "N/A"
+}
+
+var _ Execution = &trashCommand{}
@@ -0,0 +1,227 @@
+package flags
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/terminal"
+	"github.com/freakmaxi/kertish-dfs/fs-tool/dfs"
+)
+
+type versionsCommand struct {
+	headAddresses []string
+	output        terminal.Output
+	basePath      string
+	args          []string
+
+	versioning *bool
+	restore    string
+	purge      bool
+	keep       string
+	olderThan  string
+	target     string
+}
+
+// NewVersions creates the execution of file versioning operations
+func NewVersions(headAddresses []string, output terminal.Output, basePath string, args []string) Execution {
+	return &versionsCommand{
+		headAddresses: headAddresses,
+		output:        output,
+		basePath:      basePath,
+		args:          args,
+	}
+}
+
+func (v *versionsCommand) Parse() error {
+	for len(v.args) > 0 {
+		arg := v.args[0]
+		switch arg {
+		case "-e", "-d":
+			v.args = v.args[1:]
+			enabled := strings.Compare(arg, "-e") == 0
+			v.versioning = &enabled
+			continue
+		case "-r":
+			v.args = v.args[1:]
+			if len(v.args) == 0 {
+				return fmt.Errorf("restore argument needs version id")
+			}
+			v.restore = v.args[0]
+			v.args = v.args[1:]
+			continue
+		case "-p":
+			v.args = v.args[1:]
+			v.purge = true
+			continue
+		case "-k":
+			v.args = v.args[1:]
+			if len(v.args) == 0 {
+				return fmt.Errorf("keep argument needs value")
+			}
+			if _, err := strconv.ParseUint(v.args[0], 10, 32); err != nil {
+				return fmt.Errorf("keep argument value should be a positive number")
+			}
+			v.keep = v.args[0]
+			v.args = v.args[1:]
+			continue
+		case "-o":
+			v.args = v.args[1:]
+			if len(v.args) == 0 {
+				return fmt.Errorf("older than argument needs value")
+			}
+			v.olderThan = v.args[0]
+			v.args = v.args[1:]
+			continue
+		case "-h":
+			return errors.ErrShowUsage
+		default:
+			if strings.Index(arg, "-") == 0 {
+				return fmt.Errorf("unsupported argument for versions command")
+			}
+		}
+		break
+	}
+
+	operations := 0
+	if v.versioning != nil {
+		operations++
+	}
+	if len(v.restore) > 0 {
+		operations++
+	}
+	if v.purge {
+		operations++
+	}
+	if operations > 1 {
+		return fmt.Errorf("versions command can only do one operation at a time")
+	}
+
+	if !v.purge && (len(v.keep) > 0 || len(v.olderThan) > 0) {
+		return fmt.Errorf("keep and older than arguments can only be used with purge argument")
+	}
+
+	v.args = sourceTargetArguments(v.args)
+	v.args = cleanEmptyArguments(v.args)
+
+	if len(v.args) == 0 {
+		return fmt.Errorf("versions command needs target parameter")
+	}
+
+	v.target = v.args[0]
+	if !filepath.IsAbs(v.target) {
+		v.target = path.Join(v.basePath, v.target)
+	}
+
+	return nil
+}
+
+func (v *versionsCommand) PrintUsage() {
+	v.output.Println("  versions    List, restore and purge the file versions or change the folder versioning.")
+	v.output.Println("              Ex: versions [arguments] [target]")
+	v.output.Println("")
+	v.output.Println("arguments:")
+	v.output.Println("  -e          enables versioning on the target folder")
+	v.output.Println("  -d          disables versioning on the target folder, existent versions are kept")
+	v.output.Println("  -r id       restores the version as the current file of the target")
+	v.output.Println("  -p          purges the versions of the target file. All of them if there is no filter")
+	v.output.Println("  -k count    keeps the newest versions while purging")
+	v.output.Println("  -o age      purges only the versions that are older than the age. Ex: 72h")
+	v.output.Println("")
+	v.output.Println("              Use cp -v [versionId] [source] local:[target] to download a version")
+	v.output.Println("")
+	v.output.Println("marking:")
+	v.output.Println("  -           overwritten")
+	v.output.Println("  x           deleted")
+	v.output.Println("  ↯           zombie")
+	v.output.Println("")
+	v.output.Refresh()
+}
+
+func (v *versionsCommand) Name() string {
+	return "versions"
+}
+
+func (v *versionsCommand) Execute() error {
+	if strings.Index(v.target, local) == 0 {
+		return fmt.Errorf("versions command works only with dfs files/folders")
+	}
+
+	anim := common.NewAnimation(v.output, "processing...")
+	anim.Start()
+
+	var err error
+	var versions common.FileVersions
+
+	switch {
+	case v.versioning != nil:
+		err = dfs.SetVersioning(v.headAddresses, v.target, *v.versioning)
+	case len(v.restore) > 0:
+		err = dfs.RestoreVersion(v.headAddresses, v.target, v.restore)
+	case v.purge:
+		err = dfs.PurgeVersions(v.headAddresses, v.target, v.keep, v.olderThan)
+	default:
+		versions, err = dfs.Versions(v.headAddresses, v.target)
+	}
+
+	if err != nil {
+		anim.Cancel()
+		return err
+	}
+	anim.Stop()
+
+	if versions != nil {
+		v.print(versions)
+	}
+	return nil
+}
+
+func (v *versionsCommand) print(versions common.FileVersions) {
+	v.output.Printf("total %d\n", len(versions))
+
+	for _, version := range versions {
+		versionChar := "-"
+		if version.File.Zombie {
+			versionChar = "↯"
+		} else if version.Deleted {
+			versionChar = "x"
+		}
+		v.output.Printf("%s %s %7v %s\n", versionChar, version.Id, v.sizeToString(version.File.Size), version.Archived.Local().Format(common.FriendlyTimeFormat))
+	}
+
+	v.output.Refresh()
+}
+
+func (v *versionsCommand) sizeToString(size uint64) string {
+	calculatedSize := size
+	divideCount := 0
+	for {
+		calculatedSizeString := strconv.FormatUint(calculatedSize, 10)
+		if len(calculatedSizeString) < 6 {
+			break
+		}
+		calculatedSize /= 1024
+		divideCount++
+	}
+
+	switch divideCount {
+	case 0:
+		return fmt.Sprintf("%sb", strconv.FormatUint(calculatedSize, 10))
+	case 1:
+		return fmt.Sprintf("%skb", strconv.FormatUint(calculatedSize, 10))
+	case 2:
+		return fmt.Sprintf("%smb", strconv.FormatUint(calculatedSize, 10))
+	case 3:
+		return fmt.Sprintf("%sgb", strconv.FormatUint(calculatedSize, 10))
+	case 4:
+		return fmt.Sprintf("%stb", strconv.FormatUint(calculatedSize, 10))
+	}
+
+	return "N/A"
+}
+
+var _ Execution = &versionsCommand{}
@@ -7,6 +7,7 @@ require (
 	github.com/gdamore/tcell v1.4.0
 	github.com/google/uuid v1.2.0
 	github.com/mattn/go-runewidth v0.0.12
+	golang.org/x/crypto v0.0.0-20200302210943-78000ba7a073
 )
 
 require (
@@ -16,7 +17,7 @@ require (
 	go.uber.org/atomic v1.6.0 // indirect
 	go.uber.org/multierr v1.5.0 // indirect
 	go.uber.org/zap v1.16.0 // indirect
-	golang.org/x/sys v0.0.0-20190626150813-e07cf5db2756 // indirect
+	golang.org/x/sys v0.7.0 // indirect
 	golang.org/x/text v0.3.5 // indirect
 )
 
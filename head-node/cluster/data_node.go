@@ -11,15 +11,18 @@ import (
 	"time"
 
 	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 )
 
 const dialTimeout = time.Second * 30
 const commandCreate = "CREA"
+const commandCreateShard = "SHRD"
 const commandRead = "READ"
 const commandDelete = "DELE"
 
 type DataNode interface {
 	Create(data []byte) (bool, string, error)
+	CreateShard(sha512Hex string, shard []byte) (bool, error)
 	CreateShadow(sha512Hex string) error
 	Read(sha512Hex string, begins uint32, ends uint32, readHandler func(data []byte) error) error
 	Delete(sha512Hex string) error
@@ -41,7 +44,7 @@ func NewDataNode(address string) (DataNode, error) {
 }
 
 func (d *dataNode) connect(connectionHandler func(conn net.Conn) error) error {
-	conn, err := net.DialTimeout(d.address.Network(), d.address.String(), dialTimeout)
+	conn, err := secure.DialTimeout(d.address.Network(), d.address.String(), dialTimeout)
 	if err != nil {
 		return err
 	}
@@ -99,6 +102,50 @@ func (d *dataNode) Create(data []byte) (exists bool, sha512Hex string, err error
 	return
 }
 
+// CreateShard creates the shard of the erasure coded chunk with the name of the chunk. Usage of the shard is
+// increased when it already exists on the node
+func (d *dataNode) CreateShard(sha512Hex string, shard []byte) (exists bool, err error) {
+	err = d.connect(func(conn net.Conn) error {
+		if _, err := conn.Write([]byte(commandCreateShard)); err != nil {
+			return err
+		}
+
+		sha512Sum, err := hex.DecodeString(sha512Hex)
+		if err != nil {
+			return err
+		}
+		if _, err := conn.Write(sha512Sum); err != nil {
+			return err
+		}
+
+		shardSha512Sum := sha512.Sum512_256(shard)
+		if _, err := conn.Write(shardSha512Sum[:]); err != nil {
+			return err
+		}
+
+		if !d.result(conn) {
+			exists = true
+			return nil
+		}
+
+		blockSize := uint32(len(shard))
+		if err := binary.Write(conn, binary.LittleEndian, &blockSize); err != nil {
+			return err
+		}
+
+		if _, err := conn.Write(shard); err != nil {
+			return err
+		}
+
+		if !d.result(conn) {
+			return fmt.Errorf("create shard command is failed on data node")
+		}
+
+		return nil
+	})
+	return
+}
+
 func (d *dataNode) CreateShadow(sha512Hex string) error {
 	return d.connect(func(conn net.Conn) error {
 		if _, err := conn.Write([]byte(commandCreate)); err != nil {
@@ -23,6 +23,7 @@ type Metadata interface {
 	Get(folderPaths []string) ([]*common.Folder, error)
 	ChildrenTree(folderPath string, includeItself bool, reverseSort bool) ([]*common.Folder, error)
 	ParentTree(folderPath string, includeItself bool, reverseSort bool) ([]*common.Folder, error)
+	Expired() ([]string, error)
 
 	SaveBlock(folderPaths []string, saveHandler func(folders map[string]*common.Folder) (bool, error)) error
 	SaveChain(folderPath string, saveHandler func(folder *common.Folder) (bool, error)) error
@@ -57,14 +58,20 @@ func (m *metadata) context(parentContext context.Context) (context.Context, cont
 }
 
 func (m *metadata) setupIndices() error {
-	model := mongo.IndexModel{
-		Keys: bson.M{"full": 1},
+	models := []mongo.IndexModel{
+		{
+			Keys: bson.M{"full": 1},
+		},
+		{
+			Keys:    bson.M{"files.expires": 1},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 
 	ctx, cancelFunc := m.context(context.Background())
 	defer cancelFunc()
 
-	_, err := m.col.Indexes().CreateOne(ctx, model)
+	_, err := m.col.Indexes().CreateMany(ctx, models)
 	return err
 }
 
@@ -201,6 +208,40 @@ func (m *metadata) ChildrenTree(folderPath string, includeItself bool, reverseSo
 	return folders, nil
 }
 
+// Expired returns the full paths of the files that are out of their time-to-live
+func (m *metadata) Expired() ([]string, error) {
+	opts := options.Find().SetProjection(bson.M{"full": 1, "files.name": 1, "files.expires": 1})
+
+	cursor, err := m.find(bson.M{"files.expires": bson.M{"$lte": time.Now().UTC()}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		ctx, cancelFunc := m.context(context.Background())
+		defer cancelFunc()
+
+		_ = cursor.Close(ctx)
+	}()
+
+	paths := make([]string, 0)
+	for {
+		folder, err := m.next(cursor)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		for _, file := range folder.Files {
+			if file.Expired() {
+				paths = append(paths, common.Join(folder.Full, file.Name))
+			}
+		}
+	}
+	return paths, nil
+}
+
 func (m *metadata) SaveBlock(folderPaths []string, saveHandler func(folders map[string]*common.Folder) (bool, error)) error {
 	folderPaths = m.cleanDuplicates(folderPaths)
 
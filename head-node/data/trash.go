@@ -0,0 +1,168 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/locking-center-client-go/mutex"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Trash interface {
+	Create(entry *common.TrashEntry) error
+	Get(entryId string) (*common.TrashEntry, error)
+	List() (common.TrashEntries, error)
+	Expired() ([]string, error)
+
+	Delete(entryId string, deleteHandler func(entry *common.TrashEntry) error) error
+}
+
+const trashCollection = "trash"
+const trashLockKeyPrefix = "trash_"
+
+type trash struct {
+	mutex mutex.LockingCenter
+	col   *mongo.Collection
+}
+
+func NewTrash(mutex mutex.LockingCenter, conn *Connection, database string) (Trash, error) {
+	trashCol := conn.client.Database(database).Collection(trashCollection)
+
+	t := &trash{
+		mutex: mutex,
+		col:   trashCol,
+	}
+	if err := t.setupIndices(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *trash) context() (context.Context, context.CancelFunc) {
+	timeoutDuration := time.Second * 30
+	return context.WithTimeout(context.Background(), timeoutDuration)
+}
+
+func (t *trash) lockKey(entryId string) string {
+	return fmt.Sprintf("%s%s", trashLockKeyPrefix, entryId)
+}
+
+func (t *trash) setupIndices() error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.M{"id": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.M{"expires": 1},
+		},
+	}
+
+	ctx, cancelFunc := t.context()
+	defer cancelFunc()
+
+	_, err := t.col.Indexes().CreateMany(ctx, models)
+	return err
+}
+
+func (t *trash) findOne(entryId string) (*common.TrashEntry, error) {
+	ctx, cancelFunc := t.context()
+	defer cancelFunc()
+
+	var entry *common.TrashEntry
+	if err := t.col.FindOne(ctx, bson.M{"id": entryId}).Decode(&entry); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (t *trash) Create(entry *common.TrashEntry) error {
+	ctx, cancelFunc := t.context()
+	defer cancelFunc()
+
+	_, err := t.col.InsertOne(ctx, entry)
+	return err
+}
+
+func (t *trash) Get(entryId string) (*common.TrashEntry, error) {
+	return t.findOne(entryId)
+}
+
+// List returns the trash entries from the newest deletion to the oldest without their content
+func (t *trash) List() (common.TrashEntries, error) {
+	ctx, cancelFunc := t.context()
+	defer cancelFunc()
+
+	opts := options.Find().
+		SetProjection(bson.M{"file": 0, "folders": 0}).
+		SetSort(bson.M{"deleted": -1})
+	cursor, err := t.col.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	entries := make(common.TrashEntries, 0)
+	for cursor.Next(ctx) {
+		var entry *common.TrashEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, cursor.Err()
+}
+
+// Expired returns the ids of the trash entries that are out of the retention period
+func (t *trash) Expired() ([]string, error) {
+	ctx, cancelFunc := t.context()
+	defer cancelFunc()
+
+	opts := options.Find().SetProjection(bson.M{"id": 1})
+	cursor, err := t.col.Find(ctx, bson.M{"expires": bson.M{"$lte": time.Now().UTC()}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	entryIds := make([]string, 0)
+	for cursor.Next(ctx) {
+		var entry common.TrashEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, err
+		}
+		entryIds = append(entryIds, entry.Id)
+	}
+	return entryIds, cursor.Err()
+}
+
+// Delete drops the trash entry when the delete handler completes without error
+func (t *trash) Delete(entryId string, deleteHandler func(entry *common.TrashEntry) error) error {
+	t.mutex.Lock(t.lockKey(entryId))
+	defer t.mutex.Unlock(t.lockKey(entryId))
+
+	entry, err := t.findOne(entryId)
+	if err != nil {
+		return err
+	}
+
+	if err := deleteHandler(entry); err != nil {
+		return err
+	}
+
+	ctx, cancelFunc := t.context()
+	defer cancelFunc()
+
+	_, err = t.col.DeleteOne(ctx, bson.M{"id": entryId})
+	return err
+}
+
+var _ Trash = &trash{}
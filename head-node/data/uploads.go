@@ -0,0 +1,167 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/locking-center-client-go/mutex"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Uploads interface {
+	Create(session *common.UploadSession) error
+	Get(sessionId string) (*common.UploadSession, error)
+	Expired() ([]string, error)
+
+	Save(sessionId string, saveHandler func(session *common.UploadSession) (bool, error)) error
+	Delete(sessionId string, deleteHandler func(session *common.UploadSession) error) error
+}
+
+const uploadsCollection = "uploads"
+const uploadsLockKeyPrefix = "upload_"
+
+type uploads struct {
+	mutex mutex.LockingCenter
+	col   *mongo.Collection
+}
+
+func NewUploads(mutex mutex.LockingCenter, conn *Connection, database string) (Uploads, error) {
+	uploadsCol := conn.client.Database(database).Collection(uploadsCollection)
+
+	u := &uploads{
+		mutex: mutex,
+		col:   uploadsCol,
+	}
+	if err := u.setupIndices(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (u *uploads) context() (context.Context, context.CancelFunc) {
+	timeoutDuration := time.Second * 30
+	return context.WithTimeout(context.Background(), timeoutDuration)
+}
+
+func (u *uploads) lockKey(sessionId string) string {
+	return fmt.Sprintf("%s%s", uploadsLockKeyPrefix, sessionId)
+}
+
+func (u *uploads) setupIndices() error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.M{"id": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.M{"expires": 1},
+		},
+	}
+
+	ctx, cancelFunc := u.context()
+	defer cancelFunc()
+
+	_, err := u.col.Indexes().CreateMany(ctx, models)
+	return err
+}
+
+func (u *uploads) findOne(sessionId string) (*common.UploadSession, error) {
+	ctx, cancelFunc := u.context()
+	defer cancelFunc()
+
+	var session *common.UploadSession
+	if err := u.col.FindOne(ctx, bson.M{"id": sessionId}).Decode(&session); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+func (u *uploads) Create(session *common.UploadSession) error {
+	ctx, cancelFunc := u.context()
+	defer cancelFunc()
+
+	_, err := u.col.InsertOne(ctx, session)
+	return err
+}
+
+func (u *uploads) Get(sessionId string) (*common.UploadSession, error) {
+	return u.findOne(sessionId)
+}
+
+// Expired returns the ids of the upload sessions that are expired
+func (u *uploads) Expired() ([]string, error) {
+	ctx, cancelFunc := u.context()
+	defer cancelFunc()
+
+	opts := options.Find().SetProjection(bson.M{"id": 1})
+	cursor, err := u.col.Find(ctx, bson.M{"expires": bson.M{"$lte": time.Now().UTC()}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	sessionIds := make([]string, 0)
+	for cursor.Next(ctx) {
+		var session common.UploadSession
+		if err := cursor.Decode(&session); err != nil {
+			return nil, err
+		}
+		sessionIds = append(sessionIds, session.Id)
+	}
+	return sessionIds, cursor.Err()
+}
+
+func (u *uploads) Save(sessionId string, saveHandler func(session *common.UploadSession) (bool, error)) error {
+	u.mutex.Lock(u.lockKey(sessionId))
+	defer u.mutex.Unlock(u.lockKey(sessionId))
+
+	session, err := u.findOne(sessionId)
+	if err != nil {
+		return err
+	}
+
+	save, err := saveHandler(session)
+	if !save {
+		return err
+	}
+
+	ctx, cancelFunc := u.context()
+	defer cancelFunc()
+
+	if _, err := u.col.ReplaceOne(ctx, bson.M{"id": sessionId}, session); err != nil {
+		return err
+	}
+
+	return err
+}
+
+// Delete drops the upload session when the delete handler completes without error
+func (u *uploads) Delete(sessionId string, deleteHandler func(session *common.UploadSession) error) error {
+	u.mutex.Lock(u.lockKey(sessionId))
+	defer u.mutex.Unlock(u.lockKey(sessionId))
+
+	session, err := u.findOne(sessionId)
+	if err != nil {
+		return err
+	}
+
+	if err := deleteHandler(session); err != nil {
+		return err
+	}
+
+	ctx, cancelFunc := u.context()
+	defer cancelFunc()
+
+	_, err = u.col.DeleteOne(ctx, bson.M{"id": sessionId})
+	return err
+}
+
+var _ Uploads = &uploads{}
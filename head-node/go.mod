@@ -6,21 +6,26 @@ require (
 	github.com/freakmaxi/kertish-dfs/basics v0.0.0-00010101000000-000000000000
 	github.com/freakmaxi/locking-center-client-go v0.2.1
 	github.com/gorilla/mux v1.8.0
+	github.com/stretchr/testify v1.7.0
 	go.mongodb.org/mongo-driver v1.5.2
 	go.uber.org/zap v1.16.0
 )
 
 require (
 	github.com/aws/aws-sdk-go v1.34.28 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gdamore/encoding v1.0.0 // indirect
 	github.com/gdamore/tcell v1.4.0 // indirect
 	github.com/go-stack/stack v1.8.0 // indirect
 	github.com/golang/snappy v0.0.1 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 	github.com/klauspost/compress v1.9.5 // indirect
+	github.com/klauspost/cpuid/v2 v2.1.0 // indirect
+	github.com/klauspost/reedsolomon v1.10.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.0.3 // indirect
 	github.com/mattn/go-runewidth v0.0.12 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rivo/uniseg v0.1.0 // indirect
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
 	github.com/xdg-go/scram v1.0.2 // indirect
@@ -30,8 +35,9 @@ require (
 	go.uber.org/multierr v1.5.0 // indirect
 	golang.org/x/crypto v0.0.0-20200302210943-78000ba7a073 // indirect
 	golang.org/x/sync v0.0.0-20190911185100-cd5d95a43a6e // indirect
-	golang.org/x/sys v0.0.0-20190626150813-e07cf5db2756 // indirect
+	golang.org/x/sys v0.7.0 // indirect
 	golang.org/x/text v0.3.5 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
 
 replace github.com/freakmaxi/kertish-dfs/basics => ../basics
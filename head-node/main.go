@@ -3,10 +3,15 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
 	"github.com/freakmaxi/kertish-dfs/basics/hooks"
 	"github.com/freakmaxi/kertish-dfs/basics/logging"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 	"github.com/freakmaxi/kertish-dfs/head-node/data"
 	"github.com/freakmaxi/kertish-dfs/head-node/manager"
 	"github.com/freakmaxi/kertish-dfs/head-node/routing"
@@ -37,6 +42,11 @@ func main() {
 	}
 	logger.Info(fmt.Sprintf("BIND_ADDRESS: %s", bindAddr))
 
+	s3BindAddr := os.Getenv("S3_BIND_ADDRESS")
+	if len(s3BindAddr) > 0 {
+		logger.Info(fmt.Sprintf("S3_BIND_ADDRESS: %s", s3BindAddr))
+	}
+
 	mutexSourceAddr := bindAddr
 	if strings.Index(mutexSourceAddr, ":") == 0 {
 		mutexSourceAddr = fmt.Sprintf("127.0.0.1%s", mutexSourceAddr)
@@ -49,6 +59,60 @@ func main() {
 	}
 	logger.Info(fmt.Sprintf("MANAGER_ADDRESS: %s", managerAddress))
 
+	var managerCredentials *auth.Credentials
+	managerCredentialsString := os.Getenv("MANAGER_CREDENTIALS")
+	if len(managerCredentialsString) > 0 {
+		var err error
+		managerCredentials, err = auth.ParseCredentials(managerCredentialsString)
+		if err != nil {
+			logger.Error("Manager Credentials should be in [keyId]:[secret] format")
+			os.Exit(22)
+		}
+	}
+	logger.Info(fmt.Sprintf("MANAGER_CREDENTIALS: %t", managerCredentials != nil))
+
+	var authenticator auth.Authenticator
+	authKeysPath := os.Getenv("AUTH_KEYS_PATH")
+	if len(authKeysPath) > 0 {
+		var err error
+		authenticator, err = auth.NewAuthenticator(authKeysPath)
+		if err != nil {
+			logger.Error("Authentication keys are not loaded", zap.Error(err))
+			os.Exit(12)
+		}
+		logger.Info(fmt.Sprintf("AUTH_KEYS_PATH: %s", authKeysPath))
+	} else {
+		logger.Info("AUTH_KEYS_PATH: disabled")
+	}
+
+	tlsCertPath := os.Getenv("TLS_CERT_PATH")
+	if len(tlsCertPath) > 0 {
+		tlsVerifyClient := len(os.Getenv("TLS_VERIFY_CLIENT")) > 0
+
+		var err error
+		secure.CurrentConfig, err = secure.NewConfig(tlsCertPath, os.Getenv("TLS_KEY_PATH"), os.Getenv("TLS_CA_PATH"), tlsVerifyClient)
+		if err != nil {
+			logger.Error("TLS setup is failed", zap.Error(err))
+			os.Exit(23)
+		}
+		logger.Info(fmt.Sprintf("TLS_CERT_PATH: %s", tlsCertPath))
+		logger.Info(fmt.Sprintf("TLS_VERIFY_CLIENT: %t", tlsVerifyClient))
+	} else {
+		logger.Info("TLS_CERT_PATH: disabled")
+	}
+
+	var presigner *auth.Presigner
+	presignSecret := os.Getenv("PRESIGN_SECRET")
+	if len(presignSecret) > 0 {
+		var err error
+		presigner, err = auth.NewPresigner(presignSecret)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Presign Secret should be at least %d characters", auth.MinPresignSecretLength))
+			os.Exit(27)
+		}
+	}
+	logger.Info(fmt.Sprintf("PRESIGN_SECRET: %t", presigner != nil))
+
 	hooks.CurrentLoader = hooks.NewLoader(os.Getenv("HOOKS_PATH"), logger)
 	logger.Info(fmt.Sprintf("HOOKS_PATH: %s", hooks.CurrentLoader.HooksPath()))
 
@@ -75,6 +139,52 @@ func main() {
 	}
 	logger.Info(fmt.Sprintf("LOCKING_CENTER: %s", mutexConn))
 
+	trashRetentionString := os.Getenv("TRASH_RETENTION")
+	if len(trashRetentionString) == 0 {
+		trashRetentionString = "168"
+	}
+	trashRetention, err := strconv.ParseUint(trashRetentionString, 10, 64)
+	if err != nil {
+		logger.Error("Trash Retention is wrong", zap.Error(err))
+		os.Exit(16)
+	}
+	if trashRetention > 0 {
+		logger.Info(fmt.Sprintf("TRASH_RETENTION: %s hour(s)", trashRetentionString))
+	} else {
+		logger.Info("TRASH_RETENTION: disabled")
+	}
+
+	blockSize := common.DefaultBlockSize
+	if blockSizeString := os.Getenv("BLOCK_SIZE"); len(blockSizeString) > 0 {
+		value, err := strconv.ParseUint(blockSizeString, 10, 32)
+		if err != nil || value == 0 || common.ValidateBlockSize(uint32(value)) != nil {
+			logger.Error("Block size should be between 1mb and 256mb in bytes")
+			os.Exit(26)
+		}
+		blockSize = uint32(value)
+	}
+	logger.Info(fmt.Sprintf("BLOCK_SIZE: %d (%d Mb)", blockSize, blockSize/(1024*1024)))
+
+	var chunker *manager.Chunker
+	chunking := os.Getenv("CHUNKING")
+	if strings.Compare(chunking, "cdc") == 0 {
+		cdcSizes := os.Getenv("CDC_SIZES")
+		if len(cdcSizes) == 0 {
+			cdcSizes = "2097152,8388608,33554432"
+		}
+		chunker, err = parseChunker(cdcSizes)
+		if err != nil {
+			logger.Error("CDC sizes should be in [min],[avg],[max] format and max can not exceed 32mb")
+			os.Exit(25)
+		}
+		logger.Info(fmt.Sprintf("CHUNKING: cdc (%s)", cdcSizes))
+	} else if len(chunking) == 0 || strings.Compare(chunking, "fixed") == 0 {
+		logger.Info("CHUNKING: fixed")
+	} else {
+		logger.Error("CHUNKING should be fixed or cdc")
+		os.Exit(24)
+	}
+
 	m, err := mutex.NewLockingCenterWithSourceAddr(mutexConn, &mutexSourceAddr)
 	if err != nil {
 		logger.Error("Mutex Setup is failed", zap.Error(err))
@@ -94,26 +204,70 @@ func main() {
 		os.Exit(18)
 	}
 
-	cluster, err := manager.NewCluster([]string{managerAddress}, logger)
+	uploads, err := data.NewUploads(m, conn, mongoDb)
+	if err != nil {
+		logger.Error("Upload Sessions Manager is failed", zap.Error(err))
+		os.Exit(19)
+	}
+
+	trash, err := data.NewTrash(m, conn, mongoDb)
+	if err != nil {
+		logger.Error("Trash Manager is failed", zap.Error(err))
+		os.Exit(17)
+	}
+
+	cluster, err := manager.NewCluster([]string{managerAddress}, managerCredentials, chunker, blockSize, logger)
 	if err != nil {
 		logger.Error("Cluster Manager is failed", zap.Error(err))
 		os.Exit(20)
 	}
-	dfs := manager.NewDfs(metadata, cluster, logger)
+	dfs := manager.NewDfs(metadata, uploads, trash, cluster, time.Duration(trashRetention)*time.Hour, logger)
 	// create root if not exists
 	if err := dfs.CreateFolder("/"); err != nil && err != os.ErrExist {
 		logger.Error("Unable to create cluster root path", zap.Error(err))
 		os.Exit(21)
 	}
-	dfsRouter := routing.NewDfsRouter(dfs, logger)
-
 	hook := manager.NewHook(metadata, logger)
-	hookRouter := routing.NewHookRouter(hook, logger)
+	accessControl := manager.NewAccessControl(metadata, trash, dfs, hook, logger)
+
+	dfsRouter := routing.NewDfsRouter(accessControl, logger)
+	uploadRouter := routing.NewUploadRouter(accessControl, logger)
+	searchRouter := routing.NewSearchRouter(accessControl, logger)
+	versionRouter := routing.NewVersionRouter(accessControl, logger)
+	trashRouter := routing.NewTrashRouter(accessControl, logger)
+	quotaRouter := routing.NewQuotaRouter(accessControl, logger)
+	aclRouter := routing.NewAclRouter(accessControl, logger)
+	davRouter := routing.NewDavRouter(accessControl, logger)
+	hookRouter := routing.NewHookRouter(accessControl, logger)
 
 	routerManager := routing.NewManager()
 	routerManager.Add(dfsRouter)
+	routerManager.Add(uploadRouter)
+	routerManager.Add(searchRouter)
+	routerManager.Add(versionRouter)
+	routerManager.Add(trashRouter)
+	routerManager.Add(quotaRouter)
+	routerManager.Add(aclRouter)
+	routerManager.Add(davRouter)
 	routerManager.Add(hookRouter)
 
+	if presigner != nil {
+		routerManager.Add(routing.NewPresignRouter(presigner, accessControl, logger))
+		routerManager.Use(routing.NewPresignMiddleware(presigner, authenticator, logger))
+	}
+
+	if authenticator != nil {
+		routerManager.Use(routing.NewAuthMiddleware(authenticator, auth.ScopeClient, logger))
+	}
+
+	if len(s3BindAddr) > 0 {
+		s3RouterManager := routing.NewManager()
+		s3RouterManager.Add(routing.NewS3Router(accessControl, authenticator, logger))
+
+		s3Proxy := services.NewProxy(s3BindAddr, s3RouterManager, logger)
+		go s3Proxy.Start()
+	}
+
 	proxy := services.NewProxy(bindAddr, routerManager, logger)
 	proxy.Start()
 
@@ -147,3 +301,21 @@ func printWelcome(console bool) {
 	fmt.Printf("Visit: https://github.com/freakmaxi/kertish-dfs\n")
 	fmt.Println()
 }
+
+func parseChunker(cdcSizes string) (*manager.Chunker, error) {
+	sizes := strings.Split(cdcSizes, ",")
+	if len(sizes) != 3 {
+		return nil, os.ErrInvalid
+	}
+
+	values := make([]uint32, 0)
+	for _, size := range sizes {
+		value, err := strconv.ParseUint(strings.TrimSpace(size), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, uint32(value))
+	}
+
+	return manager.NewChunker(values[0], values[1], values[2])
+}
@@ -0,0 +1,233 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/data"
+	"go.uber.org/zap"
+)
+
+// AccessControl interface is for evaluating the access lists of the folder trees for the authenticated
+// identities before the file manipulation and hook operations base on REST service request
+type AccessControl interface {
+	Dfs(key *auth.Key) Dfs
+	Hook(key *auth.Key) Hook
+
+	Get(folderPath string) (string, common.AccessList, error)
+	Set(folderPath string, acl common.AccessList) error
+
+	Check(key *auth.Key, path string, permission common.Permission) error
+
+	Reserve(folderPath string)
+	Internal() Dfs
+}
+
+type accessControl struct {
+	metadata data.Metadata
+	trash    data.Trash
+	dfs      Dfs
+	hook     Hook
+	logger   *zap.Logger
+
+	reserved []string
+}
+
+// NewAccessControl creates the instance of access control object that guards the dfs and hook operations
+func NewAccessControl(metadata data.Metadata, trash data.Trash, dfs Dfs, hook Hook, logger *zap.Logger) AccessControl {
+	return &accessControl{
+		metadata: metadata,
+		trash:    trash,
+		dfs:      dfs,
+		hook:     hook,
+		logger:   logger,
+	}
+}
+
+// Dfs returns the file manipulation operations that are evaluated for the key. Access lists are not
+// evaluated when the authentication is disabled (nil key) or the key has the admin scope but the reserved
+// folders are not accessible for any key
+func (a *accessControl) Dfs(key *auth.Key) Dfs {
+	if key == nil || key.Has(auth.ScopeAdmin) {
+		return newAccessDfs(a, "", false)
+	}
+	return newAccessDfs(a, key.Id, true)
+}
+
+// Hook returns the hook manipulation operations that are evaluated for the key. Access lists are not
+// evaluated when the authentication is disabled (nil key) or the key has the admin scope
+func (a *accessControl) Hook(key *auth.Key) Hook {
+	if key == nil || key.Has(auth.ScopeAdmin) {
+		return a.hook
+	}
+	return newAccessHook(a, key.Id)
+}
+
+// Get finds the access list that is effective on the folder. It returns the path of the folder that the
+// access list is attached to and ErrNotExist if there is no access list in the folder tree
+func (a *accessControl) Get(folderPath string) (string, common.AccessList, error) {
+	folderPath = common.CorrectPath(folderPath)
+
+	if _, err := a.metadata.Get([]string{folderPath}); err != nil {
+		return "", nil, err
+	}
+
+	attachedPath, acl, err := a.find(folderPath, make(map[string]*common.Folder))
+	if err != nil {
+		return "", nil, err
+	}
+	if acl == nil {
+		return "", nil, os.ErrNotExist
+	}
+	return attachedPath, acl, nil
+}
+
+// Set attaches the access list to the folder. nil access list detaches the access list of the folder to
+// inherit the access list of the parent folders
+func (a *accessControl) Set(folderPath string, acl common.AccessList) error {
+	folderPath = common.CorrectPath(folderPath)
+
+	if err := acl.Validate(); err != nil {
+		return err
+	}
+
+	return a.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[folderPath]
+		if folder == nil {
+			return false, os.ErrNotExist
+		}
+
+		if acl == nil && folder.Acl == nil {
+			return false, os.ErrNotExist
+		}
+		folder.Acl = acl
+
+		return true, nil
+	})
+}
+
+// Reserve keeps the folder tree for the internal usage of the services. Reserved folders are only accessible
+// through the Internal file manipulation operations. It should be called before serving the requests
+func (a *accessControl) Reserve(folderPath string) {
+	a.reserved = append(a.reserved, common.CorrectPath(folderPath))
+}
+
+// Internal returns the file manipulation operations without any evaluation for the services that keep their
+// data in the reserved folders
+func (a *accessControl) Internal() Dfs {
+	return a.dfs
+}
+
+// reservedPath checks if the path is a reserved folder or placed under a reserved folder
+func (a *accessControl) reservedPath(path string) bool {
+	path = common.CorrectPath(path)
+	for _, reservedPath := range a.reserved {
+		if strings.Compare(path, reservedPath) == 0 || strings.HasPrefix(path, fmt.Sprintf("%s/", reservedPath)) {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedTree checks if the folder tree of the path contains a reserved folder
+func (a *accessControl) reservedTree(path string) bool {
+	if a.reservedPath(path) {
+		return true
+	}
+
+	prefix := common.CorrectPath(path)
+	if strings.Compare(prefix, "/") != 0 {
+		prefix = fmt.Sprintf("%s/", prefix)
+	}
+	for _, reservedPath := range a.reserved {
+		if strings.HasPrefix(reservedPath, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check validates the key has the permission on the path. Access lists are not evaluated when the
+// authentication is disabled (nil key) or the key has the admin scope
+func (a *accessControl) Check(key *auth.Key, path string, permission common.Permission) error {
+	if key == nil || key.Has(auth.ScopeAdmin) {
+		return nil
+	}
+	return a.check(key.Id, permission, make(map[string]*common.Folder), path)
+}
+
+// check validates the identity has the permission on all the paths
+func (a *accessControl) check(identity string, permission common.Permission, foldersCache map[string]*common.Folder, paths ...string) error {
+	for _, path := range paths {
+		_, acl, err := a.find(path, foldersCache)
+		if err != nil {
+			return err
+		}
+
+		if acl != nil && !acl.Allows(identity, permission) {
+			return errors.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// checkTree validates the identity has the permission on the path and on the sub folders that have their
+// own access lists. It is used for the operations that are applied on the whole folder tree
+func (a *accessControl) checkTree(identity string, permission common.Permission, foldersCache map[string]*common.Folder, path string) error {
+	if err := a.check(identity, permission, foldersCache, path); err != nil {
+		return err
+	}
+
+	children, err := a.metadata.ChildrenTree(common.CorrectPath(path), false, false)
+	if err != nil {
+		if err == os.ErrNotExist {
+			return nil
+		}
+		return err
+	}
+
+	for _, child := range children {
+		if child.Acl != nil && !child.Acl.Allows(identity, permission) {
+			return errors.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// find walks from the path to the root and returns the first access list that is attached to the folder
+func (a *accessControl) find(path string, foldersCache map[string]*common.Folder) (string, common.AccessList, error) {
+	folderTree := common.PathTree(nil, path)
+
+	for i := len(folderTree) - 1; i >= 0; i-- {
+		folderPath := folderTree[i]
+
+		folder, has := foldersCache[folderPath]
+		if !has {
+			folders, err := a.metadata.Get([]string{folderPath})
+			if err != nil && err != os.ErrNotExist {
+				return "", nil, err
+			}
+			if err == nil {
+				folder = folders[0]
+			}
+			foldersCache[folderPath] = folder
+		}
+
+		// file path or the folder that does not exist yet
+		if folder == nil {
+			continue
+		}
+
+		if folder.Acl != nil {
+			return folder.Full, folder.Acl, nil
+		}
+	}
+
+	return "", nil, nil
+}
+
+var _ AccessControl = &accessControl{}
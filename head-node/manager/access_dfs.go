@@ -0,0 +1,422 @@
+package manager
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/hooks"
+)
+
+// accessDfs evaluates the access lists for the identity before passing the operations to the dfs.
+// It is created per request, so the folders are cached for the lifetime of the request
+// evaluate is false when the access lists are not evaluated for the identity, reserved folders are rejected in any case
+type accessDfs struct {
+	control  *accessControl
+	identity string
+	evaluate bool
+
+	foldersCache map[string]*common.Folder
+}
+
+func newAccessDfs(control *accessControl, identity string, evaluate bool) Dfs {
+	return &accessDfs{
+		control:      control,
+		identity:     identity,
+		evaluate:     evaluate,
+		foldersCache: make(map[string]*common.Folder),
+	}
+}
+
+func (a *accessDfs) check(permission common.Permission, paths ...string) error {
+	for _, path := range paths {
+		if a.control.reservedPath(path) {
+			return errors.ErrForbidden
+		}
+	}
+
+	if !a.evaluate {
+		return nil
+	}
+	return a.control.check(a.identity, permission, a.foldersCache, paths...)
+}
+
+func (a *accessDfs) checkTree(permission common.Permission, paths ...string) error {
+	for _, path := range paths {
+		if a.control.reservedTree(path) {
+			return errors.ErrForbidden
+		}
+
+		if !a.evaluate {
+			continue
+		}
+		if err := a.control.checkTree(a.identity, permission, a.foldersCache, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *accessDfs) CreateFolder(folderPath string) error {
+	if err := a.check(common.PermissionWrite, folderPath); err != nil {
+		return err
+	}
+	return a.control.dfs.CreateFolder(folderPath)
+}
+
+func (a *accessDfs) CreateFile(path string, mime string, metadata common.Metadata, ttl *time.Duration, blockSize uint32, size uint64, overwrite bool, precondition *Precondition, contentReader io.Reader) error {
+	if err := a.check(common.PermissionWrite, path); err != nil {
+		return err
+	}
+	return a.control.dfs.CreateFile(path, mime, metadata, ttl, blockSize, size, overwrite, precondition, contentReader)
+}
+
+func (a *accessDfs) CreateStream(path string, mime string, metadata common.Metadata, ttl *time.Duration, blockSize uint32, overwrite bool, precondition *Precondition, contentReader io.Reader) error {
+	if err := a.check(common.PermissionWrite, path); err != nil {
+		return err
+	}
+	return a.control.dfs.CreateStream(path, mime, metadata, ttl, blockSize, overwrite, precondition, contentReader)
+}
+
+func (a *accessDfs) Extract(folderPath string, format ArchiveFormat, overwrite bool, contentReader io.Reader) (*common.ExtractionReport, error) {
+	if err := a.check(common.PermissionWrite, folderPath); err != nil {
+		return nil, err
+	}
+	// entries are created through the access evaluation to respect the access lists of the sub folders
+	return extract(a, folderPath, format, overwrite, contentReader)
+}
+
+func (a *accessDfs) CreateUpload(path string, mime string, metadata common.Metadata, ttl *time.Duration, blockSize uint32, size uint64, overwrite bool) (*common.UploadSession, error) {
+	if err := a.check(common.PermissionWrite, path); err != nil {
+		return nil, err
+	}
+	return a.control.dfs.CreateUpload(path, mime, metadata, ttl, blockSize, size, overwrite)
+}
+
+func (a *accessDfs) ReadUpload(sessionId string) (*common.UploadSession, error) {
+	session, err := a.control.dfs.ReadUpload(sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.check(common.PermissionWrite, session.Path); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (a *accessDfs) UploadPart(sessionId string, offset uint64, size uint64, contentReader io.Reader) error {
+	if _, err := a.ReadUpload(sessionId); err != nil {
+		return err
+	}
+	return a.control.dfs.UploadPart(sessionId, offset, size, contentReader)
+}
+
+func (a *accessDfs) CommitUpload(sessionId string, precondition *Precondition) error {
+	if _, err := a.ReadUpload(sessionId); err != nil {
+		return err
+	}
+	return a.control.dfs.CommitUpload(sessionId, precondition)
+}
+
+func (a *accessDfs) DiscardUpload(sessionId string) error {
+	if _, err := a.ReadUpload(sessionId); err != nil {
+		return err
+	}
+	return a.control.dfs.DiscardUpload(sessionId)
+}
+
+// Read passes the folder tree without the content of the sub folders that the identity is not allowed to read
+func (a *accessDfs) Read(paths []string, join bool) (ReadContainer, error) {
+	if err := a.check(common.PermissionRead, paths...); err != nil {
+		return nil, err
+	}
+
+	read, err := a.control.dfs.Read(paths, join)
+	if err != nil {
+		return nil, err
+	}
+	if read.Type() != RTFolder {
+		return read, nil
+	}
+	return newReadContainerForFolder(read.Folder(), a.tree), nil
+}
+
+// tree creates the folder tree by dropping the reserved sub folders and the sub folders that have their own access
+// lists which deny the read permission with their whole content. Dropped folders are kept in the tree as empty folders
+func (a *accessDfs) tree(folderPath string) (*common.Tree, error) {
+	folderPath = common.CorrectPath(folderPath)
+
+	folders, err := a.control.metadata.ChildrenTree(folderPath, true, false)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(folders, func(i, j int) bool {
+		return strings.Compare(folders[i].Full, folders[j].Full) < 0
+	})
+
+	denied := make([]string, 0)
+	allowed := make([]*common.Folder, 0)
+
+	for _, folder := range folders {
+		if strings.Compare(folder.Full, folderPath) != 0 {
+			if a.denied(denied, folder.Full) {
+				continue
+			}
+			if a.control.reservedPath(folder.Full) || a.evaluate && folder.Acl != nil && !folder.Acl.Allows(a.identity, common.PermissionRead) {
+				denied = append(denied, folder.Full)
+				continue
+			}
+		}
+		allowed = append(allowed, folder)
+	}
+
+	tree := common.NewTree()
+	if err := tree.Fill(&folderPath, allowed); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func (a *accessDfs) denied(deniedPaths []string, folderPath string) bool {
+	for _, deniedPath := range deniedPaths {
+		if strings.HasPrefix(folderPath, fmt.Sprintf("%s/", deniedPath)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *accessDfs) Size(folderPath string) (uint64, error) {
+	if err := a.check(common.PermissionRead, folderPath); err != nil {
+		return 0, err
+	}
+	return a.control.dfs.Size(folderPath)
+}
+
+func (a *accessDfs) Archive(folderPath string, format ArchiveFormat, filter *ArchiveFilter) (func(w io.Writer) error, error) {
+	if err := a.checkTree(common.PermissionRead, folderPath); err != nil {
+		return nil, err
+	}
+	return a.control.dfs.Archive(folderPath, format, filter)
+}
+
+// Search drops the entries that the identity is not allowed to read from the page
+func (a *accessDfs) Search(query *SearchQuery, continuation string, limit int) (*common.SearchResult, error) {
+	result, err := a.control.dfs.Search(query, continuation, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(common.SearchEntries, 0)
+	for _, entry := range result.Entries {
+		if err := a.check(common.PermissionRead, entry.Full); err != nil {
+			if err == errors.ErrForbidden {
+				continue
+			}
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	result.Entries = entries
+
+	return result, nil
+}
+
+// Change requires read permission on the source trees and write permission on the target. Moving
+// requires delete permission on the source trees as well
+func (a *accessDfs) Change(sources []string, target string, join bool, overwrite bool, move bool, precondition *Precondition) error {
+	if err := a.checkTree(common.PermissionRead, sources...); err != nil {
+		return err
+	}
+
+	if move {
+		if err := a.checkTree(common.PermissionDelete, sources...); err != nil {
+			return err
+		}
+	}
+
+	if err := a.check(common.PermissionWrite, target); err != nil {
+		return err
+	}
+	return a.control.dfs.Change(sources, target, join, overwrite, move, precondition)
+}
+
+func (a *accessDfs) UpdateMetadata(path string, metadata common.Metadata, replace bool, precondition *Precondition) error {
+	if err := a.check(common.PermissionWrite, path); err != nil {
+		return err
+	}
+	return a.control.dfs.UpdateMetadata(path, metadata, replace, precondition)
+}
+
+func (a *accessDfs) UpdateTimeToLive(path string, ttl time.Duration, precondition *Precondition) error {
+	if err := a.check(common.PermissionWrite, path); err != nil {
+		return err
+	}
+	return a.control.dfs.UpdateTimeToLive(path, ttl, precondition)
+}
+
+func (a *accessDfs) SetBlockSize(folderPath string, blockSize uint32) error {
+	if err := a.check(common.PermissionManage, folderPath); err != nil {
+		return err
+	}
+	return a.control.dfs.SetBlockSize(folderPath, blockSize)
+}
+
+func (a *accessDfs) Delete(path string, killZombies bool, precondition *Precondition) error {
+	if err := a.checkTree(common.PermissionDelete, path); err != nil {
+		return err
+	}
+	return a.control.dfs.Delete(path, killZombies, precondition)
+}
+
+func (a *accessDfs) Lock(lock *common.ResourceLock) error {
+	if err := a.check(common.PermissionWrite, lock.Root); err != nil {
+		return err
+	}
+	return a.control.dfs.Lock(lock)
+}
+
+func (a *accessDfs) RefreshLock(path string, tokens []string, timeout time.Duration) (*common.ResourceLock, error) {
+	if err := a.check(common.PermissionWrite, path); err != nil {
+		return nil, err
+	}
+	return a.control.dfs.RefreshLock(path, tokens, timeout)
+}
+
+func (a *accessDfs) Unlock(path string, token string) error {
+	if err := a.check(common.PermissionWrite, path); err != nil {
+		return err
+	}
+	return a.control.dfs.Unlock(path, token)
+}
+
+func (a *accessDfs) Locks(path string) (common.ResourceLocks, error) {
+	if err := a.check(common.PermissionRead, path); err != nil {
+		return nil, err
+	}
+	return a.control.dfs.Locks(path)
+}
+
+func (a *accessDfs) Quota(folderPath string) (*common.Quota, error) {
+	if err := a.check(common.PermissionRead, folderPath); err != nil {
+		return nil, err
+	}
+	return a.control.dfs.Quota(folderPath)
+}
+
+func (a *accessDfs) SetQuota(folderPath string, quota *common.Quota) error {
+	if err := a.check(common.PermissionManage, folderPath); err != nil {
+		return err
+	}
+	return a.control.dfs.SetQuota(folderPath, quota)
+}
+
+func (a *accessDfs) SetVersioning(folderPath string, enabled bool) error {
+	if err := a.check(common.PermissionManage, folderPath); err != nil {
+		return err
+	}
+	return a.control.dfs.SetVersioning(folderPath, enabled)
+}
+
+func (a *accessDfs) Versions(path string) (common.FileVersions, error) {
+	if err := a.check(common.PermissionRead, path); err != nil {
+		return nil, err
+	}
+	return a.control.dfs.Versions(path)
+}
+
+func (a *accessDfs) ReadVersion(path string, versionId string) (ReadContainer, error) {
+	if err := a.check(common.PermissionRead, path); err != nil {
+		return nil, err
+	}
+	return a.control.dfs.ReadVersion(path, versionId)
+}
+
+func (a *accessDfs) RestoreVersion(path string, versionId string, precondition *Precondition) error {
+	if err := a.check(common.PermissionWrite, path); err != nil {
+		return err
+	}
+	return a.control.dfs.RestoreVersion(path, versionId, precondition)
+}
+
+func (a *accessDfs) PurgeVersions(path string, keep *int, before *time.Time) error {
+	if err := a.check(common.PermissionDelete, path); err != nil {
+		return err
+	}
+	return a.control.dfs.PurgeVersions(path, keep, before)
+}
+
+// ListTrash drops the entries that the identity is not allowed to read from the list
+func (a *accessDfs) ListTrash() (common.TrashEntries, error) {
+	entries, err := a.control.dfs.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(common.TrashEntries, 0)
+	for _, entry := range entries {
+		if err := a.check(common.PermissionRead, entry.Path); err != nil {
+			if err == errors.ErrForbidden {
+				continue
+			}
+			return nil, err
+		}
+		allowed = append(allowed, entry)
+	}
+
+	return allowed, nil
+}
+
+func (a *accessDfs) RestoreTrash(entryId string) error {
+	entry, err := a.control.trash.Get(entryId)
+	if err != nil {
+		return err
+	}
+
+	if err := a.check(common.PermissionWrite, entry.Path); err != nil {
+		return err
+	}
+	return a.control.dfs.RestoreTrash(entryId)
+}
+
+func (a *accessDfs) PurgeTrash(entryId string) error {
+	entry, err := a.control.trash.Get(entryId)
+	if err != nil {
+		return err
+	}
+
+	if err := a.check(common.PermissionDelete, entry.Path); err != nil {
+		return err
+	}
+	return a.control.dfs.PurgeTrash(entryId)
+}
+
+// EmptyTrash requires delete permission on the paths of all the trash entries. The entries of the reserved
+// folders are emptied with the others
+func (a *accessDfs) EmptyTrash() error {
+	entries, err := a.control.dfs.ListTrash()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if a.control.reservedPath(entry.Path) {
+			continue
+		}
+		if err := a.check(common.PermissionDelete, entry.Path); err != nil {
+			return err
+		}
+	}
+	return a.control.dfs.EmptyTrash()
+}
+
+func (a *accessDfs) ExecuteActions(aI *hooks.ActionInfo, actions []hooks.Action) {
+	a.control.dfs.ExecuteActions(aI, actions)
+}
+
+var _ Dfs = &accessDfs{}
@@ -0,0 +1,42 @@
+package manager
+
+import (
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/hooks"
+)
+
+// accessHook evaluates the access lists for the identity before passing the operations to the hook
+type accessHook struct {
+	control  *accessControl
+	identity string
+
+	foldersCache map[string]*common.Folder
+}
+
+func newAccessHook(control *accessControl, identity string) Hook {
+	return &accessHook{
+		control:      control,
+		identity:     identity,
+		foldersCache: make(map[string]*common.Folder),
+	}
+}
+
+func (a *accessHook) GetAvailableList() []interface{} {
+	return a.control.hook.GetAvailableList()
+}
+
+func (a *accessHook) Add(folderPaths []string, hook *hooks.Hook) error {
+	if err := a.control.check(a.identity, common.PermissionManage, a.foldersCache, folderPaths...); err != nil {
+		return err
+	}
+	return a.control.hook.Add(folderPaths, hook)
+}
+
+func (a *accessHook) Delete(folderPath string, hookIds []string) error {
+	if err := a.control.check(a.identity, common.PermissionManage, a.foldersCache, folderPath); err != nil {
+		return err
+	}
+	return a.control.hook.Delete(folderPath, hookIds)
+}
+
+var _ Hook = &accessHook{}
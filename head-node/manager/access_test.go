@@ -0,0 +1,22 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessControl_Reserve(t *testing.T) {
+	a := &accessControl{}
+	a.Reserve("/.s3")
+
+	assert.True(t, a.reservedPath("/.s3"))
+	assert.True(t, a.reservedPath("/.s3/objects/a1b2"))
+	assert.False(t, a.reservedPath("/.s3objects"))
+	assert.False(t, a.reservedPath("/"))
+
+	assert.True(t, a.reservedTree("/"))
+	assert.True(t, a.reservedTree("/.s3/multipart"))
+	assert.False(t, a.reservedTree("/bucket"))
+	assert.False(t, a.reservedTree("/.s"))
+}
@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"math/bits"
+	"os"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+// gearTable holds the random values of the bytes for the rolling hash. It is generated with a fixed seed
+// because the boundaries have to be the same on all the head nodes to deduplicate the chunks
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+
+	seed := uint64(0x6b657274697368)
+	for i := range table {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+
+	return table
+}()
+
+// Chunker cuts the content at the content defined boundaries (FastCDC) instead of the fixed block size.
+// Boundaries follow the content, so the inserted or the removed bytes change only the chunks around them
+type Chunker struct {
+	min uint32
+	avg uint32
+	max uint32
+
+	maskS uint64
+	maskL uint64
+}
+
+// NewChunker creates the content defined chunker with the min, the average and the max chunk sizes.
+// The max size can not exceed the max block size
+func NewChunker(min uint32, avg uint32, max uint32) (*Chunker, error) {
+	if min < 64 || min >= avg || avg >= max || max > common.MaxBlockSize {
+		return nil, os.ErrInvalid
+	}
+
+	avgBits := bits.Len32(avg) - 1
+
+	return &Chunker{
+		min:   min,
+		avg:   avg,
+		max:   max,
+		maskS: boundaryMask(avgBits + 1),
+		maskL: boundaryMask(avgBits - 1),
+	}, nil
+}
+
+// boundaryMask uses the high bits of the rolling hash because they are affected by the last 64 bytes
+func boundaryMask(maskBits int) uint64 {
+	return ((uint64(1) << maskBits) - 1) << (64 - maskBits)
+}
+
+// Cut splits the data into the chunk sizes. The tail that is shorter than the max size is left to be cut
+// together with the following content unless the content is ended
+func (c *Chunker) Cut(data []byte, ended bool) []uint32 {
+	sizes := make([]uint32, 0)
+
+	for len(data) > 0 {
+		if !ended && uint32(len(data)) < c.max {
+			break
+		}
+
+		size := c.boundary(data)
+		sizes = append(sizes, size)
+		data = data[size:]
+	}
+
+	return sizes
+}
+
+// boundary finds the size of the next chunk. The harder mask is used before the average size and the easier
+// one after it, so the chunk sizes are normalized around the average
+func (c *Chunker) boundary(data []byte) uint32 {
+	size := uint32(len(data))
+	if size <= c.min {
+		return size
+	}
+	if size > c.max {
+		size = c.max
+	}
+
+	normal := c.avg
+	if size < normal {
+		normal = size
+	}
+
+	fingerprint := uint64(0)
+	i := c.min
+	for ; i < normal; i++ {
+		fingerprint = (fingerprint << 1) + gearTable[data[i]]
+		if fingerprint&c.maskS == 0 {
+			return i
+		}
+	}
+	for ; i < size; i++ {
+		fingerprint = (fingerprint << 1) + gearTable[data[i]]
+		if fingerprint&c.maskL == 0 {
+			return i
+		}
+	}
+
+	return size
+}
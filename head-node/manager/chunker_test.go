@@ -0,0 +1,136 @@
+package manager
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func createChunkerContent(size int) []byte {
+	content := make([]byte, size)
+	_, _ = rand.New(rand.NewSource(42)).Read(content)
+	return content
+}
+
+func chunkHashes(content []byte, sizes []uint32) []string {
+	hashes := make([]string, 0)
+	for _, size := range sizes {
+		sum := sha512.Sum512_256(content[:size])
+		hashes = append(hashes, hex.EncodeToString(sum[:]))
+		content = content[size:]
+	}
+	return hashes
+}
+
+func commonChunks(a []string, b []string) int {
+	set := make(map[string]bool)
+	for _, h := range a {
+		set[h] = true
+	}
+
+	count := 0
+	for _, h := range b {
+		if set[h] {
+			count++
+		}
+	}
+	return count
+}
+
+func TestNewChunker(t *testing.T) {
+	_, err := NewChunker(256, 1024, 4096)
+	assert.Nil(t, err)
+
+	_, err = NewChunker(32, 1024, 4096)
+	assert.NotNil(t, err)
+
+	_, err = NewChunker(1024, 1024, 4096)
+	assert.NotNil(t, err)
+
+	_, err = NewChunker(256, 4096, 4096)
+	assert.NotNil(t, err)
+
+	_, err = NewChunker(256, 1024, common.MaxBlockSize+1)
+	assert.NotNil(t, err)
+}
+
+func TestChunker_CutSizes(t *testing.T) {
+	chunker, err := NewChunker(256, 1024, 4096)
+	assert.Nil(t, err)
+
+	content := createChunkerContent(512 * 1024)
+	sizes := chunker.Cut(content, true)
+
+	total := uint64(0)
+	for i, size := range sizes {
+		total += uint64(size)
+
+		assert.LessOrEqual(t, size, uint32(4096))
+		if i < len(sizes)-1 {
+			assert.GreaterOrEqual(t, size, uint32(256))
+		}
+	}
+	assert.Equal(t, uint64(len(content)), total)
+
+	// chunk sizes should be normalized around the average, not cut at the max
+	assert.Greater(t, len(sizes), len(content)/4096)
+	assert.Less(t, len(sizes), len(content)/256)
+}
+
+func TestChunker_CutRepeatedContent(t *testing.T) {
+	chunker, err := NewChunker(256, 1024, 4096)
+	assert.Nil(t, err)
+
+	// repeated bytes do not have any boundary, so the chunks are cut at the max
+	content := make([]byte, 10000)
+	assert.Equal(t, []uint32{4096, 4096, 1808}, chunker.Cut(content, true))
+}
+
+func TestChunker_CutTail(t *testing.T) {
+	chunker, err := NewChunker(256, 1024, 4096)
+	assert.Nil(t, err)
+
+	content := createChunkerContent(64 * 1024)
+
+	sizes := chunker.Cut(content, false)
+	cut := uint32(0)
+	for _, size := range sizes {
+		cut += size
+	}
+	assert.Less(t, uint32(len(content))-cut, uint32(4096))
+
+	// the tail is cut at the same boundaries when the following content arrives
+	assert.Equal(t, sizes, chunker.Cut(content, true)[:len(sizes)])
+
+	assert.Empty(t, chunker.Cut(content[:4000], false))
+	assert.Equal(t, []uint32{100}, chunker.Cut(content[:100], true))
+	assert.Empty(t, chunker.Cut(nil, true))
+}
+
+func TestChunker_BoundariesAfterInsert(t *testing.T) {
+	chunker, err := NewChunker(256, 1024, 4096)
+	assert.Nil(t, err)
+
+	content := createChunkerContent(256 * 1024)
+	hashes := chunkHashes(content, chunker.Cut(content, true))
+
+	offset := len(content) / 2
+	inserted := make([]byte, 0, len(content)+100)
+	inserted = append(inserted, content[:offset]...)
+	inserted = append(inserted, createChunkerContent(100)...)
+	inserted = append(inserted, content[offset:]...)
+	insertedHashes := chunkHashes(inserted, chunker.Cut(inserted, true))
+
+	// only the chunks around the insert are changed
+	assert.GreaterOrEqual(t, commonChunks(hashes, insertedHashes), len(hashes)-3)
+
+	shifted := append(createChunkerContent(7), content...)
+	shiftedHashes := chunkHashes(shifted, chunker.Cut(shifted, true))
+
+	// only the first chunks are changed when the whole content is shifted
+	assert.GreaterOrEqual(t, commonChunks(hashes, shiftedHashes), len(hashes)-3)
+}
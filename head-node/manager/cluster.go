@@ -1,6 +1,9 @@
 package manager
 
 import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
 	errors2 "errors"
 	"fmt"
@@ -13,8 +16,10 @@ import (
 	"sync"
 	"syscall"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
 	"github.com/freakmaxi/kertish-dfs/basics/common"
 	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 	cluster2 "github.com/freakmaxi/kertish-dfs/head-node/cluster"
 	"go.uber.org/zap"
 )
@@ -22,29 +27,42 @@ import (
 const managerEndPoint = "/client/manager"
 
 type Cluster interface {
-	Create(size uint64, reader io.Reader) (*common.CreationResult, error)
+	Create(size uint64, blockSize uint32, reader io.Reader) (*common.CreationResult, error)
+	CreateStream(reader io.Reader, blockSize uint32) (*common.CreationResult, uint64, error)
 	CreateShadow(chunks common.DataChunks) error
 	Read(chunks common.DataChunks) (func(w io.Writer, begins int64, ends int64) error, error)
 	Delete(chunks common.DataChunks) (*common.DeletionResult, error)
+
+	Reserve(size uint64, blockSize uint32) (*common.ReservationMap, error)
+	CreateChunk(reservationId string, clusterMap common.ClusterMap, reader io.Reader) (*common.DataChunk, map[string]uint64, error)
+	Commit(reservationId string, clusterUsageMap map[string]uint64) error
+	Discard(reservationId string) error
 }
 
 type cluster struct {
 	client      http.Client
 	managerAddr []string
+	chunker     *Chunker
+	blockSize   uint32
 	logger      *zap.Logger
 
 	nodeCacheMutex sync.Mutex
 	nodeCache      map[string]cluster2.DataNode
 }
 
-func NewCluster(managerAddresses []string, logger *zap.Logger) (Cluster, error) {
+// NewCluster creates the cluster interface for the file chunk operations. Content is cut at the content defined
+// boundaries when the chunker is set, otherwise in the block size. blockSize is the farm default that is used
+// when the block size is not requested for the content
+func NewCluster(managerAddresses []string, managerCredentials *auth.Credentials, chunker *Chunker, blockSize uint32, logger *zap.Logger) (Cluster, error) {
 	if len(managerAddresses) == 0 {
 		return nil, os.ErrInvalid
 	}
 
 	return &cluster{
-		client:         http.Client{},
+		client:         http.Client{Transport: auth.NewTransport(managerCredentials, secure.HttpTransport())},
 		managerAddr:    managerAddresses,
+		chunker:        chunker,
+		blockSize:      blockSize,
 		logger:         logger,
 		nodeCacheMutex: sync.Mutex{},
 		nodeCache:      make(map[string]cluster2.DataNode),
@@ -68,8 +86,19 @@ func (c *cluster) getDataNode(address string) (cluster2.DataNode, error) {
 	return dn, nil
 }
 
-func (c *cluster) Create(size uint64, reader io.Reader) (*common.CreationResult, error) {
-	reservation, err := c.makeReservation(size)
+// Create creates the file chunks from the content that has known length. Content is cut at the content defined
+// boundaries when blockSize is zero and the chunker is set, otherwise in the block size. Default block size is used
+// when blockSize is zero
+func (c *cluster) Create(size uint64, blockSize uint32, reader io.Reader) (*common.CreationResult, error) {
+	chunker := c.chunkerFor(blockSize)
+	blockSize = c.effectiveBlockSize(blockSize)
+
+	if chunker != nil {
+		creationResult, _, err := c.createStream(io.LimitReader(reader, int64(size)), chunker, blockSize, int64(size))
+		return creationResult, err
+	}
+
+	reservation, err := c.makeReservation(size, blockSize)
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +127,155 @@ func (c *cluster) Create(size uint64, reader io.Reader) (*common.CreationResult,
 	return creationResult, nil
 }
 
+// CreateStream creates the file chunks from the stream that has unknown length. Stream is cut into
+// blocks and the reservation is made block by block as the content arrives
+func (c *cluster) CreateStream(reader io.Reader, blockSize uint32) (*common.CreationResult, uint64, error) {
+	return c.createStream(reader, c.chunkerFor(blockSize), c.effectiveBlockSize(blockSize), -1)
+}
+
+// createStream cuts the content in the fixed block size or at the content defined boundaries when the chunker
+// is not nil. Content is buffered in the block size, so the tail of the buffer is carried to the next round to
+// find its boundary. expectedSize is validated when it is not negative
+func (c *cluster) createStream(reader io.Reader, chunker *Chunker, blockSize uint32, expectedSize int64) (*common.CreationResult, uint64, error) {
+	sha512Hash := sha512.New512_256()
+
+	reservations := make(map[string]map[string]uint64)
+	chunks := make(common.DataChunks, 0)
+	size := uint64(0)
+
+	revert := func() {
+		if len(chunks) > 0 {
+			if _, err := c.Delete(chunks); err != nil {
+				c.logger.Error(
+					"Reverting stream chunks is failed. Orphan chunks may appear in data node! Run repair to eliminate",
+					zap.Error(err),
+				)
+			}
+		}
+
+		for reservationId := range reservations {
+			if err := c.discardReservation(reservationId); err != nil {
+				c.logger.Error(
+					"Discarding reservationMap is failed",
+					zap.String("reservationId", reservationId),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
+	// buffer should hold the biggest content defined chunk
+	bufferSize := blockSize
+	if chunker != nil && bufferSize < chunker.max {
+		bufferSize = chunker.max
+	}
+
+	buffer := make([]byte, bufferSize)
+	filled := 0
+	for sequence := uint16(0); ; {
+		n, err := io.ReadFull(reader, buffer[filled:])
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			revert()
+			return nil, 0, err
+		}
+		ended := err != nil
+		filled += n
+
+		if filled == 0 {
+			break
+		}
+
+		chunkSizes := c.cut(chunker, buffer[:filled], ended)
+
+		reservation, err := c.reserve(chunker, chunkSizes, blockSize)
+		if err != nil {
+			revert()
+			return nil, 0, err
+		}
+		reservations[reservation.Id] = make(map[string]uint64)
+
+		if len(reservation.Clusters) != len(chunkSizes) {
+			revert()
+			return nil, 0, fmt.Errorf("stream block reservation is not fitting to the chunks")
+		}
+
+		cutSize := 0
+		for i := range reservation.Clusters {
+			reservation.Clusters[i].Chunk.Sequence += sequence
+			reservation.Clusters[i].Chunk.Index += size
+			cutSize += int(reservation.Clusters[i].Chunk.Size)
+		}
+
+		create := NewCreate(reservation, c.getDataNode, c.findCluster, c.logger)
+		creationResult, clusterUsageMap, err := create.process(bytes.NewReader(buffer[:cutSize]))
+		if err != nil {
+			revert()
+			return nil, 0, err
+		}
+		reservations[reservation.Id] = clusterUsageMap
+
+		_, _ = sha512Hash.Write(buffer[:cutSize])
+		chunks = append(chunks, creationResult.Chunks...)
+		size += uint64(cutSize)
+		sequence += uint16(len(chunkSizes))
+
+		filled = copy(buffer, buffer[cutSize:filled])
+
+		if ended && filled == 0 {
+			break
+		}
+	}
+
+	if expectedSize > -1 && size != uint64(expectedSize) {
+		revert()
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+
+	for reservationId, clusterUsageMap := range reservations {
+		if err := c.commitReservation(reservationId, clusterUsageMap); err != nil {
+			c.logger.Error(
+				"Committing reservationMap is failed",
+				zap.String("reservationId", reservationId),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return common.NewCreationResult(hex.EncodeToString(sha512Hash.Sum(nil)), chunks), size, nil
+}
+
+// cut returns the chunk sizes of the buffered content. The whole buffer is a single chunk in the fixed size
+// chunking because the buffer is in the block size
+func (c *cluster) cut(chunker *Chunker, data []byte, ended bool) []uint32 {
+	if chunker == nil {
+		return []uint32{uint32(len(data))}
+	}
+	return chunker.Cut(data, ended)
+}
+
+func (c *cluster) reserve(chunker *Chunker, chunkSizes []uint32, blockSize uint32) (*common.ReservationMap, error) {
+	if chunker == nil {
+		return c.makeReservation(uint64(chunkSizes[0]), blockSize)
+	}
+	return c.makeChunkReservation(chunkSizes)
+}
+
+// chunkerFor returns the chunker when the content should be cut at the content defined boundaries. The requested
+// block size (per upload or per folder) takes precedence over the content defined chunking
+func (c *cluster) chunkerFor(blockSize uint32) *Chunker {
+	if blockSize > 0 {
+		return nil
+	}
+	return c.chunker
+}
+
+func (c *cluster) effectiveBlockSize(blockSize uint32) uint32 {
+	if blockSize == 0 {
+		return c.blockSize
+	}
+	return blockSize
+}
+
 func (c *cluster) CreateShadow(chunks common.DataChunks) error {
 	m, err := c.createClusterMap(chunks, common.MTCreate)
 	if err != nil {
@@ -108,8 +286,15 @@ func (c *cluster) CreateShadow(chunks common.DataChunks) error {
 	}
 
 	for _, chunk := range chunks {
-		if address, has := m[chunk.Hash]; has {
-			dn, err := c.getDataNode(address[0])
+		if placement, has := m[chunk.Hash]; has {
+			if placement.ErasureCoded() {
+				if err := createShadowShards(c.getDataNode, placement, chunk.Hash); err != nil {
+					return err
+				}
+				continue
+			}
+
+			dn, err := c.getDataNode(placement.Addresses[0])
 			if err != nil {
 				return err
 			}
@@ -162,13 +347,24 @@ func (c *cluster) Read(chunks common.DataChunks) (func(w io.Writer, begins int64
 				}
 			}
 
-			addresses, has := m[chunk.Hash]
+			placement, has := m[chunk.Hash]
 			if !has {
 				return errors.ErrRepair
 			}
 
+			if placement.ErasureCoded() {
+				buffer, err := readShards(c.getDataNode, placement, chunk.Hash, chunk.Size)
+				if err != nil {
+					return err
+				}
+				if _, err := w.Write(buffer[startPoint:endPoint]); err != nil && !errors2.Is(err, syscall.EPIPE) {
+					return err
+				}
+				continue
+			}
+
 			bulkErrors := errors.NewBulkError()
-			for _, address := range addresses {
+			for _, address := range placement.Addresses {
 				dn, err := c.getDataNode(address)
 				if err != nil {
 					bulkErrors.Add(err)
@@ -229,13 +425,23 @@ func (c *cluster) Delete(chunks common.DataChunks) (*common.DeletionResult, erro
 	deletionResult := common.NewDeletionResult()
 
 	for _, chunk := range chunks {
-		address, has := m[chunk.Hash]
+		placement, has := m[chunk.Hash]
 		if !has {
 			deletionResult.Missing = append(deletionResult.Missing, chunk.Hash)
 			continue
 		}
 
-		dn, err := c.getDataNode(address[0])
+		if placement.ErasureCoded() {
+			if err := deleteShards(c.getDataNode, placement, chunk.Hash); err != nil {
+				deletionResult.Untouched = append(deletionResult.Untouched, chunk.Hash)
+				continue
+			}
+
+			deletionResult.Deleted = append(deletionResult.Deleted, chunk.Hash)
+			continue
+		}
+
+		dn, err := c.getDataNode(placement.Addresses[0])
 		if err != nil {
 			deletionResult.Untouched = append(deletionResult.Untouched, chunk.Hash)
 			continue
@@ -252,13 +458,45 @@ func (c *cluster) Delete(chunks common.DataChunks) (*common.DeletionResult, erro
 	return &deletionResult, nil
 }
 
-func (c *cluster) makeReservation(size uint64) (*common.ReservationMap, error) {
+func (c *cluster) Reserve(size uint64, blockSize uint32) (*common.ReservationMap, error) {
+	if c.chunkerFor(blockSize) != nil {
+		return nil, errors.ErrChunking
+	}
+	return c.makeReservation(size, c.effectiveBlockSize(blockSize))
+}
+
+// CreateChunk creates the single file chunk on the reserved cluster without closing the reservation
+func (c *cluster) CreateChunk(reservationId string, clusterMap common.ClusterMap, reader io.Reader) (*common.DataChunk, map[string]uint64, error) {
+	reservation := &common.ReservationMap{
+		Id:       reservationId,
+		Clusters: []common.ClusterMap{clusterMap},
+	}
+
+	create := NewCreate(reservation, c.getDataNode, c.findCluster, c.logger)
+	creationResult, clusterUsageMap, err := create.process(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return creationResult.Chunks[0], clusterUsageMap, nil
+}
+
+func (c *cluster) Commit(reservationId string, clusterUsageMap map[string]uint64) error {
+	return c.commitReservation(reservationId, clusterUsageMap)
+}
+
+func (c *cluster) Discard(reservationId string) error {
+	return c.discardReservation(reservationId)
+}
+
+func (c *cluster) makeReservation(size uint64, blockSize uint32) (*common.ReservationMap, error) {
 	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s", c.managerAddr[0], managerEndPoint), nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("X-Action", "reserve")
 	req.Header.Set("X-Size", strconv.FormatUint(size, 10))
+	req.Header.Set("X-Block-Size", strconv.FormatUint(uint64(blockSize), 10))
 
 	res, err := c.client.Do(req)
 	if err != nil {
@@ -284,6 +522,44 @@ func (c *cluster) makeReservation(size uint64) (*common.ReservationMap, error) {
 	return &reservationMap, nil
 }
 
+func (c *cluster) makeChunkReservation(chunkSizes []uint32) (*common.ReservationMap, error) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s", c.managerAddr[0], managerEndPoint), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkSizesString := make([]string, 0)
+	for _, chunkSize := range chunkSizes {
+		chunkSizesString = append(chunkSizesString, strconv.FormatUint(uint64(chunkSize), 10))
+	}
+
+	req.Header.Set("X-Action", "reserve")
+	req.Header.Set("X-Chunk-Sizes", strings.Join(chunkSizesString, ","))
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != 200 {
+		if res.StatusCode == 503 {
+			return nil, errors.ErrNoAvailableClusterNode
+		}
+		if res.StatusCode == 507 {
+			return nil, errors.ErrNoSpace
+		}
+		return nil, fmt.Errorf("cluster manager request is failed (makeChunkReservation): %d - %s", res.StatusCode, common.NewErrorFromReader(res.Body).Message)
+	}
+
+	var reservationMap common.ReservationMap
+	if err := json.NewDecoder(res.Body).Decode(&reservationMap); err != nil {
+		return nil, err
+	}
+
+	return &reservationMap, nil
+}
+
 func (c *cluster) discardReservation(reservationId string) error {
 	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s%s", c.managerAddr[0], managerEndPoint), nil)
 	if err != nil {
@@ -332,10 +608,10 @@ func (c *cluster) commitReservation(reservationId string, clusterUsageMap map[st
 	return nil
 }
 
-func (c *cluster) findCluster(sha512Hex string) (string, string, error) {
+func (c *cluster) findCluster(sha512Hex string) (*common.Placement, error) {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s", c.managerAddr[0], managerEndPoint), nil)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 	req.Header.Set("X-Action", "find")
 	req.Header.Set("X-Options", sha512Hex)
@@ -346,18 +622,32 @@ func (c *cluster) findCluster(sha512Hex string) (string, string, error) {
 			"cluster manager request is failed (findCluster)",
 			zap.Error(err),
 		)
-		return "", "", errors.ErrRemote
+		return nil, errors.ErrRemote
 	}
 	defer func() { _ = res.Body.Close() }()
 
 	if res.StatusCode == 200 {
-		return res.Header.Get("X-Cluster-Id"), res.Header.Get("X-Address"), nil
+		placement := &common.Placement{
+			ClusterId: res.Header.Get("X-Cluster-Id"),
+			Addresses: []string{res.Header.Get("X-Address")},
+		}
+
+		erasureCoding := res.Header.Get("X-Erasure-Coding")
+		if len(erasureCoding) > 0 {
+			placement.Erasure, err = common.ParseErasureCoding(erasureCoding)
+			if err != nil {
+				return nil, errors.ErrRemote
+			}
+			placement.Addresses = strings.Split(res.Header.Get("X-Addresses"), ",")
+		}
+
+		return placement, nil
 	}
 
 	if res.StatusCode == 404 {
-		return "", "", errors.ErrNotFound
+		return nil, errors.ErrNotFound
 	} else if res.StatusCode == 503 {
-		return "", "", errors.ErrNoAvailableClusterNode
+		return nil, errors.ErrNoAvailableClusterNode
 	}
 
 	c.logger.Error(
@@ -368,10 +658,10 @@ func (c *cluster) findCluster(sha512Hex string) (string, string, error) {
 		),
 	)
 
-	return "", "", errors.ErrRemote
+	return nil, errors.ErrRemote
 }
 
-func (c *cluster) createClusterMap(chunks common.DataChunks, mapType common.MapType) (map[string][]string, error) {
+func (c *cluster) createClusterMap(chunks common.DataChunks, mapType common.MapType) (map[string]*common.Placement, error) {
 	sha512HexList := make([]string, 0)
 	for _, chunk := range chunks {
 		sha512HexList = append(sha512HexList, chunk.Hash)
@@ -385,7 +675,7 @@ func (c *cluster) createClusterMap(chunks common.DataChunks, mapType common.MapT
 	return m, nil
 }
 
-func (c *cluster) requestClusterMap(sha512HexList []string, mapType common.MapType) (map[string][]string, error) {
+func (c *cluster) requestClusterMap(sha512HexList []string, mapType common.MapType) (map[string]*common.Placement, error) {
 	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s", c.managerAddr[0], managerEndPoint), nil)
 	if err != nil {
 		return nil, err
@@ -418,7 +708,7 @@ func (c *cluster) requestClusterMap(sha512HexList []string, mapType common.MapTy
 		return nil, fmt.Errorf("cluster manager request is failed (requestClusterMap): %d - %s", res.StatusCode, common.NewErrorFromReader(res.Body).Message)
 	}
 
-	var clusterMapping map[string][]string
+	var clusterMapping map[string]*common.Placement
 	if err := json.NewDecoder(res.Body).Decode(&clusterMapping); err != nil {
 		return nil, err
 	}
@@ -16,7 +16,7 @@ import (
 type create struct {
 	reservationMap          *common.ReservationMap
 	dataNodeProviderHandler func(address string) (cluster2.DataNode, error)
-	findClusterHandler      func(sha512Hex string) (string, string, error)
+	findClusterHandler      func(sha512Hex string) (*common.Placement, error)
 	logger                  *zap.Logger
 
 	clusterUsageMutex sync.Mutex
@@ -29,7 +29,7 @@ type create struct {
 func NewCreate(
 	reservationMap *common.ReservationMap,
 	dataNodeProviderHandler func(address string) (cluster2.DataNode, error),
-	findClusterHandler func(sha512Hex string) (string, string, error),
+	findClusterHandler func(sha512Hex string) (*common.Placement, error),
 	logger *zap.Logger,
 ) *create {
 	return &create{
@@ -133,7 +133,7 @@ func (c *create) upload(wg *sync.WaitGroup, clusterMap common.ClusterMap, data [
 	defer wg.Done()
 
 	sha512Hex := c.calculateHash(data)
-	clusterId, address, err := c.findClusterHandler(sha512Hex)
+	placement, err := c.findClusterHandler(sha512Hex)
 	if err != nil {
 		if err == errors.ErrRemote {
 			errorChan <- errors.NewUploadError(
@@ -158,10 +158,37 @@ func (c *create) upload(wg *sync.WaitGroup, clusterMap common.ClusterMap, data [
 		}
 
 		// Does not find any entry
-		clusterId = clusterMap.Id
-		address = clusterMap.Address
+		placement = &common.Placement{
+			ClusterId: clusterMap.Id,
+			Erasure:   clusterMap.Erasure,
+			Addresses: []string{clusterMap.Address},
+		}
+		if clusterMap.Erasure != nil {
+			placement.Addresses = clusterMap.Addresses
+		}
+	}
+	clusterId := placement.ClusterId
+
+	if placement.ErasureCoded() {
+		exists, err := createShards(c.dataNodeProviderHandler, placement, sha512Hex, data)
+		if err != nil {
+			errorChan <- errors.NewUploadError(
+				fmt.Sprintf(
+					"unable to create chunk shards, failure on data node, clusterId: %s, sha512Hex: %s, error: %s",
+					clusterId,
+					sha512Hex,
+					err,
+				),
+			)
+			return
+		}
+
+		c.complete(clusterMap, clusterId, sha512Hex, data, exists, successChan)
+		return
 	}
 
+	address := placement.Addresses[0]
+
 	dn, err := c.dataNodeProviderHandler(address)
 	if err != nil {
 		errorChan <- errors.NewUploadError(
@@ -202,6 +229,10 @@ func (c *create) upload(wg *sync.WaitGroup, clusterMap common.ClusterMap, data [
 		return
 	}
 
+	c.complete(clusterMap, clusterId, sha512Hex, data, exists, successChan)
+}
+
+func (c *create) complete(clusterMap common.ClusterMap, clusterId string, sha512Hex string, data []byte, exists bool, successChan chan *common.DataChunk) {
 	clusterUsage := uint32(len(data))
 	if exists {
 		clusterUsage = 0
@@ -225,7 +256,7 @@ func (c *create) revert(errorChan chan error) bool {
 	reverted := true
 
 	for _, dataChunk := range c.chunks {
-		clusterId, address, err := c.findClusterHandler(dataChunk.Hash)
+		placement, err := c.findClusterHandler(dataChunk.Hash)
 		if err != nil {
 			reverted = false
 			errorChan <- fmt.Errorf(
@@ -235,6 +266,22 @@ func (c *create) revert(errorChan chan error) bool {
 			)
 			continue
 		}
+		clusterId := placement.ClusterId
+
+		if placement.ErasureCoded() {
+			if err := deleteShards(c.dataNodeProviderHandler, placement, dataChunk.Hash); err != nil {
+				reverted = false
+				errorChan <- fmt.Errorf(
+					"unable to delete chunk shards, failure on data node, clusterId: %s, sha512Hex: %s, error: %s",
+					clusterId,
+					dataChunk.Hash,
+					err,
+				)
+			}
+			continue
+		}
+
+		address := placement.Addresses[0]
 
 		dn, err := c.dataNodeProviderHandler(address)
 		if err != nil {
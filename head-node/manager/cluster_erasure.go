@@ -0,0 +1,273 @@
+package manager
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/erasure"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	cluster2 "github.com/freakmaxi/kertish-dfs/head-node/cluster"
+)
+
+// createShards encodes the chunk and creates the shards on the nodes of the placement concurrently.
+// Chunk is accepted as existing when any of the nodes already has its shard
+func createShards(
+	dataNodeProviderHandler func(address string) (cluster2.DataNode, error),
+	placement *common.Placement,
+	sha512Hex string,
+	data []byte,
+) (bool, error) {
+	shards, err := erasure.Encode(placement.Erasure, data)
+	if err != nil {
+		return false, err
+	}
+	if len(shards) != len(placement.Addresses) {
+		return false, errors.ErrNoAvailableActionNode
+	}
+
+	existsMutex := sync.Mutex{}
+	exists := false
+
+	bulkErrors := errors.NewBulkError()
+
+	wg := &sync.WaitGroup{}
+	for shard, address := range placement.Addresses {
+		if len(address) == 0 {
+			bulkErrors.Add(fmt.Errorf("shard %d does not have a node", shard))
+			continue
+		}
+
+		wg.Add(1)
+		go func(wg *sync.WaitGroup, shard int, address string) {
+			defer wg.Done()
+
+			dn, err := dataNodeProviderHandler(address)
+			if err != nil {
+				bulkErrors.Add(err)
+				return
+			}
+
+			shardExists, err := dn.CreateShard(sha512Hex, shards[shard])
+			if err != nil {
+				bulkErrors.Add(fmt.Errorf("shard %d on %s: %s", shard, address, err))
+				return
+			}
+
+			if shardExists {
+				existsMutex.Lock()
+				exists = true
+				existsMutex.Unlock()
+			}
+		}(wg, shard, address)
+	}
+	wg.Wait()
+
+	if bulkErrors.HasError() {
+		return false, bulkErrors
+	}
+
+	return exists, nil
+}
+
+// readShards reads the shards of the chunk and reconstructs the chunk. Data shard count of the shards is
+// enough to reconstruct, so the rest is only read when some of the shards are failed to read or the reconstructed
+// chunk is not matching with its hash. Corrupted shard is excluded using the rest of the shards in that case
+func readShards(
+	dataNodeProviderHandler func(address string) (cluster2.DataNode, error),
+	placement *common.Placement,
+	sha512Hex string,
+	size uint32,
+) ([]byte, error) {
+	if size == 0 {
+		return []byte{}, nil
+	}
+
+	shardSize := placement.Erasure.ShardSize(size)
+	shards := make([][]byte, len(placement.Addresses))
+
+	pending := make([]int, 0)
+	for shard, address := range placement.Addresses {
+		if len(address) > 0 {
+			pending = append(pending, shard)
+		}
+	}
+
+	bulkErrors := errors.NewBulkError()
+
+	collected := 0
+	for collected < int(placement.Erasure.Data) && len(pending) > 0 {
+		count := int(placement.Erasure.Data) - collected
+		if count > len(pending) {
+			count = len(pending)
+		}
+		batch := pending[:count]
+		pending = pending[count:]
+
+		collected = readShardBatch(dataNodeProviderHandler, placement, sha512Hex, shardSize, shards, batch, bulkErrors)
+	}
+
+	if collected < int(placement.Erasure.Data) {
+		if bulkErrors.HasError() {
+			return nil, bulkErrors
+		}
+		return nil, errors.ErrShards
+	}
+
+	data, err := reconstructShards(placement.Erasure, shards, sha512Hex, size)
+	if err != errors.ErrRepair {
+		return data, err
+	}
+
+	// the content of a shard is corrupted, the rest of the shards are read to find it
+	collected = readShardBatch(dataNodeProviderHandler, placement, sha512Hex, shardSize, shards, pending, bulkErrors)
+	if collected <= int(placement.Erasure.Data) {
+		return nil, errors.ErrRepair
+	}
+
+	for excluded := range shards {
+		if shards[excluded] == nil {
+			continue
+		}
+
+		candidate := make([][]byte, len(shards))
+		copy(candidate, shards)
+		candidate[excluded] = nil
+
+		if data, err := reconstructShards(placement.Erasure, candidate, sha512Hex, size); err == nil {
+			return data, nil
+		}
+	}
+
+	return nil, errors.ErrRepair
+}
+
+// readShardBatch reads the shards in the batch concurrently and returns the count of the shards that are read
+func readShardBatch(
+	dataNodeProviderHandler func(address string) (cluster2.DataNode, error),
+	placement *common.Placement,
+	sha512Hex string,
+	shardSize uint32,
+	shards [][]byte,
+	batch []int,
+	bulkErrors *errors.BulkError,
+) int {
+	wg := &sync.WaitGroup{}
+	for _, shard := range batch {
+		wg.Add(1)
+		go func(wg *sync.WaitGroup, shard int) {
+			defer wg.Done()
+
+			address := placement.Addresses[shard]
+
+			dn, err := dataNodeProviderHandler(address)
+			if err != nil {
+				bulkErrors.Add(err)
+				return
+			}
+
+			if err := dn.Read(sha512Hex, 0, shardSize, func(buffer []byte) error {
+				if uint32(len(buffer)) != shardSize {
+					return errors.ErrRepair
+				}
+				shards[shard] = buffer
+				return nil
+			}); err != nil {
+				shards[shard] = nil
+				bulkErrors.Add(fmt.Errorf("shard %d on %s: %s", shard, address, err))
+			}
+		}(wg, shard)
+	}
+	wg.Wait()
+
+	collected := 0
+	for _, shard := range shards {
+		if shard != nil {
+			collected++
+		}
+	}
+	return collected
+}
+
+// reconstructShards reconstructs the chunk without changing the shards and verifies it with the hash of the chunk.
+// It returns ErrRepair when the reconstructed chunk is not matching
+func reconstructShards(coding *common.ErasureCoding, shards [][]byte, sha512Hex string, size uint32) ([]byte, error) {
+	reconstructing := make([][]byte, len(shards))
+	copy(reconstructing, shards)
+
+	data, err := erasure.Reconstruct(coding, reconstructing, size)
+	if err != nil {
+		return nil, err
+	}
+
+	sha512Sum := sha512.Sum512_256(data)
+	if strings.Compare(hex.EncodeToString(sha512Sum[:]), sha512Hex) != 0 {
+		return nil, errors.ErrRepair
+	}
+
+	return data, nil
+}
+
+// createShadowShards increases the usage of the shards on all nodes of the placement
+func createShadowShards(
+	dataNodeProviderHandler func(address string) (cluster2.DataNode, error),
+	placement *common.Placement,
+	sha512Hex string,
+) error {
+	for _, address := range placement.Addresses {
+		if len(address) == 0 {
+			continue
+		}
+
+		dn, err := dataNodeProviderHandler(address)
+		if err != nil {
+			return err
+		}
+
+		if err := dn.CreateShadow(sha512Hex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteShards deletes the shards from all nodes of the placement. Unreachable nodes are skipped, their
+// shards are cleaned by the repair as the orphan chunks
+func deleteShards(
+	dataNodeProviderHandler func(address string) (cluster2.DataNode, error),
+	placement *common.Placement,
+	sha512Hex string,
+) error {
+	bulkErrors := errors.NewBulkError()
+
+	wg := &sync.WaitGroup{}
+	for _, address := range placement.Addresses {
+		if len(address) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(wg *sync.WaitGroup, address string) {
+			defer wg.Done()
+
+			dn, err := dataNodeProviderHandler(address)
+			if err != nil {
+				bulkErrors.Add(err)
+				return
+			}
+
+			if err := dn.Delete(sha512Hex); err != nil && !errors.IsDialError(err) {
+				bulkErrors.Add(err)
+			}
+		}(wg, address)
+	}
+	wg.Wait()
+
+	if bulkErrors.HasError() {
+		return bulkErrors
+	}
+	return nil
+}
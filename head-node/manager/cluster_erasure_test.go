@@ -0,0 +1,78 @@
+package manager
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/erasure"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	cluster2 "github.com/freakmaxi/kertish-dfs/head-node/cluster"
+	"github.com/stretchr/testify/assert"
+)
+
+type shardNode struct {
+	shard []byte
+}
+
+func (s *shardNode) Create(_ []byte) (bool, string, error)        { return false, "", nil }
+func (s *shardNode) CreateShard(_ string, _ []byte) (bool, error) { return false, nil }
+func (s *shardNode) CreateShadow(_ string) error                  { return nil }
+func (s *shardNode) Delete(_ string) error                        { return nil }
+func (s *shardNode) Read(_ string, _ uint32, _ uint32, readHandler func(data []byte) error) error {
+	if s.shard == nil {
+		return errors.ErrPing
+	}
+	return readHandler(s.shard)
+}
+
+func createShardPlacement(t *testing.T, data []byte) (*common.Placement, map[string]*shardNode) {
+	coding := &common.ErasureCoding{Data: 4, Parity: 2}
+
+	// shards share the memory of the data
+	shards, err := erasure.Encode(coding, append([]byte{}, data...))
+	assert.Nil(t, err)
+
+	nodes := make(map[string]*shardNode)
+	addresses := make([]string, 0)
+	for i, shard := range shards {
+		address := string(rune('a' + i))
+		nodes[address] = &shardNode{shard: shard}
+		addresses = append(addresses, address)
+	}
+
+	return &common.Placement{ClusterId: "cluster", Erasure: coding, Addresses: addresses}, nodes
+}
+
+func TestReadShards(t *testing.T) {
+	data := make([]byte, 1000)
+	_, _ = rand.New(rand.NewSource(42)).Read(data)
+	sha512Sum := sha512.Sum512_256(data)
+	sha512Hex := hex.EncodeToString(sha512Sum[:])
+
+	placement, nodes := createShardPlacement(t, data)
+	provider := func(address string) (cluster2.DataNode, error) { return nodes[address], nil }
+
+	result, err := readShards(provider, placement, sha512Hex, uint32(len(data)))
+	assert.Nil(t, err)
+	assert.Equal(t, data, result)
+
+	// unreachable data shards are reconstructed from the parity
+	nodes["a"].shard = nil
+	result, err = readShards(provider, placement, sha512Hex, uint32(len(data)))
+	assert.Nil(t, err)
+	assert.Equal(t, data, result)
+
+	// corrupted shard is detected with the hash and excluded
+	nodes["c"].shard[10] ^= 0xff
+	result, err = readShards(provider, placement, sha512Hex, uint32(len(data)))
+	assert.Nil(t, err)
+	assert.Equal(t, data, result)
+
+	// corruption can not be excluded without the spare shard
+	nodes["f"].shard = nil
+	_, err = readShards(provider, placement, sha512Hex, uint32(len(data)))
+	assert.Equal(t, errors.ErrRepair, err)
+}
@@ -3,7 +3,9 @@ package manager
 import (
 	"io"
 	"strings"
+	"time"
 
+	"github.com/freakmaxi/kertish-dfs/basics/common"
 	"github.com/freakmaxi/kertish-dfs/basics/hooks"
 	"github.com/freakmaxi/kertish-dfs/head-node/data"
 	"go.uber.org/zap"
@@ -12,32 +14,78 @@ import (
 // Dfs interface is for file manipulation operations base on REST service request
 type Dfs interface {
 	CreateFolder(folderPath string) error
-	CreateFile(path string, mime string, size uint64, overwrite bool, contentReader io.Reader) error
+	CreateFile(path string, mime string, metadata common.Metadata, ttl *time.Duration, blockSize uint32, size uint64, overwrite bool, precondition *Precondition, contentReader io.Reader) error
+	CreateStream(path string, mime string, metadata common.Metadata, ttl *time.Duration, blockSize uint32, overwrite bool, precondition *Precondition, contentReader io.Reader) error
+	Extract(folderPath string, format ArchiveFormat, overwrite bool, contentReader io.Reader) (*common.ExtractionReport, error)
+
+	CreateUpload(path string, mime string, metadata common.Metadata, ttl *time.Duration, blockSize uint32, size uint64, overwrite bool) (*common.UploadSession, error)
+	ReadUpload(sessionId string) (*common.UploadSession, error)
+	UploadPart(sessionId string, offset uint64, size uint64, contentReader io.Reader) error
+	CommitUpload(sessionId string, precondition *Precondition) error
+	DiscardUpload(sessionId string) error
 
 	Read(paths []string, join bool) (ReadContainer, error)
 	Size(folderPath string) (uint64, error)
+	Archive(folderPath string, format ArchiveFormat, filter *ArchiveFilter) (func(w io.Writer) error, error)
+	Search(query *SearchQuery, continuation string, limit int) (*common.SearchResult, error)
+
+	Change(sources []string, target string, join bool, overwrite bool, move bool, precondition *Precondition) error
+
+	UpdateMetadata(path string, metadata common.Metadata, replace bool, precondition *Precondition) error
+	UpdateTimeToLive(path string, ttl time.Duration, precondition *Precondition) error
+	SetBlockSize(folderPath string, blockSize uint32) error
+
+	Delete(path string, killZombies bool, precondition *Precondition) error
 
-	Change(sources []string, target string, join bool, overwrite bool, move bool) error
+	Lock(lock *common.ResourceLock) error
+	RefreshLock(path string, tokens []string, timeout time.Duration) (*common.ResourceLock, error)
+	Unlock(path string, token string) error
+	Locks(path string) (common.ResourceLocks, error)
 
-	Delete(path string, killZombies bool) error
+	Quota(folderPath string) (*common.Quota, error)
+	SetQuota(folderPath string, quota *common.Quota) error
+
+	SetVersioning(folderPath string, enabled bool) error
+	Versions(path string) (common.FileVersions, error)
+	ReadVersion(path string, versionId string) (ReadContainer, error)
+	RestoreVersion(path string, versionId string, precondition *Precondition) error
+	PurgeVersions(path string, keep *int, before *time.Time) error
+
+	ListTrash() (common.TrashEntries, error)
+	RestoreTrash(entryId string) error
+	PurgeTrash(entryId string) error
+	EmptyTrash() error
 
 	// ExecuteActions executes the hook actions in sync manner
 	ExecuteActions(aI *hooks.ActionInfo, actions []hooks.Action)
 }
 
 type dfs struct {
-	metadata data.Metadata
-	cluster  Cluster
-	logger   *zap.Logger
+	metadata       data.Metadata
+	uploads        data.Uploads
+	trash          data.Trash
+	trashRetention time.Duration
+	cluster        Cluster
+	logger         *zap.Logger
 }
 
 // NewDfs creates the instance of file manipulation operations object for REST service request
-func NewDfs(metadata data.Metadata, cluster Cluster, logger *zap.Logger) Dfs {
-	return &dfs{
-		metadata: metadata,
-		cluster:  cluster,
-		logger:   logger,
+// and starts dropping the expired upload sessions, files and trash entries in the background.
+// Zero trash retention disables the trash and deletions release the chunks immediately
+func NewDfs(metadata data.Metadata, uploads data.Uploads, trash data.Trash, cluster Cluster, trashRetention time.Duration, logger *zap.Logger) Dfs {
+	d := &dfs{
+		metadata:       metadata,
+		uploads:        uploads,
+		trash:          trash,
+		trashRetention: trashRetention,
+		cluster:        cluster,
+		logger:         logger,
 	}
+	go d.expireUploads()
+	go d.expireFiles()
+	go d.expireTrash()
+
+	return d
 }
 
 func (d *dfs) ExecuteActions(aI *hooks.ActionInfo, actions []hooks.Action) {
@@ -0,0 +1,245 @@
+package manager
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+// ArchiveFormat is the container format of the folder archive
+type ArchiveFormat string
+
+const (
+	AFZip   ArchiveFormat = "zip"
+	AFTar   ArchiveFormat = "tar"
+	AFTarGz ArchiveFormat = "tar.gz"
+)
+
+// ParseArchiveFormat validates and creates the archive format from the value
+func ParseArchiveFormat(value string) (ArchiveFormat, error) {
+	switch format := ArchiveFormat(strings.ToLower(value)); format {
+	case AFZip, AFTar, AFTarGz:
+		return format, nil
+	case "tgz":
+		return AFTarGz, nil
+	}
+	return "", os.ErrInvalid
+}
+
+// ContentType returns the mime type of the archive format
+func (a ArchiveFormat) ContentType() string {
+	switch a {
+	case AFZip:
+		return "application/zip"
+	case AFTar:
+		return "application/x-tar"
+	}
+	return "application/gzip"
+}
+
+// ArchiveFilter struct is to select the files of the folder archive with the glob patterns. Patterns are
+// matched with the file name or with the relative path in the archive when the pattern contains path separator
+type ArchiveFilter struct {
+	Include string
+	Exclude string
+}
+
+// Validate checks the glob patterns of the filter
+func (a *ArchiveFilter) Validate() error {
+	for _, pattern := range []string{a.Include, a.Exclude} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return os.ErrInvalid
+		}
+	}
+	return nil
+}
+
+func (a *ArchiveFilter) match(pattern string, relativePath string) bool {
+	target := relativePath
+	if !strings.Contains(pattern, "/") {
+		_, target = path.Split(relativePath)
+	}
+	matched, _ := path.Match(pattern, target)
+	return matched
+}
+
+// included checks the file at the relative path is selected by the filter
+func (a *ArchiveFilter) included(relativePath string) bool {
+	if a == nil {
+		return true
+	}
+	if len(a.Include) > 0 && !a.match(a.Include, relativePath) {
+		return false
+	}
+	return !a.excluded(relativePath)
+}
+
+// excluded checks the folder or the file at the relative path is dropped by the exclude pattern of the filter
+func (a *ArchiveFilter) excluded(relativePath string) bool {
+	return a != nil && len(a.Exclude) > 0 && a.match(a.Exclude, relativePath)
+}
+
+// archiveWriter is to write the folders and the files of the folder tree into the archive container
+type archiveWriter interface {
+	Folder(relativePath string, modified time.Time) error
+	File(relativePath string, size uint64, modified time.Time) (io.Writer, error)
+	Close() error
+}
+
+// Archive prepares the stream handler that writes the folder tree in the archive format. Files are streamed
+// from the clusters one by one and the locked, expired and zombie files are skipped
+func (d *dfs) Archive(folderPath string, format ArchiveFormat, filter *ArchiveFilter) (func(w io.Writer) error, error) {
+	folderPath = common.CorrectPath(folderPath)
+
+	tree, err := d.tree(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w io.Writer) error {
+		aw := newArchiveWriter(w, format)
+
+		excludedFolders := make([]string, 0)
+
+	folderLoop:
+		for _, folder := range tree.Normalize() {
+			if folder == nil {
+				continue
+			}
+
+			relativeFolderPath := strings.TrimPrefix(strings.TrimPrefix(folder.Full, folderPath), "/")
+			if len(relativeFolderPath) > 0 {
+				for _, excludedFolder := range excludedFolders {
+					if strings.HasPrefix(relativeFolderPath, excludedFolder) {
+						continue folderLoop
+					}
+				}
+				if filter.excluded(relativeFolderPath) {
+					excludedFolders = append(excludedFolders, relativeFolderPath+"/")
+					continue
+				}
+
+				// folder entries are omitted when the files are selected with the include pattern
+				if filter == nil || len(filter.Include) == 0 {
+					if err := aw.Folder(relativeFolderPath, folder.Modified); err != nil {
+						return err
+					}
+				}
+			}
+
+			for _, file := range folder.Files {
+				relativePath := path.Join(relativeFolderPath, file.Name)
+				if !filter.included(relativePath) {
+					continue
+				}
+
+				if file.Locked() || file.Expired() || file.ZombieCheck() {
+					continue
+				}
+
+				streamHandler, err := d.cluster.Read(file.Chunks)
+				if err != nil {
+					return err
+				}
+
+				fw, err := aw.File(relativePath, file.Size, file.Modified)
+				if err != nil {
+					return err
+				}
+
+				if file.Size == 0 {
+					continue
+				}
+
+				if err := streamHandler(fw, 0, -1); err != nil {
+					return err
+				}
+			}
+		}
+
+		return aw.Close()
+	}, nil
+}
+
+func newArchiveWriter(w io.Writer, format ArchiveFormat) archiveWriter {
+	switch format {
+	case AFZip:
+		return &zipArchiveWriter{zw: zip.NewWriter(w)}
+	case AFTarGz:
+		gw := gzip.NewWriter(w)
+		return &tarArchiveWriter{tw: tar.NewWriter(gw), gw: gw}
+	}
+	return &tarArchiveWriter{tw: tar.NewWriter(w)}
+}
+
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipArchiveWriter) Folder(relativePath string, modified time.Time) error {
+	_, err := z.zw.CreateHeader(&zip.FileHeader{
+		Name:     relativePath + "/",
+		Method:   zip.Store,
+		Modified: modified,
+	})
+	return err
+}
+
+func (z *zipArchiveWriter) File(relativePath string, size uint64, modified time.Time) (io.Writer, error) {
+	return z.zw.CreateHeader(&zip.FileHeader{
+		Name:     relativePath,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+}
+
+func (z *zipArchiveWriter) Close() error {
+	return z.zw.Close()
+}
+
+type tarArchiveWriter struct {
+	tw *tar.Writer
+	gw *gzip.Writer
+}
+
+func (t *tarArchiveWriter) Folder(relativePath string, modified time.Time) error {
+	return t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     relativePath + "/",
+		Mode:     0755,
+		ModTime:  modified,
+	})
+}
+
+func (t *tarArchiveWriter) File(relativePath string, size uint64, modified time.Time) (io.Writer, error) {
+	if err := t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     relativePath,
+		Size:     int64(size),
+		Mode:     0644,
+		ModTime:  modified,
+	}); err != nil {
+		return nil, err
+	}
+	return t.tw, nil
+}
+
+func (t *tarArchiveWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	if t.gw != nil {
+		return t.gw.Close()
+	}
+	return nil
+}
+
+var _ archiveWriter = &zipArchiveWriter{}
+var _ archiveWriter = &tarArchiveWriter{}
@@ -0,0 +1,31 @@
+package manager
+
+import (
+	"os"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+// SetBlockSize sets the default block size of the files that will be placed in the folder. Existent files
+// keep their chunks. Zero block size removes the folder default and the farm default is used
+func (d *dfs) SetBlockSize(folderPath string, blockSize uint32) error {
+	if err := common.ValidateBlockSize(blockSize); err != nil {
+		return err
+	}
+
+	folderPath = common.CorrectPath(folderPath)
+
+	return d.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[folderPath]
+		if folder == nil {
+			return false, os.ErrNotExist
+		}
+
+		if folder.BlockSize == blockSize {
+			return false, nil
+		}
+		folder.BlockSize = blockSize
+
+		return true, nil
+	})
+}
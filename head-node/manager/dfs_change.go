@@ -2,6 +2,7 @@ package manager
 
 import (
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/freakmaxi/kertish-dfs/basics/common"
@@ -9,20 +10,32 @@ import (
 	"github.com/freakmaxi/kertish-dfs/basics/hooks"
 )
 
-func (d *dfs) Change(sources []string, target string, join bool, overwrite bool, move bool) error {
+func (d *dfs) Change(sources []string, target string, join bool, overwrite bool, move bool, precondition *Precondition) error {
 	if len(sources) > 1 && !join {
 		return os.ErrInvalid
 	}
-	if err := d.changeFolder(sources, target, move); err != nil {
+
+	if move {
+		for _, source := range sources {
+			if err := d.checkLocks(source, true, precondition); err != nil {
+				return err
+			}
+		}
+	}
+	if err := d.checkLocks(target, true, precondition); err != nil {
+		return err
+	}
+
+	if err := d.changeFolder(sources, target, move, precondition); err != nil {
 		if err != os.ErrNotExist {
 			return err
 		}
-		return d.changeFile(sources, target, overwrite, move)
+		return d.changeFile(sources, target, overwrite, move, precondition)
 	}
 	return nil
 }
 
-func (d *dfs) changeFolder(sources []string, target string, move bool) error {
+func (d *dfs) changeFolder(sources []string, target string, move bool, precondition *Precondition) error {
 	sources = common.CorrectPaths(sources)
 	target = common.CorrectPath(target)
 
@@ -31,6 +44,11 @@ func (d *dfs) changeFolder(sources []string, target string, move bool) error {
 		return err
 	}
 
+	// folders do not have entity tag and modification tracking for the preconditions
+	if !precondition.empty() {
+		return errors.ErrPrecondition
+	}
+
 	joinedFolder, err := common.CreateJoinedFolder(sourceFolders)
 	if err != nil {
 		return err
@@ -39,6 +57,7 @@ func (d *dfs) changeFolder(sources []string, target string, move bool) error {
 	clonedFolderPaths := make([]string, 0)
 	clonedFoldersMap := make(map[string]*common.Folder)
 	createShadowChunks := make(common.DataChunks, 0)
+	quotaChanges := make([]quotaChange, 0)
 
 	for i := 0; i < len(sourceFolders); i++ {
 		sourceFolder := sourceFolders[i]
@@ -48,9 +67,25 @@ func (d *dfs) changeFolder(sources []string, target string, move bool) error {
 			return err
 		}
 
+		if move {
+			sourceParent, _ := common.Split(sourceFolder.Full)
+			usage := common.NewQuotaUsage(append([]*common.Folder{sourceFolder}, sourceChildren...))
+
+			quotaChanges = append(quotaChanges, newQuotaChange(sourceParent, usage, true))
+		}
+
 		for j := 0; j < len(sourceChildren); j++ {
 			sourceChild := sourceChildren[j]
 			sourceChild.Full = strings.Replace(sourceChild.Full, sourceFolder.Full, target, 1)
+			// locks are not moved or copied with the folder
+			sourceChild.Locks = nil
+
+			// versions are not copied, their chunks are only referenced by the source folder.
+			// quotas are not copied as well, they belong to the source folder tree
+			if !move {
+				sourceChild.Versions = nil
+				sourceChild.Quota = nil
+			}
 
 			if existsClone, has := clonedFoldersMap[sourceChild.Full]; has {
 				joinedChildFolder, err := common.CreateJoinedFolder([]*common.Folder{sourceChild, existsClone})
@@ -58,6 +93,9 @@ func (d *dfs) changeFolder(sources []string, target string, move bool) error {
 					return err
 				}
 				joinedChildFolder.CloneInto(sourceChild)
+
+				sourceChild.Versions = append(sourceChild.Versions, existsClone.Versions...)
+				sort.Sort(sourceChild.Versions)
 			}
 
 			for i := 0; i < len(sourceChild.Files); i++ {
@@ -90,13 +128,49 @@ func (d *dfs) changeFolder(sources []string, target string, move bool) error {
 		}
 	}
 
-	if err := d.metadata.SaveChain(target, func(targetFolder *common.Folder) (bool, error) {
+	targetFolders := []*common.Folder{joinedFolder}
+	for _, clonedFolder := range clonedFoldersMap {
+		targetFolders = append(targetFolders, clonedFolder)
+	}
+	usage := common.NewQuotaUsage(targetFolders)
+
+	if !move {
+		// locked and zombie files of the source folder are not copied to the target folder
+		for _, file := range joinedFolder.Files {
+			if file.Locked() || file.ZombieCheck() {
+				usage.Size -= file.Size
+				usage.Files--
+			}
+		}
+	}
+	quotaChanges = append(quotaChanges, newQuotaChange(target, usage, false))
+
+	// target folder is locked together with the quotas, so the concurrent changes can not pass the limits together
+	lockPaths, err := d.quotaLockPaths(target, quotaChanges...)
+	if err != nil {
+		return err
+	}
+
+	var softExceededPaths []string
+	if err := d.metadata.SaveBlock(lockPaths, func(folders map[string]*common.Folder) (bool, error) {
+		targetFolder := folders[target]
 		if len(targetFolder.Files) > 0 || len(targetFolder.Folders) > 0 {
 			return false, errors.ErrNotEmpty
 		}
 
+		if err := d.checkLockedQuota(folders, quotaChanges...); err != nil {
+			return false, err
+		}
+
 		joinedFolder.CloneInto(targetFolder)
 
+		if move {
+			for _, sourceFolder := range sourceFolders {
+				targetFolder.Versions = append(targetFolder.Versions, sourceFolder.Versions...)
+			}
+			sort.Sort(targetFolder.Versions)
+		}
+
 		for i := 0; i < len(targetFolder.Files); i++ {
 			file := targetFolder.Files[i]
 
@@ -121,11 +195,13 @@ func (d *dfs) changeFolder(sources []string, target string, move bool) error {
 
 			createShadowChunks = append(createShadowChunks, file.Chunks...)
 		}
+		softExceededPaths = d.applyLockedQuota(folders, quotaChanges...)
 
 		return true, nil
 	}); err != nil {
 		return err
 	}
+	d.executeQuotaHooks(softExceededPaths)
 
 	if move {
 		// joined with clonedFolderPaths to query easily
@@ -137,7 +213,7 @@ func (d *dfs) changeFolder(sources []string, target string, move bool) error {
 		}
 	}
 
-	return d.metadata.SaveBlock(clonedFolderPaths, func(folders map[string]*common.Folder) (bool, error) {
+	if err := d.metadata.SaveBlock(clonedFolderPaths, func(folders map[string]*common.Folder) (bool, error) {
 		if move {
 			for _, source := range sources {
 				sourceParent, sourceName := common.Split(source)
@@ -148,6 +224,7 @@ func (d *dfs) changeFolder(sources []string, target string, move bool) error {
 						return nil
 					})
 				}
+				d.dropLocks(folders, source)
 				folders[source] = nil
 			}
 		}
@@ -177,10 +254,15 @@ func (d *dfs) changeFolder(sources []string, target string, move bool) error {
 		}
 
 		return true, nil
-	})
+	}); err != nil {
+		d.changeQuota(reverseQuotaChanges(quotaChanges)...)
+		return err
+	}
+
+	return nil
 }
 
-func (d *dfs) changeFile(sources []string, target string, overwrite bool, move bool) error {
+func (d *dfs) changeFile(sources []string, target string, overwrite bool, move bool, precondition *Precondition) error {
 	targetParent, targetFilename := common.Split(target)
 
 	targetFolders, err := d.metadata.Get([]string{targetParent})
@@ -188,16 +270,11 @@ func (d *dfs) changeFile(sources []string, target string, overwrite bool, move b
 		return err
 	}
 
+	var overwrittenFile *common.File
 	if targetFolders != nil {
-		targetFile := targetFolders[0].File(targetFilename)
-		if targetFile != nil {
-			if !overwrite {
-				return os.ErrExist
-			}
-
-			if err := d.deleteFile(target, false); err != nil {
-				return err
-			}
+		overwrittenFile = targetFolders[0].File(targetFilename)
+		if overwrittenFile != nil && !overwrite {
+			return os.ErrExist
 		}
 	}
 
@@ -220,7 +297,7 @@ func (d *dfs) changeFile(sources []string, target string, overwrite bool, move b
 		}
 
 		sourceFile := sourceFolder.File(sourceFilename)
-		if sourceFile == nil {
+		if sourceFile == nil || sourceFile.Expired() {
 			return os.ErrNotExist
 		}
 
@@ -240,7 +317,49 @@ func (d *dfs) changeFile(sources []string, target string, overwrite bool, move b
 		return err
 	}
 
-	if err := d.metadata.SaveChain(targetParent, func(targetFolder *common.Folder) (bool, error) {
+	targetQuotaChange := newFileQuotaChange(targetParent, nil, joinedFile.Size)
+	sourceQuotaChanges := make([]quotaChange, 0)
+	if move {
+		for _, source := range sources {
+			sourceParent, sourceFilename := common.Split(source)
+			sourceFile := sourceFoldersMap[sourceParent].File(sourceFilename)
+
+			sourceQuotaChanges = append(sourceQuotaChanges, newQuotaChange(sourceParent, common.QuotaUsage{Size: sourceFile.Size, Files: 1}, true))
+		}
+	}
+	quotaChanges := append([]quotaChange{targetQuotaChange}, sourceQuotaChanges...)
+
+	if overwrittenFile != nil {
+		// quota is validated before the overwritten file is deleted and validated again in the lock
+		overwrittenQuotaChange := newQuotaChange(targetParent, common.QuotaUsage{Size: overwrittenFile.Size, Files: 1}, true)
+		if err := d.checkQuota(append(quotaChanges, overwrittenQuotaChange)...); err != nil {
+			return err
+		}
+
+		if err := d.deleteFile(target, false, precondition); err != nil {
+			return err
+		}
+		// precondition is validated on the overwritten file
+		precondition = nil
+	}
+
+	// target folder is locked together with the quotas, so the concurrent changes can not pass the limits together
+	lockPaths, err := d.quotaLockPaths(targetParent, quotaChanges...)
+	if err != nil {
+		return err
+	}
+
+	var softExceededPaths []string
+	if err := d.metadata.SaveBlock(lockPaths, func(folders map[string]*common.Folder) (bool, error) {
+		targetFolder := folders[targetParent]
+		if err := precondition.validate(targetFolder.File(targetFilename)); err != nil {
+			return false, err
+		}
+
+		if err := d.checkLockedQuota(folders, quotaChanges...); err != nil {
+			return false, err
+		}
+
 		targetFile, err := targetFolder.NewFile(targetFilename)
 		if err != nil {
 			return false, err
@@ -254,11 +373,13 @@ func (d *dfs) changeFile(sources []string, target string, overwrite bool, move b
 			}
 		}
 		targetFile.Lock.Cancel()
+		softExceededPaths = d.applyLockedQuota(folders, quotaChanges...)
 
 		return true, nil
 	}); err != nil {
 		return err
 	}
+	d.executeQuotaHooks(softExceededPaths)
 
 	if !move {
 		// Handle Hooks
@@ -270,7 +391,7 @@ func (d *dfs) changeFile(sources []string, target string, overwrite bool, move b
 		return nil
 	}
 
-	return d.metadata.SaveBlock(sourceParents, func(folders map[string]*common.Folder) (bool, error) {
+	if err := d.metadata.SaveBlock(sourceParents, func(folders map[string]*common.Folder) (bool, error) {
 		for _, source := range sources {
 			sourceParent, sourceFilename := common.Split(source)
 			sourceFolder := folders[sourceParent]
@@ -278,6 +399,7 @@ func (d *dfs) changeFile(sources []string, target string, overwrite bool, move b
 			_ = sourceFolder.DeleteFile(sourceFilename, func(file *common.File) error {
 				return nil
 			})
+			d.dropLocks(folders, source)
 		}
 
 		// Handle Hooks
@@ -287,5 +409,11 @@ func (d *dfs) changeFile(sources []string, target string, overwrite bool, move b
 		}
 
 		return true, nil
-	})
+	}); err != nil {
+		// the file is placed in the target but it is still in the source
+		d.changeQuota(reverseQuotaChanges(sourceQuotaChanges)...)
+		return err
+	}
+
+	return nil
 }
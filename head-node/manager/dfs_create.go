@@ -3,6 +3,8 @@ package manager
 import (
 	"io"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/freakmaxi/kertish-dfs/basics/common"
 	"github.com/freakmaxi/kertish-dfs/basics/errors"
@@ -21,23 +23,116 @@ func (d *dfs) CreateFolder(folderPath string) error {
 	})
 }
 
-func (d *dfs) CreateFile(path string, mime string, size uint64, overwrite bool, contentReader io.Reader) error {
+func (d *dfs) CreateFile(path string, mime string, metadata common.Metadata, ttl *time.Duration, blockSize uint32, size uint64, overwrite bool, precondition *Precondition, contentReader io.Reader) error {
 	path = common.CorrectPath(path) // It is required in here to eliminate wrong path format
 
+	file, blockSize, err := d.prepareFile(path, common.NewFileLockForSize(size), size, ttl, blockSize, overwrite, precondition)
+	if err != nil {
+		return err
+	}
+
+	creationResult, err := d.cluster.Create(size, blockSize, contentReader)
+	if err != nil {
+		d.dropFile(path, size)
+		return err
+	}
+
+	return d.completeFile(path, mime, metadata, size, size, file, creationResult)
+}
+
+func (d *dfs) CreateStream(path string, mime string, metadata common.Metadata, ttl *time.Duration, blockSize uint32, overwrite bool, precondition *Precondition, contentReader io.Reader) error {
+	path = common.CorrectPath(path) // It is required in here to eliminate wrong path format
+
+	file, blockSize, err := d.prepareFile(path, common.NewFileLock(0), 0, ttl, blockSize, overwrite, precondition)
+	if err != nil {
+		return err
+	}
+
+	creationResult, size, err := d.cluster.CreateStream(contentReader, blockSize)
+	if err != nil {
+		d.dropFile(path, 0)
+		return err
+	}
+
+	// size of the stream is known only after the content is placed
+	folderPath, _ := common.Split(path)
+	if err := d.reserveQuota(quotaChange{folderPath: folderPath, size: int64(size)}); err != nil {
+		d.releaseChunks(creationResult.Chunks)
+		d.dropFile(path, 0)
+		return err
+	}
+
+	return d.completeFile(path, mime, metadata, size, size, file, creationResult)
+}
+
+// prepareFile creates or locks the file entry in the folder and drops the chunks of the
+// existent file to make it ready for the content placement. If the folder has versioning,
+// the existent file is archived instead of dropping the chunks. Expiry of the file is set using ttl
+// or the folder default time-to-live when ttl is not provided. Size is reserved in the quotas of the folder tree
+// in the same lock with the file entry, it should be zero if the size is not known before the content placement.
+// Reservation is adjusted by completeFile or released by dropFile.
+// Block size of the content is returned as blockSize or the folder default block size when blockSize is zero
+func (d *dfs) prepareFile(path string, lock *common.FileLock, size uint64, ttl *time.Duration, blockSize uint32, overwrite bool, precondition *Precondition) (*common.File, uint32, error) {
 	folderPath, filename := common.Split(path)
 	if len(filename) == 0 {
-		return os.ErrInvalid
+		return nil, 0, os.ErrInvalid
+	}
+
+	if err := common.ValidateBlockSize(blockSize); err != nil {
+		return nil, 0, err
+	}
+
+	if err := d.checkLocks(path, false, precondition); err != nil {
+		return nil, 0, err
+	}
+
+	// folder tree is created first to lock the folder together with the quotas of the folder tree
+	if err := d.metadata.SaveChain(folderPath, func(_ *common.Folder) (bool, error) {
+		return false, nil
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	quotaPaths, err := d.quotaPaths(folderPath)
+	if err != nil {
+		return nil, 0, err
 	}
+	lockPaths := append(quotaPaths, folderPath)
+	sort.Strings(lockPaths)
 
 	var file *common.File
+	var softExceededPaths []string
 
-	if err := d.metadata.SaveChain(folderPath, func(folder *common.Folder) (bool, error) {
+	if err := d.metadata.SaveBlock(lockPaths, func(folders map[string]*common.Folder) (bool, error) {
 		var err error
 
+		folder := folders[folderPath]
+
+		if blockSize == 0 {
+			blockSize = folder.BlockSize
+		}
+
 		file = folder.File(filename)
+		if err := precondition.validate(file); err != nil {
+			return false, err
+		}
+
+		// the existent content is released or archived and the size of the new content is reserved
+		change := newFileQuotaChange(folderPath, file, size)
+
 		if file == nil {
+			if err := d.checkLockedQuota(folders, change); err != nil {
+				return false, err
+			}
+
 			file, err = folder.NewFile(filename)
-			return err == nil, err
+			if err != nil {
+				return false, err
+			}
+			file.Expires = d.fileExpiry(folder, ttl)
+			softExceededPaths = d.applyLockedQuota(folders, change)
+
+			return true, nil
 		}
 
 		if !overwrite {
@@ -48,7 +143,22 @@ func (d *dfs) CreateFile(path string, mime string, size uint64, overwrite bool,
 			return false, errors.ErrLock
 		}
 
-		file.Lock = common.NewFileLockForSize(size)
+		if err := d.checkLockedQuota(folders, change); err != nil {
+			return false, err
+		}
+
+		file.Lock = lock
+
+		if folder.Versioning {
+			if err := folder.ArchiveFile(file, false); err != nil {
+				file.Lock.Cancel()
+				return false, err
+			}
+			file.Expires = d.fileExpiry(folder, ttl)
+			softExceededPaths = d.applyLockedQuota(folders, change)
+
+			return true, nil
+		}
 
 		deletionResult, err := d.cluster.Delete(file.Chunks)
 		if deletionResult != nil {
@@ -62,30 +172,46 @@ func (d *dfs) CreateFile(path string, mime string, size uint64, overwrite bool,
 			}
 			file.Zombie = true
 		}
+		file.Expires = d.fileExpiry(folder, ttl)
+		softExceededPaths = d.applyLockedQuota(folders, change)
 
 		return true, nil
 	}); err != nil {
-		return err
+		return nil, 0, err
 	}
+	d.executeQuotaHooks(softExceededPaths)
 
-	creationResult, err := d.cluster.Create(size, contentReader)
-	if err != nil {
-		if errUpdate := d.update(path, nil); errUpdate != nil {
-			d.logger.Error(
-				"Dropping file entry due to file creation failure is failed, file is now zombie",
-				zap.String("path", path),
-				zap.Error(errUpdate),
-			)
+	return file, blockSize, nil
+}
+
+// fileExpiry calculates the expiry date of the file that is placed in the folder
+func (d *dfs) fileExpiry(folder *common.Folder, ttl *time.Duration) *time.Time {
+	if ttl == nil {
+		if folder.Ttl == 0 {
+			return nil
 		}
-		return err
+		folderTtl := time.Duration(folder.Ttl) * time.Second
+		ttl = &folderTtl
 	}
 
+	if *ttl <= 0 {
+		return nil
+	}
+
+	expires := time.Now().UTC().Add(*ttl)
+	return &expires
+}
+
+// completeFile places the created content to the file entry and releases the file lock. Quota usage is adjusted
+// with the difference of the size and the reserved size
+func (d *dfs) completeFile(path string, mime string, metadata common.Metadata, size uint64, reserved uint64, file *common.File, creationResult *common.CreationResult) error {
 	file.Reset(mime, size)
 	file.Checksum = creationResult.Checksum
+	file.Metadata = metadata.Merge(nil)
 	file.Chunks = append(file.Chunks, creationResult.Chunks...)
 	file.Lock.Cancel()
 
-	err = d.update(path, file)
+	err := d.update(path, file)
 	if err != nil {
 		d.logger.Error(
 			"Saving file creation is failed. File is now zombie with orphan chunks in data node! Run repair to eliminate",
@@ -93,12 +219,30 @@ func (d *dfs) CreateFile(path string, mime string, size uint64, overwrite bool,
 			zap.Error(err),
 		)
 	} else {
+		folderPath, _ := common.Split(path)
+		d.changeQuota(quotaChange{folderPath: folderPath, size: int64(size) - int64(reserved)})
+
 		actions := d.compileHookActions(folderPath, hooks.Created)
 		d.ExecuteActions(hooks.NewActionInfoForCreated(path, false), actions)
 	}
 	return err
 }
 
+// dropFile removes the file entry that the content is not placed and releases the reserved size
+func (d *dfs) dropFile(path string, reserved uint64) {
+	if err := d.update(path, nil); err != nil {
+		d.logger.Error(
+			"Dropping file entry due to file creation failure is failed, file is now zombie",
+			zap.String("path", path),
+			zap.Error(err),
+		)
+		return
+	}
+
+	folderPath, _ := common.Split(path)
+	d.changeQuota(quotaChange{folderPath: folderPath, size: -int64(reserved), files: -1})
+}
+
 func (d *dfs) update(folderPath string, file *common.File) error {
 	parent, filename := common.Split(folderPath)
 
@@ -9,39 +9,107 @@ import (
 	"github.com/freakmaxi/kertish-dfs/basics/hooks"
 )
 
-func (d *dfs) Delete(target string, killZombies bool) error {
-	if err := d.deleteFolder(target, killZombies); err != nil {
+func (d *dfs) Delete(target string, killZombies bool, precondition *Precondition) error {
+	if err := d.checkLocks(target, true, precondition); err != nil {
+		return err
+	}
+
+	if err := d.deleteFolder(target, killZombies, precondition); err != nil {
 		if err != os.ErrNotExist {
 			return err
 		}
-		return d.deleteFile(target, killZombies)
+		return d.deleteFile(target, killZombies, precondition)
 	}
 	return nil
 }
 
-func (d *dfs) deleteFolder(folderPath string, killZombies bool) error {
+func (d *dfs) deleteFolder(folderPath string, killZombies bool, precondition *Precondition) error {
 	parentPath, pathName := common.Split(folderPath)
 
-	return d.metadata.SaveBlock([]string{parentPath}, func(folders map[string]*common.Folder) (bool, error) {
+	var entry *common.TrashEntry
+	var usage common.QuotaUsage
+	if err := d.metadata.SaveBlock([]string{parentPath}, func(folders map[string]*common.Folder) (bool, error) {
 		folder := folders[parentPath]
 		if folder == nil {
 			return false, os.ErrNotExist
 		}
 
-		return true, folder.DeleteFolder(pathName, func(fullPath string) error {
-			return d.deleteFolderContent(fullPath, killZombies, folders)
+		// folders do not have entity tag and modification tracking for the preconditions
+		if folder.Folder(pathName) != nil && !precondition.empty() {
+			return false, errors.ErrPrecondition
+		}
+
+		err := folder.DeleteFolder(pathName, func(fullPath string) error {
+			var err error
+
+			// killing zombies is a cleanup request, it should not keep the content in the trash
+			if !d.trashEnabled() || killZombies {
+				usage, err = d.deleteFolderContent(fullPath, killZombies, folders)
+				return err
+			}
+
+			entry, usage, err = d.trashFolderContent(fullPath, folders)
+			return err
 		})
-	})
+		if err == nil {
+			d.dropLocks(folders, folderPath)
+		}
+		return true, err
+	}); err != nil {
+		return err
+	}
+
+	d.createTrashEntry(entry)
+	d.changeQuota(newQuotaChange(parentPath, usage, true))
+
+	return nil
 }
 
-func (d *dfs) deleteFolderContent(fullPath string, killZombies bool, foldersCache map[string]*common.Folder) error {
+// trashFolderContent drops the folder and its sub folders from the metadata without releasing the chunks
+// and creates the trash entry to keep them till the retention period expires
+func (d *dfs) trashFolderContent(fullPath string, foldersCache map[string]*common.Folder) (*common.TrashEntry, common.QuotaUsage, error) {
+	trashingFolders, err := d.metadata.ChildrenTree(fullPath, true, true)
+	if err != nil {
+		if err == os.ErrNotExist {
+			return nil, common.QuotaUsage{}, errors.ErrRepair
+		}
+		return nil, common.QuotaUsage{}, err
+	}
+
+	for _, folder := range trashingFolders {
+		if folder.Locked() {
+			return nil, common.QuotaUsage{}, errors.ErrLock
+		}
+		// locks are released with the folder, they are not restored from the trash
+		folder.Locks = nil
+	}
+
+	entry, err := common.NewTrashEntryForFolder(fullPath, trashingFolders, d.trashRetention)
+	if err != nil {
+		return nil, common.QuotaUsage{}, err
+	}
+
+	for _, folder := range trashingFolders {
+		actions := d.compileHookActions(folder.Full, hooks.Deleted)
+
+		foldersCache[folder.Full] = nil
+
+		// QueueActions for the folder
+		d.ExecuteActions(hooks.NewActionInfoForDeleted(folder.Full, true), actions)
+	}
+
+	return entry, common.NewQuotaUsage(trashingFolders), nil
+}
+
+func (d *dfs) deleteFolderContent(fullPath string, killZombies bool, foldersCache map[string]*common.Folder) (common.QuotaUsage, error) {
 	deletingFolders, err := d.metadata.ChildrenTree(fullPath, true, true)
 	if err != nil {
 		if err == os.ErrNotExist {
-			return errors.ErrRepair
+			return common.QuotaUsage{}, errors.ErrRepair
 		}
-		return err
+		return common.QuotaUsage{}, err
 	}
+	usage := common.NewQuotaUsage(deletingFolders)
 
 	searchForFolderFunc := func(fullPath string) *common.Folder {
 		for _, folder := range deletingFolders {
@@ -54,7 +122,7 @@ func (d *dfs) deleteFolderContent(fullPath string, killZombies bool, foldersCach
 
 	for _, folder := range deletingFolders {
 		if folder.Locked() {
-			return errors.ErrLock
+			return common.QuotaUsage{}, errors.ErrLock
 		}
 
 		actions := d.compileHookActions(folder.Full, hooks.Deleted)
@@ -65,7 +133,17 @@ func (d *dfs) deleteFolderContent(fullPath string, killZombies bool, foldersCach
 			if err := folder.DeleteFile(file.Name, func(file *common.File) error {
 				return d.deleteFileChunks(file, killZombies)
 			}); err != nil {
-				return err
+				return common.QuotaUsage{}, err
+			}
+		}
+
+		for len(folder.Versions) > 0 {
+			version := folder.Versions[0]
+
+			if err := folder.DeleteFileVersions(version.File.Name, nil, nil, func(version *common.FileVersion) error {
+				return d.deleteFileChunks(version.File, killZombies)
+			}); err != nil {
+				return common.QuotaUsage{}, err
 			}
 		}
 
@@ -84,25 +162,45 @@ func (d *dfs) deleteFolderContent(fullPath string, killZombies bool, foldersCach
 		d.ExecuteActions(hooks.NewActionInfoForDeleted(folder.Full, true), actions)
 	}
 
-	return nil
+	return usage, nil
 }
 
-func (d *dfs) deleteFile(path string, killZombies bool) error {
+func (d *dfs) deleteFile(path string, killZombies bool, precondition *Precondition) error {
 	folderPath, filename := common.Split(path)
 
-	return d.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
+	var entry *common.TrashEntry
+	var change quotaChange
+	if err := d.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
 		folder := folders[folderPath]
 		if folder == nil {
 			return false, os.ErrNotExist
 		}
 
-		return true, folder.DeleteFile(filename, func(file *common.File) error {
+		if err := precondition.validate(folder.File(filename)); err != nil {
+			return false, err
+		}
+
+		err := folder.DeleteFile(filename, func(file *common.File) error {
 			if file.Locked() {
 				return errors.ErrLock
 			}
-			err := d.deleteFileChunks(file, killZombies)
-			if err != nil {
-				return err
+			change = newQuotaChange(folderPath, common.QuotaUsage{Size: file.Size, Files: 1}, true)
+
+			switch {
+			case folder.Versioning:
+				if err := folder.ArchiveFile(file, true); err != nil {
+					return err
+				}
+			case d.trashEnabled() && !killZombies:
+				var err error
+				entry, err = common.NewTrashEntryForFile(common.Join(folder.Full, file.Name), file, d.trashRetention)
+				if err != nil {
+					return err
+				}
+			default:
+				if err := d.deleteFileChunks(file, killZombies); err != nil {
+					return err
+				}
 			}
 
 			// Handle Hook Actions
@@ -111,7 +209,18 @@ func (d *dfs) deleteFile(path string, killZombies bool) error {
 
 			return nil
 		})
-	})
+		if err == nil {
+			d.dropLocks(folders, path)
+		}
+		return true, err
+	}); err != nil {
+		return err
+	}
+
+	d.createTrashEntry(entry)
+	d.changeQuota(change)
+
+	return nil
 }
 
 func (d *dfs) deleteFileChunks(file *common.File, killZombies bool) error {
@@ -0,0 +1,177 @@
+package manager
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+)
+
+// Extract creates the folders and the files of the archive in the folder. Existent files are overwritten or
+// skipped based on overwrite. Results of the entries are returned in the report and ErrArchive is returned with
+// the report of the already extracted entries if the archive is not readable
+func (d *dfs) Extract(folderPath string, format ArchiveFormat, overwrite bool, contentReader io.Reader) (*common.ExtractionReport, error) {
+	return extract(d, folderPath, format, overwrite, contentReader)
+}
+
+// extract places the archive entries using the folder and file creation operations of the target, so the
+// access lists, the quotas and the hooks are applied per entry
+func extract(target Dfs, folderPath string, format ArchiveFormat, overwrite bool, contentReader io.Reader) (*common.ExtractionReport, error) {
+	folderPath = common.CorrectPath(folderPath)
+
+	if err := target.CreateFolder(folderPath); err != nil {
+		return nil, err
+	}
+
+	e := &extractor{
+		target:     target,
+		folderPath: folderPath,
+		overwrite:  overwrite,
+		report:     common.NewExtractionReport(),
+	}
+
+	switch format {
+	case AFZip:
+		return e.report, e.zip(contentReader)
+	case AFTarGz:
+		gr, err := gzip.NewReader(contentReader)
+		if err != nil {
+			return e.report, errors.ErrArchive
+		}
+		defer func() { _ = gr.Close() }()
+
+		return e.report, e.tar(gr)
+	}
+	return e.report, e.tar(contentReader)
+}
+
+type extractor struct {
+	target     Dfs
+	folderPath string
+	overwrite  bool
+	report     *common.ExtractionReport
+}
+
+func (e *extractor) tar(contentReader io.Reader) error {
+	tr := tar.NewReader(contentReader)
+
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.ErrArchive
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			e.folder(header.Name)
+		case tar.TypeReg:
+			e.file(header.Name, uint64(header.Size), tr)
+		case tar.TypeXGlobalHeader:
+		default:
+			e.report.Add(e.full(header.Name), "file", common.ERSkipped, errors.ErrUnsupported)
+		}
+	}
+}
+
+// zip keeps the archive in a temporary file because the entries are located using the central directory
+// at the end of the archive
+func (e *extractor) zip(contentReader io.Reader) error {
+	temp, err := os.CreateTemp("", "kertish-extract-*.zip")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+	}()
+
+	size, err := io.Copy(temp, contentReader)
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(temp, size)
+	if err != nil {
+		return errors.ErrArchive
+	}
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			e.folder(f.Name)
+			continue
+		}
+
+		if !f.Mode().IsRegular() {
+			e.report.Add(e.full(f.Name), "file", common.ERSkipped, errors.ErrUnsupported)
+			continue
+		}
+
+		fr, err := f.Open()
+		if err != nil {
+			e.report.Add(e.full(f.Name), "file", common.ERFailed, errors.ErrArchive)
+			continue
+		}
+		e.file(f.Name, f.UncompressedSize64, fr)
+		_ = fr.Close()
+	}
+
+	return nil
+}
+
+// full creates the path of the archive entry in the folder. Entry names can not point outside of the folder
+func (e *extractor) full(name string) string {
+	return common.Join(e.folderPath, path.Clean("/"+strings.ReplaceAll(name, "\\", "/")))
+}
+
+func (e *extractor) folder(name string) {
+	full := e.full(name)
+
+	if err := e.target.CreateFolder(full); err != nil {
+		e.report.Add(full, "folder", common.ERFailed, err)
+		return
+	}
+	e.report.Add(full, "folder", common.ERCreated, nil)
+}
+
+func (e *extractor) file(name string, size uint64, contentReader io.Reader) {
+	full := e.full(name)
+	if strings.Compare(full, e.folderPath) == 0 {
+		e.report.Add(full, "file", common.ERFailed, os.ErrInvalid)
+		return
+	}
+
+	mimeType := mime.TypeByExtension(path.Ext(full))
+	if len(mimeType) == 0 {
+		mimeType = "application/octet-stream"
+	}
+
+	result := common.ERCreated
+
+	// existence is checked before the content is read, so the content is still available to overwrite
+	err := e.target.CreateFile(full, mimeType, nil, nil, 0, size, false, nil, contentReader)
+	if err == os.ErrExist {
+		if !e.overwrite {
+			e.report.Add(full, "file", common.ERSkipped, err)
+			return
+		}
+
+		result = common.EROverwritten
+		err = e.target.CreateFile(full, mimeType, nil, nil, 0, size, true, nil, contentReader)
+	}
+
+	if err != nil {
+		e.report.Add(full, "file", common.ERFailed, err)
+		return
+	}
+	e.report.Add(full, "file", result, nil)
+}
@@ -0,0 +1,166 @@
+package manager
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+)
+
+// lockHolder returns the folder path that keeps the locks of the path. Root folder keeps its own locks,
+// the other folders and the files are locked in their parent folder
+func lockHolder(path string) string {
+	holder, _ := common.Split(path)
+	return holder
+}
+
+// locks collects the active locks that are kept in the folder tree of the path. If children is true, the
+// locks that are kept under the path are also collected
+func (d *dfs) locks(path string, children bool) (common.ResourceLocks, error) {
+	path = common.CorrectPath(path)
+
+	locks := make(common.ResourceLocks, 0)
+	for _, folderPath := range common.PathTree(nil, path) {
+		folders, err := d.metadata.Get([]string{folderPath})
+		if err != nil {
+			if err == os.ErrNotExist {
+				return locks, nil
+			}
+			return nil, err
+		}
+		locks = append(locks, folders[0].Locks.Active()...)
+	}
+
+	if !children {
+		return locks, nil
+	}
+
+	folders, err := d.metadata.ChildrenTree(path, false, false)
+	if err != nil {
+		if err == os.ErrNotExist {
+			return locks, nil
+		}
+		return nil, err
+	}
+	for _, folder := range folders {
+		locks = append(locks, folder.Locks.Active()...)
+	}
+
+	return locks, nil
+}
+
+// checkLocks checks the lock tokens of the precondition are sufficient to change the path. If children is
+// true, the locks under the path are also evaluated. It returns ErrLock if the path is locked
+func (d *dfs) checkLocks(path string, children bool, precondition *Precondition) error {
+	locks, err := d.locks(path, children)
+	if err != nil {
+		return err
+	}
+
+	if !locks.Allowed(common.CorrectPath(path), children, precondition.lockTokens()) {
+		return errors.ErrLock
+	}
+	return nil
+}
+
+// dropLocks removes the locks of the path and the locks under it from the lock holder folder of the path
+func (d *dfs) dropLocks(folders map[string]*common.Folder, path string) {
+	holder, has := folders[lockHolder(path)]
+	if !has || holder == nil {
+		return
+	}
+	holder.Locks = holder.Locks.Drop(path)
+}
+
+func (d *dfs) Lock(lock *common.ResourceLock) error {
+	holderPath := lockHolder(lock.Root)
+
+	locks, err := d.locks(lock.Root, lock.Infinite)
+	if err != nil {
+		return err
+	}
+
+	return d.metadata.SaveBlock([]string{holderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		holder := folders[holderPath]
+
+		// the locks of the holder folder are evaluated again in the metadata lock
+		if append(locks, holder.Locks...).Conflicts(lock) {
+			return false, errors.ErrLock
+		}
+		holder.Locks = append(holder.Locks.Active(), lock)
+
+		return true, nil
+	})
+}
+
+func (d *dfs) RefreshLock(path string, tokens []string, timeout time.Duration) (*common.ResourceLock, error) {
+	path = common.CorrectPath(path)
+
+	locks, err := d.locks(path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		lock := locks.Find(path, token)
+		if lock == nil {
+			continue
+		}
+
+		holderPath := lockHolder(lock.Root)
+		if err := d.metadata.SaveBlock([]string{holderPath}, func(folders map[string]*common.Folder) (bool, error) {
+			lock = folders[holderPath].Locks.Find(lock.Root, token)
+			if lock == nil {
+				return false, os.ErrNotExist
+			}
+			lock.Refresh(timeout)
+
+			return true, nil
+		}); err != nil {
+			return nil, err
+		}
+		return lock, nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func (d *dfs) Unlock(path string, token string) error {
+	path = common.CorrectPath(path)
+
+	locks, err := d.locks(path, false)
+	if err != nil {
+		return err
+	}
+
+	lock := locks.Find(path, token)
+	if lock == nil {
+		return os.ErrNotExist
+	}
+
+	holderPath := lockHolder(lock.Root)
+	return d.metadata.SaveBlock([]string{holderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		holder := folders[holderPath]
+		active := holder.Locks.Active()
+
+		remaining := make(common.ResourceLocks, 0)
+		for _, l := range active {
+			if strings.Compare(l.Token, token) == 0 {
+				continue
+			}
+			remaining = append(remaining, l)
+		}
+		if len(remaining) == len(active) {
+			return false, os.ErrNotExist
+		}
+		holder.Locks = remaining
+
+		return true, nil
+	})
+}
+
+func (d *dfs) Locks(path string) (common.ResourceLocks, error) {
+	return d.locks(path, false)
+}
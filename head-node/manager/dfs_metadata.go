@@ -0,0 +1,57 @@
+package manager
+
+import (
+	"os"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+)
+
+// UpdateMetadata changes the user-defined metadata of the file without touching the content. Metadata is merged
+// with the existent one and the keys with empty value are removed, if replace is set, it is replaced as a whole
+func (d *dfs) UpdateMetadata(path string, metadata common.Metadata, replace bool, precondition *Precondition) error {
+	folderPath, filename := common.Split(path)
+	if len(filename) == 0 {
+		return os.ErrInvalid
+	}
+
+	if err := d.checkLocks(path, false, precondition); err != nil {
+		return err
+	}
+
+	return d.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[folderPath]
+		if folder == nil {
+			return false, os.ErrNotExist
+		}
+
+		file := folder.File(filename)
+		if file == nil {
+			return false, os.ErrNotExist
+		}
+
+		if err := precondition.validate(file); err != nil {
+			return false, err
+		}
+
+		if file.Locked() {
+			return false, errors.ErrLock
+		}
+
+		if replace {
+			metadata = metadata.Merge(nil)
+		} else {
+			metadata = file.Metadata.Merge(metadata)
+		}
+
+		if !metadata.Valid() {
+			return false, os.ErrInvalid
+		}
+
+		file.Metadata = metadata
+		file.Modified = time.Now().UTC()
+
+		return true, nil
+	})
+}
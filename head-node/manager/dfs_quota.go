@@ -0,0 +1,326 @@
+package manager
+
+import (
+	"os"
+	"sort"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/hooks"
+	"go.uber.org/zap"
+)
+
+// quotaChange is the usage difference that an operation causes in the folder and its parents
+type quotaChange struct {
+	folderPath string
+	size       int64
+	files      int64
+
+	quota *common.Quota
+}
+
+func newQuotaChange(folderPath string, usage common.QuotaUsage, decrease bool) quotaChange {
+	change := quotaChange{
+		folderPath: folderPath,
+		size:       int64(usage.Size),
+		files:      int64(usage.Files),
+	}
+	if decrease {
+		change.size = -change.size
+		change.files = -change.files
+	}
+	return change
+}
+
+// newFileQuotaChange creates the change of placing the content with size in place of the current file
+func newFileQuotaChange(folderPath string, current *common.File, size uint64) quotaChange {
+	if current == nil {
+		return quotaChange{folderPath: folderPath, size: int64(size), files: 1}
+	}
+	return quotaChange{folderPath: folderPath, size: int64(size) - int64(current.Size)}
+}
+
+// reverseQuotaChanges creates the changes that revert the applied changes
+func reverseQuotaChanges(changes []quotaChange) []quotaChange {
+	reversed := make([]quotaChange, 0)
+	for _, change := range changes {
+		change.size = -change.size
+		change.files = -change.files
+		reversed = append(reversed, change)
+	}
+	return reversed
+}
+
+// Quota gets the quota of the folder. It returns ErrNotExist if the folder does not have quota
+func (d *dfs) Quota(folderPath string) (*common.Quota, error) {
+	folderPath = common.CorrectPath(folderPath)
+
+	folders, err := d.metadata.Get([]string{folderPath})
+	if err != nil {
+		return nil, err
+	}
+
+	if folders[0].Quota == nil {
+		return nil, os.ErrNotExist
+	}
+	return folders[0].Quota, nil
+}
+
+// SetQuota attaches the quota to the folder or changes the limits of the existent one. The usage is
+// calculated only when the quota is attached and kept up to date with the changes later.
+// nil quota detaches the quota of the folder
+func (d *dfs) SetQuota(folderPath string, quota *common.Quota) error {
+	folderPath = common.CorrectPath(folderPath)
+
+	return d.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[folderPath]
+		if folder == nil {
+			return false, os.ErrNotExist
+		}
+
+		if quota == nil {
+			if folder.Quota == nil {
+				return false, os.ErrNotExist
+			}
+			folder.Quota = nil
+
+			return true, nil
+		}
+
+		if folder.Quota != nil {
+			quota.Usage = folder.Quota.Usage
+			folder.Quota = quota
+
+			return true, nil
+		}
+
+		children, err := d.metadata.ChildrenTree(folderPath, true, false)
+		if err != nil {
+			return false, err
+		}
+		quota.Usage = common.NewQuotaUsage(children)
+		folder.Quota = quota
+
+		return true, nil
+	})
+}
+
+// checkQuota validates the changes against the hard limits of the quotas in the folder trees.
+// It does not lock the folders, so it is safe to be called in the metadata save handlers. Use reserveQuota
+// when the usage should be applied with the validation
+func (d *dfs) checkQuota(changes ...quotaChange) error {
+	quotaChanges, err := d.groupQuotaChanges(changes)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range quotaChanges {
+		if change.quota.Exceeds(change.size, change.files) {
+			return errors.ErrQuota
+		}
+	}
+	return nil
+}
+
+// changeQuota applies the changes to the quota usages in the folder trees and executes the quota hooks of
+// the folders that have just passed their soft limits. The operation is already completed when it is called,
+// so the failures are only logged
+func (d *dfs) changeQuota(changes ...quotaChange) {
+	if err := d.saveQuota(false, changes...); err != nil {
+		d.logger.Error(
+			"Updating quota usage is failed. Set the quota again to recalculate the usage",
+			zap.Error(err),
+		)
+	}
+}
+
+// reserveQuota validates the changes against the hard limits of the quotas and applies them to the usages in the
+// same lock, so the concurrent operations can not pass the limits together. Reserved usage should be adjusted
+// with changeQuota when the operation is completed or failed
+func (d *dfs) reserveQuota(changes ...quotaChange) error {
+	return d.saveQuota(true, changes...)
+}
+
+func (d *dfs) saveQuota(check bool, changes ...quotaChange) error {
+	folderPaths := make([]string, 0)
+	for _, change := range changes {
+		if change.size == 0 && change.files == 0 {
+			continue
+		}
+		folderPaths = append(folderPaths, change.folderPath)
+	}
+
+	quotaPaths, err := d.quotaPaths(folderPaths...)
+	if err != nil || len(quotaPaths) == 0 {
+		return err
+	}
+
+	softExceededPaths := make([]string, 0)
+	if err := d.metadata.SaveBlock(quotaPaths, func(folders map[string]*common.Folder) (bool, error) {
+		if check {
+			if err := d.checkLockedQuota(folders, changes...); err != nil {
+				return false, err
+			}
+		}
+		softExceededPaths = d.applyLockedQuota(folders, changes...)
+
+		return true, nil
+	}); err != nil {
+		return err
+	}
+	d.executeQuotaHooks(softExceededPaths)
+
+	return nil
+}
+
+// quotaPaths finds the folders that have quota in the folder trees in the lock order. Folders are read without
+// the lock to decide the folders to lock, the quotas are evaluated again in the lock with checkLockedQuota and
+// applyLockedQuota
+func (d *dfs) quotaPaths(folderPaths ...string) ([]string, error) {
+	changes := make([]quotaChange, 0)
+	for _, folderPath := range folderPaths {
+		changes = append(changes, quotaChange{folderPath: folderPath, files: 1})
+	}
+
+	quotaChanges, err := d.groupQuotaChanges(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	quotaPaths := make([]string, 0)
+	for folderPath := range quotaChanges {
+		quotaPaths = append(quotaPaths, folderPath)
+	}
+	sort.Strings(quotaPaths)
+
+	return quotaPaths, nil
+}
+
+// quotaLockPaths creates the folder tree of the folder path and returns the paths to lock the folder together with
+// the folders that have quota in the folder trees of the changes in the lock order
+func (d *dfs) quotaLockPaths(folderPath string, changes ...quotaChange) ([]string, error) {
+	if err := d.metadata.SaveChain(folderPath, func(_ *common.Folder) (bool, error) {
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	folderPaths := make([]string, 0)
+	for _, change := range changes {
+		if change.size == 0 && change.files == 0 {
+			continue
+		}
+		folderPaths = append(folderPaths, change.folderPath)
+	}
+
+	quotaPaths, err := d.quotaPaths(folderPaths...)
+	if err != nil {
+		return nil, err
+	}
+	lockPaths := append(quotaPaths, folderPath)
+	sort.Strings(lockPaths)
+
+	return lockPaths, nil
+}
+
+// lockedQuotaChanges sums the changes for the locked folders that have quota
+func (d *dfs) lockedQuotaChanges(folders map[string]*common.Folder, changes []quotaChange) map[string]*quotaChange {
+	quotaChanges := make(map[string]*quotaChange)
+
+	for _, change := range changes {
+		if change.size == 0 && change.files == 0 {
+			continue
+		}
+
+		for _, folderPath := range common.PathTree(nil, change.folderPath) {
+			folder := folders[folderPath]
+			if folder == nil || folder.Quota == nil {
+				continue
+			}
+
+			group, has := quotaChanges[folderPath]
+			if !has {
+				group = &quotaChange{folderPath: folderPath, quota: folder.Quota}
+				quotaChanges[folderPath] = group
+			}
+			group.size += change.size
+			group.files += change.files
+		}
+	}
+
+	return quotaChanges
+}
+
+// checkLockedQuota validates the changes against the hard limits of the quotas of the locked folders
+func (d *dfs) checkLockedQuota(folders map[string]*common.Folder, changes ...quotaChange) error {
+	for _, change := range d.lockedQuotaChanges(folders, changes) {
+		if change.quota.Exceeds(change.size, change.files) {
+			return errors.ErrQuota
+		}
+	}
+	return nil
+}
+
+// applyLockedQuota applies the changes to the quota usages of the locked folders. It returns the paths of
+// the folders that have just passed their soft limits
+func (d *dfs) applyLockedQuota(folders map[string]*common.Folder, changes ...quotaChange) []string {
+	softExceededPaths := make([]string, 0)
+	for folderPath, change := range d.lockedQuotaChanges(folders, changes) {
+		if change.quota.Change(change.size, change.files) {
+			softExceededPaths = append(softExceededPaths, folderPath)
+		}
+	}
+	return softExceededPaths
+}
+
+func (d *dfs) executeQuotaHooks(softExceededPaths []string) {
+	for _, folderPath := range softExceededPaths {
+		actions := d.compileHookActions(folderPath, hooks.Quota)
+		d.ExecuteActions(hooks.NewActionInfoForQuota(folderPath), actions)
+	}
+}
+
+// groupQuotaChanges sums the changes for the folders that have quota in the folder trees of the changes
+func (d *dfs) groupQuotaChanges(changes []quotaChange) (map[string]*quotaChange, error) {
+	foldersCache := make(map[string]*common.Folder)
+	quotaChanges := make(map[string]*quotaChange)
+
+	for _, change := range changes {
+		if change.size == 0 && change.files == 0 {
+			continue
+		}
+
+		for _, folderPath := range common.PathTree(nil, change.folderPath) {
+			folder, has := foldersCache[folderPath]
+			if !has {
+				folders, err := d.metadata.Get([]string{folderPath})
+				if err != nil && err != os.ErrNotExist {
+					return nil, err
+				}
+				if err == nil {
+					folder = folders[0]
+				}
+				foldersCache[folderPath] = folder
+			}
+
+			// rest of the tree does not exist yet
+			if folder == nil {
+				break
+			}
+
+			if folder.Quota == nil {
+				continue
+			}
+
+			group, has := quotaChanges[folderPath]
+			if !has {
+				group = &quotaChange{folderPath: folderPath, quota: folder.Quota}
+				quotaChanges[folderPath] = group
+			}
+			group.size += change.size
+			group.files += change.files
+		}
+	}
+
+	return quotaChanges, nil
+}
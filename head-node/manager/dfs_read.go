@@ -16,6 +16,7 @@ func (d *dfs) Read(paths []string, join bool) (ReadContainer, error) {
 	if len(paths) == 1 {
 		folder, err := d.folder(paths[0])
 		if err == nil {
+			folder.DropExpiredFiles()
 			return newReadContainerForFolder(folder, d.tree), nil
 		}
 
@@ -71,7 +72,7 @@ func (d *dfs) file(paths []string) (*common.File, func(w io.Writer, begins int64
 		}
 
 		file := folders[0].File(filename)
-		if file == nil {
+		if file == nil || file.Expired() {
 			return nil, nil, os.ErrNotExist
 		}
 
@@ -0,0 +1,148 @@
+package manager
+
+import (
+	"encoding/base64"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+// searchKey is the position of the search entry in the folder tree. Folder entries are positioned
+// in their own folder with an empty name, so they are listed before their files
+type searchKey struct {
+	folder string
+	name   string
+}
+
+func (s searchKey) less(other searchKey) bool {
+	if c := strings.Compare(s.folder, other.folder); c != 0 {
+		return c < 0
+	}
+	return strings.Compare(s.name, other.name) < 0
+}
+
+func (s searchKey) token() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s.folder + "\x00" + s.name))
+}
+
+func parseSearchToken(token string) (*searchKey, error) {
+	if len(token) == 0 {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, os.ErrInvalid
+	}
+
+	parts := strings.SplitN(string(b), "\x00", 2)
+	if len(parts) != 2 {
+		return nil, os.ErrInvalid
+	}
+	return &searchKey{folder: parts[0], name: parts[1]}, nil
+}
+
+type searchCandidate struct {
+	key   searchKey
+	entry *common.SearchEntry
+}
+
+// Search walks the folder tree of the query path and returns the page of the matching folders and files
+// that are following the continuation token
+func (d *dfs) Search(query *SearchQuery, continuation string, limit int) (*common.SearchResult, error) {
+	if limit < 1 {
+		return nil, os.ErrInvalid
+	}
+
+	after, err := parseSearchToken(continuation)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := common.CorrectPath(query.Path)
+
+	folders, err := d.searchFolders(basePath, query)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]searchCandidate, 0)
+	for _, folder := range folders {
+		if strings.Compare(folder.Full, basePath) != 0 {
+			entry := common.NewSearchEntryForFolder(folder)
+			if query.match(entry) {
+				candidates = append(candidates, searchCandidate{key: searchKey{folder: folder.Full}, entry: entry})
+			}
+		}
+
+		if !query.Recursive && strings.Compare(folder.Full, basePath) != 0 {
+			continue
+		}
+
+		for _, file := range folder.Files {
+			if file.Expired() {
+				continue
+			}
+
+			entry := common.NewSearchEntryForFile(folder.Full, file)
+			if query.match(entry) {
+				candidates = append(candidates, searchCandidate{key: searchKey{folder: folder.Full, name: file.Name}, entry: entry})
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].key.less(candidates[j].key) })
+
+	result := &common.SearchResult{
+		Entries: make(common.SearchEntries, 0),
+	}
+	for i, candidate := range candidates {
+		if after != nil && !after.less(candidate.key) {
+			continue
+		}
+
+		if len(result.Entries) == limit {
+			result.Next = candidates[i-1].key.token()
+			break
+		}
+		result.Entries = append(result.Entries, candidate.entry)
+	}
+
+	return result, nil
+}
+
+// searchFolders gets the folders that the search will walk on. The query path is always the first folder
+func (d *dfs) searchFolders(basePath string, query *SearchQuery) ([]*common.Folder, error) {
+	if query.Recursive {
+		folders, err := d.metadata.ChildrenTree(basePath, true, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(folders) == 0 || strings.Compare(folders[0].Full, basePath) != 0 {
+			return nil, os.ErrNotExist
+		}
+		return folders, nil
+	}
+
+	folders, err := d.metadata.Get([]string{basePath})
+	if err != nil {
+		return nil, err
+	}
+
+	if query.fileOnly() || len(folders[0].Folders) == 0 {
+		return folders, nil
+	}
+
+	subFolderPaths := make([]string, 0)
+	for _, shadow := range folders[0].Folders {
+		subFolderPaths = append(subFolderPaths, shadow.Full)
+	}
+
+	subFolders, err := d.metadata.Get(subFolderPaths)
+	if err != nil {
+		return nil, err
+	}
+	return append(folders, subFolders...), nil
+}
@@ -0,0 +1,225 @@
+package manager
+
+import (
+	"os"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/hooks"
+	"go.uber.org/zap"
+)
+
+const trashExpiryInterval = time.Minute * 10
+
+func (d *dfs) trashEnabled() bool {
+	return d.trashRetention > 0
+}
+
+// createTrashEntry keeps the entry after the metadata is saved. If it fails, the chunks of the entry are not
+// referenced anymore and the repair operation will clean them up
+func (d *dfs) createTrashEntry(entry *common.TrashEntry) {
+	if entry == nil {
+		return
+	}
+
+	if err := d.trash.Create(entry); err != nil {
+		d.logger.Error(
+			"Keeping the deleted entry in the trash is failed",
+			zap.String("path", entry.Path),
+			zap.Error(err),
+		)
+	}
+}
+
+// ListTrash gets the trash entries from the newest deletion to the oldest
+func (d *dfs) ListTrash() (common.TrashEntries, error) {
+	return d.trash.List()
+}
+
+// RestoreTrash places the folder or the file back to its original path. If there is a folder or a file in the
+// same path, it will return ErrExist error
+func (d *dfs) RestoreTrash(entryId string) error {
+	return d.trash.Delete(entryId, func(entry *common.TrashEntry) error {
+		if err := d.checkLocks(entry.Path, false, nil); err != nil {
+			return err
+		}
+
+		if entry.Folder {
+			return d.restoreTrashFolder(entry)
+		}
+		return d.restoreTrashFile(entry)
+	})
+}
+
+func (d *dfs) restoreTrashFile(entry *common.TrashEntry) error {
+	if entry.File == nil {
+		return errors.ErrRepair
+	}
+	folderPath, filename := common.Split(entry.Path)
+	change := newFileQuotaChange(folderPath, nil, entry.File.Size)
+
+	lockPaths, err := d.quotaLockPaths(folderPath, change)
+	if err != nil {
+		return err
+	}
+
+	var softExceededPaths []string
+	if err := d.metadata.SaveBlock(lockPaths, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[folderPath]
+		if folder.File(filename) != nil || folder.Folder(filename) != nil {
+			return false, os.ErrExist
+		}
+
+		if err := d.checkLockedQuota(folders, change); err != nil {
+			return false, err
+		}
+
+		d.keepExpiredFile(entry.File)
+		folder.ReplaceFile(filename, entry.File)
+		softExceededPaths = d.applyLockedQuota(folders, change)
+
+		actions := d.compileHookActions(folderPath, hooks.Created)
+		d.ExecuteActions(hooks.NewActionInfoForCreated(entry.Path, false), actions)
+
+		return true, nil
+	}); err != nil {
+		return err
+	}
+	d.executeQuotaHooks(softExceededPaths)
+
+	return nil
+}
+
+func (d *dfs) restoreTrashFolder(entry *common.TrashEntry) error {
+	if len(entry.Folders) == 0 {
+		return errors.ErrRepair
+	}
+	parentPath, name := common.Split(entry.Path)
+
+	parentFolders, err := d.metadata.Get([]string{parentPath})
+	if err != nil && err != os.ErrNotExist {
+		return err
+	}
+	if parentFolders != nil && parentFolders[0].File(name) != nil {
+		return os.ErrExist
+	}
+
+	// quota of the folder is restored with its usage, only the parent folder tree is changing
+	change := newQuotaChange(parentPath, common.NewQuotaUsage(entry.Folders), false)
+
+	lockPaths, err := d.quotaLockPaths(entry.Path, change)
+	if err != nil {
+		return err
+	}
+
+	var softExceededPaths []string
+	if err := d.metadata.SaveBlock(lockPaths, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[entry.Path]
+		if len(folder.Folders) > 0 || len(folder.Files) > 0 || len(folder.Versions) > 0 {
+			return false, os.ErrExist
+		}
+
+		if err := d.checkLockedQuota(folders, change); err != nil {
+			return false, err
+		}
+
+		deletedFolder := entry.Folders[0]
+		for _, file := range deletedFolder.Files {
+			d.keepExpiredFile(file)
+		}
+
+		folder.Created = deletedFolder.Created
+		folder.Folders = deletedFolder.Folders
+		folder.Files = deletedFolder.Files
+		folder.Hooks = deletedFolder.Hooks
+		folder.Versioning = deletedFolder.Versioning
+		folder.Versions = deletedFolder.Versions
+		folder.Ttl = deletedFolder.Ttl
+		folder.Quota = deletedFolder.Quota
+		folder.Acl = deletedFolder.Acl
+		softExceededPaths = d.applyLockedQuota(folders, change)
+
+		return true, nil
+	}); err != nil {
+		return err
+	}
+	d.executeQuotaHooks(softExceededPaths)
+
+	if err := d.metadata.SaveBlock([]string{entry.Path}, func(folders map[string]*common.Folder) (bool, error) {
+		for _, subFolder := range entry.Folders[1:] {
+			for _, file := range subFolder.Files {
+				d.keepExpiredFile(file)
+			}
+			subFolder.Locks = nil
+			folders[subFolder.Full] = subFolder
+		}
+		return true, nil
+	}); err != nil {
+		d.changeQuota(reverseQuotaChanges([]quotaChange{change})...)
+		return err
+	}
+
+	actions := d.compileHookActions(entry.Path, hooks.Created)
+	d.ExecuteActions(hooks.NewActionInfoForCreated(entry.Path, true), actions)
+
+	return nil
+}
+
+// keepExpiredFile removes the expiry of the restoring file if its time-to-live is over,
+// otherwise it would be deleted again right after the restore
+func (d *dfs) keepExpiredFile(file *common.File) {
+	if file.Expired() {
+		file.Expires = nil
+	}
+}
+
+// PurgeTrash drops the trash entry and releases the chunks of it
+func (d *dfs) PurgeTrash(entryId string) error {
+	return d.trash.Delete(entryId, func(entry *common.TrashEntry) error {
+		for _, file := range entry.Files() {
+			if err := d.deleteFileChunks(file, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// EmptyTrash drops all the trash entries and releases the chunks of them
+func (d *dfs) EmptyTrash() error {
+	entries, err := d.trash.List()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := d.PurgeTrash(entry.Id); err != nil && err != os.ErrNotExist {
+			return err
+		}
+	}
+	return nil
+}
+
+// expireTrash drops the trash entries that are out of the retention period
+func (d *dfs) expireTrash() {
+	for {
+		time.Sleep(trashExpiryInterval)
+
+		entryIds, err := d.trash.Expired()
+		if err != nil {
+			d.logger.Error("Unable to get expired trash entries", zap.Error(err))
+			continue
+		}
+
+		for _, entryId := range entryIds {
+			if err := d.PurgeTrash(entryId); err != nil && err != os.ErrNotExist {
+				d.logger.Error(
+					"Purging expired trash entry is failed",
+					zap.String("entryId", entryId),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package manager
+
+import (
+	"os"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"go.uber.org/zap"
+)
+
+const fileExpiryInterval = time.Minute
+
+// UpdateTimeToLive sets the expiry of the file or the default time-to-live of the files that will be placed
+// in the folder. Zero ttl removes the expiry
+func (d *dfs) UpdateTimeToLive(path string, ttl time.Duration, precondition *Precondition) error {
+	if ttl < 0 {
+		return os.ErrInvalid
+	}
+
+	if err := d.checkLocks(path, false, precondition); err != nil {
+		return err
+	}
+
+	if err := d.updateFolderTimeToLive(path, ttl, precondition); err != nil {
+		if err != os.ErrNotExist {
+			return err
+		}
+		return d.updateFileTimeToLive(path, ttl, precondition)
+	}
+	return nil
+}
+
+func (d *dfs) updateFolderTimeToLive(folderPath string, ttl time.Duration, precondition *Precondition) error {
+	folderPath = common.CorrectPath(folderPath)
+
+	return d.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[folderPath]
+		if folder == nil {
+			return false, os.ErrNotExist
+		}
+
+		// folders do not have entity tag and modification tracking for the preconditions
+		if !precondition.empty() {
+			return false, errors.ErrPrecondition
+		}
+
+		folder.Ttl = uint64(ttl / time.Second)
+
+		return true, nil
+	})
+}
+
+func (d *dfs) updateFileTimeToLive(path string, ttl time.Duration, precondition *Precondition) error {
+	folderPath, filename := common.Split(path)
+	if len(filename) == 0 {
+		return os.ErrInvalid
+	}
+
+	return d.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[folderPath]
+		if folder == nil {
+			return false, os.ErrNotExist
+		}
+
+		file := folder.File(filename)
+		if file == nil || file.Expired() {
+			return false, os.ErrNotExist
+		}
+
+		if err := precondition.validate(file); err != nil {
+			return false, err
+		}
+
+		if file.Locked() {
+			return false, errors.ErrLock
+		}
+
+		file.Expires = nil
+		if ttl > 0 {
+			expires := time.Now().UTC().Add(ttl)
+			file.Expires = &expires
+		}
+		file.Modified = time.Now().UTC()
+
+		return true, nil
+	})
+}
+
+// expireFiles deletes the files that are out of their time-to-live. Deletion follows the regular flow,
+// so the hooks are executed and the files are moved to the trash or kept as versions
+func (d *dfs) expireFiles() {
+	for {
+		time.Sleep(fileExpiryInterval)
+
+		// files that are overwritten after the query should not be deleted
+		queried := time.Now().UTC()
+		precondition := NewPrecondition(nil, &queried)
+
+		paths, err := d.metadata.Expired()
+		if err != nil {
+			d.logger.Error("Unable to get expired files", zap.Error(err))
+			continue
+		}
+
+		for _, path := range paths {
+			if err := d.Delete(path, false, precondition); err != nil && err != os.ErrNotExist && err != errors.ErrLock && err != errors.ErrPrecondition {
+				d.logger.Error(
+					"Deleting expired file is failed",
+					zap.String("path", path),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
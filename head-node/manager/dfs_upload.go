@@ -0,0 +1,309 @@
+package manager
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"go.uber.org/zap"
+)
+
+const uploadExpiryInterval = time.Minute * 10
+
+func (d *dfs) CreateUpload(path string, mime string, metadata common.Metadata, ttl *time.Duration, blockSize uint32, size uint64, overwrite bool) (*common.UploadSession, error) {
+	path = common.CorrectPath(path)
+
+	folderPath, filename := common.Split(path)
+	if len(filename) == 0 || size == 0 {
+		return nil, os.ErrInvalid
+	}
+
+	if err := common.ValidateBlockSize(blockSize); err != nil {
+		return nil, err
+	}
+
+	folders, err := d.metadata.Get([]string{folderPath})
+	if err != nil && err != os.ErrNotExist {
+		return nil, err
+	}
+
+	var file *common.File
+	if err == nil {
+		file = folders[0].File(filename)
+
+		if blockSize == 0 {
+			blockSize = folders[0].BlockSize
+		}
+	}
+
+	if !overwrite && file != nil {
+		return nil, os.ErrExist
+	}
+
+	// quota is validated again on commit, it is just to avoid the upload that is going to be rejected
+	if err := d.checkQuota(newFileQuotaChange(folderPath, file, size)); err != nil {
+		return nil, err
+	}
+
+	reservation, err := d.cluster.Reserve(size, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := common.NewUploadSession(path, mime, metadata, size, overwrite, reservation)
+	if err == nil {
+		session.Ttl = ttl
+		err = d.uploads.Create(session)
+	}
+	if err != nil {
+		d.discardReservation(reservation.Id)
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (d *dfs) ReadUpload(sessionId string) (*common.UploadSession, error) {
+	session, err := d.uploads.Get(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if session.Expired() {
+		return nil, os.ErrNotExist
+	}
+	return session, nil
+}
+
+func (d *dfs) UploadPart(sessionId string, offset uint64, size uint64, contentReader io.Reader) error {
+	session, err := d.ReadUpload(sessionId)
+	if err != nil {
+		return err
+	}
+
+	part := session.Part(offset)
+	if part == nil || uint64(part.Size) != size {
+		return os.ErrInvalid
+	}
+
+	clusterMap := session.ClusterMap(part.Sequence)
+	if clusterMap == nil {
+		return errors.ErrRepair
+	}
+
+	buffer := make([]byte, part.Size)
+	if _, err := io.ReadFull(contentReader, buffer); err != nil {
+		return err
+	}
+
+	dataChunk, clusterUsageMap, err := d.cluster.CreateChunk(session.Reservation.Id, *clusterMap, bytes.NewReader(buffer))
+	if err != nil {
+		return err
+	}
+
+	// every chunk creation increases the chunk usage in the data node even the content is the same,
+	// so the chunk of the previous upload of the part should be released
+	var releasingChunk *common.DataChunk
+
+	if err := d.uploads.Save(sessionId, func(session *common.UploadSession) (bool, error) {
+		if session.Expired() {
+			return false, os.ErrNotExist
+		}
+
+		part := session.Part(offset)
+		if part.Received {
+			releasingChunk = common.NewDataChunk(part.Sequence, part.Size, part.Hash)
+
+			if strings.Compare(part.Hash, dataChunk.Hash) != 0 {
+				part.Usage = clusterUsageMap
+
+				// the content is changed after it is included in the checksum calculation
+				if part.Index < session.HashOffset {
+					session.HashState = nil
+					session.HashOffset = 0
+				}
+			}
+		} else {
+			part.Usage = clusterUsageMap
+		}
+		part.Received = true
+		part.Hash = dataChunk.Hash
+
+		if part.Index == session.HashOffset {
+			d.updateUploadHash(session, buffer)
+		}
+
+		return true, nil
+	}); err != nil {
+		d.releaseChunks(common.DataChunks{dataChunk})
+		return err
+	}
+
+	if releasingChunk != nil {
+		d.releaseChunks(common.DataChunks{releasingChunk})
+	}
+
+	return nil
+}
+
+func (d *dfs) CommitUpload(sessionId string, precondition *Precondition) error {
+	return d.uploads.Delete(sessionId, func(session *common.UploadSession) error {
+		if session.Expired() {
+			return os.ErrNotExist
+		}
+
+		if !session.Completed() {
+			return errors.ErrIncomplete
+		}
+
+		chunks := session.Chunks()
+
+		checksum, err := d.uploadChecksum(session, chunks)
+		if err != nil {
+			return err
+		}
+
+		file, _, err := d.prepareFile(session.Path, common.NewFileLockForSize(session.Size), session.Size, session.Ttl, 0, session.Overwrite, precondition)
+		if err != nil {
+			return err
+		}
+
+		if err := d.completeFile(session.Path, session.Mime, session.Metadata, session.Size, session.Size, file, common.NewCreationResult(checksum, chunks)); err != nil {
+			return err
+		}
+
+		if err := d.cluster.Commit(session.Reservation.Id, session.Usage()); err != nil {
+			d.logger.Error(
+				"Committing reservationMap is failed",
+				zap.String("reservationId", session.Reservation.Id),
+				zap.Error(err),
+			)
+		}
+
+		return nil
+	})
+}
+
+func (d *dfs) DiscardUpload(sessionId string) error {
+	return d.uploads.Delete(sessionId, func(session *common.UploadSession) error {
+		d.dropUpload(session)
+		return nil
+	})
+}
+
+// uploadHash restores the checksum calculation of the upload session
+func (d *dfs) uploadHash(session *common.UploadSession) hash.Hash {
+	sha512Hash := sha512.New512_256()
+	if len(session.HashState) == 0 {
+		session.HashOffset = 0
+		return sha512Hash
+	}
+
+	if err := sha512Hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(session.HashState); err != nil {
+		session.HashState = nil
+		session.HashOffset = 0
+		return sha512.New512_256()
+	}
+	return sha512Hash
+}
+
+// updateUploadHash ingests the part content to the checksum calculation of the upload session
+// when the content is following the calculated part
+func (d *dfs) updateUploadHash(session *common.UploadSession, data []byte) {
+	sha512Hash := d.uploadHash(session)
+	_, _ = sha512Hash.Write(data)
+
+	hashState, err := sha512Hash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return
+	}
+	session.HashState = hashState
+	session.HashOffset += uint64(len(data))
+}
+
+// uploadChecksum completes the checksum calculation of the upload session by reading the
+// rest of the content that is not received in order
+func (d *dfs) uploadChecksum(session *common.UploadSession, chunks common.DataChunks) (string, error) {
+	sha512Hash := d.uploadHash(session)
+
+	if session.HashOffset < session.Size {
+		streamHandler, err := d.cluster.Read(chunks)
+		if err != nil {
+			return "", err
+		}
+
+		if err := streamHandler(sha512Hash, int64(session.HashOffset), -1); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(sha512Hash.Sum(nil)), nil
+}
+
+// dropUpload releases the uploaded chunks and the cluster reservation of the upload session
+func (d *dfs) dropUpload(session *common.UploadSession) {
+	d.releaseChunks(session.Chunks())
+	d.discardReservation(session.Reservation.Id)
+}
+
+func (d *dfs) releaseChunks(chunks common.DataChunks) {
+	if len(chunks) == 0 {
+		return
+	}
+
+	deletionResult, err := d.cluster.Delete(chunks)
+	if err != nil {
+		d.logger.Error(
+			"Releasing uploaded chunks is failed, orphan chunks may appear. Run repair to eliminate",
+			zap.Error(err),
+		)
+		return
+	}
+
+	if len(deletionResult.Untouched) > 0 {
+		d.logger.Warn(
+			"Some of the uploaded chunks are not released, orphan chunks may appear. Run repair to eliminate",
+			zap.Strings("sha512Hex", deletionResult.Untouched),
+		)
+	}
+}
+
+func (d *dfs) discardReservation(reservationId string) {
+	if err := d.cluster.Discard(reservationId); err != nil {
+		d.logger.Error(
+			"Discarding reservationMap is failed",
+			zap.String("reservationId", reservationId),
+			zap.Error(err),
+		)
+	}
+}
+
+// expireUploads drops the upload sessions that are not committed in time
+func (d *dfs) expireUploads() {
+	for {
+		time.Sleep(uploadExpiryInterval)
+
+		sessionIds, err := d.uploads.Expired()
+		if err != nil {
+			d.logger.Error("Unable to get expired upload sessions", zap.Error(err))
+			continue
+		}
+
+		for _, sessionId := range sessionIds {
+			if err := d.DiscardUpload(sessionId); err != nil && err != os.ErrNotExist {
+				d.logger.Error(
+					"Dropping expired upload session is failed",
+					zap.String("sessionId", sessionId),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package manager
+
+import (
+	"os"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/basics/hooks"
+)
+
+// SetVersioning enables or disables keeping the previous states of the overwritten and deleted files
+// in the folder. Disabling does not drop the existent versions, they should be purged explicitly
+func (d *dfs) SetVersioning(folderPath string, enabled bool) error {
+	folderPath = common.CorrectPath(folderPath)
+
+	return d.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[folderPath]
+		if folder == nil {
+			return false, os.ErrNotExist
+		}
+
+		if folder.Versioning == enabled {
+			return false, nil
+		}
+		folder.Versioning = enabled
+
+		return true, nil
+	})
+}
+
+// Versions gets the versions of the file from the newest to the oldest. Deleted files can still have versions
+func (d *dfs) Versions(path string) (common.FileVersions, error) {
+	folderPath, filename := common.Split(path)
+	if len(filename) == 0 {
+		return nil, os.ErrInvalid
+	}
+
+	folders, err := d.metadata.Get([]string{folderPath})
+	if err != nil {
+		return nil, err
+	}
+	folder := folders[0]
+
+	versions := folder.FileVersions(filename)
+	if len(versions) == 0 && folder.File(filename) == nil {
+		return nil, os.ErrNotExist
+	}
+	return versions, nil
+}
+
+func (d *dfs) ReadVersion(path string, versionId string) (ReadContainer, error) {
+	folderPath, filename := common.Split(path)
+	if len(filename) == 0 {
+		return nil, os.ErrInvalid
+	}
+
+	folders, err := d.metadata.Get([]string{folderPath})
+	if err != nil {
+		return nil, err
+	}
+
+	version := folders[0].FileVersion(filename, versionId)
+	if version == nil {
+		return nil, os.ErrNotExist
+	}
+
+	if version.File.ZombieCheck() {
+		return nil, errors.ErrZombie
+	}
+
+	streamHandler, err := d.cluster.Read(version.File.Chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReadContainerForFile(version.File, streamHandler), nil
+}
+
+// RestoreVersion places the version as the current file. The current state of the file is archived as a new version
+func (d *dfs) RestoreVersion(path string, versionId string, precondition *Precondition) error {
+	folderPath, filename := common.Split(path)
+	if len(filename) == 0 {
+		return os.ErrInvalid
+	}
+
+	if err := d.checkLocks(path, false, precondition); err != nil {
+		return err
+	}
+
+	var change quotaChange
+	if err := d.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[folderPath]
+		if folder == nil {
+			return false, os.ErrNotExist
+		}
+
+		file := folder.File(filename)
+		if err := precondition.validate(file); err != nil {
+			return false, err
+		}
+
+		if file != nil && file.Locked() {
+			return false, errors.ErrLock
+		}
+
+		version := folder.FileVersion(filename, versionId)
+		if version == nil {
+			return false, os.ErrNotExist
+		}
+
+		if version.File.ZombieCheck() {
+			return false, errors.ErrZombie
+		}
+
+		change = newFileQuotaChange(folderPath, file, version.File.Size)
+		if err := d.checkQuota(change); err != nil {
+			return false, err
+		}
+
+		restored, err := folder.RestoreFileVersion(filename, versionId)
+		if err != nil {
+			return false, err
+		}
+		d.keepExpiredFile(restored)
+
+		actions := d.compileHookActions(folderPath, hooks.Created)
+		d.ExecuteActions(hooks.NewActionInfoForCreated(path, false), actions)
+
+		return true, nil
+	}); err != nil {
+		return err
+	}
+	d.changeQuota(change)
+
+	return nil
+}
+
+// PurgeVersions deletes the versions of the file that are out of the newest keep count or archived before
+// the provided date. If both are not provided, all versions of the file are deleted
+func (d *dfs) PurgeVersions(path string, keep *int, before *time.Time) error {
+	folderPath, filename := common.Split(path)
+	if len(filename) == 0 {
+		return os.ErrInvalid
+	}
+
+	if keep != nil && *keep < 0 {
+		return os.ErrInvalid
+	}
+
+	return d.metadata.SaveBlock([]string{folderPath}, func(folders map[string]*common.Folder) (bool, error) {
+		folder := folders[folderPath]
+		if folder == nil {
+			return false, os.ErrNotExist
+		}
+
+		if len(folder.FileVersions(filename)) == 0 {
+			return false, os.ErrNotExist
+		}
+
+		return true, folder.DeleteFileVersions(filename, keep, before, func(version *common.FileVersion) error {
+			return d.deleteFileChunks(version.File, true)
+		})
+	})
+}
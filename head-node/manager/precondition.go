@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+)
+
+// Precondition struct is to hold the conditional request requirements (If-Match, If-Unmodified-Since)
+// of the file changes. It is validated inside the metadata lock to keep the check and the change atomic.
+// LockTokens are the WebDAV lock tokens that the client submits to change the locked folders and files.
+// nil Precondition does not have any requirement
+type Precondition struct {
+	Match           []string
+	UnmodifiedSince *time.Time
+	LockTokens      []string
+}
+
+// NewPrecondition creates the precondition if any of the requirements is provided
+func NewPrecondition(match []string, unmodifiedSince *time.Time) *Precondition {
+	if len(match) == 0 && unmodifiedSince == nil {
+		return nil
+	}
+	return &Precondition{
+		Match:           match,
+		UnmodifiedSince: unmodifiedSince,
+	}
+}
+
+// NewLockPrecondition creates the precondition that only submits the WebDAV lock tokens
+func NewLockPrecondition(tokens []string) *Precondition {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return &Precondition{
+		LockTokens: tokens,
+	}
+}
+
+// ETag creates the entity tag of the file using the file checksum and the modification date, so the metadata
+// changes that keep the content as it is also change the entity tag. Modification date is in milliseconds as it is
+// kept in the metadata store. Files that do not have the checksum (joined files) do not have the entity tag
+func ETag(file *common.File) string {
+	if len(file.Checksum) == 0 || file.Size > 0 && strings.Compare(file.Checksum, common.EmptyChecksum()) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\"%s-%x\"", file.Checksum, file.Modified.UnixNano()/int64(time.Millisecond))
+}
+
+// empty checks if the precondition does not have any requirement on the file state. Lock tokens are not
+// the requirements, they are only submitted to pass the locks
+func (p *Precondition) empty() bool {
+	return p == nil || len(p.Match) == 0 && p.UnmodifiedSince == nil
+}
+
+func (p *Precondition) lockTokens() []string {
+	if p == nil {
+		return nil
+	}
+	return p.LockTokens
+}
+
+// validate checks the requirements against the current state of the file. file is nil if it does not exist
+func (p *Precondition) validate(file *common.File) error {
+	if p.empty() {
+		return nil
+	}
+
+	if len(p.Match) > 0 {
+		if file == nil {
+			return errors.ErrPrecondition
+		}
+
+		etag := ETag(file)
+		for _, match := range p.Match {
+			if strings.Compare(match, "*") == 0 {
+				return nil
+			}
+			// strong comparison
+			if len(etag) > 0 && strings.Compare(match, etag) == 0 {
+				return nil
+			}
+		}
+		return errors.ErrPrecondition
+	}
+
+	if file == nil {
+		return nil
+	}
+
+	if file.Modified.UTC().Truncate(time.Second).After(p.UnmodifiedSince.UTC()) {
+		return errors.ErrPrecondition
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package manager
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+// SearchQuery struct is to hold the filters of the search in the folder tree.
+// nil filters are not applied, Name is the glob pattern and NameRegex is the regular expression
+// of the folder/file name, Mime is the glob pattern of the file mime type
+type SearchQuery struct {
+	Path      string
+	Recursive bool
+	Type      string
+
+	Name      string
+	NameRegex *regexp.Regexp
+	Mime      string
+
+	MinSize *uint64
+	MaxSize *uint64
+
+	CreatedSince  *time.Time
+	CreatedUntil  *time.Time
+	ModifiedSince *time.Time
+	ModifiedUntil *time.Time
+
+	Zombie *bool
+	Locked *bool
+}
+
+// ValidSearchType checks if the search entry type filter is supported
+func ValidSearchType(searchType string) bool {
+	switch searchType {
+	case "", "folder", "file":
+		return true
+	}
+	return false
+}
+
+// fileOnly checks if the query has a filter that only a file can satisfy
+func (s *SearchQuery) fileOnly() bool {
+	return strings.Compare(s.Type, "file") == 0 ||
+		len(s.Mime) > 0 || s.MinSize != nil || s.MaxSize != nil || s.Zombie != nil || s.Locked != nil
+}
+
+func (s *SearchQuery) match(entry *common.SearchEntry) bool {
+	if strings.Compare(entry.Type, "folder") == 0 {
+		if s.fileOnly() {
+			return false
+		}
+	} else if strings.Compare(s.Type, "folder") == 0 {
+		return false
+	}
+
+	_, name := common.Split(entry.Full)
+	if len(s.Name) > 0 {
+		if matched, _ := path.Match(s.Name, name); !matched {
+			return false
+		}
+	}
+	if s.NameRegex != nil && !s.NameRegex.MatchString(name) {
+		return false
+	}
+
+	if len(s.Mime) > 0 {
+		if matched, _ := path.Match(s.Mime, entry.Mime); !matched {
+			return false
+		}
+	}
+
+	if s.MinSize != nil && entry.Size < *s.MinSize || s.MaxSize != nil && entry.Size > *s.MaxSize {
+		return false
+	}
+
+	if !s.matchTime(entry.Created, s.CreatedSince, s.CreatedUntil) ||
+		!s.matchTime(entry.Modified, s.ModifiedSince, s.ModifiedUntil) {
+		return false
+	}
+
+	if s.Zombie != nil && *s.Zombie != entry.Zombie || s.Locked != nil && *s.Locked != entry.Locked {
+		return false
+	}
+
+	return true
+}
+
+func (s *SearchQuery) matchTime(t time.Time, since *time.Time, until *time.Time) bool {
+	if since != nil && t.Before(*since) {
+		return false
+	}
+	if until != nil && t.After(*until) {
+		return false
+	}
+	return true
+}
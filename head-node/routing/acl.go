@@ -0,0 +1,95 @@
+package routing
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"go.uber.org/zap"
+)
+
+const aclEndPoint = "/client/acl"
+
+type aclRouter struct {
+	access manager.AccessControl
+	logger *zap.Logger
+
+	definitions []*Definition
+}
+
+// NewAclRouter creates the router of the folder access lists
+func NewAclRouter(access manager.AccessControl, logger *zap.Logger) Router {
+	pR := &aclRouter{
+		access:      access,
+		logger:      logger,
+		definitions: make([]*Definition, 0),
+	}
+	pR.setup()
+
+	return pR
+}
+
+func (a *aclRouter) setup() {
+	a.definitions =
+		append(a.definitions,
+			&Definition{
+				Path:    aclEndPoint,
+				Handler: a.manipulate,
+			},
+		)
+}
+
+func (a *aclRouter) Get() []*Definition {
+	return a.definitions
+}
+
+func (a *aclRouter) manipulate(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	// access lists are managed only by the admin keys when the authentication is enabled
+	if key := auth.FromContext(r.Context()); key != nil && !key.Has(auth.ScopeAdmin) {
+		w.WriteHeader(403)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		a.handleGet(w, r)
+	case http.MethodPut:
+		a.handlePut(w, r)
+	case http.MethodDelete:
+		a.handleDelete(w, r)
+	default:
+		w.WriteHeader(406)
+	}
+}
+
+func (a *aclRouter) describeXPath(xPath string) (string, error) {
+	requestedPath, err := url.QueryUnescape(xPath)
+	if err != nil {
+		return "", err
+	}
+	if len(requestedPath) == 0 || !common.ValidatePath(requestedPath) {
+		return "", os.ErrInvalid
+	}
+	return requestedPath, nil
+}
+
+func (a *aclRouter) writeError(w http.ResponseWriter, err error, path string, logMessage string) {
+	switch err {
+	case os.ErrNotExist:
+		w.WriteHeader(404)
+		return
+	case os.ErrInvalid:
+		w.WriteHeader(422)
+		return
+	}
+
+	w.WriteHeader(500)
+	a.logger.Error(logMessage, zap.String("path", path), zap.Error(err))
+}
+
+var _ Router = &aclRouter{}
@@ -0,0 +1,20 @@
+package routing
+
+import (
+	"net/http"
+)
+
+func (a *aclRouter) handleDelete(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := a.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	if err := a.access.Set(requestedPath, nil); err != nil {
+		a.writeError(w, err, requestedPath, "Remove access list request is failed")
+		return
+	}
+
+	w.WriteHeader(200)
+}
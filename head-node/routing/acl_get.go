@@ -0,0 +1,33 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+func (a *aclRouter) handleGet(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := a.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	attachedPath, acl, err := a.access.Get(requestedPath)
+	if err != nil {
+		a.writeError(w, err, requestedPath, "Access list request is failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Acl-Path", attachedPath)
+	if err := json.NewEncoder(w).Encode(acl); err != nil {
+		w.WriteHeader(500)
+		a.logger.Error(
+			"Response of access list request is failed",
+			zap.String("path", requestedPath),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,29 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+func (a *aclRouter) handlePut(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := a.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	acl := make(common.AccessList, 0)
+	if err := json.NewDecoder(r.Body).Decode(&acl); err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	if err := a.access.Set(requestedPath, acl); err != nil {
+		a.writeError(w, err, requestedPath, "Set access list request is failed")
+		return
+	}
+
+	w.WriteHeader(202)
+}
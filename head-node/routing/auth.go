@@ -0,0 +1,44 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// NewAuthMiddleware creates the middleware that rejects the requests that are not authenticated for the scope
+// and passes the authenticated key to the handlers in the request context. Requests that are authorized with
+// the presigned urls are passed with the issuer key of the url
+func NewAuthMiddleware(authenticator auth.Authenticator, scope auth.Scope, logger *zap.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if auth.IsPresigned(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := authenticator.Authenticate(r, scope)
+			if err == nil {
+				next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), key)))
+				return
+			}
+
+			if err == errors.ErrForbidden {
+				w.WriteHeader(403)
+			} else {
+				w.Header().Set("WWW-Authenticate", `Basic realm="kertish-dfs"`)
+				w.WriteHeader(401)
+			}
+
+			e := common.NewError(800, err.Error())
+			if err := json.NewEncoder(w).Encode(e); err != nil {
+				logger.Error("Response of authentication failure is failed", zap.Error(err))
+			}
+		})
+	}
+}
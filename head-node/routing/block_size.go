@@ -0,0 +1,24 @@
+package routing
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+// describeBlockSize parses the X-Block-Size header in bytes. It returns zero if the header is absent to let
+// the folder or the farm default block size to be used
+func describeBlockSize(header http.Header) (uint32, error) {
+	blockSizeHeader := header.Get("X-Block-Size")
+	if len(blockSizeHeader) == 0 {
+		return 0, nil
+	}
+
+	blockSize, err := strconv.ParseUint(blockSizeHeader, 10, 32)
+	if err != nil {
+		return 0, os.ErrInvalid
+	}
+	return uint32(blockSize), common.ValidateBlockSize(uint32(blockSize))
+}
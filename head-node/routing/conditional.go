@@ -0,0 +1,58 @@
+package routing
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+)
+
+// describePrecondition parses the If-Match and If-Unmodified-Since headers of the change requests (RFC 7232).
+// Malformed If-Unmodified-Since value is ignored
+func describePrecondition(r *http.Request) *manager.Precondition {
+	match := describeEntityTags(r.Header.Get("If-Match"))
+
+	var unmodifiedSince *time.Time
+	if t, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil {
+		unmodifiedSince = &t
+	}
+
+	return manager.NewPrecondition(match, unmodifiedSince)
+}
+
+func describeEntityTags(header string) []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) == 0 {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// notModified validates the If-None-Match and If-Modified-Since headers of the read request. If-Modified-Since
+// is only considered when If-None-Match is not provided
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if len(ifNoneMatch) > 0 {
+		for _, tag := range describeEntityTags(ifNoneMatch) {
+			if strings.Compare(tag, "*") == 0 {
+				return true
+			}
+			// weak comparison
+			if len(etag) > 0 && strings.Compare(strings.TrimPrefix(tag, "W/"), etag) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	t, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.UTC().Truncate(time.Second).After(t.UTC())
+}
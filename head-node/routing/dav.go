@@ -0,0 +1,205 @@
+package routing
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const davEndPoint = "/client/dav"
+
+type davRouter struct {
+	access manager.AccessControl
+	logger *zap.Logger
+
+	definitions []*Definition
+}
+
+// NewDavRouter creates the router that serves the dfs file tree over WebDAV (class 1 and 2)
+func NewDavRouter(access manager.AccessControl, logger *zap.Logger) Router {
+	pR := &davRouter{
+		access:      access,
+		logger:      logger,
+		definitions: make([]*Definition, 0),
+	}
+	pR.setup()
+
+	return pR
+}
+
+func (d *davRouter) setup() {
+	d.definitions =
+		append(d.definitions,
+			&Definition{
+				Path:    davEndPoint,
+				Handler: d.manipulate,
+			},
+			&Definition{
+				Path:    fmt.Sprintf("%s/{path:.*}", davEndPoint),
+				Handler: d.manipulate,
+			},
+		)
+}
+
+func (d *davRouter) Get() []*Definition {
+	return d.definitions
+}
+
+// dfs returns the file manipulation operations that are evaluated for the authenticated key of the request
+func (d *davRouter) dfs(r *http.Request) manager.Dfs {
+	return d.access.Dfs(auth.FromContext(r.Context()))
+}
+
+func (d *davRouter) manipulate(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	requestedPath := common.CorrectPath(mux.Vars(r)["path"])
+
+	switch r.Method {
+	case http.MethodOptions:
+		d.handleOptions(w, r)
+	case "PROPFIND":
+		d.handlePropfind(w, r, requestedPath)
+	case "PROPPATCH":
+		d.handleProppatch(w, r, requestedPath)
+	case "MKCOL":
+		d.handleMkcol(w, r, requestedPath)
+	case http.MethodGet:
+		d.handleGet(w, r, requestedPath, false)
+	case http.MethodHead:
+		d.handleGet(w, r, requestedPath, true)
+	case http.MethodPut:
+		d.handlePut(w, r, requestedPath)
+	case http.MethodDelete:
+		d.handleDelete(w, r, requestedPath)
+	case "COPY":
+		d.handleChange(w, r, requestedPath, false)
+	case "MOVE":
+		d.handleChange(w, r, requestedPath, true)
+	case "LOCK":
+		d.handleLock(w, r, requestedPath)
+	case "UNLOCK":
+		d.handleUnlock(w, r, requestedPath)
+	default:
+		w.WriteHeader(405)
+	}
+}
+
+func (d *davRouter) handleOptions(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("DAV", "1, 2")
+	w.Header().Set("MS-Author-Via", "DAV")
+	w.Header().Set("Allow", "OPTIONS, PROPFIND, PROPPATCH, MKCOL, GET, HEAD, PUT, DELETE, COPY, MOVE, LOCK, UNLOCK")
+	w.WriteHeader(200)
+}
+
+func (d *davRouter) href(folderPath string, collection bool) string {
+	u := url.URL{Path: davEndPoint}
+	if strings.Compare(folderPath, "/") != 0 {
+		u.Path = fmt.Sprintf("%s%s", davEndPoint, folderPath)
+	}
+	if collection {
+		u.Path = fmt.Sprintf("%s/", u.Path)
+	}
+	return u.EscapedPath()
+}
+
+// describeDestination extracts the dfs path from the Destination header that can be
+// an absolute url or an absolute path
+func (d *davRouter) describeDestination(destination string) (string, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return "", os.ErrInvalid
+	}
+
+	if !strings.HasPrefix(u.Path, davEndPoint) {
+		return "", os.ErrInvalid
+	}
+	targetPath := u.Path[len(davEndPoint):]
+
+	if len(targetPath) > 0 && targetPath[0] != '/' {
+		return "", os.ErrInvalid
+	}
+
+	return common.CorrectPath(targetPath), nil
+}
+
+// stat finds the folder or the file that the path points
+func (d *davRouter) stat(r *http.Request, requestedPath string) (*common.Folder, *common.File, error) {
+	if strings.Compare(requestedPath, "/") != 0 {
+		parentPath, name := common.Split(requestedPath)
+
+		read, err := d.dfs(r).Read([]string{parentPath}, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if read.Type() != manager.RTFolder {
+			return nil, nil, os.ErrNotExist
+		}
+
+		if file := read.Folder().File(name); file != nil {
+			return nil, file, nil
+		}
+	}
+
+	read, err := d.dfs(r).Read([]string{requestedPath}, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if read.Type() != manager.RTFolder {
+		return nil, nil, os.ErrNotExist
+	}
+
+	return read.Folder(), nil, nil
+}
+
+func (d *davRouter) writeDfsError(w http.ResponseWriter, err error, requestedPath string, logMessage string) {
+	switch err {
+	case os.ErrNotExist:
+		w.WriteHeader(404)
+		return
+	case errors.ErrForbidden:
+		w.WriteHeader(403)
+		return
+	case os.ErrExist:
+		w.WriteHeader(405)
+		return
+	case os.ErrInvalid:
+		w.WriteHeader(400)
+		return
+	case errors.ErrNotEmpty, errors.ErrJoinConflict:
+		w.WriteHeader(409)
+		return
+	case errors.ErrLock:
+		w.WriteHeader(423)
+		return
+	case errors.ErrNoAvailableActionNode:
+		w.WriteHeader(503)
+		return
+	case errors.ErrNoSpace, errors.ErrQuota:
+		w.WriteHeader(507)
+		return
+	case errors.ErrZombie:
+		w.WriteHeader(524)
+		return
+	case errors.ErrZombieAlive:
+		w.WriteHeader(525)
+		return
+	case errors.ErrRepair:
+		w.WriteHeader(526)
+		return
+	}
+
+	w.WriteHeader(500)
+	d.logger.Error(logMessage, zap.String("path", requestedPath), zap.Error(err))
+}
+
+var _ Router = &davRouter{}
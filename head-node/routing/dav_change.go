@@ -0,0 +1,72 @@
+package routing
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+func (d *davRouter) handleChange(w http.ResponseWriter, r *http.Request, requestedPath string, move bool) {
+	targetPath, err := d.describeDestination(r.Header.Get("Destination"))
+	if err != nil {
+		w.WriteHeader(400)
+		return
+	}
+
+	overwrite := strings.Compare(strings.ToUpper(r.Header.Get("Overwrite")), "F") != 0
+
+	if strings.Compare(requestedPath, "/") == 0 ||
+		strings.Compare(requestedPath, targetPath) == 0 ||
+		strings.HasPrefix(targetPath, fmt.Sprintf("%s/", requestedPath)) {
+		w.WriteHeader(403)
+		return
+	}
+
+	sourceFolder, _, err := d.stat(r, requestedPath)
+	if err != nil {
+		d.writeDfsError(w, err, requestedPath, "Dav change (source) request is failed")
+		return
+	}
+
+	targetParentPath, _ := common.Split(targetPath)
+	if parent, _, err := d.stat(r, targetParentPath); err != nil || parent == nil {
+		w.WriteHeader(409)
+		return
+	}
+
+	targetFolder, targetFile, err := d.stat(r, targetPath)
+	if err != nil && err != os.ErrNotExist {
+		d.writeDfsError(w, err, targetPath, "Dav change (target) request is failed")
+		return
+	}
+	exists := targetFolder != nil || targetFile != nil
+
+	if exists {
+		if !overwrite {
+			w.WriteHeader(412)
+			return
+		}
+
+		// folder targets are replaced as a whole
+		if targetFolder != nil || sourceFolder != nil {
+			if err := d.dfs(r).Delete(targetPath, false, d.precondition(r)); err != nil {
+				d.writeDfsError(w, err, targetPath, "Dav change (overwrite) request is failed")
+				return
+			}
+		}
+	}
+
+	if err := d.dfs(r).Change([]string{requestedPath}, targetPath, false, overwrite, move, d.precondition(r)); err != nil {
+		d.writeDfsError(w, err, requestedPath, "Dav change request is failed")
+		return
+	}
+
+	if exists {
+		w.WriteHeader(204)
+		return
+	}
+	w.WriteHeader(201)
+}
@@ -0,0 +1,25 @@
+package routing
+
+import (
+	"net/http"
+	"strings"
+)
+
+func (d *davRouter) handleDelete(w http.ResponseWriter, r *http.Request, requestedPath string) {
+	if strings.Compare(requestedPath, "/") == 0 {
+		w.WriteHeader(403)
+		return
+	}
+
+	if _, _, err := d.stat(r, requestedPath); err != nil {
+		d.writeDfsError(w, err, requestedPath, "Dav delete (check) request is failed")
+		return
+	}
+
+	if err := d.dfs(r).Delete(requestedPath, false, d.precondition(r)); err != nil {
+		d.writeDfsError(w, err, requestedPath, "Dav delete request is failed")
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,65 @@
+package routing
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"go.uber.org/zap"
+)
+
+func (d *davRouter) handleGet(w http.ResponseWriter, r *http.Request, requestedPath string, head bool) {
+	read, err := d.dfs(r).Read([]string{requestedPath}, false)
+	if err != nil {
+		d.writeDfsError(w, err, requestedPath, "Dav read request is failed")
+		return
+	}
+
+	if read.Type() == manager.RTFolder {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, PROPPATCH, MKCOL, DELETE, COPY, MOVE, LOCK, UNLOCK")
+		w.WriteHeader(405)
+		return
+	}
+
+	file := read.File()
+
+	w.Header().Set("Content-Type", file.Mime)
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("ETag", fmt.Sprintf("\"%s\"", file.Checksum))
+	w.Header().Set("Last-Modified", file.Modified.UTC().Format(http.TimeFormat))
+
+	begins, ends := int64(0), int64(file.Size)-1
+	statusCode := 200
+
+	if requestRange := r.Header.Get("Range"); len(requestRange) > 0 {
+		var satisfiable bool
+		begins, ends, satisfiable = describeByteRange(requestRange, int64(file.Size))
+		if !satisfiable {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", file.Size))
+			w.WriteHeader(416)
+			return
+		}
+		if begins != 0 || ends != int64(file.Size)-1 {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", begins, ends, file.Size))
+			statusCode = 206
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(ends-begins+1, 10))
+	w.WriteHeader(statusCode)
+
+	if head || file.Size == 0 {
+		return
+	}
+
+	if err := read.Read(w, begins, ends); err != nil {
+		d.logger.Warn(
+			"Streaming file content is failed",
+			zap.String("path", requestedPath),
+			zap.Int64("begins", begins),
+			zap.Int64("ends", ends),
+			zap.Error(err),
+		)
+	}
+}
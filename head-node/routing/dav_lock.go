@@ -0,0 +1,196 @@
+package routing
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+)
+
+const davLockMaxTimeout = time.Hour * 24
+
+var davLockTokenRegex = regexp.MustCompile(`<(opaquelocktoken:[^>]+)>`)
+
+// discovery converts the locks that cover the path to the dav lock discovery
+func (d *davRouter) discovery(locks common.ResourceLocks, folderPath string) *davLockDiscovery {
+	discovery := &davLockDiscovery{ActiveLocks: make([]davActiveLock, 0)}
+	for _, lock := range locks.Related(folderPath, false) {
+		discovery.ActiveLocks = append(discovery.ActiveLocks, d.activeLock(lock))
+	}
+	return discovery
+}
+
+func (d *davRouter) activeLock(lock *common.ResourceLock) davActiveLock {
+	activeLock := davActiveLock{
+		Depth:     "0",
+		Timeout:   fmt.Sprintf("Second-%d", lock.Timeout),
+		LockToken: davHref{Href: lock.Token},
+		LockRoot:  davHref{Href: d.href(lock.Root, false)},
+	}
+	if len(lock.Owner) > 0 {
+		activeLock.Owner = &davInnerXml{InnerXml: lock.Owner}
+	}
+	if lock.Infinite {
+		activeLock.Depth = "infinity"
+	}
+	if lock.Exclusive {
+		activeLock.LockScope.Exclusive = &struct{}{}
+	} else {
+		activeLock.LockScope.Shared = &struct{}{}
+	}
+	return activeLock
+}
+
+func (d *davRouter) submittedTokens(r *http.Request) []string {
+	tokens := make([]string, 0)
+	for _, match := range davLockTokenRegex.FindAllStringSubmatch(r.Header.Get("If"), -1) {
+		tokens = append(tokens, match[1])
+	}
+	return tokens
+}
+
+// allowed checks the submitted lock tokens are sufficient to change the path for the requests that are not
+// evaluated by the dfs operations against the locks
+func (d *davRouter) allowed(w http.ResponseWriter, r *http.Request, folderPath string) bool {
+	locks, err := d.dfs(r).Locks(folderPath)
+	if err != nil {
+		d.writeDfsError(w, err, folderPath, "Lock check is failed")
+		return false
+	}
+	if locks.Allowed(folderPath, false, d.submittedTokens(r)) {
+		return true
+	}
+	w.WriteHeader(423)
+	return false
+}
+
+// precondition submits the lock tokens of the request to the dfs operations that change the locked folders and files
+func (d *davRouter) precondition(r *http.Request) *manager.Precondition {
+	return manager.NewLockPrecondition(d.submittedTokens(r))
+}
+
+func (d *davRouter) describeTimeout(timeoutHeader string) time.Duration {
+	for _, timeout := range strings.Split(timeoutHeader, ",") {
+		timeout = strings.TrimSpace(timeout)
+		if !strings.HasPrefix(timeout, "Second-") {
+			continue
+		}
+		seconds, err := strconv.ParseUint(timeout[len("Second-"):], 10, 32)
+		if err != nil || seconds == 0 {
+			continue
+		}
+		duration := time.Second * time.Duration(seconds)
+		if duration > davLockMaxTimeout {
+			duration = davLockMaxTimeout
+		}
+		return duration
+	}
+	return time.Hour
+}
+
+func (d *davRouter) handleLock(w http.ResponseWriter, r *http.Request, requestedPath string) {
+	timeout := d.describeTimeout(r.Header.Get("Timeout"))
+
+	var lockInfo davLockInfo
+	if err := xml.NewDecoder(r.Body).Decode(&lockInfo); err != nil {
+		if err != io.EOF {
+			w.WriteHeader(400)
+			return
+		}
+
+		// Refresh request, the submitted token should belong to a lock that covers the path
+		lock, err := d.dfs(r).RefreshLock(requestedPath, d.submittedTokens(r), timeout)
+		if err != nil {
+			if err == os.ErrNotExist {
+				w.WriteHeader(412)
+				return
+			}
+			d.writeDfsError(w, err, requestedPath, "Lock (refresh) request is failed")
+			return
+		}
+
+		d.writeXml(w, 200, &davProp{
+			Xmlns:         davNamespace,
+			LockDiscovery: &davLockDiscovery{ActiveLocks: []davActiveLock{d.activeLock(lock)}},
+		})
+		return
+	}
+
+	if lockInfo.Write == nil || lockInfo.Exclusive == nil && lockInfo.Shared == nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	infinite := true
+	switch r.Header.Get("Depth") {
+	case "0":
+		infinite = false
+	case "", "infinity":
+	default:
+		w.WriteHeader(400)
+		return
+	}
+
+	if strings.Compare(requestedPath, "/") != 0 {
+		parentPath, _ := common.Split(requestedPath)
+		if folder, _, err := d.stat(r, parentPath); err != nil || folder == nil {
+			w.WriteHeader(409)
+			return
+		}
+	}
+
+	owner := ""
+	if lockInfo.Owner != nil {
+		owner = lockInfo.Owner.InnerXml
+	}
+
+	lock, err := common.NewResourceLock(requestedPath, infinite, lockInfo.Exclusive != nil, owner, timeout)
+	if err != nil {
+		d.writeDfsError(w, err, requestedPath, "Lock request is failed")
+		return
+	}
+
+	if err := d.dfs(r).Lock(lock); err != nil {
+		if err == os.ErrNotExist {
+			w.WriteHeader(409)
+			return
+		}
+		d.writeDfsError(w, err, requestedPath, "Lock request is failed")
+		return
+	}
+
+	w.Header().Set("Lock-Token", fmt.Sprintf("<%s>", lock.Token))
+	d.writeXml(w, 200, &davProp{
+		Xmlns:         davNamespace,
+		LockDiscovery: &davLockDiscovery{ActiveLocks: []davActiveLock{d.activeLock(lock)}},
+	})
+}
+
+func (d *davRouter) handleUnlock(w http.ResponseWriter, r *http.Request, requestedPath string) {
+	token := strings.TrimSpace(r.Header.Get("Lock-Token"))
+	token = strings.TrimSuffix(strings.TrimPrefix(token, "<"), ">")
+
+	if len(token) == 0 {
+		w.WriteHeader(400)
+		return
+	}
+
+	if err := d.dfs(r).Unlock(requestedPath, token); err != nil {
+		if err == os.ErrNotExist {
+			w.WriteHeader(409)
+			return
+		}
+		d.writeDfsError(w, err, requestedPath, "Unlock request is failed")
+		return
+	}
+
+	w.WriteHeader(204)
+}
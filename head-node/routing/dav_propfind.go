@@ -0,0 +1,194 @@
+package routing
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+)
+
+func (d *davRouter) handlePropfind(w http.ResponseWriter, r *http.Request, requestedPath string) {
+	depth := r.Header.Get("Depth")
+	switch depth {
+	case "0", "1":
+	case "", "infinity":
+		d.writeXml(w, 403, &struct {
+			XMLName xml.Name `xml:"D:error"`
+			Xmlns   string   `xml:"xmlns:D,attr"`
+			Finite  struct{} `xml:"D:propfind-finite-depth"`
+		}{Xmlns: davNamespace})
+		return
+	default:
+		w.WriteHeader(400)
+		return
+	}
+
+	var propfind davPropfind
+	if err := xml.NewDecoder(r.Body).Decode(&propfind); err != nil {
+		if err != io.EOF {
+			w.WriteHeader(400)
+			return
+		}
+		propfind.AllProp = &struct{}{}
+	}
+
+	folder, file, err := d.stat(r, requestedPath)
+	if err != nil {
+		d.writeDfsError(w, err, requestedPath, "Propfind request is failed")
+		return
+	}
+
+	// locks of the direct children are also kept in the folder
+	locks, err := d.dfs(r).Locks(requestedPath)
+	if err != nil {
+		d.writeDfsError(w, err, requestedPath, "Propfind (locks) request is failed")
+		return
+	}
+
+	multistatus := &davMultistatus{
+		Xmlns:     davNamespace,
+		Responses: make([]davResponse, 0),
+	}
+
+	if file != nil {
+		multistatus.Responses = append(multistatus.Responses, d.propfindResponse(&propfind, locks, requestedPath, nil, file))
+		d.writeXml(w, 207, multistatus)
+		return
+	}
+
+	multistatus.Responses = append(multistatus.Responses, d.propfindResponse(&propfind, locks, requestedPath, folder, nil))
+
+	if depth == "1" {
+		for _, shadow := range folder.Folders {
+			subFolder := common.NewFolder(shadow.Full)
+			subFolder.Created = shadow.Created
+			subFolder.Modified = shadow.Created
+
+			multistatus.Responses = append(multistatus.Responses, d.propfindResponse(&propfind, locks, shadow.Full, subFolder, nil))
+		}
+
+		for _, file := range folder.Files {
+			if file.Locked() {
+				continue
+			}
+			multistatus.Responses = append(multistatus.Responses, d.propfindResponse(&propfind, locks, common.Join(folder.Full, file.Name), nil, file))
+		}
+	}
+
+	d.writeXml(w, 207, multistatus)
+}
+
+func (d *davRouter) propfindResponse(propfind *davPropfind, locks common.ResourceLocks, full string, folder *common.Folder, file *common.File) davResponse {
+	properties := d.properties(locks, full, folder, file)
+
+	response := davResponse{
+		Href:      d.href(full, folder != nil),
+		Propstats: make([]davPropstat, 0),
+	}
+
+	if propfind.PropName != nil {
+		response.Propstats = append(response.Propstats, davPropstat{Prop: properties.names(), Status: d.status(200)})
+		return response
+	}
+
+	if propfind.Prop == nil {
+		response.Propstats = append(response.Propstats, davPropstat{Prop: properties, Status: d.status(200)})
+		return response
+	}
+
+	found := davProp{}
+	missing := davProp{Others: make([]davEmpty, 0)}
+
+	for _, name := range propfind.Prop.Names {
+		if found.pick(&properties, name.XMLName) {
+			continue
+		}
+		missing.Others = append(missing.Others, name)
+	}
+
+	response.Propstats = append(response.Propstats, davPropstat{Prop: found, Status: d.status(200)})
+	if len(missing.Others) > 0 {
+		response.Propstats = append(response.Propstats, davPropstat{Prop: missing, Status: d.status(404)})
+	}
+
+	return response
+}
+
+// properties converts the folder or file metadata to dav properties
+func (d *davRouter) properties(locks common.ResourceLocks, full string, folder *common.Folder, file *common.File) davProp {
+	_, name := common.Split(full)
+
+	var created, modified time.Time
+	properties := davProp{
+		DisplayName:  &name,
+		ResourceType: &davResourceType{},
+		SupportedLock: &davSupportedLock{
+			LockEntries: []davLockEntry{
+				{LockScope: davLockScope{Exclusive: &struct{}{}}},
+				{LockScope: davLockScope{Shared: &struct{}{}}},
+			},
+		},
+		LockDiscovery: d.discovery(locks, full),
+	}
+
+	if folder != nil {
+		created, modified = folder.Created, folder.Modified
+		properties.ResourceType.Collection = &struct{}{}
+	} else {
+		created, modified = file.Created, file.Modified
+
+		size := file.Size
+		mime := file.Mime
+		properties.GetContentLength = &size
+		properties.GetContentType = &mime
+		if etag := manager.ETag(file); len(etag) > 0 {
+			properties.GetETag = &etag
+		}
+	}
+
+	creationDate := created.UTC().Format(time.RFC3339)
+	lastModified := modified.UTC().Format(http.TimeFormat)
+
+	properties.CreationDate = &creationDate
+	properties.GetLastModified = &lastModified
+
+	return properties
+}
+
+// handleProppatch accepts the property changes without persisting them as the dav properties
+// are generated from the folder and file metadata. Some clients (e.g. Windows) require it
+func (d *davRouter) handleProppatch(w http.ResponseWriter, r *http.Request, requestedPath string) {
+	if !d.allowed(w, r, requestedPath) {
+		return
+	}
+
+	var propertyUpdate davPropertyUpdate
+	if err := xml.NewDecoder(r.Body).Decode(&propertyUpdate); err != nil {
+		w.WriteHeader(400)
+		return
+	}
+
+	folder, file, err := d.stat(r, requestedPath)
+	if err != nil {
+		d.writeDfsError(w, err, requestedPath, "Proppatch request is failed")
+		return
+	}
+
+	changed := davProp{Others: make([]davEmpty, 0)}
+	for _, props := range append(propertyUpdate.Set, propertyUpdate.Remove...) {
+		changed.Others = append(changed.Others, props.Names...)
+	}
+
+	d.writeXml(w, 207, &davMultistatus{
+		Xmlns: davNamespace,
+		Responses: []davResponse{
+			{
+				Href:      d.href(requestedPath, folder != nil && file == nil),
+				Propstats: []davPropstat{{Prop: changed, Status: d.status(200)}},
+			},
+		},
+	})
+}
@@ -0,0 +1,106 @@
+package routing
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+func (d *davRouter) handlePut(w http.ResponseWriter, r *http.Request, requestedPath string) {
+	if strings.Compare(requestedPath, "/") == 0 {
+		w.WriteHeader(405)
+		return
+	}
+
+	parentPath, name := common.Split(requestedPath)
+	parent, _, err := d.stat(r, parentPath)
+	if err != nil || parent == nil {
+		w.WriteHeader(409)
+		return
+	}
+
+	for _, shadow := range parent.Folders {
+		if strings.Compare(shadow.Name, name) == 0 {
+			w.WriteHeader(405)
+			return
+		}
+	}
+	exists := parent.File(name) != nil
+
+	contentType := r.Header.Get("Content-Type")
+	if len(contentType) == 0 {
+		contentType = mime.TypeByExtension(path.Ext(name))
+	}
+	if len(contentType) == 0 {
+		contentType = "application/octet-stream"
+	}
+
+	contentLength := r.ContentLength
+	contentReader := bufio.NewReader(r.Body)
+
+	// unknown length content (chunked transfer) is streamed if it is not empty
+	stream := false
+	if contentLength == -1 {
+		_, err := contentReader.Peek(1)
+		switch err {
+		case nil:
+			stream = true
+		case io.EOF:
+			contentLength = 0
+		default:
+			w.WriteHeader(400)
+			return
+		}
+	}
+
+	if stream {
+		err = d.dfs(r).CreateStream(requestedPath, contentType, nil, nil, 0, true, d.precondition(r), contentReader)
+	} else {
+		err = d.dfs(r).CreateFile(requestedPath, contentType, nil, nil, 0, uint64(contentLength), true, d.precondition(r), contentReader)
+	}
+	if err != nil {
+		d.writeDfsError(w, err, requestedPath, "Dav put request is failed")
+		return
+	}
+
+	if exists {
+		w.WriteHeader(204)
+		return
+	}
+	w.WriteHeader(201)
+}
+
+func (d *davRouter) handleMkcol(w http.ResponseWriter, r *http.Request, requestedPath string) {
+	if !d.allowed(w, r, requestedPath) {
+		return
+	}
+
+	if r.ContentLength > 0 {
+		w.WriteHeader(415)
+		return
+	}
+
+	folder, file, err := d.stat(r, requestedPath)
+	if err == nil && (folder != nil || file != nil) {
+		w.WriteHeader(405)
+		return
+	}
+
+	parentPath, _ := common.Split(requestedPath)
+	if parent, _, err := d.stat(r, parentPath); err != nil || parent == nil {
+		w.WriteHeader(409)
+		return
+	}
+
+	if err := d.dfs(r).CreateFolder(requestedPath); err != nil {
+		d.writeDfsError(w, err, requestedPath, "Dav mkcol request is failed")
+		return
+	}
+
+	w.WriteHeader(201)
+}
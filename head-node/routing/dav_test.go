@@ -0,0 +1,348 @@
+package routing
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const davTestLockInfo = `<?xml version="1.0" encoding="utf-8"?>
+<D:lockinfo xmlns:D="DAV:">
+  <D:lockscope><D:exclusive/></D:lockscope>
+  <D:locktype><D:write/></D:locktype>
+  <D:owner><D:href>tester</D:href></D:owner>
+</D:lockinfo>`
+
+type davTestReadContainer struct {
+	manager.ReadContainer
+	folder *common.Folder
+}
+
+func (d *davTestReadContainer) Type() manager.ReadType { return manager.RTFolder }
+func (d *davTestReadContainer) Folder() *common.Folder { return d.folder }
+
+// davTestDfs keeps the folders and the locks in memory and evaluates the locks as the dfs operations do
+type davTestDfs struct {
+	manager.Dfs
+
+	folders  map[string]*common.Folder
+	locks    common.ResourceLocks
+	streamed int
+}
+
+func newDavTestDfs(folderPaths ...string) *davTestDfs {
+	d := &davTestDfs{
+		folders: map[string]*common.Folder{"/": common.NewFolder("/")},
+		locks:   make(common.ResourceLocks, 0),
+	}
+	for _, folderPath := range folderPaths {
+		parentPath, _ := common.Split(folderPath)
+		folder, _ := d.folders[parentPath].NewFolder(folderPath[len(parentPath):])
+		d.folders[folderPath] = folder
+	}
+	return d
+}
+
+func (d *davTestDfs) allowed(path string, children bool, precondition *manager.Precondition) error {
+	var tokens []string
+	if precondition != nil {
+		tokens = precondition.LockTokens
+	}
+	if !d.locks.Allowed(path, children, tokens) {
+		return errors.ErrLock
+	}
+	return nil
+}
+
+func (d *davTestDfs) Read(paths []string, _ bool) (manager.ReadContainer, error) {
+	folder, has := d.folders[paths[0]]
+	if !has {
+		return nil, os.ErrNotExist
+	}
+	return &davTestReadContainer{folder: folder}, nil
+}
+
+func (d *davTestDfs) CreateFile(path string, mime string, _ common.Metadata, _ *time.Duration, _ uint32, _ uint64, _ bool, precondition *manager.Precondition, contentReader io.Reader) error {
+	if err := d.allowed(path, false, precondition); err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadAll(contentReader)
+	if err != nil {
+		return err
+	}
+
+	parentPath, name := common.Split(path)
+	folder := d.folders[parentPath]
+
+	file := folder.File(name)
+	if file == nil {
+		if file, err = folder.NewFile(name); err != nil {
+			return err
+		}
+	}
+	file.Reset(mime, uint64(len(content)))
+	file.Lock.Cancel()
+
+	return nil
+}
+
+func (d *davTestDfs) CreateStream(path string, mime string, metadata common.Metadata, ttl *time.Duration, blockSize uint32, _ bool, precondition *manager.Precondition, contentReader io.Reader) error {
+	d.streamed++
+	return d.CreateFile(path, mime, metadata, ttl, blockSize, 0, true, precondition, contentReader)
+}
+
+func (d *davTestDfs) Change(sources []string, target string, _ bool, _ bool, move bool, precondition *manager.Precondition) error {
+	if move {
+		if err := d.allowed(sources[0], true, precondition); err != nil {
+			return err
+		}
+	}
+	if err := d.allowed(target, true, precondition); err != nil {
+		return err
+	}
+
+	sourceParent, sourceName := common.Split(sources[0])
+	file := d.folders[sourceParent].File(sourceName)
+	if file == nil {
+		return os.ErrNotExist
+	}
+
+	targetParent, targetName := common.Split(target)
+	targetFile, err := d.folders[targetParent].NewFile(targetName)
+	if err != nil {
+		return err
+	}
+	targetFile.Reset(file.Mime, file.Size)
+	targetFile.Lock.Cancel()
+
+	if move {
+		d.locks = d.locks.Drop(sources[0])
+		return d.folders[sourceParent].DeleteFile(sourceName, func(_ *common.File) error { return nil })
+	}
+	return nil
+}
+
+func (d *davTestDfs) Delete(path string, _ bool, precondition *manager.Precondition) error {
+	if err := d.allowed(path, true, precondition); err != nil {
+		return err
+	}
+
+	parentPath, name := common.Split(path)
+	d.locks = d.locks.Drop(path)
+	return d.folders[parentPath].DeleteFile(name, func(_ *common.File) error { return nil })
+}
+
+func (d *davTestDfs) Lock(lock *common.ResourceLock) error {
+	if d.locks.Conflicts(lock) {
+		return errors.ErrLock
+	}
+	d.locks = append(d.locks, lock)
+	return nil
+}
+
+func (d *davTestDfs) RefreshLock(path string, tokens []string, timeout time.Duration) (*common.ResourceLock, error) {
+	for _, token := range tokens {
+		if lock := d.locks.Find(path, token); lock != nil {
+			lock.Refresh(timeout)
+			return lock, nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+func (d *davTestDfs) Unlock(path string, token string) error {
+	lock := d.locks.Find(path, token)
+	if lock == nil {
+		return os.ErrNotExist
+	}
+
+	locks := make(common.ResourceLocks, 0)
+	for _, l := range d.locks {
+		if l != lock {
+			locks = append(locks, l)
+		}
+	}
+	d.locks = locks
+
+	return nil
+}
+
+func (d *davTestDfs) Locks(_ string) (common.ResourceLocks, error) {
+	return d.locks.Active(), nil
+}
+
+type davTestAccessControl struct {
+	manager.AccessControl
+	dfs manager.Dfs
+}
+
+func (d *davTestAccessControl) Dfs(_ *auth.Key) manager.Dfs {
+	return d.dfs
+}
+
+func davTestRequest(dfs manager.Dfs, method string, path string, body string, header map[string]string) *httptest.ResponseRecorder {
+	m := NewManager()
+	m.Add(NewDavRouter(&davTestAccessControl{dfs: dfs}, zap.NewNop()))
+
+	r := httptest.NewRequest(method, fmt.Sprintf("%s%s", davEndPoint, path), strings.NewReader(body))
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	m.Get().ServeHTTP(w, r)
+
+	return w
+}
+
+func davTestLock(t *testing.T, dfs manager.Dfs, path string) string {
+	w := davTestRequest(dfs, "LOCK", path, davTestLockInfo, map[string]string{"Depth": "0", "Timeout": "Second-60"})
+	assert.Equal(t, 200, w.Code)
+
+	token := w.Header().Get("Lock-Token")
+	assert.True(t, strings.HasPrefix(token, "<opaquelocktoken:"))
+	assert.Contains(t, w.Body.String(), "<D:href>tester</D:href>")
+
+	return strings.Trim(token, "<>")
+}
+
+func TestDavRouter_Lock(t *testing.T) {
+	dfs := newDavTestDfs("/Foo")
+
+	token := davTestLock(t, dfs, "/Foo/Bar.txt")
+	assert.Len(t, dfs.locks, 1)
+	assert.Equal(t, "/Foo/Bar.txt", dfs.locks[0].Root)
+	assert.Equal(t, uint64(60), dfs.locks[0].Timeout)
+
+	// exclusive lock is already placed
+	w := davTestRequest(dfs, "LOCK", "/Foo/Bar.txt", davTestLockInfo, nil)
+	assert.Equal(t, 423, w.Code)
+
+	// parent folder does not exist
+	w = davTestRequest(dfs, "LOCK", "/Baz/Bar.txt", davTestLockInfo, nil)
+	assert.Equal(t, 409, w.Code)
+
+	// refresh is bound to the path of the lock
+	w = davTestRequest(dfs, "LOCK", "/Foo", "", map[string]string{"If": fmt.Sprintf("(<%s>)", token), "Timeout": "Second-120"})
+	assert.Equal(t, 412, w.Code)
+	assert.Equal(t, uint64(60), dfs.locks[0].Timeout)
+
+	w = davTestRequest(dfs, "LOCK", "/Foo/Bar.txt", "", map[string]string{"If": fmt.Sprintf("(<%s>)", token), "Timeout": "Second-120"})
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, uint64(120), dfs.locks[0].Timeout)
+}
+
+func TestDavRouter_Unlock(t *testing.T) {
+	dfs := newDavTestDfs("/Foo")
+	token := davTestLock(t, dfs, "/Foo/Bar.txt")
+
+	w := davTestRequest(dfs, "UNLOCK", "/Foo/Bar.txt", "", nil)
+	assert.Equal(t, 400, w.Code)
+
+	w = davTestRequest(dfs, "UNLOCK", "/Foo/Other.txt", "", map[string]string{"Lock-Token": fmt.Sprintf("<%s>", token)})
+	assert.Equal(t, 409, w.Code)
+	assert.Len(t, dfs.locks, 1)
+
+	w = davTestRequest(dfs, "UNLOCK", "/Foo/Bar.txt", "", map[string]string{"Lock-Token": fmt.Sprintf("<%s>", token)})
+	assert.Equal(t, 204, w.Code)
+	assert.Len(t, dfs.locks, 0)
+}
+
+func TestDavRouter_Put(t *testing.T) {
+	dfs := newDavTestDfs("/Foo")
+	token := davTestLock(t, dfs, "/Foo/Bar.txt")
+
+	w := davTestRequest(dfs, http.MethodPut, "/Foo/Bar.txt", "content", nil)
+	assert.Equal(t, 423, w.Code)
+	assert.Nil(t, dfs.folders["/Foo"].File("Bar.txt"))
+
+	w = davTestRequest(dfs, http.MethodPut, "/Foo/Bar.txt", "content", map[string]string{"If": "(<opaquelocktoken:other>)"})
+	assert.Equal(t, 423, w.Code)
+
+	w = davTestRequest(dfs, http.MethodPut, "/Foo/Bar.txt", "content", map[string]string{"If": fmt.Sprintf("(<%s>)", token)})
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", dfs.folders["/Foo"].File("Bar.txt").Mime)
+
+	w = davTestRequest(dfs, http.MethodPut, "/Foo/Bar.txt", "new content", map[string]string{"If": fmt.Sprintf("</client/dav/Foo/Bar.txt> (<%s>)", token)})
+	assert.Equal(t, 204, w.Code)
+	assert.Equal(t, uint64(11), dfs.folders["/Foo"].File("Bar.txt").Size)
+
+	w = davTestRequest(dfs, http.MethodPut, "/Baz/Bar.txt", "content", nil)
+	assert.Equal(t, 409, w.Code)
+}
+
+func TestDavRouter_Put_Chunked(t *testing.T) {
+	dfs := newDavTestDfs("/Foo")
+
+	m := NewManager()
+	m.Add(NewDavRouter(&davTestAccessControl{dfs: dfs}, zap.NewNop()))
+
+	r := httptest.NewRequest(http.MethodPut, "/client/dav/Foo/Bar.bin", strings.NewReader("chunked content"))
+	r.ContentLength = -1
+
+	w := httptest.NewRecorder()
+	m.Get().ServeHTTP(w, r)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, 1, dfs.streamed)
+	assert.Equal(t, uint64(15), dfs.folders["/Foo"].File("Bar.bin").Size)
+
+	// empty chunked content is created as an empty file
+	r = httptest.NewRequest(http.MethodPut, "/client/dav/Foo/Empty.bin", strings.NewReader(""))
+	r.ContentLength = -1
+
+	w = httptest.NewRecorder()
+	m.Get().ServeHTTP(w, r)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, 1, dfs.streamed)
+	assert.Equal(t, uint64(0), dfs.folders["/Foo"].File("Empty.bin").Size)
+}
+
+func TestDavRouter_Move(t *testing.T) {
+	dfs := newDavTestDfs("/Foo", "/Baz")
+
+	w := davTestRequest(dfs, http.MethodPut, "/Foo/Bar.txt", "content", nil)
+	assert.Equal(t, 201, w.Code)
+
+	token := davTestLock(t, dfs, "/Foo/Bar.txt")
+	destination := map[string]string{"Destination": "http://127.0.0.1:4000/client/dav/Baz/Bar.txt"}
+
+	w = davTestRequest(dfs, "MOVE", "/Foo/Bar.txt", "", destination)
+	assert.Equal(t, 423, w.Code)
+	assert.NotNil(t, dfs.folders["/Foo"].File("Bar.txt"))
+
+	destination["If"] = fmt.Sprintf("(<%s>)", token)
+	w = davTestRequest(dfs, "MOVE", "/Foo/Bar.txt", "", destination)
+	assert.Equal(t, 201, w.Code)
+	assert.Nil(t, dfs.folders["/Foo"].File("Bar.txt"))
+	assert.NotNil(t, dfs.folders["/Baz"].File("Bar.txt"))
+	assert.Len(t, dfs.locks, 0)
+
+	// target is locked
+	token = davTestLock(t, dfs, "/Foo/Other.txt")
+	delete(destination, "If")
+	destination["Destination"] = "/client/dav/Foo/Other.txt"
+
+	w = davTestRequest(dfs, "MOVE", "/Baz/Bar.txt", "", destination)
+	assert.Equal(t, 423, w.Code)
+
+	destination["If"] = fmt.Sprintf("(<%s>)", token)
+	w = davTestRequest(dfs, "MOVE", "/Baz/Bar.txt", "", destination)
+	assert.Equal(t, 201, w.Code)
+	assert.NotNil(t, dfs.folders["/Foo"].File("Other.txt"))
+}
@@ -0,0 +1,206 @@
+package routing
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+const davNamespace = "DAV:"
+
+type davEmpty struct {
+	XMLName xml.Name
+}
+
+type davInnerXml struct {
+	InnerXml string `xml:",innerxml"`
+}
+
+type davPropfind struct {
+	XMLName  xml.Name      `xml:"DAV: propfind"`
+	AllProp  *struct{}     `xml:"DAV: allprop"`
+	PropName *struct{}     `xml:"DAV: propname"`
+	Prop     *davPropNames `xml:"DAV: prop"`
+}
+
+type davPropNames struct {
+	Names []davEmpty `xml:",any"`
+}
+
+type davPropertyUpdate struct {
+	XMLName xml.Name       `xml:"DAV: propertyupdate"`
+	Set     []davPropNames `xml:"DAV: set>prop"`
+	Remove  []davPropNames `xml:"DAV: remove>prop"`
+}
+
+type davLockInfo struct {
+	XMLName   xml.Name     `xml:"DAV: lockinfo"`
+	Exclusive *struct{}    `xml:"DAV: lockscope>exclusive"`
+	Shared    *struct{}    `xml:"DAV: lockscope>shared"`
+	Write     *struct{}    `xml:"DAV: locktype>write"`
+	Owner     *davInnerXml `xml:"DAV: owner"`
+}
+
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"D:multistatus"`
+	Xmlns     string        `xml:"xmlns:D,attr"`
+	Responses []davResponse `xml:"D:response"`
+}
+
+type davResponse struct {
+	Href      string        `xml:"D:href"`
+	Propstats []davPropstat `xml:"D:propstat"`
+	Status    string        `xml:"D:status,omitempty"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"D:prop"`
+	Status string  `xml:"D:status"`
+}
+
+type davResourceType struct {
+	Collection *struct{} `xml:"D:collection,omitempty"`
+}
+
+type davLockScope struct {
+	Exclusive *struct{} `xml:"D:exclusive,omitempty"`
+	Shared    *struct{} `xml:"D:shared,omitempty"`
+}
+
+type davLockType struct {
+	Write struct{} `xml:"D:write"`
+}
+
+type davHref struct {
+	Href string `xml:"D:href"`
+}
+
+type davActiveLock struct {
+	LockType  davLockType  `xml:"D:locktype"`
+	LockScope davLockScope `xml:"D:lockscope"`
+	Depth     string       `xml:"D:depth"`
+	Owner     *davInnerXml `xml:"D:owner,omitempty"`
+	Timeout   string       `xml:"D:timeout"`
+	LockToken davHref      `xml:"D:locktoken"`
+	LockRoot  davHref      `xml:"D:lockroot"`
+}
+
+type davLockDiscovery struct {
+	ActiveLocks []davActiveLock `xml:"D:activelock"`
+}
+
+type davLockEntry struct {
+	LockScope davLockScope `xml:"D:lockscope"`
+	LockType  davLockType  `xml:"D:locktype"`
+}
+
+type davSupportedLock struct {
+	LockEntries []davLockEntry `xml:"D:lockentry"`
+}
+
+type davProp struct {
+	XMLName          xml.Name          `xml:"D:prop"`
+	Xmlns            string            `xml:"xmlns:D,attr,omitempty"`
+	CreationDate     *string           `xml:"D:creationdate,omitempty"`
+	DisplayName      *string           `xml:"D:displayname,omitempty"`
+	GetContentLength *uint64           `xml:"D:getcontentlength,omitempty"`
+	GetContentType   *string           `xml:"D:getcontenttype,omitempty"`
+	GetETag          *string           `xml:"D:getetag,omitempty"`
+	GetLastModified  *string           `xml:"D:getlastmodified,omitempty"`
+	ResourceType     *davResourceType  `xml:"D:resourcetype,omitempty"`
+	SupportedLock    *davSupportedLock `xml:"D:supportedlock,omitempty"`
+	LockDiscovery    *davLockDiscovery `xml:"D:lockdiscovery,omitempty"`
+	Others           []davEmpty        `xml:",any"`
+}
+
+// pick copies the named property from the source if it is available
+func (p *davProp) pick(source *davProp, name xml.Name) bool {
+	if name.Space != davNamespace {
+		return false
+	}
+
+	switch name.Local {
+	case "creationdate":
+		p.CreationDate = source.CreationDate
+		return p.CreationDate != nil
+	case "displayname":
+		p.DisplayName = source.DisplayName
+		return p.DisplayName != nil
+	case "getcontentlength":
+		p.GetContentLength = source.GetContentLength
+		return p.GetContentLength != nil
+	case "getcontenttype":
+		p.GetContentType = source.GetContentType
+		return p.GetContentType != nil
+	case "getetag":
+		p.GetETag = source.GetETag
+		return p.GetETag != nil
+	case "getlastmodified":
+		p.GetLastModified = source.GetLastModified
+		return p.GetLastModified != nil
+	case "resourcetype":
+		p.ResourceType = source.ResourceType
+		return p.ResourceType != nil
+	case "supportedlock":
+		p.SupportedLock = source.SupportedLock
+		return p.SupportedLock != nil
+	case "lockdiscovery":
+		p.LockDiscovery = source.LockDiscovery
+		return p.LockDiscovery != nil
+	}
+	return false
+}
+
+// names creates the property with the empty values of the available properties
+func (p *davProp) names() davProp {
+	empty := ""
+	zero := uint64(0)
+
+	names := davProp{}
+	if p.CreationDate != nil {
+		names.CreationDate = &empty
+	}
+	if p.DisplayName != nil {
+		names.DisplayName = &empty
+	}
+	if p.GetContentLength != nil {
+		names.GetContentLength = &zero
+	}
+	if p.GetContentType != nil {
+		names.GetContentType = &empty
+	}
+	if p.GetETag != nil {
+		names.GetETag = &empty
+	}
+	if p.GetLastModified != nil {
+		names.GetLastModified = &empty
+	}
+	if p.ResourceType != nil {
+		names.ResourceType = &davResourceType{}
+	}
+	if p.SupportedLock != nil {
+		names.SupportedLock = &davSupportedLock{}
+	}
+	if p.LockDiscovery != nil {
+		names.LockDiscovery = &davLockDiscovery{}
+	}
+	return names
+}
+
+func (d *davRouter) status(statusCode int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", statusCode, http.StatusText(statusCode))
+}
+
+func (d *davRouter) writeXml(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return
+	}
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		d.logger.Warn("Response of dav request is failed", zap.Error(err))
+	}
+}
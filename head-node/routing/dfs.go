@@ -6,21 +6,22 @@ import (
 	"os"
 	"strings"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
 	"github.com/freakmaxi/kertish-dfs/basics/common"
 	"github.com/freakmaxi/kertish-dfs/head-node/manager"
 	"go.uber.org/zap"
 )
 
 type dfsRouter struct {
-	dfs    manager.Dfs
+	access manager.AccessControl
 	logger *zap.Logger
 
 	definitions []*Definition
 }
 
-func NewDfsRouter(dfs manager.Dfs, logger *zap.Logger) Router {
+func NewDfsRouter(access manager.AccessControl, logger *zap.Logger) Router {
 	pR := &dfsRouter{
-		dfs:         dfs,
+		access:      access,
 		logger:      logger,
 		definitions: make([]*Definition, 0),
 	}
@@ -43,6 +44,11 @@ func (d *dfsRouter) Get() []*Definition {
 	return d.definitions
 }
 
+// dfs returns the file manipulation operations that are evaluated for the authenticated key of the request
+func (d *dfsRouter) dfs(r *http.Request) manager.Dfs {
+	return d.access.Dfs(auth.FromContext(r.Context()))
+}
+
 func (d *dfsRouter) manipulate(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = r.Body.Close() }()
 
@@ -53,6 +59,8 @@ func (d *dfsRouter) manipulate(w http.ResponseWriter, r *http.Request) {
 		d.handlePost(w, r)
 	case http.MethodPut:
 		d.handlePut(w, r)
+	case http.MethodPatch:
+		d.handlePatch(w, r)
 	case http.MethodDelete:
 		d.handleDelete(w, r)
 	default:
@@ -62,7 +70,7 @@ func (d *dfsRouter) manipulate(w http.ResponseWriter, r *http.Request) {
 
 func (d *dfsRouter) validateApplyTo(applyTo string) bool {
 	switch applyTo {
-	case "folder", "file":
+	case "folder", "file", "archive":
 		return true
 	}
 	return false
@@ -19,10 +19,16 @@ func (d *dfsRouter) handleDelete(w http.ResponseWriter, r *http.Request) {
 	killZombiesHeader := strings.ToLower(r.Header.Get("X-Kill-Zombies"))
 	killZombies := len(killZombiesHeader) > 0 && (strings.Compare(killZombiesHeader, "1") == 0 || strings.Compare(killZombiesHeader, "true") == 0)
 
-	if err := d.dfs.Delete(requestedPaths[0], killZombies); err != nil {
+	if err := d.dfs(r).Delete(requestedPaths[0], killZombies, describePrecondition(r)); err != nil {
 		if err == os.ErrNotExist {
 			w.WriteHeader(404)
 			return
+		} else if err == errors.ErrForbidden {
+			w.WriteHeader(403)
+			return
+		} else if err == errors.ErrPrecondition {
+			w.WriteHeader(412)
+			return
 		} else if err == errors.ErrNoAvailableActionNode {
 			w.WriteHeader(503)
 			return
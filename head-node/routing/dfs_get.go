@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
 	"github.com/freakmaxi/kertish-dfs/basics/common"
 	"github.com/freakmaxi/kertish-dfs/basics/errors"
 	"github.com/freakmaxi/kertish-dfs/head-node/manager"
@@ -21,11 +22,24 @@ func (d *dfsRouter) handleGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	read, err := d.dfs.Read(requestedPaths, strings.Compare(sourceAction, "j") == 0)
+	var read manager.ReadContainer
+
+	if versionId := r.Header.Get("X-Version"); len(versionId) > 0 {
+		if len(requestedPaths) > 1 || len(sourceAction) > 0 {
+			w.WriteHeader(422)
+			return
+		}
+		read, err = d.dfs(r).ReadVersion(requestedPaths[0], versionId)
+	} else {
+		read, err = d.dfs(r).Read(requestedPaths, strings.Compare(sourceAction, "j") == 0)
+	}
 	if err != nil {
 		if err == os.ErrNotExist {
 			w.WriteHeader(404)
 			return
+		} else if err == errors.ErrForbidden {
+			w.WriteHeader(403)
+			return
 		} else if err == os.ErrInvalid {
 			w.WriteHeader(422)
 			return
@@ -46,8 +60,19 @@ func (d *dfsRouter) handleGet(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if read.Type() == manager.RTFolder {
+		// presigned urls are only valid for the file downloads
+		if auth.IsPresigned(r.Context()) {
+			w.WriteHeader(422)
+			return
+		}
+
 		w.Header().Set("X-Type", "folder")
 
+		if archiveHeader := r.Header.Get("X-Archive"); len(archiveHeader) > 0 {
+			d.writeArchive(w, r, read.Folder(), archiveHeader)
+			return
+		}
+
 		calculateUsageHeader := strings.ToLower(r.Header.Get("X-Calculate-Usage"))
 		calculateUsage := len(calculateUsageHeader) > 0 && (strings.Compare(calculateUsageHeader, "1") == 0 || strings.Compare(calculateUsageHeader, "true") == 0)
 
@@ -80,17 +105,34 @@ func (d *dfsRouter) handleGet(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		listing, err := d.describeListing(r)
+		if err != nil {
+			w.WriteHeader(422)
+			return
+		}
+
 		folder := read.Folder()
 
 		if calculateUsage {
 			folder.CalculateUsage(func(shadows common.FolderShadows) {
 				for _, shadow := range shadows {
-					shadow.Size, _ = d.dfs.Size(shadow.Full)
+					shadow.Size, _ = d.dfs(r).Size(shadow.Full)
 				}
 			})
 		}
 
-		if err := json.NewEncoder(w).Encode(folder); err != nil {
+		page, next, err := folder.Page(*listing)
+		if err != nil {
+			w.WriteHeader(422)
+			return
+		}
+
+		w.Header().Set("X-Total", strconv.Itoa(listing.Total(folder)))
+		if len(next) > 0 {
+			w.Header().Set("X-Continue", next)
+		}
+
+		if err := json.NewEncoder(w).Encode(page); err != nil {
 			w.WriteHeader(500)
 			d.logger.Error(
 				"Response of read request (folder) is failed",
@@ -106,14 +148,41 @@ func (d *dfsRouter) handleGet(w http.ResponseWriter, r *http.Request) {
 	downloadHeader := strings.ToLower(r.Header.Get("X-Download"))
 	download := len(downloadHeader) > 0 && (strings.Compare(downloadHeader, "1") == 0 || strings.Compare(downloadHeader, "true") == 0)
 
+	file := read.File()
+	etag := manager.ETag(file)
+
+	if len(etag) > 0 {
+		w.Header().Set("ETag", etag)
+	}
+	w.Header().Set("Last-Modified", file.Modified.UTC().Format(http.TimeFormat))
+
+	if notModified(r, etag, file.Modified) {
+		w.WriteHeader(304)
+		return
+	}
+
 	requestRange := r.Header.Get("Range")
-	partialRequest := len(requestRange) > 0
+	if !ifRangeMatches(r.Header.Get("If-Range"), etag, file.Modified) {
+		requestRange = ""
+	}
 
-	push, begins, ends := d.prepareResponseHeaders(w, read.File(), download, partialRequest, requestRange)
+	push, ranges, multipart := d.prepareResponseHeaders(w, file, download, requestRange)
 	if !push {
 		return
 	}
 
+	if multipart != nil {
+		if err := multipart.Write(w, read.Read); err != nil {
+			d.logger.Warn(
+				"Streaming file content ranges is failed",
+				zap.Strings("paths", requestedPaths),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+
+	begins, ends := ranges[0].begins, ranges[0].ends
 	if err := read.Read(w, begins, ends); err != nil {
 		d.logger.Warn(
 			"Streaming file content is failed",
@@ -125,49 +194,137 @@ func (d *dfsRouter) handleGet(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (d *dfsRouter) prepareResponseHeaders(w http.ResponseWriter, file *common.File, download bool, partialRequest bool, requestRange string) (bool, int64, int64) {
+// writeArchive streams the folder tree in the requested archive format with the files that are selected by
+// the include and exclude glob patterns
+func (d *dfsRouter) writeArchive(w http.ResponseWriter, r *http.Request, folder *common.Folder, archiveHeader string) {
+	format, err := manager.ParseArchiveFormat(archiveHeader)
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	filter := &manager.ArchiveFilter{
+		Include: r.Header.Get("X-Archive-Include"),
+		Exclude: r.Header.Get("X-Archive-Exclude"),
+	}
+	if err := filter.Validate(); err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	streamHandler, err := d.dfs(r).Archive(folder.Full, format, filter)
+	if err != nil {
+		if err == os.ErrNotExist {
+			w.WriteHeader(404)
+			return
+		} else if err == errors.ErrForbidden {
+			w.WriteHeader(403)
+			return
+		}
+		w.WriteHeader(500)
+		d.logger.Error("Archive request is failed", zap.String("path", folder.Full), zap.Error(err))
+		return
+	}
+
+	name := folder.Name
+	if len(name) == 0 {
+		name = "root"
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.%s\"", name, format))
+
+	if err := streamHandler(w); err != nil {
+		d.logger.Warn(
+			"Streaming folder archive is failed",
+			zap.String("path", folder.Full),
+			zap.String("format", string(format)),
+			zap.Error(err),
+		)
+	}
+}
+
+func (d *dfsRouter) prepareResponseHeaders(w http.ResponseWriter, file *common.File, download bool, requestRange string) (bool, []byteRange, *multipartByteRanges) {
 	w.Header().Set("Content-Type", file.Mime)
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("X-Checksum", file.Checksum)
+	writeMetadata(w, file.Metadata)
+	if file.Expires != nil {
+		w.Header().Set("X-Expires", file.Expires.UTC().Format(http.TimeFormat))
+	}
 
-	begins, ends := int64(0), int64(file.Size)-1
+	size := int64(file.Size)
+
+	var ranges []byteRange
+	partialRequest := false
+
+	if len(requestRange) > 0 {
+		ranges, partialRequest = describeByteRanges(requestRange, size)
+	}
 
 	if !partialRequest {
-		w.Header().Set("Accept-Ranges", "bytes")
 		w.Header().Set("Content-Length", strconv.FormatUint(file.Size, 10))
 		if download {
 			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", file.Name))
 		}
-		w.Header().Set("X-Checksum", file.Checksum)
-		return true, begins, ends
+		return true, []byteRange{{begins: 0, ends: size - 1}}, nil
 	}
 
-	bytesTag := "bytes="
-	bytesIdx := strings.Index(requestRange, bytesTag)
-	if bytesIdx == 0 {
-		ranges := requestRange[bytesIdx+len(bytesTag):]
-		byteRanges := strings.Split(ranges, "-")
-
-		var err error
-		begins, err = strconv.ParseInt(byteRanges[0], 10, 64)
-		if err != nil {
-			w.WriteHeader(416)
-			return false, 0, 0
-		}
-		ends, err = strconv.ParseInt(byteRanges[1], 10, 64)
-		if err != nil {
-			ends = int64(file.Size) - 1
-		}
-
-		if ends > int64(file.Size)-1 || begins > ends {
-			w.WriteHeader(416)
-			return false, 0, 0
-		}
+	if len(ranges) == 0 {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", file.Size))
+		w.WriteHeader(416)
+		return false, nil, nil
+	}
 
-		w.Header().Set("Content-Length", strconv.FormatInt(ends-begins+1, 10))
+	if len(ranges) == 1 {
+		w.Header().Set("Content-Length", strconv.FormatInt(ranges[0].length(), 10))
 		w.Header().Set("Content-Encoding", "identity")
-		w.Header().Set("Content-Range",
-			fmt.Sprintf("bytes %d-%d/%d", begins, ends, file.Size))
+		w.Header().Set("Content-Range", ranges[0].contentRange(size))
 		w.WriteHeader(206)
+
+		return true, ranges, nil
+	}
+
+	multipart, err := newMultipartByteRanges(file.Mime, size, ranges)
+	if err != nil {
+		w.WriteHeader(500)
+		d.logger.Error("Preparing multipart ranges response is failed", zap.Error(err))
+		return false, nil, nil
 	}
 
-	return true, begins, ends
+	w.Header().Set("Content-Type", multipart.ContentType())
+	w.Header().Set("Content-Length", strconv.FormatInt(multipart.ContentLength(), 10))
+	w.WriteHeader(206)
+
+	return true, ranges, multipart
+}
+
+// describeListing parses the sorting, filtering and paging requirements of the folder listing
+func (d *dfsRouter) describeListing(r *http.Request) (*common.FolderListing, error) {
+	listing := &common.FolderListing{
+		Sort:         strings.ToLower(r.Header.Get("X-Sort")),
+		Only:         strings.ToLower(r.Header.Get("X-Only")),
+		Continuation: r.Header.Get("X-Continue"),
+	}
+
+	switch strings.ToLower(r.Header.Get("X-Sort-Order")) {
+	case "", "asc":
+	case "desc":
+		listing.Descending = true
+	default:
+		return nil, os.ErrInvalid
+	}
+
+	if limitHeader := r.Header.Get("X-Limit"); len(limitHeader) > 0 {
+		limit, err := strconv.Atoi(limitHeader)
+		if err != nil || limit < 1 {
+			return nil, os.ErrInvalid
+		}
+		listing.Limit = limit
+	}
+
+	if !listing.Valid() {
+		return nil, os.ErrInvalid
+	}
+	return listing, nil
 }
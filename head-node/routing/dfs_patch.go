@@ -0,0 +1,95 @@
+package routing
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"go.uber.org/zap"
+)
+
+func (d *dfsRouter) handlePatch(w http.ResponseWriter, r *http.Request) {
+	requestedPaths, _, err := d.describeXPath(r.Header.Get("X-Path"))
+	if err != nil || len(requestedPaths) > 1 {
+		w.WriteHeader(422)
+		return
+	}
+
+	metadata, err := describeMetadata(r.Header)
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	ttl, err := describeTimeToLive(r.Header)
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	var blockSize *uint32
+	if len(r.Header.Get("X-Block-Size")) > 0 {
+		value, err := describeBlockSize(r.Header)
+		if err != nil {
+			w.WriteHeader(422)
+			return
+		}
+		blockSize = &value
+	}
+
+	replaceHeader := strings.ToLower(r.Header.Get("X-Replace-Metadata"))
+	replace := len(replaceHeader) > 0 && (strings.Compare(replaceHeader, "1") == 0 || strings.Compare(replaceHeader, "true") == 0)
+
+	precondition := describePrecondition(r)
+
+	if blockSize != nil {
+		if err := d.dfs(r).SetBlockSize(requestedPaths[0], *blockSize); err != nil {
+			d.writePatchError(w, err, requestedPaths[0], "Update block size request is failed")
+			return
+		}
+
+		// block size is the only change of the request
+		if ttl == nil && len(metadata) == 0 && !replace {
+			return
+		}
+	}
+
+	if ttl != nil {
+		if err := d.dfs(r).UpdateTimeToLive(requestedPaths[0], *ttl, precondition); err != nil {
+			d.writePatchError(w, err, requestedPaths[0], "Update time-to-live request is failed")
+			return
+		}
+
+		// time-to-live is the only change of the request
+		if len(metadata) == 0 && !replace {
+			return
+		}
+	}
+
+	if err := d.dfs(r).UpdateMetadata(requestedPaths[0], metadata, replace, precondition); err != nil {
+		d.writePatchError(w, err, requestedPaths[0], "Update metadata request is failed")
+	}
+}
+
+func (d *dfsRouter) writePatchError(w http.ResponseWriter, err error, path string, logMessage string) {
+	if err == os.ErrNotExist {
+		w.WriteHeader(404)
+		return
+	} else if err == errors.ErrForbidden {
+		w.WriteHeader(403)
+		return
+	} else if err == errors.ErrPrecondition {
+		w.WriteHeader(412)
+		return
+	} else if err == os.ErrInvalid {
+		w.WriteHeader(422)
+		return
+	} else if err == errors.ErrLock {
+		w.WriteHeader(523)
+		return
+	} else {
+		w.WriteHeader(500)
+	}
+	d.logger.Error(logMessage, zap.String("path", path), zap.Error(err))
+}
@@ -1,11 +1,15 @@
 package routing
 
 import (
+	"bufio"
+	"encoding/json"
+	"io"
 	"net/http"
 	"os"
 	"strings"
 
 	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
 	"go.uber.org/zap"
 )
 
@@ -18,12 +22,19 @@ func (d *dfsRouter) handlePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	switch applyTo {
+	case "archive":
+		d.handleExtract(w, r, requestedPaths[0])
+		return
 	case "folder":
-		if err := d.dfs.CreateFolder(requestedPaths[0]); err != nil {
+		if err := d.dfs(r).CreateFolder(requestedPaths[0]); err != nil {
 			if err == os.ErrExist {
 				w.WriteHeader(409)
 				return
 			}
+			if err == errors.ErrForbidden {
+				w.WriteHeader(403)
+				return
+			}
 			w.WriteHeader(500)
 			d.logger.Error(
 				"Create folder request is failed",
@@ -43,24 +54,67 @@ func (d *dfsRouter) handlePost(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		contentLength := r.ContentLength
+		metadata, err := describeMetadata(r.Header)
+		if err != nil {
+			w.WriteHeader(422)
+			return
+		}
 
-		if !allowEmpty && contentLength == -1 {
-			w.WriteHeader(411)
+		ttl, err := describeTimeToLive(r.Header)
+		if err != nil {
+			w.WriteHeader(422)
 			return
 		}
 
-		if contentLength == -1 {
-			contentLength = 0
+		blockSize, err := describeBlockSize(r.Header)
+		if err != nil {
+			w.WriteHeader(422)
+			return
 		}
 
 		overwriteHeader := strings.ToLower(r.Header.Get("X-Overwrite"))
 		overwrite := len(overwriteHeader) > 0 && (strings.Compare(overwriteHeader, "1") == 0 || strings.Compare(overwriteHeader, "true") == 0)
 
-		if err := d.dfs.CreateFile(requestedPaths[0], contentType, uint64(contentLength), overwrite, r.Body); err != nil {
+		contentLength := r.ContentLength
+		contentReader := bufio.NewReader(r.Body)
+
+		// unknown length content (chunked transfer) is streamed if it is not empty
+		stream := false
+		if contentLength == -1 {
+			_, err := contentReader.Peek(1)
+			switch err {
+			case nil:
+				stream = true
+			case io.EOF:
+				if !allowEmpty {
+					w.WriteHeader(411)
+					return
+				}
+				contentLength = 0
+			default:
+				w.WriteHeader(400)
+				return
+			}
+		}
+
+		precondition := describePrecondition(r)
+
+		if stream {
+			err = d.dfs(r).CreateStream(requestedPaths[0], contentType, metadata, ttl, blockSize, overwrite, precondition, contentReader)
+		} else {
+			err = d.dfs(r).CreateFile(requestedPaths[0], contentType, metadata, ttl, blockSize, uint64(contentLength), overwrite, precondition, contentReader)
+		}
+
+		if err != nil {
 			if err == os.ErrExist {
 				w.WriteHeader(409)
 				return
+			} else if err == errors.ErrForbidden {
+				w.WriteHeader(403)
+				return
+			} else if err == errors.ErrPrecondition {
+				w.WriteHeader(412)
+				return
 			} else if err == os.ErrInvalid {
 				w.WriteHeader(422)
 				return
@@ -70,6 +124,12 @@ func (d *dfsRouter) handlePost(w http.ResponseWriter, r *http.Request) {
 			} else if err == errors.ErrNoSpace {
 				w.WriteHeader(507)
 				return
+			} else if err == errors.ErrLock {
+				w.WriteHeader(523)
+				return
+			} else if err == errors.ErrQuota {
+				w.WriteHeader(527)
+				return
 			} else {
 				w.WriteHeader(500)
 			}
@@ -85,3 +145,49 @@ func (d *dfsRouter) handlePost(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(202)
 }
+
+// handleExtract creates the folders and the files of the archive in the request body inside the folder and
+// responds the results of the archive entries
+func (d *dfsRouter) handleExtract(w http.ResponseWriter, r *http.Request, folderPath string) {
+	format, err := manager.ParseArchiveFormat(r.Header.Get("X-Archive"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	overwriteHeader := strings.ToLower(r.Header.Get("X-Overwrite"))
+	overwrite := len(overwriteHeader) > 0 && (strings.Compare(overwriteHeader, "1") == 0 || strings.Compare(overwriteHeader, "true") == 0)
+
+	report, err := d.dfs(r).Extract(folderPath, format, overwrite, r.Body)
+	if err != nil {
+		switch err {
+		case errors.ErrArchive:
+			// already extracted entries are reported with the failure
+		case errors.ErrForbidden:
+			w.WriteHeader(403)
+			return
+		default:
+			w.WriteHeader(500)
+			d.logger.Error(
+				"Extract archive request is failed",
+				zap.String("path", folderPath),
+				zap.String("format", string(format)),
+				zap.Error(err),
+			)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err == errors.ErrArchive {
+		w.WriteHeader(422)
+	}
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		d.logger.Error(
+			"Response of extract archive request is failed",
+			zap.String("path", folderPath),
+			zap.Error(err),
+		)
+	}
+}
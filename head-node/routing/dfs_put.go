@@ -34,17 +34,20 @@ func (d *dfsRouter) handlePut(w http.ResponseWriter, r *http.Request) {
 		operation = "Move"
 	}
 
-	if err := d.dfs.Change(requestedPaths, targetPath, join, overwrite, strings.Compare(targetAction, "m") == 0); err != nil {
+	if err := d.dfs(r).Change(requestedPaths, targetPath, join, overwrite, strings.Compare(targetAction, "m") == 0, describePrecondition(r)); err != nil {
 		if err == os.ErrNotExist {
 			w.WriteHeader(404)
 			return
+		} else if err == errors.ErrForbidden {
+			w.WriteHeader(403)
+			return
 		} else if err == errors.ErrNotEmpty {
 			w.WriteHeader(406)
 			return
 		} else if err == os.ErrExist {
 			w.WriteHeader(409)
 			return
-		} else if err == errors.ErrJoinConflict {
+		} else if err == errors.ErrJoinConflict || err == errors.ErrPrecondition {
 			w.WriteHeader(412)
 			return
 		} else if err == os.ErrInvalid {
@@ -53,9 +56,15 @@ func (d *dfsRouter) handlePut(w http.ResponseWriter, r *http.Request) {
 		} else if err == errors.ErrNoAvailableActionNode {
 			w.WriteHeader(503)
 			return
+		} else if err == errors.ErrLock {
+			w.WriteHeader(523)
+			return
 		} else if err == errors.ErrZombie {
 			w.WriteHeader(524)
 			return
+		} else if err == errors.ErrQuota {
+			w.WriteHeader(527)
+			return
 		} else {
 			w.WriteHeader(500)
 		}
@@ -6,21 +6,22 @@ import (
 	"os"
 	"strings"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
 	"github.com/freakmaxi/kertish-dfs/basics/common"
 	"github.com/freakmaxi/kertish-dfs/head-node/manager"
 	"go.uber.org/zap"
 )
 
 type hookRouter struct {
-	hook   manager.Hook
+	access manager.AccessControl
 	logger *zap.Logger
 
 	definitions []*Definition
 }
 
-func NewHookRouter(hook manager.Hook, logger *zap.Logger) Router {
+func NewHookRouter(access manager.AccessControl, logger *zap.Logger) Router {
 	pR := &hookRouter{
-		hook:        hook,
+		access:      access,
 		logger:      logger,
 		definitions: make([]*Definition, 0),
 	}
@@ -43,6 +44,11 @@ func (h *hookRouter) Get() []*Definition {
 	return h.definitions
 }
 
+// hook returns the hook manipulation operations that are evaluated for the authenticated key of the request
+func (h *hookRouter) hook(r *http.Request) manager.Hook {
+	return h.access.Hook(auth.FromContext(r.Context()))
+}
+
 func (h *hookRouter) manipulate(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = r.Body.Close() }()
 
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
 	"go.uber.org/zap"
 )
 
@@ -21,10 +22,13 @@ func (h *hookRouter) handleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.hook.Delete(requestedPaths[0], hookIds); err != nil {
+	if err := h.hook(r).Delete(requestedPaths[0], hookIds); err != nil {
 		if err == os.ErrNotExist {
 			w.WriteHeader(404)
 			return
+		} else if err == errors.ErrForbidden {
+			w.WriteHeader(403)
+			return
 		} else {
 			w.WriteHeader(500)
 		}
@@ -7,8 +7,8 @@ import (
 	"go.uber.org/zap"
 )
 
-func (h *hookRouter) handleGet(w http.ResponseWriter, _ *http.Request) {
-	availableHooks := h.hook.GetAvailableList()
+func (h *hookRouter) handleGet(w http.ResponseWriter, r *http.Request) {
+	availableHooks := h.hook(r).GetAvailableList()
 
 	if err := json.NewEncoder(w).Encode(availableHooks); err != nil {
 		w.WriteHeader(500)
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
 	"github.com/freakmaxi/kertish-dfs/basics/hooks"
 	"go.uber.org/zap"
 )
@@ -24,11 +25,15 @@ func (h *hookRouter) handlePost(w http.ResponseWriter, r *http.Request) {
 	}
 	hook.Prepare()
 
-	if err := h.hook.Add(requestedPaths, &hook); err != nil {
+	if err := h.hook(r).Add(requestedPaths, &hook); err != nil {
 		if err == os.ErrExist {
 			w.WriteHeader(409)
 			return
 		}
+		if err == errors.ErrForbidden {
+			w.WriteHeader(403)
+			return
+		}
 		w.WriteHeader(500)
 		h.logger.Error(
 			"Add hook request is failed",
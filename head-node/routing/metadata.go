@@ -0,0 +1,35 @@
+package routing
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+const metadataHeaderPrefix = "X-Meta-"
+
+// describeMetadata collects the user-defined metadata from the X-Meta-* headers. Keys are kept in lowercase and
+// the header values of the same key are joined with comma. Empty values are kept for the removal on update
+func describeMetadata(header http.Header) (common.Metadata, error) {
+	metadata := make(common.Metadata)
+	for name, values := range header {
+		if !strings.HasPrefix(name, metadataHeaderPrefix) {
+			continue
+		}
+		metadata[strings.ToLower(name[len(metadataHeaderPrefix):])] = strings.Join(values, ",")
+	}
+
+	if !metadata.Valid() {
+		return nil, os.ErrInvalid
+	}
+	return metadata, nil
+}
+
+// writeMetadata exports the user-defined metadata of the file as X-Meta-* headers
+func writeMetadata(w http.ResponseWriter, metadata common.Metadata) {
+	for k, v := range metadata {
+		w.Header().Set(metadataHeaderPrefix+k, v)
+	}
+}
@@ -0,0 +1,164 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	presignEndPoint  = "/client/presign"
+	presignTargetUrl = "/client/dfs"
+)
+
+type presignRouter struct {
+	presigner *auth.Presigner
+	access    manager.AccessControl
+	logger    *zap.Logger
+
+	definitions []*Definition
+}
+
+// NewPresignRouter creates the router that issues the presigned urls of the file download and upload requests
+func NewPresignRouter(presigner *auth.Presigner, access manager.AccessControl, logger *zap.Logger) Router {
+	pR := &presignRouter{
+		presigner:   presigner,
+		access:      access,
+		logger:      logger,
+		definitions: make([]*Definition, 0),
+	}
+	pR.setup()
+
+	return pR
+}
+
+func (p *presignRouter) setup() {
+	p.definitions =
+		append(p.definitions,
+			&Definition{
+				Path:    presignEndPoint,
+				Handler: p.manipulate,
+			},
+		)
+}
+
+func (p *presignRouter) Get() []*Definition {
+	return p.definitions
+}
+
+func (p *presignRouter) manipulate(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	switch r.Method {
+	case http.MethodGet:
+		p.handleGet(w, r)
+	default:
+		w.WriteHeader(406)
+	}
+}
+
+func (p *presignRouter) describeXPath(xPath string) (string, error) {
+	requestedPath, err := url.QueryUnescape(xPath)
+	if err != nil {
+		return "", err
+	}
+	if len(requestedPath) == 0 || !common.ValidatePath(requestedPath) {
+		return "", os.ErrInvalid
+	}
+	return requestedPath, nil
+}
+
+// describeMethod parses the X-Method header as the method that the presigned url grants and returns the
+// permission that is required to issue it. Default: GET
+func (p *presignRouter) describeMethod(xMethod string) (string, common.Permission, error) {
+	switch strings.ToUpper(xMethod) {
+	case "", http.MethodGet:
+		return http.MethodGet, common.PermissionRead, nil
+	case http.MethodPost:
+		return http.MethodPost, common.PermissionWrite, nil
+	}
+	return "", "", os.ErrInvalid
+}
+
+// presignIssuer returns the key id that the presigned url is evaluated for. Access lists are not evaluated for
+// the admin keys and when the authentication is disabled so the url is issued without the issuer
+func (p *presignRouter) presignIssuer(key *auth.Key) string {
+	if key == nil || key.Has(auth.ScopeAdmin) {
+		return ""
+	}
+	return key.Id
+}
+
+// presignedRequestHeaders are the request headers that the presigned requests can carry for the method. The other
+// headers are dropped, so the request stays as the plain file download or upload
+var presignedRequestHeaders = map[string][]string{
+	http.MethodGet:  {"Range", "If-Range", "If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"},
+	http.MethodPost: {"Content-Type", "Content-Length"},
+}
+
+// NewPresignMiddleware creates the middleware that authorizes the presigned file download and upload requests.
+// Presigned path is passed to the handlers as X-Path header and the issuer key of the url is passed in the
+// request context, so the access lists of the issuer are evaluated. The issuer key should still exist in the keys
+// with the client scope. The other middlewares do not authenticate the request again
+func NewPresignMiddleware(presigner *auth.Presigner, authenticator auth.Authenticator, logger *zap.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path, issuer, err := presigner.Validate(r)
+			if err == nil && len(path) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var key *auth.Key
+			if err == nil && len(issuer) > 0 {
+				// the issuer can not be evaluated without the keys
+				if authenticator == nil {
+					err = errors.ErrUnauthorized
+				} else {
+					key, err = authenticator.Key(issuer, auth.ScopeClient)
+				}
+			}
+
+			// presigned urls are only valid for the file download and upload requests
+			allowedHeaders, has := presignedRequestHeaders[r.Method]
+			if err == nil && strings.Compare(r.URL.Path, presignTargetUrl) == 0 && has {
+				header := make(http.Header)
+				for _, name := range allowedHeaders {
+					for _, value := range r.Header.Values(name) {
+						header.Add(name, value)
+					}
+				}
+				header.Set("X-Path", url.QueryEscape(path))
+				if r.Method == http.MethodPost {
+					header.Set("X-Apply-To", "file")
+				}
+				r.Header = header
+
+				ctx := auth.NewPresignedContext(r.Context())
+				if key != nil {
+					ctx = auth.NewContext(ctx, key)
+				}
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			w.WriteHeader(401)
+
+			e := common.NewError(801, errors.ErrUnauthorized.Error())
+			if err := json.NewEncoder(w).Encode(e); err != nil {
+				logger.Error("Response of presigned url failure is failed", zap.Error(err))
+			}
+		})
+	}
+}
+
+var _ Router = &presignRouter{}
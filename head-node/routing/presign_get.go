@@ -0,0 +1,75 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"go.uber.org/zap"
+)
+
+type presignedUrl struct {
+	Url       string    `json:"url"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (p *presignRouter) handleGet(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := p.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	method, permission, err := p.describeMethod(r.Header.Get("X-Method"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	expiresIn := time.Hour
+	if expiresInHeader := r.Header.Get("X-Expires-In"); len(expiresInHeader) > 0 {
+		expiresIn, err = time.ParseDuration(expiresInHeader)
+		if err != nil || expiresIn <= 0 || expiresIn > auth.MaxPresignExpiry {
+			w.WriteHeader(422)
+			return
+		}
+	}
+
+	key := auth.FromContext(r.Context())
+	if err := p.access.Check(key, requestedPath, permission); err != nil {
+		if err == errors.ErrForbidden {
+			w.WriteHeader(403)
+			return
+		}
+		w.WriteHeader(500)
+		p.logger.Error(
+			"Presign request is failed",
+			zap.String("path", requestedPath),
+			zap.String("method", method),
+			zap.Error(err),
+		)
+		return
+	}
+
+	expiresAt := time.Now().Add(expiresIn).Truncate(time.Second)
+	query := p.presigner.Presign(method, presignTargetUrl, requestedPath, p.presignIssuer(key), expiresAt)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&presignedUrl{
+		Url:       presignTargetUrl + "?" + query.Encode(),
+		Method:    method,
+		Path:      requestedPath,
+		ExpiresAt: expiresAt.UTC(),
+	}); err != nil {
+		w.WriteHeader(500)
+		p.logger.Error(
+			"Response of presign request is failed",
+			zap.String("path", requestedPath),
+			zap.Error(err),
+		)
+	}
+}
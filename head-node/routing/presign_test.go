@@ -0,0 +1,71 @@
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestPresignMiddleware(t *testing.T) {
+	presigner, err := auth.NewPresigner("pr3s1gn-s3cr3t-k3y")
+	assert.Nil(t, err)
+
+	authenticator, err := auth.NewAuthenticatorWithKeys([]*auth.Key{
+		{Id: "app", Secret: "4pp", Scopes: []auth.Scope{auth.ScopeClient}},
+	})
+	assert.Nil(t, err)
+
+	var header http.Header
+	var key *auth.Key
+	handler := NewPresignMiddleware(presigner, authenticator, zap.NewNop())(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			key = auth.FromContext(r.Context())
+		}))
+
+	request := func(method string, issuer string) *http.Request {
+		query := presigner.Presign(method, presignTargetUrl, "/Foo/Bar.jpg", issuer, time.Now().Add(time.Minute))
+		return httptest.NewRequest(method, presignTargetUrl+"?"+query.Encode(), nil)
+	}
+
+	r := request(http.MethodGet, "app")
+	r.Header.Set("Range", "bytes=0-9")
+	r.Header.Set("If-None-Match", "\"etag\"")
+	r.Header.Set("X-Archive", "1")
+	r.Header.Set("X-Version", "1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "bytes=0-9", header.Get("Range"))
+	assert.Equal(t, "\"etag\"", header.Get("If-None-Match"))
+	assert.Equal(t, "%2FFoo%2FBar.jpg", header.Get("X-Path"))
+	assert.Empty(t, header.Get("X-Archive"))
+	assert.Empty(t, header.Get("X-Version"))
+	assert.Equal(t, "app", key.Id)
+
+	r = request(http.MethodPost, "")
+	r.Header.Set("Content-Type", "image/jpeg")
+	r.Header.Set("Range", "bytes=0-9")
+	r.Header.Set("X-Overwrite", "1")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "image/jpeg", header.Get("Content-Type"))
+	assert.Equal(t, "file", header.Get("X-Apply-To"))
+	assert.Empty(t, header.Get("Range"))
+	assert.Empty(t, header.Get("X-Overwrite"))
+	assert.Nil(t, key)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, request(http.MethodGet, "revoked"))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	NewPresignMiddleware(presigner, nil, zap.NewNop())(handler).ServeHTTP(w, request(http.MethodGet, "app"))
+	assert.Equal(t, 401, w.Code)
+}
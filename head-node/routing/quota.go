@@ -0,0 +1,98 @@
+package routing
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"go.uber.org/zap"
+)
+
+const quotaEndPoint = "/client/quota"
+
+type quotaRouter struct {
+	access manager.AccessControl
+	logger *zap.Logger
+
+	definitions []*Definition
+}
+
+// NewQuotaRouter creates the router of the folder quotas
+func NewQuotaRouter(access manager.AccessControl, logger *zap.Logger) Router {
+	pR := &quotaRouter{
+		access:      access,
+		logger:      logger,
+		definitions: make([]*Definition, 0),
+	}
+	pR.setup()
+
+	return pR
+}
+
+func (q *quotaRouter) setup() {
+	q.definitions =
+		append(q.definitions,
+			&Definition{
+				Path:    quotaEndPoint,
+				Handler: q.manipulate,
+			},
+		)
+}
+
+func (q *quotaRouter) Get() []*Definition {
+	return q.definitions
+}
+
+// dfs returns the file manipulation operations that are evaluated for the authenticated key of the request
+func (q *quotaRouter) dfs(r *http.Request) manager.Dfs {
+	return q.access.Dfs(auth.FromContext(r.Context()))
+}
+
+func (q *quotaRouter) manipulate(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	switch r.Method {
+	case http.MethodGet:
+		q.handleGet(w, r)
+	case http.MethodPut:
+		q.handlePut(w, r)
+	case http.MethodDelete:
+		q.handleDelete(w, r)
+	default:
+		w.WriteHeader(406)
+	}
+}
+
+func (q *quotaRouter) describeXPath(xPath string) (string, error) {
+	requestedPath, err := url.QueryUnescape(xPath)
+	if err != nil {
+		return "", err
+	}
+	if len(requestedPath) == 0 || !common.ValidatePath(requestedPath) {
+		return "", os.ErrInvalid
+	}
+	return requestedPath, nil
+}
+
+func (q *quotaRouter) writeError(w http.ResponseWriter, err error, path string, logMessage string) {
+	switch err {
+	case os.ErrNotExist:
+		w.WriteHeader(404)
+		return
+	case errors.ErrForbidden:
+		w.WriteHeader(403)
+		return
+	case os.ErrInvalid:
+		w.WriteHeader(422)
+		return
+	}
+
+	w.WriteHeader(500)
+	q.logger.Error(logMessage, zap.String("path", path), zap.Error(err))
+}
+
+var _ Router = &quotaRouter{}
@@ -0,0 +1,20 @@
+package routing
+
+import (
+	"net/http"
+)
+
+func (q *quotaRouter) handleDelete(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := q.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	if err := q.dfs(r).SetQuota(requestedPath, nil); err != nil {
+		q.writeError(w, err, requestedPath, "Remove quota request is failed")
+		return
+	}
+
+	w.WriteHeader(200)
+}
@@ -0,0 +1,32 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+func (q *quotaRouter) handleGet(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := q.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	quota, err := q.dfs(r).Quota(requestedPath)
+	if err != nil {
+		q.writeError(w, err, requestedPath, "Quota request is failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(quota); err != nil {
+		w.WriteHeader(500)
+		q.logger.Error(
+			"Response of quota request is failed",
+			zap.String("path", requestedPath),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,47 @@
+package routing
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+func (q *quotaRouter) handlePut(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := q.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	limits := make([]uint64, 0)
+	for _, header := range []string{"X-Quota-Size", "X-Quota-Files", "X-Quota-Soft-Size", "X-Quota-Soft-Files"} {
+		limit, err := q.describeLimit(r.Header.Get(header))
+		if err != nil {
+			w.WriteHeader(422)
+			return
+		}
+		limits = append(limits, limit)
+	}
+
+	quota, err := common.NewQuota(limits[0], limits[1], limits[2], limits[3])
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	if err := q.dfs(r).SetQuota(requestedPath, quota); err != nil {
+		q.writeError(w, err, requestedPath, "Set quota request is failed")
+		return
+	}
+
+	w.WriteHeader(202)
+}
+
+// describeLimit parses the limit header value. Absent header means no limit
+func (q *quotaRouter) describeLimit(limitHeader string) (uint64, error) {
+	if len(limitHeader) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseUint(limitHeader, 10, 64)
+}
@@ -0,0 +1,243 @@
+package routing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// has the multipart response overhead relation, too many ranges are served as the whole content
+const maxByteRanges = 64
+
+type byteRange struct {
+	begins int64
+	ends   int64
+}
+
+func (b byteRange) length() int64 {
+	return b.ends - b.begins + 1
+}
+
+func (b byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", b.begins, b.ends, size)
+}
+
+// describeByteRanges parses the byte range request (RFC 7233) and returns the satisfiable ranges. If the range
+// request is malformed or should be ignored, it returns false to serve the whole content. Empty range list means
+// none of the ranges is satisfiable
+func describeByteRanges(requestRange string, size int64) ([]byteRange, bool) {
+	bytesTag := "bytes="
+	if strings.Index(requestRange, bytesTag) != 0 {
+		return nil, false
+	}
+
+	specs := 0
+	ranges := make([]byteRange, 0)
+	for _, spec := range strings.Split(requestRange[len(bytesTag):], ",") {
+		spec = strings.TrimSpace(spec)
+		if len(spec) == 0 {
+			continue
+		}
+		specs++
+
+		dashIdx := strings.Index(spec, "-")
+		if dashIdx == -1 {
+			return nil, false
+		}
+		first, last := strings.TrimSpace(spec[:dashIdx]), strings.TrimSpace(spec[dashIdx+1:])
+
+		if len(first) == 0 {
+			suffix, err := parseBytePosition(last)
+			if err != nil {
+				return nil, false
+			}
+			if suffix == 0 || size == 0 {
+				continue
+			}
+			if suffix > size {
+				suffix = size
+			}
+			ranges = append(ranges, byteRange{begins: size - suffix, ends: size - 1})
+			continue
+		}
+
+		begins, err := parseBytePosition(first)
+		if err != nil {
+			return nil, false
+		}
+
+		ends := size - 1
+		if len(last) > 0 {
+			v, err := parseBytePosition(last)
+			if err != nil || v < begins {
+				return nil, false
+			}
+			if v < ends {
+				ends = v
+			}
+		}
+
+		if begins >= size {
+			continue
+		}
+		ranges = append(ranges, byteRange{begins: begins, ends: ends})
+	}
+
+	if specs == 0 || len(ranges) > maxByteRanges {
+		return nil, false
+	}
+
+	return coalesceByteRanges(ranges), true
+}
+
+func parseBytePosition(position string) (int64, error) {
+	for _, c := range position {
+		if c < '0' || c > '9' {
+			return 0, strconv.ErrSyntax
+		}
+	}
+	return strconv.ParseInt(position, 10, 64)
+}
+
+// coalesceByteRanges merges the overlapping ranges. Ranges are kept in the requested order if they
+// do not overlap
+func coalesceByteRanges(ranges []byteRange) []byteRange {
+	if len(ranges) < 2 {
+		return ranges
+	}
+
+	sorted := make([]byteRange, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].begins < sorted[j].begins })
+
+	overlaps := false
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].begins <= sorted[i-1].ends {
+			overlaps = true
+			break
+		}
+	}
+	if !overlaps {
+		return ranges
+	}
+
+	coalesced := []byteRange{sorted[0]}
+	for _, r := range sorted[1:] {
+		current := &coalesced[len(coalesced)-1]
+		if r.begins <= current.ends+1 {
+			if r.ends > current.ends {
+				current.ends = r.ends
+			}
+			continue
+		}
+		coalesced = append(coalesced, r)
+	}
+	return coalesced
+}
+
+// describeByteRange parses the single byte range request including the suffix ranges. Multiple ranges
+// or malformed values are ignored and the whole content is served
+func describeByteRange(requestRange string, size int64) (int64, int64, bool) {
+	ranges, valid := describeByteRanges(requestRange, size)
+	if !valid || len(ranges) > 1 {
+		return 0, size - 1, true
+	}
+	if len(ranges) == 0 {
+		return 0, 0, false
+	}
+	return ranges[0].begins, ranges[0].ends, true
+}
+
+// ifRangeMatches validates the If-Range precondition with the entity tag or the modification date
+// of the content. Range request should be ignored when it does not match
+func ifRangeMatches(ifRange string, etag string, modified time.Time) bool {
+	ifRange = strings.TrimSpace(ifRange)
+	if len(ifRange) == 0 {
+		return true
+	}
+
+	if strings.HasPrefix(ifRange, "\"") {
+		return strings.Compare(ifRange, etag) == 0
+	}
+	if strings.HasPrefix(ifRange, "W/") {
+		return false
+	}
+
+	t, err := http.ParseTime(ifRange)
+	if err != nil {
+		return false
+	}
+	return modified.UTC().Truncate(time.Second).Equal(t.UTC())
+}
+
+// multipartByteRanges prepares the multipart/byteranges response of the multiple ranges
+type multipartByteRanges struct {
+	boundary    string
+	contentType string
+	size        int64
+	ranges      []byteRange
+}
+
+func newMultipartByteRanges(contentType string, size int64, ranges []byteRange) (*multipartByteRanges, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return &multipartByteRanges{
+		boundary:    hex.EncodeToString(b),
+		contentType: contentType,
+		size:        size,
+		ranges:      ranges,
+	}, nil
+}
+
+func (m *multipartByteRanges) ContentType() string {
+	return fmt.Sprintf("multipart/byteranges; boundary=%s", m.boundary)
+}
+
+func (m *multipartByteRanges) partHeader(index int) string {
+	delimiter := fmt.Sprintf("--%s\r\n", m.boundary)
+	if index > 0 {
+		delimiter = fmt.Sprintf("\r\n%s", delimiter)
+	}
+	return fmt.Sprintf(
+		"%sContent-Type: %s\r\nContent-Range: %s\r\n\r\n",
+		delimiter,
+		m.contentType,
+		m.ranges[index].contentRange(m.size),
+	)
+}
+
+func (m *multipartByteRanges) closing() string {
+	return fmt.Sprintf("\r\n--%s--\r\n", m.boundary)
+}
+
+// ContentLength calculates the exact length of the multipart response body
+func (m *multipartByteRanges) ContentLength() int64 {
+	length := int64(len(m.closing()))
+	for i, r := range m.ranges {
+		length += int64(len(m.partHeader(i))) + r.length()
+	}
+	return length
+}
+
+// Write streams the parts using the read handler that only touches the requested part of the content
+func (m *multipartByteRanges) Write(w io.Writer, readHandler func(w io.Writer, begins int64, ends int64) error) error {
+	for i, r := range m.ranges {
+		if _, err := io.WriteString(w, m.partHeader(i)); err != nil {
+			return err
+		}
+		if err := readHandler(w, r.begins, r.ends); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, m.closing())
+	return err
+}
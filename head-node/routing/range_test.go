@@ -0,0 +1,136 @@
+package routing
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeByteRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		size   int64
+		ranges []byteRange
+		valid  bool
+	}{
+		{name: "single", value: "bytes=0-99", size: 1000, ranges: []byteRange{{0, 99}}, valid: true},
+		{name: "open end", value: "bytes=500-", size: 1000, ranges: []byteRange{{500, 999}}, valid: true},
+		{name: "end after size", value: "bytes=900-5000", size: 1000, ranges: []byteRange{{900, 999}}, valid: true},
+		{name: "end at size", value: "bytes=0-1000", size: 1000, ranges: []byteRange{{0, 999}}, valid: true},
+		{name: "last byte", value: "bytes=999-999", size: 1000, ranges: []byteRange{{999, 999}}, valid: true},
+		{name: "spaces", value: "bytes= 0-9 , 20-29 ", size: 1000, ranges: []byteRange{{0, 9}, {20, 29}}, valid: true},
+		{name: "suffix", value: "bytes=-100", size: 1000, ranges: []byteRange{{900, 999}}, valid: true},
+		{name: "suffix larger than size", value: "bytes=-2000", size: 1000, ranges: []byteRange{{0, 999}}, valid: true},
+		{name: "zero suffix", value: "bytes=-0", size: 1000, ranges: []byteRange{}, valid: true},
+		{name: "begins at size", value: "bytes=1000-", size: 1000, ranges: []byteRange{}, valid: true},
+		{name: "begins after size", value: "bytes=2000-3000", size: 1000, ranges: []byteRange{}, valid: true},
+		{name: "empty file", value: "bytes=0-0", size: 0, ranges: []byteRange{}, valid: true},
+		{name: "empty file suffix", value: "bytes=-5", size: 0, ranges: []byteRange{}, valid: true},
+		{name: "partially satisfiable", value: "bytes=2000-,0-9", size: 1000, ranges: []byteRange{{0, 9}}, valid: true},
+		{name: "multiple in order", value: "bytes=20-29,0-9", size: 1000, ranges: []byteRange{{20, 29}, {0, 9}}, valid: true},
+		{name: "adjacent", value: "bytes=0-9,10-19", size: 1000, ranges: []byteRange{{0, 9}, {10, 19}}, valid: true},
+		{name: "overlaps", value: "bytes=40-49,0-9,5-19", size: 1000, ranges: []byteRange{{0, 19}, {40, 49}}, valid: true},
+		{name: "overlaps with adjacent", value: "bytes=0-9,5-19,20-29", size: 1000, ranges: []byteRange{{0, 29}}, valid: true},
+		{name: "contained", value: "bytes=0-99,10-19", size: 1000, ranges: []byteRange{{0, 99}}, valid: true},
+		{name: "overlapping suffix", value: "bytes=900-949,-75", size: 1000, ranges: []byteRange{{900, 999}}, valid: true},
+		{name: "unit", value: "items=0-9", size: 1000, valid: false},
+		{name: "no spec", value: "bytes=", size: 1000, valid: false},
+		{name: "only commas", value: "bytes=,,", size: 1000, valid: false},
+		{name: "no dash", value: "bytes=10", size: 1000, valid: false},
+		{name: "reversed", value: "bytes=9-0", size: 1000, valid: false},
+		{name: "not numeric", value: "bytes=a-9", size: 1000, valid: false},
+		{name: "signed", value: "bytes=+1-9", size: 1000, valid: false},
+		{name: "negative end", value: "bytes=0--9", size: 1000, valid: false},
+		{name: "malformed suffix", value: "bytes=-x", size: 1000, valid: false},
+		{name: "malformed among valid", value: "bytes=0-9,x-y", size: 1000, valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ranges, valid := describeByteRanges(test.value, test.size)
+			assert.Equal(t, test.valid, valid)
+			if test.valid {
+				assert.Equal(t, test.ranges, ranges)
+			}
+		})
+	}
+}
+
+func TestDescribeByteRanges_TooMany(t *testing.T) {
+	specs := make([]string, 0)
+	for i := 0; i < maxByteRanges; i++ {
+		specs = append(specs, fmt.Sprintf("%d-%d", i*10, i*10+4))
+	}
+
+	ranges, valid := describeByteRanges(fmt.Sprintf("bytes=%s", strings.Join(specs, ",")), 10000)
+	assert.True(t, valid)
+	assert.Len(t, ranges, maxByteRanges)
+
+	specs = append(specs, "9000-9004")
+	_, valid = describeByteRanges(fmt.Sprintf("bytes=%s", strings.Join(specs, ",")), 10000)
+	assert.False(t, valid)
+}
+
+func TestDescribeByteRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		size        int64
+		begins      int64
+		ends        int64
+		satisfiable bool
+	}{
+		{name: "single", value: "bytes=10-19", size: 100, begins: 10, ends: 19, satisfiable: true},
+		{name: "suffix", value: "bytes=-10", size: 100, begins: 90, ends: 99, satisfiable: true},
+		{name: "multiple", value: "bytes=0-9,20-29", size: 100, begins: 0, ends: 99, satisfiable: true},
+		{name: "coalesced", value: "bytes=0-9,5-19", size: 100, begins: 0, ends: 19, satisfiable: true},
+		{name: "malformed", value: "bytes=x", size: 100, begins: 0, ends: 99, satisfiable: true},
+		{name: "unsatisfiable", value: "bytes=100-", size: 100, begins: 0, ends: 0, satisfiable: false},
+		{name: "empty file", value: "bytes=-1", size: 0, begins: 0, ends: 0, satisfiable: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			begins, ends, satisfiable := describeByteRange(test.value, test.size)
+			assert.Equal(t, test.satisfiable, satisfiable)
+			assert.Equal(t, test.begins, begins)
+			assert.Equal(t, test.ends, ends)
+		})
+	}
+}
+
+func TestIfRangeMatches(t *testing.T) {
+	modified := time.Date(2020, 1, 1, 10, 30, 15, 500, time.UTC)
+	etag := "\"abc\""
+
+	assert.True(t, ifRangeMatches("", etag, modified))
+	assert.True(t, ifRangeMatches(etag, etag, modified))
+	assert.False(t, ifRangeMatches("\"xyz\"", etag, modified))
+	assert.False(t, ifRangeMatches("W/\"abc\"", etag, modified))
+	assert.True(t, ifRangeMatches(modified.Format(http.TimeFormat), etag, modified))
+	assert.False(t, ifRangeMatches(modified.Add(-time.Hour).Format(http.TimeFormat), etag, modified))
+	assert.False(t, ifRangeMatches("yesterday", etag, modified))
+}
+
+func TestMultipartByteRanges_Write(t *testing.T) {
+	content := "0123456789abcdefghij"
+	m, err := newMultipartByteRanges("text/plain", int64(len(content)), []byteRange{{0, 4}, {10, 14}})
+	assert.Nil(t, err)
+
+	var b strings.Builder
+	err = m.Write(&b, func(w io.Writer, begins int64, ends int64) error {
+		_, err := io.WriteString(w, content[begins:ends+1])
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, m.ContentLength(), int64(b.Len()))
+	assert.Contains(t, b.String(), "Content-Range: bytes 0-4/20\r\n\r\n01234")
+	assert.Contains(t, b.String(), "Content-Range: bytes 10-14/20\r\n\r\nabcde")
+	assert.True(t, strings.HasSuffix(b.String(), fmt.Sprintf("\r\n--%s--\r\n", m.boundary)))
+}
@@ -33,6 +33,11 @@ func (m *Manager) Add(router Router) {
 	}
 }
 
+// Use adds the middleware that is executed before the handlers of the routers
+func (m *Manager) Use(middleware mux.MiddlewareFunc) {
+	m.mux.Use(middleware)
+}
+
 func (m *Manager) Get() *mux.Router {
 	return m.mux
 }
@@ -0,0 +1,311 @@
+package routing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// s3StagingRoot is the reserved dfs folder that keeps the staging folders, it is not accessible out of the S3 router
+const s3StagingRoot = "/.s3"
+
+// s3MultipartRoot is the hidden dfs folder that keeps the parts of the ongoing multipart uploads
+const s3MultipartRoot = s3StagingRoot + "/multipart"
+
+// s3ObjectRoot is the hidden dfs folder that keeps the content of the objects until the content is verified
+const s3ObjectRoot = s3StagingRoot + "/objects"
+
+// s3StagingExpiry is the idle time of the multipart uploads and the staged objects before they are dropped
+const s3StagingExpiry = time.Hour * 24 * 7
+const s3StagingExpiryInterval = time.Hour
+
+type s3Router struct {
+	accessControl manager.AccessControl
+	authenticator auth.Authenticator
+	logger        *zap.Logger
+
+	definitions []*Definition
+}
+
+// NewS3Router creates the router that exposes the dfs as an S3 compatible (path-style) service.
+// Buckets are the folders in the root of dfs and the object keys are the paths under them. Requests should be signed
+// with AWS signature version 4 using the authentication keys when the authenticator is set and the operations are
+// evaluated with the access lists for the key of the request
+func NewS3Router(accessControl manager.AccessControl, authenticator auth.Authenticator, logger *zap.Logger) Router {
+	pR := &s3Router{
+		accessControl: accessControl,
+		authenticator: authenticator,
+		logger:        logger,
+		definitions:   make([]*Definition, 0),
+	}
+	pR.setup()
+
+	accessControl.Reserve(s3StagingRoot)
+	go pR.expireStaging()
+
+	return pR
+}
+
+func (s *s3Router) setup() {
+	s.definitions =
+		append(s.definitions,
+			&Definition{
+				Path:    "/",
+				Handler: s.manipulate,
+			},
+			&Definition{
+				Path:    "/{bucket}",
+				Handler: s.manipulate,
+			},
+			&Definition{
+				Path:    "/{bucket}/",
+				Handler: s.manipulate,
+			},
+			&Definition{
+				Path:    "/{bucket}/{key:.+}",
+				Handler: s.manipulate,
+			},
+		)
+}
+
+func (s *s3Router) Get() []*Definition {
+	return s.definitions
+}
+
+func (s *s3Router) manipulate(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	if s.authenticator != nil {
+		key, err := s.authenticator.AuthenticateS3(r, auth.ScopeClient)
+		if err != nil {
+			if err == errors.ErrForbidden {
+				s.writeError(w, r, 403, "AccessDenied", "Access Denied")
+				return
+			}
+			s.writeError(w, r, 403, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.")
+			return
+		}
+		r = r.WithContext(auth.NewContext(r.Context(), key))
+	}
+
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	if len(bucket) > 0 && !s.validateBucket(bucket) {
+		s.writeError(w, r, 400, "InvalidBucketName", "The specified bucket is not valid.")
+		return
+	}
+
+	if len(key) > 0 {
+		if _, err := s.objectPath(bucket, key); err != nil {
+			s.writeError(w, r, 400, "InvalidArgument", "The specified key is not valid.")
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.handleGet(w, r, bucket, key, false)
+	case http.MethodHead:
+		s.handleGet(w, r, bucket, key, true)
+	case http.MethodPut:
+		s.handlePut(w, r, bucket, key)
+	case http.MethodPost:
+		s.handlePost(w, r, bucket, key)
+	case http.MethodDelete:
+		s.handleDelete(w, r, bucket, key)
+	default:
+		s.writeError(w, r, 405, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+	}
+}
+
+func (s *s3Router) validateBucket(bucket string) bool {
+	return len(bucket) > 0 && len(bucket) <= 255 && bucket[0] != '.'
+}
+
+func (s *s3Router) bucketPath(bucket string) string {
+	return common.Join("/", bucket)
+}
+
+func (s *s3Router) objectPath(bucket string, key string) (string, error) {
+	bucketPath := s.bucketPath(bucket)
+	objectPath := common.Join(bucketPath, key)
+
+	if !strings.HasPrefix(objectPath, fmt.Sprintf("%s/", bucketPath)) {
+		return "", os.ErrInvalid
+	}
+	return objectPath, nil
+}
+
+// dfs returns the file manipulation operations that are evaluated for the key of the request
+func (s *s3Router) dfs(r *http.Request) manager.Dfs {
+	return s.accessControl.Dfs(auth.FromContext(r.Context()))
+}
+
+// stagingDfs returns the file manipulation operations for the staged objects and the parts of the multipart uploads.
+// They are kept in the reserved folders that are not covered by the access lists of the buckets, so the access is
+// checked with checkStaging on the object that the content is uploaded for
+func (s *s3Router) stagingDfs() manager.Dfs {
+	return s.accessControl.Internal()
+}
+
+// checkStaging validates the key of the request is allowed to write the object that the content is staged for
+func (s *s3Router) checkStaging(w http.ResponseWriter, r *http.Request, bucket string, key string) bool {
+	objectPath, _ := s.objectPath(bucket, key)
+
+	if err := s.accessControl.Check(auth.FromContext(r.Context()), objectPath, common.PermissionWrite); err != nil {
+		s.writeDfsError(w, r, err, "NoSuchKey", "Staging (access) request is failed")
+		return false
+	}
+	return true
+}
+
+func (s *s3Router) folder(dfs manager.Dfs, folderPath string) (*common.Folder, error) {
+	read, err := dfs.Read([]string{folderPath}, false)
+	if err != nil {
+		return nil, err
+	}
+	if read.Type() != manager.RTFolder {
+		return nil, os.ErrNotExist
+	}
+	return read.Folder(), nil
+}
+
+func (s *s3Router) bucketExists(w http.ResponseWriter, r *http.Request, bucket string) bool {
+	if _, err := s.folder(s.dfs(r), s.bucketPath(bucket)); err != nil {
+		if err == os.ErrNotExist {
+			s.writeError(w, r, 404, "NoSuchBucket", "The specified bucket does not exist.")
+			return false
+		}
+		s.writeDfsError(w, r, err, "NoSuchBucket", "Bucket check request is failed")
+		return false
+	}
+	return true
+}
+
+func (s *s3Router) newObjectStagingPath() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return common.Join(s3ObjectRoot, hex.EncodeToString(b)), nil
+}
+
+func (s *s3Router) newUploadId(mime string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.%s", hex.EncodeToString(b), hex.EncodeToString([]byte(mime))), nil
+}
+
+func (s *s3Router) describeUploadId(uploadId string) (string, string, error) {
+	dotIdx := strings.Index(uploadId, ".")
+	if dotIdx != 32 {
+		return "", "", os.ErrInvalid
+	}
+
+	if _, err := hex.DecodeString(uploadId[:dotIdx]); err != nil {
+		return "", "", os.ErrInvalid
+	}
+
+	mime, err := hex.DecodeString(uploadId[dotIdx+1:])
+	if err != nil || len(mime) == 0 {
+		return "", "", os.ErrInvalid
+	}
+
+	return common.Join(s3MultipartRoot, uploadId), string(mime), nil
+}
+
+func (s *s3Router) writeDfsError(w http.ResponseWriter, r *http.Request, err error, notFoundCode string, logMessage string) {
+	switch err {
+	case os.ErrNotExist:
+		message := "The specified key does not exist."
+		if strings.Compare(notFoundCode, "NoSuchBucket") == 0 {
+			message = "The specified bucket does not exist."
+		} else if strings.Compare(notFoundCode, "NoSuchUpload") == 0 {
+			message = "The specified multipart upload does not exist."
+		}
+		s.writeError(w, r, 404, notFoundCode, message)
+		return
+	case os.ErrInvalid:
+		s.writeError(w, r, 400, "InvalidArgument", "The request is not valid for the dfs.")
+		return
+	case errors.ErrForbidden:
+		s.writeError(w, r, 403, "AccessDenied", "Access Denied")
+		return
+	case errors.ErrLock:
+		s.writeError(w, r, 409, "OperationAborted", "The object is locked by another operation.")
+		return
+	case errors.ErrZombie, errors.ErrZombieAlive:
+		s.writeError(w, r, 409, "OperationAborted", "The object is in zombie state and requires repair.")
+		return
+	case errors.ErrRepair, errors.ErrNoAvailableActionNode:
+		s.writeError(w, r, 503, "ServiceUnavailable", "Reduce your request rate.")
+		return
+	case errors.ErrNoSpace:
+		s.writeError(w, r, 507, "InsufficientStorage", "There is not enough space in the dfs.")
+		return
+	case errors.ErrQuota:
+		s.writeError(w, r, 507, "InsufficientStorage", "The quota of the folder is exceeded.")
+		return
+	case errors.ErrUnauthorized:
+		if strings.Compare(r.Header.Get("X-Amz-Content-Sha256"), auth.StreamingSignedPayload) == 0 {
+			s.writeError(w, r, 403, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.")
+			return
+		}
+		s.writeError(w, r, 400, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.")
+		return
+	}
+
+	s.writeError(w, r, 500, "InternalError", "We encountered an internal error. Please try again.")
+	s.logger.Error(logMessage, zap.String("path", r.URL.Path), zap.Error(err))
+}
+
+var _ Router = &s3Router{}
+
+// expireStaging drops the multipart uploads that do not have any activity and the staged objects that are left by
+// the interrupted uploads in s3StagingExpiry
+func (s *s3Router) expireStaging() {
+	for {
+		time.Sleep(s3StagingExpiryInterval)
+
+		expiresAt := time.Now().UTC().Add(-s3StagingExpiry)
+
+		if multipart, err := s.folder(s.stagingDfs(), s3MultipartRoot); err == nil {
+			for _, shadow := range multipart.Folders {
+				upload, err := s.folder(s.stagingDfs(), shadow.Full)
+				if err != nil || upload.Modified.After(expiresAt) {
+					continue
+				}
+				s.dropStaging(upload.Full)
+			}
+		}
+
+		if objects, err := s.folder(s.stagingDfs(), s3ObjectRoot); err == nil {
+			for _, file := range objects.Files {
+				if file.Modified.After(expiresAt) {
+					continue
+				}
+				s.dropStaging(common.Join(objects.Full, file.Name))
+			}
+		}
+	}
+}
+
+func (s *s3Router) dropStaging(stagingPath string) {
+	if err := s.stagingDfs().Delete(stagingPath, true, nil); err != nil && err != os.ErrNotExist {
+		s.logger.Warn("Dropping staged content is failed", zap.String("path", stagingPath), zap.Error(err))
+	}
+}
@@ -0,0 +1,143 @@
+package routing
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+)
+
+// s3ChunkedReader decodes the aws-chunked content encoding that is used by the SDKs for the streaming uploads.
+// Chunk signatures are verified with the signer of the streaming signed payloads, the last part of a chunk is
+// not returned when its signature is not matching. Trailing headers are skipped
+type s3ChunkedReader struct {
+	reader *bufio.Reader
+	signer *auth.ChunkSigner
+
+	remaining int64
+	done      bool
+	hash      hash.Hash
+	signature []byte
+}
+
+func newS3ChunkedReader(reader io.Reader, signer *auth.ChunkSigner) io.Reader {
+	return &s3ChunkedReader{
+		reader: bufio.NewReader(reader),
+		signer: signer,
+		hash:   sha256.New(),
+	}
+}
+
+func (c *s3ChunkedReader) Read(p []byte) (int, error) {
+	if c.remaining == 0 && !c.done {
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	if c.done {
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+
+	n, err := c.reader.Read(p)
+	c.remaining -= int64(n)
+	_, _ = c.hash.Write(p[:n])
+
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return n, err
+	}
+
+	if c.remaining == 0 {
+		if err := c.complete(); err != nil {
+			return 0, err
+		}
+	}
+
+	return n, nil
+}
+
+// complete verifies the signature of the chunk and reads the header of the next chunk ahead, so the final chunk
+// is also verified when the last part of the content is read
+func (c *s3ChunkedReader) complete() error {
+	if c.signer != nil && !c.signer.Verify(c.hash.Sum(nil), c.signature) {
+		return errors.ErrUnauthorized
+	}
+
+	if err := c.skipLine(); err != nil {
+		return err
+	}
+	return c.next()
+}
+
+func (c *s3ChunkedReader) next() error {
+	header, err := c.reader.ReadString('\n')
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	header = strings.TrimSpace(header)
+	extension := ""
+	if semicolonIdx := strings.Index(header, ";"); semicolonIdx > -1 {
+		extension = header[semicolonIdx+1:]
+		header = header[:semicolonIdx]
+	}
+
+	size, err := strconv.ParseInt(header, 16, 64)
+	if err != nil || size < 0 {
+		return fmt.Errorf("aws-chunked content has invalid chunk header")
+	}
+
+	c.hash.Reset()
+	c.signature = nil
+	if c.signer != nil {
+		if !strings.HasPrefix(extension, "chunk-signature=") {
+			return errors.ErrUnauthorized
+		}
+		c.signature, err = hex.DecodeString(strings.TrimPrefix(extension, "chunk-signature="))
+		if err != nil {
+			return errors.ErrUnauthorized
+		}
+	}
+
+	if size == 0 {
+		if c.signer != nil && !c.signer.Verify(c.hash.Sum(nil), c.signature) {
+			return errors.ErrUnauthorized
+		}
+		c.done = true
+		return nil
+	}
+	c.remaining = size
+
+	return nil
+}
+
+func (c *s3ChunkedReader) skipLine() error {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if len(strings.TrimSpace(line)) > 0 {
+		return fmt.Errorf("aws-chunked content has invalid chunk termination")
+	}
+	return nil
+}
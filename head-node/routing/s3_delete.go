@@ -0,0 +1,116 @@
+package routing
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+)
+
+func (s *s3Router) handleDelete(w http.ResponseWriter, r *http.Request, bucket string, key string) {
+	if len(bucket) == 0 {
+		s.writeError(w, r, 405, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+		return
+	}
+
+	if len(key) == 0 {
+		s.deleteBucket(w, r, bucket)
+		return
+	}
+
+	if uploadId := r.URL.Query().Get("uploadId"); len(uploadId) > 0 {
+		s.abortMultipartUpload(w, r, bucket, key, uploadId)
+		return
+	}
+
+	if !s.bucketExists(w, r, bucket) {
+		return
+	}
+
+	if err := s.deleteObject(r, bucket, key); err != nil {
+		s.writeDfsError(w, r, err, "NoSuchKey", "Delete object request is failed")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (s *s3Router) deleteBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	folder, err := s.folder(s.dfs(r), s.bucketPath(bucket))
+	if err != nil {
+		s.writeDfsError(w, r, err, "NoSuchBucket", "Delete bucket (check) request is failed")
+		return
+	}
+
+	if len(folder.Files) > 0 || len(folder.Folders) > 0 {
+		s.writeError(w, r, 409, "BucketNotEmpty", "The bucket you tried to delete is not empty.")
+		return
+	}
+
+	if err := s.dfs(r).Delete(folder.Full, false, nil); err != nil {
+		s.writeDfsError(w, r, err, "NoSuchBucket", "Delete bucket request is failed")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+// deleteObject deletes the file that the key points. Keys ending with "/" are considered as
+// folder objects and deleted only if they are empty as S3 does not delete the content of the prefix
+func (s *s3Router) deleteObject(r *http.Request, bucket string, key string) error {
+	objectPath, err := s.objectPath(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(key, "/") {
+		folder, err := s.folder(s.dfs(r), objectPath)
+		if err != nil {
+			if err == os.ErrNotExist {
+				return nil
+			}
+			return err
+		}
+		if len(folder.Files) > 0 || len(folder.Folders) > 0 {
+			return nil
+		}
+	} else {
+		parentPath, filename := common.Split(objectPath)
+
+		folder, err := s.folder(s.dfs(r), parentPath)
+		if err != nil {
+			if err == os.ErrNotExist {
+				return nil
+			}
+			return err
+		}
+		if folder.File(filename) == nil {
+			return nil
+		}
+	}
+
+	if err := s.dfs(r).Delete(objectPath, false, nil); err != nil && err != os.ErrNotExist {
+		return err
+	}
+	return nil
+}
+
+func (s *s3Router) abortMultipartUpload(w http.ResponseWriter, r *http.Request, bucket string, key string, uploadId string) {
+	uploadPath, _, err := s.describeUploadId(uploadId)
+	if err != nil {
+		s.writeError(w, r, 404, "NoSuchUpload", "The specified multipart upload does not exist.")
+		return
+	}
+
+	if !s.checkStaging(w, r, bucket, key) {
+		return
+	}
+
+	if err := s.stagingDfs().Delete(uploadPath, true, nil); err != nil {
+		s.writeDfsError(w, r, err, "NoSuchUpload", "Abort multipart upload request is failed")
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,418 @@
+package routing
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"go.uber.org/zap"
+)
+
+const s3MaxKeys = 1000
+
+type s3ListEntry struct {
+	key  string
+	file *common.File
+}
+
+func (s *s3Router) handleGet(w http.ResponseWriter, r *http.Request, bucket string, key string, head bool) {
+	if len(bucket) == 0 {
+		if head {
+			s.writeError(w, r, 405, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+			return
+		}
+		s.listBuckets(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+
+	if len(key) == 0 {
+		if !s.bucketExists(w, r, bucket) {
+			return
+		}
+
+		if head {
+			w.WriteHeader(200)
+			return
+		}
+
+		if _, has := query["location"]; has {
+			s.writeXml(w, 200, &s3LocationConstraint{Xmlns: s3Namespace})
+			return
+		}
+
+		if _, has := query["uploads"]; has {
+			s.writeError(w, r, 501, "NotImplemented", "Listing multipart uploads is not supported.")
+			return
+		}
+
+		s.listObjects(w, r, bucket, query)
+		return
+	}
+
+	if uploadId := query.Get("uploadId"); len(uploadId) > 0 && !head {
+		s.listParts(w, r, bucket, key, uploadId, query)
+		return
+	}
+
+	s.getObject(w, r, bucket, key, head)
+}
+
+func (s *s3Router) listBuckets(w http.ResponseWriter, r *http.Request) {
+	// root is read without the access lists, the buckets are filtered for the key of the request
+	root, err := s.folder(s.accessControl.Dfs(nil), "/")
+	if err != nil {
+		s.writeDfsError(w, r, err, "NoSuchBucket", "List buckets request is failed")
+		return
+	}
+
+	result := &s3ListAllMyBucketsResult{
+		Xmlns:   s3Namespace,
+		Owner:   s3Owner{ID: "kertish-dfs", DisplayName: "kertish-dfs"},
+		Buckets: make([]s3Bucket, 0),
+	}
+	key := auth.FromContext(r.Context())
+	for _, folder := range root.Folders {
+		if !s.validateBucket(folder.Name) {
+			continue
+		}
+		// buckets that the key is not allowed to read are not listed
+		if err := s.accessControl.Check(key, folder.Full, common.PermissionRead); err != nil {
+			continue
+		}
+		result.Buckets = append(result.Buckets, s3Bucket{
+			Name:         folder.Name,
+			CreationDate: s.formatTime(folder.Created),
+		})
+	}
+
+	s.writeXml(w, 200, result)
+}
+
+func (s *s3Router) listObjects(w http.ResponseWriter, r *http.Request, bucket string, query url.Values) {
+	v2 := strings.Compare(query.Get("list-type"), "2") == 0
+
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+	encodingType := query.Get("encoding-type")
+
+	maxKeys := s3MaxKeys
+	if maxKeysQuery := query.Get("max-keys"); len(maxKeysQuery) > 0 {
+		v, err := strconv.Atoi(maxKeysQuery)
+		if err != nil || v < 0 {
+			s.writeError(w, r, 400, "InvalidArgument", "max-keys is not valid.")
+			return
+		}
+		if v < maxKeys {
+			maxKeys = v
+		}
+	}
+
+	after := query.Get("marker")
+	continuationToken := query.Get("continuation-token")
+	if v2 {
+		after = query.Get("start-after")
+
+		if len(continuationToken) > 0 {
+			token, err := base64.RawURLEncoding.DecodeString(continuationToken)
+			if err != nil {
+				s.writeError(w, r, 400, "InvalidArgument", "The continuation token provided is incorrect.")
+				return
+			}
+			after = string(token)
+		}
+	}
+
+	entries, err := s.listEntries(r, bucket, prefix, delimiter)
+	if err != nil {
+		s.writeDfsError(w, r, err, "NoSuchBucket", "List objects request is failed")
+		return
+	}
+
+	encode := func(v string) string {
+		if strings.Compare(encodingType, "url") != 0 {
+			return v
+		}
+		return url.PathEscape(v)
+	}
+
+	result := &s3ListBucketResult{
+		Xmlns:          s3Namespace,
+		Name:           bucket,
+		Prefix:         encode(prefix),
+		MaxKeys:        maxKeys,
+		Delimiter:      encode(delimiter),
+		EncodingType:   encodingType,
+		Contents:       make([]s3Object, 0),
+		CommonPrefixes: make([]s3CommonPrefix, 0),
+	}
+
+	last := ""
+	count := 0
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.key, prefix) {
+			continue
+		}
+		if len(after) > 0 {
+			if strings.Compare(entry.key, after) <= 0 {
+				continue
+			}
+			// after value can be a common prefix that is returned in the previous page
+			if len(delimiter) > 0 && strings.HasSuffix(after, delimiter) && strings.HasPrefix(entry.key, after) {
+				continue
+			}
+		}
+
+		commonPrefix := ""
+		if len(delimiter) > 0 {
+			if idx := strings.Index(entry.key[len(prefix):], delimiter); idx > -1 {
+				commonPrefix = entry.key[:len(prefix)+idx+len(delimiter)]
+			}
+		}
+
+		if len(commonPrefix) > 0 && strings.Compare(commonPrefix, last) == 0 {
+			continue
+		}
+
+		if count == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+		count++
+
+		if len(commonPrefix) > 0 {
+			result.CommonPrefixes = append(result.CommonPrefixes, s3CommonPrefix{Prefix: encode(commonPrefix)})
+			last = commonPrefix
+			continue
+		}
+
+		object := s3Object{
+			Key:          encode(entry.key),
+			StorageClass: "STANDARD",
+		}
+		if entry.file != nil {
+			object.LastModified = s.formatTime(entry.file.Modified)
+			object.ETag = s.etag(entry.file)
+			object.Size = entry.file.Size
+		}
+		result.Contents = append(result.Contents, object)
+		last = entry.key
+	}
+
+	if v2 {
+		result.ContinuationToken = continuationToken
+		result.StartAfter = encode(query.Get("start-after"))
+		result.KeyCount = &count
+		if result.IsTruncated {
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(last))
+		}
+	} else {
+		marker := encode(query.Get("marker"))
+		result.Marker = &marker
+		if result.IsTruncated && len(delimiter) > 0 {
+			result.NextMarker = encode(last)
+		}
+	}
+
+	s.writeXml(w, 200, result)
+}
+
+// listEntries collects the object keys of the bucket in sorted order. Only the folder that is pointed by
+// the prefix is visited when the delimiter is "/", otherwise the whole tree under the prefix folder is walked.
+// Empty folders are represented with the keys that end with "/"
+func (s *s3Router) listEntries(r *http.Request, bucket string, prefix string, delimiter string) ([]s3ListEntry, error) {
+	bucketPath := s.bucketPath(bucket)
+
+	basePath := bucketPath
+	if idx := strings.LastIndex(prefix, "/"); idx > -1 {
+		basePath = common.Join(bucketPath, prefix[:idx])
+		if !strings.HasPrefix(basePath, bucketPath) {
+			return nil, os.ErrInvalid
+		}
+	}
+
+	read, err := s.dfs(r).Read([]string{basePath}, false)
+	if err != nil {
+		if err == os.ErrNotExist && strings.Compare(basePath, bucketPath) != 0 {
+			return []s3ListEntry{}, nil
+		}
+		return nil, err
+	}
+	if read.Type() != manager.RTFolder {
+		return []s3ListEntry{}, nil
+	}
+
+	keyOf := func(full string) string {
+		return strings.TrimPrefix(full, fmt.Sprintf("%s/", bucketPath))
+	}
+
+	entries := make([]s3ListEntry, 0)
+	appendFiles := func(folder *common.Folder) {
+		for _, file := range folder.Files {
+			if file.Locked() {
+				continue
+			}
+			entries = append(entries, s3ListEntry{key: keyOf(common.Join(folder.Full, file.Name)), file: file})
+		}
+	}
+
+	if strings.Compare(delimiter, "/") == 0 {
+		folder := read.Folder()
+		appendFiles(folder)
+		for _, shadow := range folder.Folders {
+			entries = append(entries, s3ListEntry{key: fmt.Sprintf("%s/", keyOf(shadow.Full))})
+		}
+	} else {
+		tree, err := read.Tree()
+		if err != nil {
+			return nil, err
+		}
+		for _, folder := range tree.Normalize() {
+			if strings.Compare(folder.Full, bucketPath) == 0 {
+				appendFiles(folder)
+				continue
+			}
+			if len(folder.Files) == 0 && len(folder.Folders) == 0 {
+				entries = append(entries, s3ListEntry{key: fmt.Sprintf("%s/", keyOf(folder.Full))})
+				continue
+			}
+			appendFiles(folder)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	return entries, nil
+}
+
+func (s *s3Router) listParts(w http.ResponseWriter, r *http.Request, bucket string, key string, uploadId string, query url.Values) {
+	uploadPath, _, err := s.describeUploadId(uploadId)
+	if err != nil {
+		s.writeError(w, r, 404, "NoSuchUpload", "The specified multipart upload does not exist.")
+		return
+	}
+
+	if !s.checkStaging(w, r, bucket, key) {
+		return
+	}
+
+	folder, err := s.folder(s.stagingDfs(), uploadPath)
+	if err != nil {
+		s.writeDfsError(w, r, err, "NoSuchUpload", "List parts request is failed")
+		return
+	}
+
+	marker, _ := strconv.Atoi(query.Get("part-number-marker"))
+	maxParts := s3MaxKeys
+	if v, err := strconv.Atoi(query.Get("max-parts")); err == nil && v >= 0 && v < maxParts {
+		maxParts = v
+	}
+
+	result := &s3ListPartsResult{
+		Xmlns:            s3Namespace,
+		Bucket:           bucket,
+		Key:              key,
+		UploadId:         uploadId,
+		PartNumberMarker: marker,
+		MaxParts:         maxParts,
+		Parts:            make([]s3Part, 0),
+	}
+
+	sort.Sort(folder.Files)
+	for _, file := range folder.Files {
+		partNumber, err := strconv.Atoi(file.Name)
+		if err != nil || partNumber <= marker || file.Locked() {
+			continue
+		}
+		if len(result.Parts) == maxParts {
+			result.IsTruncated = true
+			break
+		}
+		result.Parts = append(result.Parts, s3Part{
+			PartNumber:   partNumber,
+			ETag:         s.etag(file),
+			LastModified: s.formatTime(file.Modified),
+			Size:         file.Size,
+		})
+		result.NextPartNumberMarker = partNumber
+	}
+
+	s.writeXml(w, 200, result)
+}
+
+func (s *s3Router) getObject(w http.ResponseWriter, r *http.Request, bucket string, key string, head bool) {
+	objectPath, _ := s.objectPath(bucket, key)
+
+	read, err := s.dfs(r).Read([]string{objectPath}, false)
+	if err != nil {
+		if err == os.ErrNotExist && !s.bucketExists(w, r, bucket) {
+			return
+		}
+		s.writeDfsError(w, r, err, "NoSuchKey", "Get object request is failed")
+		return
+	}
+
+	if read.Type() == manager.RTFolder {
+		if !strings.HasSuffix(key, "/") {
+			s.writeError(w, r, 404, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-directory")
+		w.Header().Set("Content-Length", "0")
+		w.Header().Set("Last-Modified", read.Folder().Modified.UTC().Format(http.TimeFormat))
+		w.WriteHeader(200)
+		return
+	}
+
+	file := read.File()
+
+	w.Header().Set("Content-Type", file.Mime)
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("ETag", s.etag(file))
+	w.Header().Set("Last-Modified", file.Modified.UTC().Format(http.TimeFormat))
+
+	begins, ends := int64(0), int64(file.Size)-1
+	statusCode := 200
+
+	if requestRange := r.Header.Get("Range"); len(requestRange) > 0 {
+		var satisfiable bool
+		begins, ends, satisfiable = describeByteRange(requestRange, int64(file.Size))
+		if !satisfiable {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", file.Size))
+			s.writeError(w, r, 416, "InvalidRange", "The requested range is not satisfiable.")
+			return
+		}
+		if begins != 0 || ends != int64(file.Size)-1 {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", begins, ends, file.Size))
+			statusCode = 206
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(ends-begins+1, 10))
+	w.WriteHeader(statusCode)
+
+	if head || file.Size == 0 {
+		return
+	}
+
+	if err := read.Read(w, begins, ends); err != nil {
+		s.logger.Warn(
+			"Streaming object content is failed",
+			zap.String("path", objectPath),
+			zap.Int64("begins", begins),
+			zap.Int64("ends", ends),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,222 @@
+package routing
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"go.uber.org/zap"
+)
+
+func (s *s3Router) handlePost(w http.ResponseWriter, r *http.Request, bucket string, key string) {
+	if len(bucket) == 0 {
+		s.writeError(w, r, 405, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+		return
+	}
+
+	query := r.URL.Query()
+
+	if len(key) == 0 {
+		if _, has := query["delete"]; has {
+			if !s.bucketExists(w, r, bucket) {
+				return
+			}
+			s.deleteObjects(w, r, bucket)
+			return
+		}
+		s.writeError(w, r, 405, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+		return
+	}
+
+	if !s.bucketExists(w, r, bucket) {
+		return
+	}
+
+	if _, has := query["uploads"]; has {
+		s.createMultipartUpload(w, r, bucket, key)
+		return
+	}
+
+	if uploadId := query.Get("uploadId"); len(uploadId) > 0 {
+		s.completeMultipartUpload(w, r, bucket, key, uploadId)
+		return
+	}
+
+	s.writeError(w, r, 405, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+}
+
+func (s *s3Router) createMultipartUpload(w http.ResponseWriter, r *http.Request, bucket string, key string) {
+	mime := r.Header.Get("Content-Type")
+	if len(mime) == 0 {
+		mime = "application/octet-stream"
+	}
+
+	if !s.checkStaging(w, r, bucket, key) {
+		return
+	}
+
+	uploadId, err := s.newUploadId(mime)
+	if err != nil {
+		s.writeDfsError(w, r, err, "NoSuchUpload", "Create multipart upload (id) request is failed")
+		return
+	}
+
+	uploadPath, _, _ := s.describeUploadId(uploadId)
+	if err := s.stagingDfs().CreateFolder(uploadPath); err != nil {
+		s.writeDfsError(w, r, err, "NoSuchUpload", "Create multipart upload request is failed")
+		return
+	}
+
+	s.writeXml(w, 200, &s3InitiateMultipartUploadResult{
+		Xmlns:    s3Namespace,
+		Bucket:   bucket,
+		Key:      key,
+		UploadId: uploadId,
+	})
+}
+
+func (s *s3Router) completeMultipartUpload(w http.ResponseWriter, r *http.Request, bucket string, key string, uploadId string) {
+	objectPath, _ := s.objectPath(bucket, key)
+
+	uploadPath, _, err := s.describeUploadId(uploadId)
+	if err != nil {
+		s.writeError(w, r, 404, "NoSuchUpload", "The specified multipart upload does not exist.")
+		return
+	}
+
+	var request s3CompleteMultipartUpload
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Parts) == 0 {
+		s.writeError(w, r, 400, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema.")
+		return
+	}
+
+	if !s.checkStaging(w, r, bucket, key) {
+		return
+	}
+
+	folder, err := s.folder(s.stagingDfs(), uploadPath)
+	if err != nil {
+		s.writeDfsError(w, r, err, "NoSuchUpload", "Complete multipart upload (check) request is failed")
+		return
+	}
+
+	sources := make([]string, 0)
+	files := make(common.Files, 0)
+	previousPartNumber := 0
+	partsHash := md5.New()
+
+	for _, part := range request.Parts {
+		if part.PartNumber <= previousPartNumber {
+			s.writeError(w, r, 400, "InvalidPartOrder", "The list of parts was not in ascending order.")
+			return
+		}
+		previousPartNumber = part.PartNumber
+
+		partPath := s.partPath(uploadPath, part.PartNumber)
+		_, partName := common.Split(partPath)
+
+		file := folder.File(partName)
+		if file == nil || file.Locked() {
+			s.writeError(w, r, 400, "InvalidPart", "One or more of the specified parts could not be found.")
+			return
+		}
+
+		partTag := strings.Trim(s.etag(file), "\"")
+		if len(part.ETag) > 0 && strings.Compare(strings.Trim(part.ETag, "\""), partTag) != 0 {
+			s.writeError(w, r, 400, "InvalidPart", "One or more of the specified parts does not match with the entity tag.")
+			return
+		}
+
+		// the entity tag of the multipart upload is the MD5 of the concatenated part MD5s
+		partMd5, err := hex.DecodeString(partTag)
+		if err != nil || len(partMd5) != md5.Size {
+			s.writeError(w, r, 400, "InvalidPart", "One or more of the specified parts does not have a valid entity tag.")
+			return
+		}
+		_, _ = partsHash.Write(partMd5)
+
+		sources = append(sources, partPath)
+		files = append(files, file)
+	}
+
+	joinedFile, err := common.CreateJoinedFile(files)
+	if err != nil {
+		s.writeDfsError(w, r, err, "NoSuchUpload", "Complete multipart upload (join) request is failed")
+		return
+	}
+
+	if err := s.stagingDfs().Change(sources, objectPath, true, true, true, nil); err != nil {
+		s.writeDfsError(w, r, err, "NoSuchUpload", "Complete multipart upload request is failed")
+		return
+	}
+
+	etag := fmt.Sprintf("\"%s-%d\"", hex.EncodeToString(partsHash.Sum(nil)), len(files))
+	if err := s.stagingDfs().UpdateMetadata(objectPath, s.etagMetadata(etag, s.contentTag(joinedFile)), false, nil); err != nil {
+		s.logger.Warn(
+			"Tagging multipart upload object is failed",
+			zap.String("objectPath", objectPath),
+			zap.Error(err),
+		)
+	}
+
+	if err := s.stagingDfs().Delete(uploadPath, true, nil); err != nil {
+		s.logger.Warn(
+			"Dropping multipart upload parts is failed",
+			zap.String("uploadPath", uploadPath),
+			zap.Error(err),
+		)
+	}
+
+	s.writeXml(w, 200, &s3CompleteMultipartUploadResult{
+		Xmlns:  s3Namespace,
+		Bucket: bucket,
+		Key:    key,
+		ETag:   etag,
+	})
+}
+
+func (s *s3Router) deleteObjects(w http.ResponseWriter, r *http.Request, bucket string) {
+	var request s3Delete
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Objects) > s3MaxKeys {
+		s.writeError(w, r, 400, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema.")
+		return
+	}
+
+	result := &s3DeleteResult{
+		Xmlns:   s3Namespace,
+		Deleted: make([]s3DeleteObject, 0),
+		Errors:  make([]s3DeleteError, 0),
+	}
+
+	for _, object := range request.Objects {
+		if err := s.deleteObject(r, bucket, object.Key); err != nil {
+			s.logger.Warn(
+				"Delete object request in bulk is failed",
+				zap.String("bucket", bucket),
+				zap.String("key", object.Key),
+				zap.Error(err),
+			)
+			code := "InternalError"
+			if err == errors.ErrForbidden {
+				code = "AccessDenied"
+			}
+			result.Errors = append(result.Errors, s3DeleteError{
+				Key:     object.Key,
+				Code:    code,
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		if !request.Quiet {
+			result.Deleted = append(result.Deleted, object)
+		}
+	}
+
+	s.writeXml(w, 200, result)
+}
@@ -0,0 +1,338 @@
+package routing
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+)
+
+const s3MaxPartNumber = 10000
+
+type s3Content struct {
+	reader   io.Reader
+	size     uint64
+	sha512   hash.Hash
+	md5      hash.Hash
+	checkMd5 []byte
+}
+
+// etag returns the MD5 of the content as the entity tag that the S3 clients validate the upload with
+func (c *s3Content) etag() string {
+	return fmt.Sprintf("\"%s\"", hex.EncodeToString(c.md5.Sum(nil)))
+}
+
+// contentTag returns the tag that matches with the checksum of the file that is created from the content
+func (c *s3Content) contentTag() string {
+	return hex.EncodeToString(c.sha512.Sum(nil))
+}
+
+func (c *s3Content) verify() bool {
+	return c.checkMd5 == nil || bytes.Equal(c.checkMd5, c.md5.Sum(nil))
+}
+
+func (s *s3Router) handlePut(w http.ResponseWriter, r *http.Request, bucket string, key string) {
+	if len(bucket) == 0 {
+		s.writeError(w, r, 405, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+		return
+	}
+
+	if len(key) == 0 {
+		s.createBucket(w, r, bucket)
+		return
+	}
+
+	if !s.bucketExists(w, r, bucket) {
+		return
+	}
+
+	query := r.URL.Query()
+	copySource := r.Header.Get("X-Amz-Copy-Source")
+
+	if uploadId := query.Get("uploadId"); len(uploadId) > 0 {
+		if len(copySource) > 0 {
+			s.writeError(w, r, 501, "NotImplemented", "Copying a part from an existing object is not supported.")
+			return
+		}
+		s.uploadPart(w, r, bucket, key, uploadId, query.Get("partNumber"))
+		return
+	}
+
+	if len(copySource) > 0 {
+		s.copyObject(w, r, bucket, key, copySource)
+		return
+	}
+
+	s.putObject(w, r, bucket, key)
+}
+
+func (s *s3Router) createBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	if _, err := s.folder(s.dfs(r), s.bucketPath(bucket)); err == nil {
+		s.writeError(w, r, 409, "BucketAlreadyOwnedByYou", "The bucket you tried to create already exists, and you own it.")
+		return
+	}
+
+	if err := s.dfs(r).CreateFolder(s.bucketPath(bucket)); err != nil {
+		if err == os.ErrExist {
+			s.writeError(w, r, 409, "BucketAlreadyOwnedByYou", "The bucket you tried to create already exists, and you own it.")
+			return
+		}
+		s.writeDfsError(w, r, err, "NoSuchBucket", "Create bucket request is failed")
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/%s", bucket))
+	w.WriteHeader(200)
+}
+
+func (s *s3Router) putObject(w http.ResponseWriter, r *http.Request, bucket string, key string) {
+	objectPath, _ := s.objectPath(bucket, key)
+
+	content, ok := s.describeContent(w, r)
+	if !ok {
+		return
+	}
+
+	if strings.HasSuffix(key, "/") {
+		if content.size > 0 {
+			s.writeError(w, r, 400, "InvalidArgument", "Folder objects can not have content.")
+			return
+		}
+		if err := s.dfs(r).CreateFolder(objectPath); err != nil && err != os.ErrExist {
+			s.writeDfsError(w, r, err, "NoSuchKey", "Put object (folder) request is failed")
+			return
+		}
+		w.WriteHeader(200)
+		return
+	}
+
+	mime := r.Header.Get("Content-Type")
+	if len(mime) == 0 {
+		mime = "application/octet-stream"
+	}
+
+	if !s.checkStaging(w, r, bucket, key) {
+		return
+	}
+
+	if !s.stage(w, r, content, mime, objectPath, "NoSuchKey") {
+		return
+	}
+
+	w.Header().Set("ETag", content.etag())
+	w.WriteHeader(200)
+}
+
+func (s *s3Router) copyObject(w http.ResponseWriter, r *http.Request, bucket string, key string, copySource string) {
+	objectPath, _ := s.objectPath(bucket, key)
+
+	sourcePath, err := s.describeCopySource(copySource)
+	if err != nil {
+		s.writeError(w, r, 400, "InvalidArgument", "Copy Source must mention the source bucket and key: sourcebucket/sourcekey.")
+		return
+	}
+
+	if strings.Compare(sourcePath, objectPath) == 0 {
+		s.writeError(w, r, 400, "InvalidRequest", "This copy request is illegal because it is trying to copy an object to itself.")
+		return
+	}
+
+	read, err := s.dfs(r).Read([]string{sourcePath}, false)
+	if err != nil {
+		s.writeDfsError(w, r, err, "NoSuchKey", "Copy object (source) request is failed")
+		return
+	}
+	if read.Type() != manager.RTFile {
+		s.writeError(w, r, 404, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	if err := s.dfs(r).Change([]string{sourcePath}, objectPath, false, true, false, nil); err != nil {
+		s.writeDfsError(w, r, err, "NoSuchKey", "Copy object request is failed")
+		return
+	}
+
+	s.writeXml(w, 200, &s3CopyObjectResult{
+		Xmlns:        s3Namespace,
+		LastModified: s.formatTime(time.Now()),
+		ETag:         s.etag(read.File()),
+	})
+}
+
+func (s *s3Router) uploadPart(w http.ResponseWriter, r *http.Request, bucket string, key string, uploadId string, partNumberQuery string) {
+	partNumber, err := strconv.Atoi(partNumberQuery)
+	if err != nil || partNumber < 1 || partNumber > s3MaxPartNumber {
+		s.writeError(w, r, 400, "InvalidArgument", "Part number must be an integer between 1 and 10000, inclusive.")
+		return
+	}
+
+	uploadPath, mime, err := s.describeUploadId(uploadId)
+	if err != nil {
+		s.writeError(w, r, 404, "NoSuchUpload", "The specified multipart upload does not exist.")
+		return
+	}
+
+	if !s.checkStaging(w, r, bucket, key) {
+		return
+	}
+
+	if _, err := s.folder(s.stagingDfs(), uploadPath); err != nil {
+		s.writeDfsError(w, r, err, "NoSuchUpload", "Upload part (check) request is failed")
+		return
+	}
+
+	content, ok := s.describeContent(w, r)
+	if !ok {
+		return
+	}
+
+	if !s.stage(w, r, content, mime, s.partPath(uploadPath, partNumber), "NoSuchUpload") {
+		return
+	}
+
+	w.Header().Set("ETag", content.etag())
+	w.WriteHeader(200)
+}
+
+// stage uploads the content to the staging folder and moves it to the target after the content is verified, so the
+// existent object or part is kept when the upload is failed or the content is not matching with the digest
+func (s *s3Router) stage(w http.ResponseWriter, r *http.Request, content *s3Content, mime string, targetPath string, notFoundCode string) bool {
+	stagingPath, err := s.newObjectStagingPath()
+	if err != nil {
+		s.writeDfsError(w, r, err, notFoundCode, "Upload (staging) request is failed")
+		return false
+	}
+
+	if err := s.stagingDfs().CreateFile(stagingPath, mime, nil, nil, 0, content.size, false, nil, content.reader); err != nil {
+		s.writeDfsError(w, r, err, notFoundCode, "Upload request is failed")
+		return false
+	}
+
+	if !content.verify() {
+		s.dropStaging(stagingPath)
+		s.writeError(w, r, 400, "BadDigest", "The Content-MD5 you specified did not match what we received.")
+		return false
+	}
+
+	if err := s.stagingDfs().UpdateMetadata(stagingPath, s.etagMetadata(content.etag(), content.contentTag()), false, nil); err != nil {
+		s.dropStaging(stagingPath)
+		s.writeDfsError(w, r, err, notFoundCode, "Upload (tag) request is failed")
+		return false
+	}
+
+	if err := s.stagingDfs().Change([]string{stagingPath}, targetPath, false, true, true, nil); err != nil {
+		s.dropStaging(stagingPath)
+		s.writeDfsError(w, r, err, notFoundCode, "Upload (commit) request is failed")
+		return false
+	}
+	return true
+}
+
+func (s *s3Router) partPath(uploadPath string, partNumber int) string {
+	return fmt.Sprintf("%s/%05d", uploadPath, partNumber)
+}
+
+// describeContent prepares the request body for the upload with the support of aws-chunked encoding
+// and calculates the checksums during the streaming
+func (s *s3Router) describeContent(w http.ResponseWriter, r *http.Request) (*s3Content, bool) {
+	content := &s3Content{
+		reader: r.Body,
+		sha512: sha512.New512_256(),
+		md5:    md5.New(),
+	}
+
+	contentLength := r.ContentLength
+
+	contentSha256 := r.Header.Get("X-Amz-Content-Sha256")
+	if strings.HasPrefix(contentSha256, "STREAMING-") || strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		decodedLength, err := strconv.ParseInt(r.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64)
+		if err != nil || decodedLength < 0 {
+			s.writeError(w, r, 411, "MissingContentLength", "You must provide the x-amz-decoded-content-length HTTP header.")
+			return nil, false
+		}
+		contentLength = decodedLength
+
+		signer, ok := s.chunkSigner(w, r, contentSha256)
+		if !ok {
+			return nil, false
+		}
+		content.reader = newS3ChunkedReader(r.Body, signer)
+	}
+
+	if contentLength < 0 {
+		s.writeError(w, r, 411, "MissingContentLength", "You must provide the Content-Length HTTP header.")
+		return nil, false
+	}
+	content.size = uint64(contentLength)
+
+	if contentMd5 := r.Header.Get("Content-Md5"); len(contentMd5) > 0 {
+		checkMd5, err := base64.StdEncoding.DecodeString(contentMd5)
+		if err != nil || len(checkMd5) != md5.Size {
+			s.writeError(w, r, 400, "InvalidDigest", "The Content-MD5 you specified is not valid.")
+			return nil, false
+		}
+		content.checkMd5 = checkMd5
+	}
+
+	content.reader = io.TeeReader(io.LimitReader(content.reader, contentLength), io.MultiWriter(content.sha512, content.md5))
+
+	return content, true
+}
+
+// chunkSigner creates the signer to verify the chunks of the streaming signed payload. The signatures are not
+// verified when the authentication is disabled or the payload is not signed
+func (s *s3Router) chunkSigner(w http.ResponseWriter, r *http.Request, contentSha256 string) (*auth.ChunkSigner, bool) {
+	if s.authenticator == nil || !strings.HasPrefix(contentSha256, "STREAMING-") ||
+		strings.Compare(contentSha256, auth.StreamingUnsignedPayload) == 0 {
+		return nil, true
+	}
+
+	if strings.Compare(contentSha256, auth.StreamingSignedPayload) != 0 {
+		s.writeError(w, r, 501, "NotImplemented", "The streaming payload signature you provided is not supported.")
+		return nil, false
+	}
+
+	signer, err := s.authenticator.ChunkSigner(r)
+	if err != nil {
+		s.writeError(w, r, 403, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.")
+		return nil, false
+	}
+	return signer, true
+}
+
+func (s *s3Router) describeCopySource(copySource string) (string, error) {
+	if questionIdx := strings.Index(copySource, "?"); questionIdx > -1 {
+		copySource = copySource[:questionIdx]
+	}
+
+	copySource, err := url.PathUnescape(copySource)
+	if err != nil {
+		return "", err
+	}
+	copySource = strings.TrimPrefix(copySource, "/")
+
+	slashIdx := strings.Index(copySource, "/")
+	if slashIdx < 1 || slashIdx == len(copySource)-1 {
+		return "", os.ErrInvalid
+	}
+
+	bucket := copySource[:slashIdx]
+	if !s.validateBucket(bucket) {
+		return "", os.ErrInvalid
+	}
+
+	return s.objectPath(bucket, copySource[slashIdx+1:])
+}
@@ -0,0 +1,215 @@
+package routing
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"go.uber.org/zap"
+)
+
+const s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+const s3TimeFormat = "2006-01-02T15:04:05.000Z"
+
+// s3EtagMetadataKey is the metadata key of the file that keeps the MD5 based entity tag of the object
+const s3EtagMetadataKey = "s3-etag"
+
+type s3Error struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+type s3Owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type s3Bucket struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type s3ListAllMyBucketsResult struct {
+	XMLName xml.Name   `xml:"ListAllMyBucketsResult"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Owner   s3Owner    `xml:"Owner"`
+	Buckets []s3Bucket `xml:"Buckets>Bucket"`
+}
+
+type s3LocationConstraint struct {
+	XMLName xml.Name `xml:"LocationConstraint"`
+	Xmlns   string   `xml:"xmlns,attr"`
+}
+
+type s3Object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         uint64 `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type s3CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type s3ListBucketResult struct {
+	XMLName               xml.Name         `xml:"ListBucketResult"`
+	Xmlns                 string           `xml:"xmlns,attr"`
+	Name                  string           `xml:"Name"`
+	Prefix                string           `xml:"Prefix"`
+	Marker                *string          `xml:"Marker,omitempty"`
+	NextMarker            string           `xml:"NextMarker,omitempty"`
+	StartAfter            string           `xml:"StartAfter,omitempty"`
+	ContinuationToken     string           `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string           `xml:"NextContinuationToken,omitempty"`
+	KeyCount              *int             `xml:"KeyCount,omitempty"`
+	MaxKeys               int              `xml:"MaxKeys"`
+	Delimiter             string           `xml:"Delimiter,omitempty"`
+	EncodingType          string           `xml:"EncodingType,omitempty"`
+	IsTruncated           bool             `xml:"IsTruncated"`
+	Contents              []s3Object       `xml:"Contents"`
+	CommonPrefixes        []s3CommonPrefix `xml:"CommonPrefixes"`
+}
+
+type s3CopyObjectResult struct {
+	XMLName      xml.Name `xml:"CopyObjectResult"`
+	Xmlns        string   `xml:"xmlns,attr"`
+	LastModified string   `xml:"LastModified"`
+	ETag         string   `xml:"ETag"`
+}
+
+type s3InitiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadId string   `xml:"UploadId"`
+}
+
+type s3Part struct {
+	PartNumber   int    `xml:"PartNumber"`
+	ETag         string `xml:"ETag"`
+	LastModified string `xml:"LastModified,omitempty"`
+	Size         uint64 `xml:"Size,omitempty"`
+}
+
+type s3CompleteMultipartUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []s3Part `xml:"Part"`
+}
+
+type s3CompleteMultipartUploadResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Bucket  string   `xml:"Bucket"`
+	Key     string   `xml:"Key"`
+	ETag    string   `xml:"ETag"`
+}
+
+type s3ListPartsResult struct {
+	XMLName              xml.Name `xml:"ListPartsResult"`
+	Xmlns                string   `xml:"xmlns,attr"`
+	Bucket               string   `xml:"Bucket"`
+	Key                  string   `xml:"Key"`
+	UploadId             string   `xml:"UploadId"`
+	PartNumberMarker     int      `xml:"PartNumberMarker"`
+	NextPartNumberMarker int      `xml:"NextPartNumberMarker"`
+	MaxParts             int      `xml:"MaxParts"`
+	IsTruncated          bool     `xml:"IsTruncated"`
+	Parts                []s3Part `xml:"Part"`
+}
+
+type s3DeleteObject struct {
+	Key string `xml:"Key"`
+}
+
+type s3Delete struct {
+	XMLName xml.Name         `xml:"Delete"`
+	Quiet   bool             `xml:"Quiet"`
+	Objects []s3DeleteObject `xml:"Object"`
+}
+
+type s3DeleteError struct {
+	Key     string `xml:"Key"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type s3DeleteResult struct {
+	XMLName xml.Name         `xml:"DeleteResult"`
+	Xmlns   string           `xml:"xmlns,attr"`
+	Deleted []s3DeleteObject `xml:"Deleted"`
+	Errors  []s3DeleteError  `xml:"Error"`
+}
+
+func (s *s3Router) writeXml(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return
+	}
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Warn("Response of s3 request is failed", zap.Error(err))
+	}
+}
+
+func (s *s3Router) writeError(w http.ResponseWriter, r *http.Request, statusCode int, code string, message string) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	s.writeXml(w, statusCode, &s3Error{
+		Code:     code,
+		Message:  message,
+		Resource: r.URL.Path,
+	})
+}
+
+// etag returns the entity tag of the object. The objects uploaded through the S3 api keep the MD5 based tag in
+// the metadata together with the content tag that it is calculated for, the content tag is served when the content
+// is replaced out of the S3 api
+func (s *s3Router) etag(file *common.File) string {
+	contentTag := s.contentTag(file)
+
+	if value, has := file.Metadata[s3EtagMetadataKey]; has {
+		if separatorIdx := strings.Index(value, ":"); separatorIdx > -1 && strings.Compare(value[separatorIdx+1:], contentTag) == 0 {
+			return fmt.Sprintf("\"%s\"", value[:separatorIdx])
+		}
+	}
+	return fmt.Sprintf("\"%s\"", contentTag)
+}
+
+// etagMetadata creates the metadata that keeps the entity tag of the object for the content tag
+func (s *s3Router) etagMetadata(etag string, contentTag string) common.Metadata {
+	return common.Metadata{
+		s3EtagMetadataKey: fmt.Sprintf("%s:%s", strings.Trim(etag, "\""), contentTag),
+	}
+}
+
+// contentTag creates the tag of the file content using the content checksum. Joined files (multipart uploads)
+// do not have content checksum so the tag is calculated from the chunk hashes
+func (s *s3Router) contentTag(file *common.File) string {
+	if file.Size == 0 || strings.Compare(file.Checksum, common.EmptyChecksum()) != 0 {
+		return file.Checksum
+	}
+
+	hash := sha512.New512_256()
+	for _, chunk := range file.Chunks {
+		_, _ = hash.Write([]byte(chunk.Hash))
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(hash.Sum(nil)), len(file.Chunks))
+}
+
+func (s *s3Router) formatTime(t time.Time) string {
+	return t.UTC().Format(s3TimeFormat)
+}
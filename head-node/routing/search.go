@@ -0,0 +1,261 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"go.uber.org/zap"
+)
+
+const searchEndPoint = "/client/search"
+const defaultSearchLimit = 1000
+const maxSearchLimit = 10000
+
+type searchRouter struct {
+	access manager.AccessControl
+	logger *zap.Logger
+
+	definitions []*Definition
+}
+
+// NewSearchRouter creates the router of the folder/file search in the folder tree
+func NewSearchRouter(access manager.AccessControl, logger *zap.Logger) Router {
+	pR := &searchRouter{
+		access:      access,
+		logger:      logger,
+		definitions: make([]*Definition, 0),
+	}
+	pR.setup()
+
+	return pR
+}
+
+func (s *searchRouter) setup() {
+	s.definitions =
+		append(s.definitions,
+			&Definition{
+				Path:    searchEndPoint,
+				Handler: s.manipulate,
+			},
+		)
+}
+
+func (s *searchRouter) Get() []*Definition {
+	return s.definitions
+}
+
+// dfs returns the file manipulation operations that are evaluated for the authenticated key of the request
+func (s *searchRouter) dfs(r *http.Request) manager.Dfs {
+	return s.access.Dfs(auth.FromContext(r.Context()))
+}
+
+func (s *searchRouter) manipulate(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	switch r.Method {
+	case http.MethodGet:
+		s.handleGet(w, r)
+	default:
+		w.WriteHeader(406)
+	}
+}
+
+func (s *searchRouter) handleGet(w http.ResponseWriter, r *http.Request) {
+	query, err := s.describeQuery(r)
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if limitHeader := r.Header.Get("X-Limit"); len(limitHeader) > 0 {
+		limit, err = strconv.Atoi(limitHeader)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			w.WriteHeader(422)
+			return
+		}
+	}
+
+	result, err := s.dfs(r).Search(query, r.Header.Get("X-Continue"), limit)
+	if err != nil {
+		if err == os.ErrNotExist {
+			w.WriteHeader(404)
+			return
+		} else if err == errors.ErrForbidden {
+			w.WriteHeader(403)
+			return
+		} else if err == os.ErrInvalid {
+			w.WriteHeader(422)
+			return
+		} else {
+			w.WriteHeader(500)
+		}
+		s.logger.Error("Search request is failed", zap.String("path", query.Path), zap.Error(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(result.Next) > 0 {
+		w.Header().Set("X-Continue", result.Next)
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		w.WriteHeader(500)
+		s.logger.Error(
+			"Response of search request is failed",
+			zap.String("path", query.Path),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *searchRouter) describeQuery(r *http.Request) (*manager.SearchQuery, error) {
+	requestedPath, err := url.QueryUnescape(r.Header.Get("X-Path"))
+	if err != nil {
+		return nil, err
+	}
+	if len(requestedPath) == 0 || !common.ValidatePath(requestedPath) {
+		return nil, os.ErrInvalid
+	}
+
+	recursiveHeader := strings.ToLower(r.Header.Get("X-Recursive"))
+	recursive := len(recursiveHeader) > 0 && (strings.Compare(recursiveHeader, "1") == 0 || strings.Compare(recursiveHeader, "true") == 0)
+
+	query := &manager.SearchQuery{
+		Path:      requestedPath,
+		Recursive: recursive,
+		Type:      strings.ToLower(r.Header.Get("X-Type")),
+		Name:      r.Header.Get("X-Name"),
+		Mime:      r.Header.Get("X-Mime"),
+	}
+
+	if !manager.ValidSearchType(query.Type) {
+		return nil, os.ErrInvalid
+	}
+	if _, err := path.Match(query.Name, ""); err != nil {
+		return nil, err
+	}
+	if _, err := path.Match(query.Mime, ""); err != nil {
+		return nil, err
+	}
+
+	if nameRegex := r.Header.Get("X-Name-Regex"); len(nameRegex) > 0 {
+		query.NameRegex, err = regexp.Compile(nameRegex)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if query.MinSize, query.MaxSize, err = s.describeSizeRange(r.Header.Get("X-Size")); err != nil {
+		return nil, err
+	}
+	if query.CreatedSince, query.CreatedUntil, err = s.describeTimeRange(r.Header.Get("X-Created")); err != nil {
+		return nil, err
+	}
+	if query.ModifiedSince, query.ModifiedUntil, err = s.describeTimeRange(r.Header.Get("X-Modified")); err != nil {
+		return nil, err
+	}
+
+	if query.Zombie, err = s.describeState(r.Header.Get("X-Zombie")); err != nil {
+		return nil, err
+	}
+	if query.Locked, err = s.describeState(r.Header.Get("X-Locked")); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+// describeRange splits the [begins],[ends] formatted header. Any of the sides can be empty
+func (s *searchRouter) describeRange(rangeHeader string) (string, string, error) {
+	if len(rangeHeader) == 0 {
+		return "", "", nil
+	}
+
+	commaIdx := strings.Index(rangeHeader, ",")
+	if commaIdx == -1 {
+		return "", "", os.ErrInvalid
+	}
+	return strings.TrimSpace(rangeHeader[:commaIdx]), strings.TrimSpace(rangeHeader[commaIdx+1:]), nil
+}
+
+func (s *searchRouter) describeSizeRange(rangeHeader string) (*uint64, *uint64, error) {
+	begins, ends, err := s.describeRange(rangeHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parse := func(v string) (*uint64, error) {
+		if len(v) == 0 {
+			return nil, nil
+		}
+		size, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return &size, nil
+	}
+
+	minSize, err := parse(begins)
+	if err != nil {
+		return nil, nil, err
+	}
+	maxSize, err := parse(ends)
+	if err != nil {
+		return nil, nil, err
+	}
+	return minSize, maxSize, nil
+}
+
+func (s *searchRouter) describeTimeRange(rangeHeader string) (*time.Time, *time.Time, error) {
+	begins, ends, err := s.describeRange(rangeHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parse := func(v string) (*time.Time, error) {
+		if len(v) == 0 {
+			return nil, nil
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+
+	since, err := parse(begins)
+	if err != nil {
+		return nil, nil, err
+	}
+	until, err := parse(ends)
+	if err != nil {
+		return nil, nil, err
+	}
+	return since, until, nil
+}
+
+func (s *searchRouter) describeState(stateHeader string) (*bool, error) {
+	if len(stateHeader) == 0 {
+		return nil, nil
+	}
+
+	state, err := strconv.ParseBool(stateHeader)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+var _ Router = &searchRouter{}
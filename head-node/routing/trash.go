@@ -0,0 +1,124 @@
+package routing
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const trashEndPoint = "/client/trash"
+
+type trashRouter struct {
+	access manager.AccessControl
+	logger *zap.Logger
+
+	definitions []*Definition
+}
+
+// NewTrashRouter creates the router of the deleted folders and files that are kept in the trash
+func NewTrashRouter(access manager.AccessControl, logger *zap.Logger) Router {
+	pR := &trashRouter{
+		access:      access,
+		logger:      logger,
+		definitions: make([]*Definition, 0),
+	}
+	pR.setup()
+
+	return pR
+}
+
+func (t *trashRouter) setup() {
+	t.definitions =
+		append(t.definitions,
+			&Definition{
+				Path:    trashEndPoint,
+				Handler: t.manipulate,
+			},
+			&Definition{
+				Path:    trashEndPoint + "/{entryId}",
+				Handler: t.manipulateEntry,
+			},
+		)
+}
+
+func (t *trashRouter) Get() []*Definition {
+	return t.definitions
+}
+
+// dfs returns the file manipulation operations that are evaluated for the authenticated key of the request
+func (t *trashRouter) dfs(r *http.Request) manager.Dfs {
+	return t.access.Dfs(auth.FromContext(r.Context()))
+}
+
+func (t *trashRouter) manipulate(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	switch r.Method {
+	case http.MethodGet:
+		t.handleGet(w, r)
+	case http.MethodDelete:
+		t.handleEmpty(w, r)
+	default:
+		w.WriteHeader(406)
+	}
+}
+
+func (t *trashRouter) manipulateEntry(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	entryId := mux.Vars(r)["entryId"]
+
+	switch r.Method {
+	case http.MethodPost:
+		t.handleRestore(w, r, entryId)
+	case http.MethodDelete:
+		t.handleDelete(w, r, entryId)
+	default:
+		w.WriteHeader(406)
+	}
+}
+
+func (t *trashRouter) writeError(w http.ResponseWriter, err error, entryId string, logMessage string) {
+	switch err {
+	case os.ErrNotExist:
+		w.WriteHeader(404)
+		return
+	case errors.ErrForbidden:
+		w.WriteHeader(403)
+		return
+	case os.ErrExist:
+		w.WriteHeader(409)
+		return
+	case os.ErrInvalid:
+		w.WriteHeader(422)
+		return
+	case errors.ErrNoAvailableActionNode:
+		w.WriteHeader(503)
+		return
+	case errors.ErrLock:
+		w.WriteHeader(523)
+		return
+	case errors.ErrZombie:
+		w.WriteHeader(524)
+		return
+	case errors.ErrZombieAlive:
+		w.WriteHeader(525)
+		return
+	case errors.ErrRepair:
+		w.WriteHeader(526)
+		return
+	case errors.ErrQuota:
+		w.WriteHeader(527)
+		return
+	}
+
+	w.WriteHeader(500)
+	t.logger.Error(logMessage, zap.String("entryId", entryId), zap.Error(err))
+}
+
+var _ Router = &trashRouter{}
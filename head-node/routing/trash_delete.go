@@ -0,0 +1,23 @@
+package routing
+
+import (
+	"net/http"
+)
+
+func (t *trashRouter) handleDelete(w http.ResponseWriter, r *http.Request, entryId string) {
+	if err := t.dfs(r).PurgeTrash(entryId); err != nil {
+		t.writeError(w, err, entryId, "Trash purge request is failed")
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (t *trashRouter) handleEmpty(w http.ResponseWriter, r *http.Request) {
+	if err := t.dfs(r).EmptyTrash(); err != nil {
+		t.writeError(w, err, "", "Empty trash request is failed")
+		return
+	}
+
+	w.WriteHeader(200)
+}
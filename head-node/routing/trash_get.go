@@ -0,0 +1,22 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+func (t *trashRouter) handleGet(w http.ResponseWriter, r *http.Request) {
+	entries, err := t.dfs(r).ListTrash()
+	if err != nil {
+		t.writeError(w, err, "", "Trash list request is failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		w.WriteHeader(500)
+		t.logger.Error("Response of trash list request is failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,14 @@
+package routing
+
+import (
+	"net/http"
+)
+
+func (t *trashRouter) handleRestore(w http.ResponseWriter, r *http.Request, entryId string) {
+	if err := t.dfs(r).RestoreTrash(entryId); err != nil {
+		t.writeError(w, err, entryId, "Trash restore request is failed")
+		return
+	}
+
+	w.WriteHeader(202)
+}
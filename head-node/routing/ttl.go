@@ -0,0 +1,22 @@
+package routing
+
+import (
+	"net/http"
+	"os"
+	"time"
+)
+
+// describeTimeToLive parses the X-TTL header as duration (Ex: 72h). It returns nil if the header is absent
+// to let the folder default time-to-live to be used. Zero duration means no expiry
+func describeTimeToLive(header http.Header) (*time.Duration, error) {
+	ttlHeader := header.Get("X-TTL")
+	if len(ttlHeader) == 0 {
+		return nil, nil
+	}
+
+	ttl, err := time.ParseDuration(ttlHeader)
+	if err != nil || ttl < 0 {
+		return nil, os.ErrInvalid
+	}
+	return &ttl, nil
+}
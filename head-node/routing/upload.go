@@ -0,0 +1,146 @@
+package routing
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const uploadEndPoint = "/client/upload"
+
+type uploadRouter struct {
+	access manager.AccessControl
+	logger *zap.Logger
+
+	definitions []*Definition
+}
+
+// NewUploadRouter creates the router of the resumable upload sessions
+func NewUploadRouter(access manager.AccessControl, logger *zap.Logger) Router {
+	pR := &uploadRouter{
+		access:      access,
+		logger:      logger,
+		definitions: make([]*Definition, 0),
+	}
+	pR.setup()
+
+	return pR
+}
+
+func (u *uploadRouter) setup() {
+	u.definitions =
+		append(u.definitions,
+			&Definition{
+				Path:    uploadEndPoint,
+				Handler: u.manipulate,
+			},
+			&Definition{
+				Path:    uploadEndPoint + "/{sessionId}",
+				Handler: u.manipulateSession,
+			},
+		)
+}
+
+func (u *uploadRouter) Get() []*Definition {
+	return u.definitions
+}
+
+// dfs returns the file manipulation operations that are evaluated for the authenticated key of the request
+func (u *uploadRouter) dfs(r *http.Request) manager.Dfs {
+	return u.access.Dfs(auth.FromContext(r.Context()))
+}
+
+func (u *uploadRouter) manipulate(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	switch r.Method {
+	case http.MethodPost:
+		u.handleCreate(w, r)
+	default:
+		w.WriteHeader(406)
+	}
+}
+
+func (u *uploadRouter) manipulateSession(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	sessionId := mux.Vars(r)["sessionId"]
+
+	switch r.Method {
+	case http.MethodGet:
+		u.handleGet(w, r, sessionId)
+	case http.MethodPut:
+		u.handlePut(w, r, sessionId)
+	case http.MethodPost:
+		u.handleCommit(w, r, sessionId)
+	case http.MethodDelete:
+		u.handleDelete(w, r, sessionId)
+	default:
+		w.WriteHeader(406)
+	}
+}
+
+func (u *uploadRouter) describeXPath(xPath string) (string, error) {
+	requestedPath, err := url.QueryUnescape(xPath)
+	if err != nil {
+		return "", err
+	}
+	if len(requestedPath) == 0 || !common.ValidatePath(requestedPath) {
+		return "", os.ErrInvalid
+	}
+	return requestedPath, nil
+}
+
+func (u *uploadRouter) writeError(w http.ResponseWriter, err error, sessionId string, logMessage string) {
+	switch err {
+	case os.ErrNotExist:
+		w.WriteHeader(404)
+		return
+	case errors.ErrForbidden:
+		w.WriteHeader(403)
+		return
+	case os.ErrExist:
+		w.WriteHeader(409)
+		return
+	case io.EOF, io.ErrUnexpectedEOF:
+		w.WriteHeader(400)
+		return
+	case os.ErrInvalid, errors.ErrChunking:
+		w.WriteHeader(422)
+		return
+	case errors.ErrIncomplete, errors.ErrPrecondition:
+		w.WriteHeader(412)
+		return
+	case errors.ErrNoAvailableActionNode, errors.ErrNoAvailableClusterNode:
+		w.WriteHeader(503)
+		return
+	case errors.ErrNoSpace:
+		w.WriteHeader(507)
+		return
+	case errors.ErrLock:
+		w.WriteHeader(523)
+		return
+	case errors.ErrZombie:
+		w.WriteHeader(524)
+		return
+	case errors.ErrRepair:
+		w.WriteHeader(526)
+		return
+	case errors.ErrQuota:
+		w.WriteHeader(527)
+		return
+	}
+
+	w.WriteHeader(500)
+	u.logger.Error(logMessage, zap.String("sessionId", sessionId), zap.Error(err))
+}
+
+var _ Router = &uploadRouter{}
@@ -0,0 +1,14 @@
+package routing
+
+import (
+	"net/http"
+)
+
+func (u *uploadRouter) handleDelete(w http.ResponseWriter, r *http.Request, sessionId string) {
+	if err := u.dfs(r).DiscardUpload(sessionId); err != nil {
+		u.writeError(w, err, sessionId, "Discard upload session request is failed")
+		return
+	}
+
+	w.WriteHeader(200)
+}
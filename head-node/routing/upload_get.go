@@ -0,0 +1,26 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+func (u *uploadRouter) handleGet(w http.ResponseWriter, r *http.Request, sessionId string) {
+	session, err := u.dfs(r).ReadUpload(sessionId)
+	if err != nil {
+		u.writeError(w, err, sessionId, "Read upload session request is failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(session); err != nil {
+		w.WriteHeader(500)
+		u.logger.Error(
+			"Response of read upload session request is failed",
+			zap.String("sessionId", sessionId),
+			zap.Error(err),
+		)
+	}
+}
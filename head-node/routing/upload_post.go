@@ -0,0 +1,78 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+func (u *uploadRouter) handleCreate(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := u.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if len(contentType) == 0 {
+		w.WriteHeader(422)
+		return
+	}
+
+	size, err := strconv.ParseUint(r.Header.Get("X-Size"), 10, 64)
+	if err != nil || size == 0 {
+		w.WriteHeader(422)
+		return
+	}
+
+	metadata, err := describeMetadata(r.Header)
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	ttl, err := describeTimeToLive(r.Header)
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	blockSize, err := describeBlockSize(r.Header)
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	overwriteHeader := strings.ToLower(r.Header.Get("X-Overwrite"))
+	overwrite := len(overwriteHeader) > 0 && (strings.Compare(overwriteHeader, "1") == 0 || strings.Compare(overwriteHeader, "true") == 0)
+
+	session, err := u.dfs(r).CreateUpload(requestedPath, contentType, metadata, ttl, blockSize, size, overwrite)
+	if err != nil {
+		u.writeError(w, err, "", "Create upload session request is failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Upload-Id", session.Id)
+	w.WriteHeader(202)
+
+	if err := json.NewEncoder(w).Encode(session); err != nil {
+		u.logger.Error(
+			"Response of create upload session request is failed",
+			zap.String("sessionId", session.Id),
+			zap.Error(err),
+		)
+	}
+}
+
+func (u *uploadRouter) handleCommit(w http.ResponseWriter, r *http.Request, sessionId string) {
+	if err := u.dfs(r).CommitUpload(sessionId, describePrecondition(r)); err != nil {
+		u.writeError(w, err, sessionId, "Commit upload session request is failed")
+		return
+	}
+
+	w.WriteHeader(202)
+}
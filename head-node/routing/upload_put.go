@@ -0,0 +1,26 @@
+package routing
+
+import (
+	"net/http"
+	"strconv"
+)
+
+func (u *uploadRouter) handlePut(w http.ResponseWriter, r *http.Request, sessionId string) {
+	offset, err := strconv.ParseUint(r.Header.Get("X-Offset"), 10, 64)
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	if r.ContentLength < 1 {
+		w.WriteHeader(411)
+		return
+	}
+
+	if err := u.dfs(r).UploadPart(sessionId, offset, uint64(r.ContentLength), r.Body); err != nil {
+		u.writeError(w, err, sessionId, "Upload part request is failed")
+		return
+	}
+
+	w.WriteHeader(202)
+}
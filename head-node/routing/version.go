@@ -0,0 +1,118 @@
+package routing
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	"github.com/freakmaxi/kertish-dfs/head-node/manager"
+	"go.uber.org/zap"
+)
+
+const versionEndPoint = "/client/version"
+
+type versionRouter struct {
+	access manager.AccessControl
+	logger *zap.Logger
+
+	definitions []*Definition
+}
+
+// NewVersionRouter creates the router of the file versioning
+func NewVersionRouter(access manager.AccessControl, logger *zap.Logger) Router {
+	pR := &versionRouter{
+		access:      access,
+		logger:      logger,
+		definitions: make([]*Definition, 0),
+	}
+	pR.setup()
+
+	return pR
+}
+
+func (v *versionRouter) setup() {
+	v.definitions =
+		append(v.definitions,
+			&Definition{
+				Path:    versionEndPoint,
+				Handler: v.manipulate,
+			},
+		)
+}
+
+func (v *versionRouter) Get() []*Definition {
+	return v.definitions
+}
+
+// dfs returns the file manipulation operations that are evaluated for the authenticated key of the request
+func (v *versionRouter) dfs(r *http.Request) manager.Dfs {
+	return v.access.Dfs(auth.FromContext(r.Context()))
+}
+
+func (v *versionRouter) manipulate(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	switch r.Method {
+	case http.MethodGet:
+		v.handleGet(w, r)
+	case http.MethodPut:
+		v.handlePut(w, r)
+	case http.MethodPost:
+		v.handlePost(w, r)
+	case http.MethodDelete:
+		v.handleDelete(w, r)
+	default:
+		w.WriteHeader(406)
+	}
+}
+
+func (v *versionRouter) describeXPath(xPath string) (string, error) {
+	requestedPath, err := url.QueryUnescape(xPath)
+	if err != nil {
+		return "", err
+	}
+	if len(requestedPath) == 0 || !common.ValidatePath(requestedPath) {
+		return "", os.ErrInvalid
+	}
+	return requestedPath, nil
+}
+
+func (v *versionRouter) writeError(w http.ResponseWriter, err error, path string, logMessage string) {
+	switch err {
+	case os.ErrNotExist:
+		w.WriteHeader(404)
+		return
+	case errors.ErrForbidden:
+		w.WriteHeader(403)
+		return
+	case os.ErrInvalid:
+		w.WriteHeader(422)
+		return
+	case errors.ErrPrecondition:
+		w.WriteHeader(412)
+		return
+	case errors.ErrNoAvailableActionNode:
+		w.WriteHeader(503)
+		return
+	case errors.ErrLock:
+		w.WriteHeader(523)
+		return
+	case errors.ErrZombie:
+		w.WriteHeader(524)
+		return
+	case errors.ErrZombieAlive:
+		w.WriteHeader(525)
+		return
+	case errors.ErrQuota:
+		w.WriteHeader(527)
+		return
+	}
+
+	w.WriteHeader(500)
+	v.logger.Error(logMessage, zap.String("path", path), zap.Error(err))
+}
+
+var _ Router = &versionRouter{}
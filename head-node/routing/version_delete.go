@@ -0,0 +1,43 @@
+package routing
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (v *versionRouter) handleDelete(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := v.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	var keep *int
+	if keepHeader := r.Header.Get("X-Keep"); len(keepHeader) > 0 {
+		count, err := strconv.Atoi(keepHeader)
+		if err != nil || count < 0 {
+			w.WriteHeader(422)
+			return
+		}
+		keep = &count
+	}
+
+	var before *time.Time
+	if olderThanHeader := r.Header.Get("X-Older-Than"); len(olderThanHeader) > 0 {
+		age, err := time.ParseDuration(olderThanHeader)
+		if err != nil || age < 0 {
+			w.WriteHeader(422)
+			return
+		}
+		t := time.Now().UTC().Add(-age)
+		before = &t
+	}
+
+	if err := v.dfs(r).PurgeVersions(requestedPath, keep, before); err != nil {
+		v.writeError(w, err, requestedPath, "Purge versions request is failed")
+		return
+	}
+
+	w.WriteHeader(200)
+}
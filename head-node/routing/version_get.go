@@ -0,0 +1,32 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+func (v *versionRouter) handleGet(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := v.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	versions, err := v.dfs(r).Versions(requestedPath)
+	if err != nil {
+		v.writeError(w, err, requestedPath, "Versions request is failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(versions); err != nil {
+		w.WriteHeader(500)
+		v.logger.Error(
+			"Response of versions request is failed",
+			zap.String("path", requestedPath),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,26 @@
+package routing
+
+import (
+	"net/http"
+)
+
+func (v *versionRouter) handlePost(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := v.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	versionId := r.Header.Get("X-Version")
+	if len(versionId) == 0 {
+		w.WriteHeader(422)
+		return
+	}
+
+	if err := v.dfs(r).RestoreVersion(requestedPath, versionId, describePrecondition(r)); err != nil {
+		v.writeError(w, err, requestedPath, "Restore version request is failed")
+		return
+	}
+
+	w.WriteHeader(202)
+}
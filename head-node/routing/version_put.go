@@ -0,0 +1,27 @@
+package routing
+
+import (
+	"net/http"
+	"strconv"
+)
+
+func (v *versionRouter) handlePut(w http.ResponseWriter, r *http.Request) {
+	requestedPath, err := v.describeXPath(r.Header.Get("X-Path"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	enabled, err := strconv.ParseBool(r.Header.Get("X-Versioning"))
+	if err != nil {
+		w.WriteHeader(422)
+		return
+	}
+
+	if err := v.dfs(r).SetVersioning(requestedPath, enabled); err != nil {
+		v.writeError(w, err, requestedPath, "Versioning request is failed")
+		return
+	}
+
+	w.WriteHeader(202)
+}
@@ -12,10 +12,12 @@ import (
 	"time"
 
 	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 )
 
 const (
 	commandCreate           = "CREA"
+	commandCreateShard      = "SHRD"
 	commandRead             = "READ"
 	commandDelete           = "DELE"
 	commandHardwareId       = "HWID"
@@ -32,9 +34,11 @@ const (
 	commandSnapshotCreate   = "SSCR"
 	commandSnapshotDelete   = "SSDE"
 	commandSnapshotRestore  = "SSRS"
+	commandRotateKeys       = "ROTK"
 	commandPing             = "PING"
 	commandSize             = "SIZE"
 	commandUsed             = "USED"
+	commandUsedLogical      = "USDL"
 	commandRequestHandshake = "RQHS"
 )
 
@@ -43,6 +47,7 @@ const pingWaitDuration = time.Second * 5
 
 type DataNode interface {
 	Create(data []byte) (string, error)
+	CreateShard(sha512Hex string, shard []byte) error
 	Read(sha512Hex string, begins uint32, ends uint32, readHandler func(data []byte) error) error
 	Delete(sha512Hex string) error
 
@@ -63,9 +68,11 @@ type DataNode interface {
 	SnapshotDelete(snapshotIndex uint64) bool
 	SnapshotRestore(snapshotIndex uint64) bool
 
+	RotateKeys() bool
+
 	Ping() int64
 	Size() (uint64, error)
-	Used() (uint64, error)
+	Used() (uint64, uint64, error)
 
 	RequestHandshake() bool
 }
@@ -86,7 +93,7 @@ func NewDataNode(nodeAddress string) (DataNode, error) {
 }
 
 func (d *dataNode) connect(connectionHandler func(conn net.Conn) error) error {
-	conn, err := net.DialTimeout(d.address.Network(), d.address.String(), dialTimeout)
+	conn, err := secure.DialTimeout(d.address.Network(), d.address.String(), dialTimeout)
 	if err != nil {
 		return err
 	}
@@ -96,7 +103,7 @@ func (d *dataNode) connect(connectionHandler func(conn net.Conn) error) error {
 }
 
 func (d *dataNode) connectWithTimeout(timeout time.Duration, connectionHandler func(conn net.Conn) error) error {
-	conn, err := net.DialTimeout(d.address.Network(), d.address.String(), timeout)
+	conn, err := secure.DialTimeout(d.address.Network(), d.address.String(), timeout)
 	if err != nil {
 		return err
 	}
@@ -175,6 +182,48 @@ func (d *dataNode) Create(data []byte) (sha512Hex string, err error) {
 	return
 }
 
+// CreateShard creates the shard of the erasure coded chunk with the name of the chunk. Usage of the shard is
+// increased if it already exists on the node
+func (d *dataNode) CreateShard(sha512Hex string, shard []byte) error {
+	return d.connect(func(conn net.Conn) error {
+		if _, err := conn.Write([]byte(commandCreateShard)); err != nil {
+			return err
+		}
+
+		sha512Sum, err := hex.DecodeString(sha512Hex)
+		if err != nil {
+			return err
+		}
+		if _, err := conn.Write(sha512Sum); err != nil {
+			return err
+		}
+
+		shardSha512Sum := sha512.Sum512_256(shard)
+		if _, err := conn.Write(shardSha512Sum[:]); err != nil {
+			return err
+		}
+
+		if !d.result(conn) {
+			return nil
+		}
+
+		blockSize := uint32(len(shard))
+		if err := binary.Write(conn, binary.LittleEndian, &blockSize); err != nil {
+			return err
+		}
+
+		if _, err := conn.Write(shard); err != nil {
+			return err
+		}
+
+		if !d.result(conn) {
+			return fmt.Errorf("create shard command is failed on data node")
+		}
+
+		return nil
+	})
+}
+
 func (d *dataNode) Read(sha512Hex string, begins uint32, ends uint32, readHandler func([]byte) error) error {
 	return d.connect(func(conn net.Conn) error {
 		if _, err := conn.Write([]byte(commandRead)); err != nil {
@@ -668,6 +717,21 @@ func (d *dataNode) SnapshotRestore(snapshotIndex uint64) bool {
 	}) == nil
 }
 
+// RotateKeys starts the re-encryption of the block files with the current key of the data node in the background
+func (d *dataNode) RotateKeys() bool {
+	return d.connect(func(conn net.Conn) error {
+		if _, err := conn.Write([]byte(commandRotateKeys)); err != nil {
+			return err
+		}
+
+		if !d.result(conn) {
+			return fmt.Errorf("rotate keys command is failed on data node")
+		}
+
+		return nil
+	}) == nil
+}
+
 func (d *dataNode) Ping() (latency int64) {
 	starts := time.Now().UTC()
 
@@ -715,7 +779,38 @@ func (d *dataNode) Size() (size uint64, err error) {
 	return
 }
 
-func (d *dataNode) Used() (used uint64, usedErr error) {
+// Used returns the physical and the logical used sizes of the data node. The data nodes that do not know the logical
+// size refuse USDL, their logical size is the same as the physical one
+func (d *dataNode) Used() (used uint64, logical uint64, usedErr error) {
+	refused := false
+	usedErr = d.connect(func(conn net.Conn) error {
+		if _, err := conn.Write([]byte(commandUsedLogical)); err != nil {
+			return err
+		}
+
+		if !d.result(conn) {
+			refused = true
+			return fmt.Errorf("data node refused the used request")
+		}
+
+		if err := binary.Read(conn, binary.LittleEndian, &used); err != nil {
+			return err
+		}
+
+		if err := binary.Read(conn, binary.LittleEndian, &logical); err != nil {
+			return err
+		}
+
+		if !d.result(conn) {
+			return fmt.Errorf("used command is failed on data node")
+		}
+
+		return nil
+	})
+	if !refused {
+		return
+	}
+
 	usedErr = d.connect(func(conn net.Conn) error {
 		if _, err := conn.Write([]byte(commandUsed)); err != nil {
 			return err
@@ -735,6 +830,7 @@ func (d *dataNode) Used() (used uint64, usedErr error) {
 
 		return nil
 	})
+	logical = used
 	return
 }
 
@@ -392,6 +392,7 @@ func (c *clusters) ResetStats(cluster *common.Cluster) error {
 		"$set": bson.M{
 			"reservations": cluster.Reservations,
 			"used":         cluster.Used,
+			"logical":      cluster.Logical,
 			"snapshots":    cluster.Snapshots,
 		},
 	}
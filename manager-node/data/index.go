@@ -25,6 +25,7 @@ type Index interface {
 	QueueUpsert(item *common.CacheFileItem, syncTime *time.Time)
 	QueueDrop(clusterId string, sha512Hex string)
 	QueueUpsertChunkNode(sha512Hex string, nodeId string)
+	QueueUpsertShard(item *common.CacheFileItem)
 	QueueUpsertUsageInMap(clusterId string, items common.SyncFileItemList)
 
 	Get(sha512Hex string) (*common.CacheFileItem, error)
@@ -246,6 +247,36 @@ func (i *index) QueueUpsertChunkNode(sha512Hex string, nodeId string) {
 		time.Now().UTC().Format(time.RFC3339))
 }
 
+// QueueUpsertShard adds the node of the erasure coded chunk to the index. Nodes notify their shards
+// one by one, so the update time of the chunk is only set by the first one to keep the others valid
+func (i *index) QueueUpsertShard(item *common.CacheFileItem) {
+	if item == nil {
+		return
+	}
+
+	chunkKey := i.key(item.FileItem.Sha512Hex, ksChunk)
+	chunkNodesKey := i.key(item.FileItem.Sha512Hex, ksChunkNodes)
+	clusterKey := i.key(item.ClusterId, ksCluster)
+
+	currentTime := time.Now().UTC().Format(time.RFC3339)
+	expiresAt := strconv.FormatInt(item.ExpiresAt.Unix(), 10)
+
+	i.commandChan <- radix.Cmd(nil, "HSETNX", chunkKey, updatedAtKey, currentTime)
+	for k, v := range item.Export() {
+		i.commandChan <- radix.Cmd(nil, "HSET", chunkKey, k, v)
+	}
+	i.commandChan <- radix.Cmd(nil, "EXPIREAT", chunkKey, expiresAt)
+
+	i.commandChan <- radix.Cmd(nil, "HSETNX", chunkNodesKey, updatedAtKey, currentTime)
+	for nodeId := range item.ExistsIn {
+		i.commandChan <- radix.Cmd(nil, "HSET", chunkNodesKey, nodeId, currentTime)
+	}
+	i.commandChan <- radix.Cmd(nil, "EXPIREAT", chunkNodesKey, expiresAt)
+
+	i.commandChan <- radix.Cmd(nil, "HSET", clusterKey, item.FileItem.Sha512Hex,
+		fmt.Sprintf("%d|%s", item.FileItem.Usage, currentTime))
+}
+
 func (i *index) QueueUpsertUsageInMap(clusterId string, fileItemList common.SyncFileItemList) {
 	if len(fileItemList) == 0 {
 		return
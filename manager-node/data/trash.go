@@ -0,0 +1,86 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Trash interface is to reach the deleted folders and files that are kept by the head node(s)
+// till the retention period expires. Their chunks should be accepted as in use
+type Trash interface {
+	Cursor(entryHandler func(entry *common.TrashEntry) error) error
+}
+
+const trashCollection = "trash"
+
+type trash struct {
+	col *mongo.Collection
+}
+
+func NewTrash(conn *Connection, database string) Trash {
+	trashCol := conn.client.Database(database).Collection(trashCollection)
+
+	return &trash{
+		col: trashCol,
+	}
+}
+
+func (t *trash) context() (context.Context, context.CancelFunc) {
+	timeoutDuration := time.Second * 30
+	return context.WithTimeout(context.Background(), timeoutDuration)
+}
+
+func (t *trash) Cursor(entryHandler func(entry *common.TrashEntry) error) error {
+	ctx, cancelFunc := t.context()
+	defer cancelFunc()
+
+	opts := options.Find()
+	opts.SetNoCursorTimeout(true)
+
+	cursor, err := t.col.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancelFunc := t.context()
+		defer cancelFunc()
+
+		_ = cursor.Close(ctx)
+	}()
+
+	for {
+		entry, err := t.next(cursor)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			return nil
+		}
+
+		if err := entryHandler(entry); err != nil {
+			return err
+		}
+	}
+}
+
+func (t *trash) next(cursor *mongo.Cursor) (*common.TrashEntry, error) {
+	ctx, cancelFunc := t.context()
+	defer cancelFunc()
+
+	if !cursor.Next(ctx) {
+		return nil, cursor.Err()
+	}
+
+	var entry *common.TrashEntry
+	if err := cursor.Decode(&entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+var _ Trash = &trash{}
@@ -7,7 +7,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/freakmaxi/kertish-dfs/basics/auth"
 	"github.com/freakmaxi/kertish-dfs/basics/logging"
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 	"github.com/freakmaxi/kertish-dfs/manager-node/data"
 	"github.com/freakmaxi/kertish-dfs/manager-node/manager"
 	"github.com/freakmaxi/kertish-dfs/manager-node/routing"
@@ -105,6 +107,33 @@ func main() {
 	}
 	logger.Info(fmt.Sprintf("LOCKING_CENTER: %s", mutexConn))
 
+	var authenticator auth.Authenticator
+	authKeysPath := os.Getenv("AUTH_KEYS_PATH")
+	if len(authKeysPath) > 0 {
+		authenticator, err = auth.NewAuthenticator(authKeysPath)
+		if err != nil {
+			logger.Error("Authentication keys are not loaded", zap.Error(err))
+			os.Exit(16)
+		}
+		logger.Info(fmt.Sprintf("AUTH_KEYS_PATH: %s", authKeysPath))
+	} else {
+		logger.Info("AUTH_KEYS_PATH: disabled")
+	}
+
+	tlsCertPath := os.Getenv("TLS_CERT_PATH")
+	if len(tlsCertPath) > 0 {
+		tlsVerifyClient := len(os.Getenv("TLS_VERIFY_CLIENT")) > 0
+		secure.CurrentConfig, err = secure.NewConfig(tlsCertPath, os.Getenv("TLS_KEY_PATH"), os.Getenv("TLS_CA_PATH"), tlsVerifyClient)
+		if err != nil {
+			logger.Error("TLS setup is failed", zap.Error(err))
+			os.Exit(26)
+		}
+		logger.Info(fmt.Sprintf("TLS_CERT_PATH: %s", tlsCertPath))
+		logger.Info(fmt.Sprintf("TLS_VERIFY_CLIENT: %t", tlsVerifyClient))
+	} else {
+		logger.Info("TLS_CERT_PATH: disabled")
+	}
+
 	m, err := mutex.NewLockingCenterWithSourceAddr(mutexConn, &mutexSourceAddr)
 	if err != nil {
 		logger.Error("Mutex Setup is failed", zap.Error(err))
@@ -143,8 +172,10 @@ func main() {
 		os.Exit(24)
 	}
 
+	trash := data.NewTrash(conn, mongoDb)
+
 	synchronize := manager.NewSynchronize(dataClusters, index, logger)
-	repair := manager.NewRepair(dataClusters, metadata, index, operation, synchronize, logger)
+	repair := manager.NewRepair(dataClusters, metadata, trash, index, operation, synchronize, logger)
 
 	health := manager.NewHealthTracker(dataClusters, index, synchronize, repair, logger, time.Second*time.Duration(healthCheckInterval))
 	health.Start()
@@ -169,6 +200,10 @@ func main() {
 	nodeRouter := routing.NewNodeRouter(managerNode, logger)
 	routerManager.Add(nodeRouter)
 
+	if authenticator != nil {
+		routerManager.Use(routing.NewAuthMiddleware(authenticator, auth.ScopeAdmin, logger))
+	}
+
 	proxy := services.NewProxy(bindAddr, routerManager, logger)
 	proxy.Start()
 
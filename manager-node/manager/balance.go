@@ -59,6 +59,14 @@ func (b *balance) Balance(clusterIds []string) error {
 	clusterMap := make(map[string]*common.Cluster)
 	balancingClusters := make(common.Clusters, 0)
 	for _, cluster := range clusters {
+		// chunks are moved between the masters, so erasure coded clusters can not be balanced
+		if cluster.ErasureCoded() {
+			if _, has := clusterIdsMap[cluster.Id]; has {
+				return errors.ErrErasureCoded
+			}
+			continue
+		}
+
 		if len(clusterIdsMap) == 0 {
 			clusterMap[cluster.Id] = cluster
 			balancingClusters = append(balancingClusters, cluster)
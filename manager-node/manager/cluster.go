@@ -13,7 +13,7 @@ import (
 
 // Cluster interface contains functions to handle the cluster administration in the dfs farm
 type Cluster interface {
-	Register(nodeAddresses []string) (*common.Cluster, error)
+	Register(nodeAddresses []string, erasure *common.ErasureCoding) (*common.Cluster, error)
 	RegisterNodesTo(clusterId string, nodeAddresses []string) error
 
 	UnRegisterCluster(clusterId string) error
@@ -24,7 +24,8 @@ type Cluster interface {
 	GetClusters() (common.Clusters, error)
 	GetCluster(clusterId string) (*common.Cluster, error)
 
-	Reserve(size uint64) (*common.ReservationMap, error)
+	Reserve(size uint64, blockSize uint32) (*common.ReservationMap, error)
+	ReserveChunks(chunkSizes []uint32) (*common.ReservationMap, error)
 	Commit(reservationId string, clusterMap map[string]uint64) error
 	Discard(reservationId string) error
 
@@ -37,8 +38,10 @@ type Cluster interface {
 	DeleteSnapshot(clusterId string, snapshotIndex uint64) error
 	RestoreSnapshot(clusterId string, snapshotIndex uint64) error
 
-	Map(sha512HexList []string, mapType common.MapType) (map[string][]string, error)
-	Find(sha512Hex string, mapType common.MapType) (string, []string, error)
+	RotateKeys(clusterIds []string) error
+
+	Map(sha512HexList []string, mapType common.MapType) (map[string]*common.Placement, error)
+	Find(sha512Hex string, mapType common.MapType) (*common.Placement, error)
 }
 
 type cluster struct {
@@ -58,14 +61,29 @@ func NewCluster(clusters data.Clusters, index data.Index, synchronize Synchroniz
 	}, nil
 }
 
-func (c *cluster) Register(nodeAddresses []string) (*common.Cluster, error) {
+// Register creates the cluster with the nodes. Cluster keeps the full copies of the chunks on every node
+// when erasure is nil, otherwise every node keeps a shard of the chunks and the node count should match
+// the shard count
+func (c *cluster) Register(nodeAddresses []string, erasure *common.ErasureCoding) (*common.Cluster, error) {
+	if erasure != nil && len(nodeAddresses) != erasure.Shards() {
+		return nil, fmt.Errorf("erasure coded cluster requires %d nodes", erasure.Shards())
+	}
+
 	cluster := common.NewCluster(newClusterId())
+	cluster.Erasure = erasure
 
 	nodes, clusterSize, err := c.prepareNodes(nodeAddresses, 0)
 	if err != nil {
 		return nil, err
 	}
 	cluster.Size = clusterSize
+	if cluster.ErasureCoded() {
+		cluster.Size = clusterSize * uint64(erasure.Data)
+
+		if err := c.assignShards(cluster, nodes); err != nil {
+			return nil, err
+		}
+	}
 	cluster.Nodes = append(cluster.Nodes, nodes...)
 
 	masterAddress := ""
@@ -101,10 +119,20 @@ func (c *cluster) RegisterNodesTo(clusterId string, nodeAddresses []string) erro
 
 		masterNode := cluster.Master()
 
-		nodes, _, err := c.prepareNodes(nodeAddresses, cluster.Size)
+		nodeSize := cluster.Size
+		if cluster.ErasureCoded() {
+			nodeSize /= uint64(cluster.Erasure.Data)
+		}
+
+		nodes, _, err := c.prepareNodes(nodeAddresses, nodeSize)
 		if err != nil {
 			return err
 		}
+		if cluster.ErasureCoded() {
+			if err := c.assignShards(cluster, nodes); err != nil {
+				return err
+			}
+		}
 		cluster.Nodes = append(cluster.Nodes, nodes...)
 
 		for _, node := range nodes {
@@ -184,6 +212,27 @@ func (c *cluster) prepareNodes(nodeAddresses []string, clusterSize uint64) (comm
 	return r, clusterSize, nil
 }
 
+// assignShards places the nodes to the shards of the erasure coded cluster that do not have a node.
+// The shards of the new nodes are rebuilt on the cluster synchronisation
+func (c *cluster) assignShards(cluster *common.Cluster, nodes common.NodeList) error {
+	freeShards := make([]uint8, 0)
+	for shard, node := range cluster.ShardNodes() {
+		if node == nil {
+			freeShards = append(freeShards, uint8(shard))
+		}
+	}
+
+	if len(nodes) > len(freeShards) {
+		return fmt.Errorf("erasure coded cluster has %d free shard(s) for the nodes", len(freeShards))
+	}
+
+	for i, node := range nodes {
+		node.Shard = freeShards[i]
+	}
+
+	return nil
+}
+
 func (c *cluster) UnRegisterCluster(clusterId string) error {
 	if err := c.clusters.Save(clusterId, func(cluster *common.Cluster) error {
 		if cluster.Maintain {
@@ -293,12 +342,39 @@ func (c *cluster) GetCluster(clusterId string) (*common.Cluster, error) {
 	return c.clusters.Get(clusterId)
 }
 
-func (c *cluster) Reserve(size uint64) (*common.ReservationMap, error) {
+// Reserve reserves the space for the chunks of the size that are cut in the block size
+func (c *cluster) Reserve(size uint64, blockSize uint32) (*common.ReservationMap, error) {
+	if err := common.ValidateBlockSize(blockSize); err != nil {
+		return nil, err
+	}
+
+	var reservationMap *common.ReservationMap
+
+	if err := c.clusters.SaveAll(func(clusters common.Clusters) error {
+		var err error
+		reservationMap, err = c.createReservationMap(c.calculateChunks(size, blockSize), clusters)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return reservationMap, nil
+}
+
+// ReserveChunks reserves the space for the chunks that are already cut by the head node. It is used for the
+// variable sized chunks of the content defined chunking
+func (c *cluster) ReserveChunks(chunkSizes []uint32) (*common.ReservationMap, error) {
+	chunks, err := c.layoutChunks(chunkSizes)
+	if err != nil {
+		return nil, err
+	}
+
 	var reservationMap *common.ReservationMap
 
 	if err := c.clusters.SaveAll(func(clusters common.Clusters) error {
 		var err error
-		reservationMap, err = c.createReservationMap(size, clusters)
+		reservationMap, err = c.createReservationMap(chunks, clusters)
 
 		return err
 	}); err != nil {
@@ -362,12 +438,60 @@ func (c *cluster) ChangeStateAll(state common.States) error {
 	})
 }
 
+// RotateKeys requests all nodes of the clusters to re-encrypt their block files with their current keys. Every node
+// keeps its own keys, so the request is sent to all of them. Empty cluster ids apply to all clusters in the setup
+func (c *cluster) RotateKeys(clusterIds []string) error {
+	clusters := make(common.Clusters, 0)
+	if len(clusterIds) == 0 {
+		var err error
+		clusters, err = c.clusters.GetAll()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, clusterId := range clusterIds {
+		cluster, err := c.clusters.Get(clusterId)
+		if err != nil {
+			return err
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	rotationFailed := false
+	for _, cluster := range clusters {
+		for _, node := range cluster.Nodes {
+			dn, err := cluster2.NewDataNode(node.Address)
+			if err == nil && dn.RotateKeys() {
+				continue
+			}
+
+			rotationFailed = true
+			c.logger.Error(
+				"Key rotation request is failed on data node",
+				zap.String("clusterId", cluster.Id),
+				zap.String("nodeId", node.Id),
+				zap.String("nodeAddress", node.Address),
+			)
+		}
+	}
+
+	if rotationFailed {
+		return errors.ErrRotation
+	}
+	return nil
+}
+
 func (c *cluster) CreateSnapshot(clusterId string) error {
 	cluster, err := c.clusters.Get(clusterId)
 	if err != nil {
 		return err
 	}
 
+	// snapshots are taken on the master node that only keeps a shard of the erasure coded chunks
+	if cluster.ErasureCoded() {
+		return errors.ErrErasureCoded
+	}
 	if cluster.Maintain {
 		return errors.ErrMaintain
 	}
@@ -394,6 +518,10 @@ func (c *cluster) DeleteSnapshot(clusterId string, snapshotIndex uint64) error {
 		return err
 	}
 
+	// snapshots are taken on the master node that only keeps a shard of the erasure coded chunks
+	if cluster.ErasureCoded() {
+		return errors.ErrErasureCoded
+	}
 	if cluster.Maintain {
 		return errors.ErrMaintain
 	}
@@ -420,6 +548,10 @@ func (c *cluster) RestoreSnapshot(clusterId string, snapshotIndex uint64) error
 		return err
 	}
 
+	// snapshots are taken on the master node that only keeps a shard of the erasure coded chunks
+	if cluster.ErasureCoded() {
+		return errors.ErrErasureCoded
+	}
 	if cluster.Maintain {
 		return errors.ErrMaintain
 	}
@@ -440,40 +572,44 @@ func (c *cluster) RestoreSnapshot(clusterId string, snapshotIndex uint64) error
 	return c.synchronize.Cluster(cluster.Id, true, false, false)
 }
 
-func (c *cluster) Map(sha512HexList []string, mapType common.MapType) (map[string][]string, error) {
-	clusterMapping := make(map[string][]string)
+func (c *cluster) Map(sha512HexList []string, mapType common.MapType) (map[string]*common.Placement, error) {
+	clusterMapping := make(map[string]*common.Placement)
 	for _, sha512Hex := range sha512HexList {
-		_, addresses, err := c.Find(sha512Hex, mapType)
+		placement, err := c.Find(sha512Hex, mapType)
 		if err != nil {
 			if err == os.ErrNotExist && mapType == common.MTDelete {
 				continue
 			}
 			return nil, err
 		}
-		clusterMapping[sha512Hex] = addresses
+		clusterMapping[sha512Hex] = placement
 	}
 	return clusterMapping, nil
 }
 
-func (c *cluster) Find(sha512Hex string, mapType common.MapType) (string, []string, error) {
+func (c *cluster) Find(sha512Hex string, mapType common.MapType) (*common.Placement, error) {
 	cacheFileItem, err := c.index.Get(sha512Hex)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
 	cluster, err := c.clusters.Get(cacheFileItem.ClusterId)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
 	// if it is a read request, try other nodes even the cluster is paralyzed.
 	// maybe there is no master candidate but other nodes can contain the
 	// requested file chunk to provide
 	if cluster.State == common.StateOffline {
-		return "", nil, errors.ErrNoAvailableActionNode
+		return nil, errors.ErrNoAvailableActionNode
 	}
 	if !cluster.CanSchedule() && mapType != common.MTRead {
-		return "", nil, errors.ErrNoAvailableActionNode
+		return nil, errors.ErrNoAvailableActionNode
+	}
+
+	if cluster.ErasureCoded() {
+		return c.findShards(cluster, cacheFileItem, mapType)
 	}
 
 	// addresses should always contain node address, if it will be empty or nil
@@ -484,7 +620,7 @@ func (c *cluster) Find(sha512Hex string, mapType common.MapType) (string, []stri
 	case common.MTRead:
 		nodes := cluster.PrioritizedHighQualityNodes(cacheFileItem.ExistsIn)
 		if nodes == nil {
-			return "", nil, errors.ErrNoAvailableActionNode
+			return nil, errors.ErrNoAvailableActionNode
 		}
 		for _, n := range nodes {
 			addresses = append(addresses, n.Address)
@@ -492,12 +628,40 @@ func (c *cluster) Find(sha512Hex string, mapType common.MapType) (string, []stri
 	default:
 		node := cluster.Master()
 		if node == nil {
-			return "", nil, errors.ErrNoAvailableActionNode
+			return nil, errors.ErrNoAvailableActionNode
 		}
 		addresses = []string{node.Address}
 	}
 
-	return cluster.Id, addresses, nil
+	return common.NewPlacement(cluster, addresses), nil
+}
+
+// findShards places the addresses in the shard order. Shards those are not on the node are left empty for
+// the read request and there should be enough shards to reconstruct the chunk
+func (c *cluster) findShards(cluster *common.Cluster, cacheFileItem *common.CacheFileItem, mapType common.MapType) (*common.Placement, error) {
+	shardNodes := cluster.ShardNodes()
+
+	addresses := make([]string, len(shardNodes))
+	available := 0
+
+	for shard, n := range shardNodes {
+		if n == nil {
+			continue
+		}
+		if mapType == common.MTRead {
+			if exists, has := cacheFileItem.ExistsIn[n.Id]; !has || !exists {
+				continue
+			}
+		}
+		addresses[shard] = n.Address
+		available++
+	}
+
+	if available < int(cluster.Erasure.Data) {
+		return nil, errors.ErrNoAvailableActionNode
+	}
+
+	return common.NewPlacement(cluster, addresses), nil
 }
 
 var _ Cluster = &cluster{}
@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"os"
 	"sort"
 
 	"github.com/freakmaxi/kertish-dfs/basics/common"
@@ -8,10 +9,7 @@ import (
 	"github.com/google/uuid"
 )
 
-const blockSize uint32 = 1024 * 1024 * 32 // 32Mb
-
-func (c *cluster) createReservationMap(size uint64, clusters common.Clusters) (*common.ReservationMap, error) {
-	chunks := c.calculateChunks(size)
+func (c *cluster) createReservationMap(chunks []common.Chunk, clusters common.Clusters) (*common.ReservationMap, error) {
 	reservationId := uuid.New().String()
 
 	r := make([]common.ClusterMap, 0)
@@ -33,11 +31,22 @@ func (c *cluster) createReservationMap(size uint64, clusters common.Clusters) (*
 			return nil, errors.ErrNoDiskSpace
 		}
 
-		r = append(r, common.ClusterMap{
+		clusterMap := common.ClusterMap{
 			Id:      cluster.Id,
 			Address: cluster.Master().Address,
 			Chunk:   chunk,
-		})
+		}
+		if cluster.ErasureCoded() {
+			clusterMap.Erasure = cluster.Erasure
+			clusterMap.Addresses = make([]string, 0)
+			for _, n := range cluster.ShardNodes() {
+				if n == nil {
+					return nil, errors.ErrNoAvailableClusterNode
+				}
+				clusterMap.Addresses = append(clusterMap.Addresses, n.Address)
+			}
+		}
+		r = append(r, clusterMap)
 
 		cluster.Reserve(reservationId, uint64(chunk.Size))
 		chunks = chunks[1:]
@@ -49,7 +58,12 @@ func (c *cluster) createReservationMap(size uint64, clusters common.Clusters) (*
 	}, nil
 }
 
-func (c *cluster) calculateChunks(size uint64) []common.Chunk {
+// calculateChunks cuts the size into the chunks in the block size. Default block size is used when it is zero
+func (c *cluster) calculateChunks(size uint64, blockSize uint32) []common.Chunk {
+	if blockSize == 0 {
+		blockSize = common.DefaultBlockSize
+	}
+
 	if size < uint64(blockSize) {
 		return []common.Chunk{{Index: 0, Size: uint32(size)}}
 	}
@@ -67,3 +81,22 @@ func (c *cluster) calculateChunks(size uint64) []common.Chunk {
 
 	return chunks
 }
+
+// layoutChunks places the chunks with the given sizes one after another. Chunk size can not exceed the max block size
+func (c *cluster) layoutChunks(chunkSizes []uint32) ([]common.Chunk, error) {
+	if len(chunkSizes) == 0 {
+		return nil, os.ErrInvalid
+	}
+
+	chunks := make([]common.Chunk, 0)
+	idx := uint64(0)
+	for seq, chunkSize := range chunkSizes {
+		if chunkSize == 0 || chunkSize > common.MaxBlockSize {
+			return nil, os.ErrInvalid
+		}
+		chunks = append(chunks, common.Chunk{Sequence: uint16(seq), Index: idx, Size: chunkSize})
+		idx += uint64(chunkSize)
+	}
+
+	return chunks, nil
+}
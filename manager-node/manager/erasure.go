@@ -0,0 +1,184 @@
+package manager
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	"github.com/freakmaxi/kertish-dfs/basics/erasure"
+	"github.com/freakmaxi/kertish-dfs/basics/errors"
+	cluster2 "github.com/freakmaxi/kertish-dfs/manager-node/cluster"
+)
+
+// readShards reads the shards of the chunk from the nodes those have them in the shard order. Reading stops
+// when the count of the shards is collected, the rest is left nil to be reconstructed
+func readShards(cluster *common.Cluster, existsIn common.CacheFileItemLocationMap, sha512Hex string, shardSize uint32, count int) ([][]byte, error) {
+	shardNodes := cluster.ShardNodes()
+	shards := make([][]byte, len(shardNodes))
+
+	if shardSize == 0 {
+		for shard := range shards {
+			shards[shard] = []byte{}
+		}
+		return shards, nil
+	}
+
+	bulkErrors := errors.NewBulkError()
+
+	collected := 0
+	for shard, node := range shardNodes {
+		if collected == count {
+			break
+		}
+
+		if node == nil {
+			continue
+		}
+		if exists, has := existsIn[node.Id]; !has || !exists {
+			continue
+		}
+
+		dn, err := cluster2.NewDataNode(node.Address)
+		if err != nil {
+			bulkErrors.Add(err)
+			continue
+		}
+
+		if err := dn.Read(sha512Hex, 0, 0, func(data []byte) error {
+			if uint32(len(data)) != shardSize {
+				return errors.ErrRepair
+			}
+			shards[shard] = data
+			return nil
+		}); err != nil {
+			shards[shard] = nil
+			bulkErrors.Add(fmt.Errorf("shard %d on %s: %s", shard, node.Address, err))
+			continue
+		}
+
+		collected++
+	}
+
+	if collected < int(cluster.Erasure.Data) {
+		if bulkErrors.HasError() {
+			return nil, bulkErrors
+		}
+		return nil, errors.ErrShards
+	}
+
+	return shards, nil
+}
+
+// readErasureCodedChunk reads the shards of the chunk and reconstructs the chunk in the size
+func readErasureCodedChunk(cluster *common.Cluster, existsIn common.CacheFileItemLocationMap, sha512Hex string, size uint32) ([]byte, error) {
+	shards, err := readShards(cluster, existsIn, sha512Hex, cluster.Erasure.ShardSize(size), int(cluster.Erasure.Data))
+	if err != nil {
+		return nil, err
+	}
+	return erasure.Reconstruct(cluster.Erasure, shards, size)
+}
+
+// rebuildChunkShards reconstructs the shards of the chunk for the nodes those lost them and creates the shards
+// on these nodes with the usage of the chunk. Shards are verified with the hash of the chunk before they are created
+func rebuildChunkShards(cluster *common.Cluster, item *common.CacheFileItem, lackingNodes common.NodeList) error {
+	shards, err := readShards(cluster, item.ExistsIn, item.FileItem.Sha512Hex, item.FileItem.Size, int(cluster.Erasure.Data))
+	if err != nil {
+		return err
+	}
+
+	if item.FileItem.Size > 0 {
+		shards, err = rebuildVerifiedShards(cluster, item, shards)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, node := range lackingNodes {
+		dn, err := cluster2.NewDataNode(node.Address)
+		if err != nil {
+			return err
+		}
+
+		if err := dn.CreateShard(item.FileItem.Sha512Hex, shards[node.Shard]); err != nil {
+			return err
+		}
+
+		if err := dn.SyncUsage(map[string]uint16{item.FileItem.Sha512Hex: item.FileItem.Usage}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rebuildVerifiedShards rebuilds the missing shards and verifies them with the hash of the chunk. If the content of
+// a shard is corrupted, the rest of the shards are read and the shards are rebuilt by excluding them one by one
+func rebuildVerifiedShards(cluster *common.Cluster, item *common.CacheFileItem, shards [][]byte) ([][]byte, error) {
+	rebuilt, err := rebuildShards(cluster.Erasure, shards, item.FileItem.Sha512Hex)
+	if err != errors.ErrRepair {
+		return rebuilt, err
+	}
+
+	shards, err = readShards(cluster, item.ExistsIn, item.FileItem.Sha512Hex, item.FileItem.Size, cluster.Erasure.Shards())
+	if err != nil {
+		return nil, err
+	}
+
+	for excluded := range shards {
+		if shards[excluded] == nil {
+			continue
+		}
+
+		candidate := make([][]byte, len(shards))
+		copy(candidate, shards)
+		candidate[excluded] = nil
+
+		if rebuilt, err := rebuildShards(cluster.Erasure, candidate, item.FileItem.Sha512Hex); err == nil {
+			return rebuilt, nil
+		}
+	}
+
+	return nil, errors.ErrRepair
+}
+
+// rebuildShards rebuilds the missing shards without changing the provided shards and verifies the chunk that
+// the data shards form with the hash of the chunk. It returns ErrRepair when the chunk is not matching
+func rebuildShards(coding *common.ErasureCoding, shards [][]byte, sha512Hex string) ([][]byte, error) {
+	rebuilding := make([][]byte, len(shards))
+	copy(rebuilding, shards)
+
+	if err := erasure.Rebuild(coding, rebuilding); err != nil {
+		return nil, err
+	}
+
+	if !verifyShards(coding, rebuilding, sha512Hex) {
+		return nil, errors.ErrRepair
+	}
+	return rebuilding, nil
+}
+
+// verifyShards checks the data shards form the chunk of the hash. The size of the chunk is not known in the
+// synchronization, so the chunk is verified for all the sizes that have the same shard size
+func verifyShards(coding *common.ErasureCoding, shards [][]byte, sha512Hex string) bool {
+	data := make([]byte, 0)
+	for _, shard := range shards[:coding.Data] {
+		data = append(data, shard...)
+	}
+
+	shardSize := len(shards[0])
+	minSize := (shardSize-1)*int(coding.Data) + 1
+
+	hash := sha512.New512_256()
+	_, _ = hash.Write(data[:minSize-1])
+
+	for size := minSize; size <= len(data); size++ {
+		_, _ = hash.Write(data[size-1 : size])
+
+		if strings.Compare(hex.EncodeToString(hash.Sum(nil)), sha512Hex) == 0 {
+			return true
+		}
+	}
+	return false
+}
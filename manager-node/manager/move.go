@@ -44,6 +44,9 @@ func (m *move) Move(sourceClusterId string, targetClusterId string) error {
 		return err
 	}
 
+	if sourceCluster.ErasureCoded() {
+		return errors.ErrErasureCoded
+	}
 	if !sourceCluster.CanSchedule() {
 		return errors.ErrNotAvailableForClusterAction
 	}
@@ -56,6 +59,9 @@ func (m *move) Move(sourceClusterId string, targetClusterId string) error {
 		return err
 	}
 
+	if targetCluster.ErasureCoded() {
+		return errors.ErrErasureCoded
+	}
 	if !targetCluster.CanSchedule() {
 		return errors.ErrNotAvailableForClusterAction
 	}
@@ -68,9 +68,10 @@ func (n *node) Handshake(nodeHardwareAddr string, nodeAddress string, size uint6
 		return "", "", "", err
 	}
 
+	// nodes of the erasure coded cluster keep different shards of the chunks, so they are not synced from the master
 	syncSourceAddrBind := ""
 	node := cluster.Node(nodeId)
-	if !node.Master {
+	if !node.Master && !cluster.ErasureCoded() {
 		syncSourceAddrBind = cluster.Master().Address
 	}
 
@@ -131,6 +132,14 @@ func (n *node) create(nodeId string, fileItemList common.SyncFileItemList) error
 		return fmt.Errorf("getting cluster by node id is failed. nodeId: %s, error: %s", nodeId, err)
 	}
 
+	if cluster.ErasureCoded() {
+		// every node notifies its own shard, so the chunk nodes are collected one by one
+		for _, fileItem := range fileItemList {
+			n.index.QueueUpsertShard(common.NewCacheFileItem(cluster.Id, nodeId, fileItem))
+		}
+		return nil
+	}
+
 	sourceNode := cluster.Node(nodeId)
 	targetNodes := cluster.Others(nodeId)
 	if targetNodes == nil {
@@ -175,6 +184,21 @@ func (n *node) delete(nodeId string, fileItemList common.SyncFileItemList) error
 			return fmt.Errorf("node id didn't match to get others: %s\n", nodeId)
 		}
 
+		if cluster.ErasureCoded() {
+			// head deletes the shards from all nodes, master is enough to keep the index and usage
+			if !sourceNode.Master {
+				return nil
+			}
+
+			n.index.QueueUpsertUsageInMap(cluster.Id, fileItemList.ShadowItems())
+			for _, fileItem := range fileItemList {
+				n.index.QueueDrop(cluster.Id, fileItem.Sha512Hex)
+			}
+			cluster.Used -= fileItemList.PhysicalSize() * uint64(cluster.Erasure.Data)
+
+			return nil
+		}
+
 		n.index.QueueUpsertUsageInMap(cluster.Id, fileItemList.ShadowItems())
 		for _, fileItem := range fileItemList {
 			n.index.QueueDrop(cluster.Id, fileItem.Sha512Hex)
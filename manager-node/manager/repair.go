@@ -39,16 +39,18 @@ type Repair interface {
 type repair struct {
 	clusters    data.Clusters
 	metadata    data.Metadata
+	trash       data.Trash
 	index       data.Index
 	operation   data.Operation
 	synchronize Synchronize
 	logger      *zap.Logger
 }
 
-func NewRepair(clusters data.Clusters, metadata data.Metadata, index data.Index, operation data.Operation, synchronize Synchronize, logger *zap.Logger) Repair {
+func NewRepair(clusters data.Clusters, metadata data.Metadata, trash data.Trash, index data.Index, operation data.Operation, synchronize Synchronize, logger *zap.Logger) Repair {
 	return &repair{
 		clusters:    clusters,
 		metadata:    metadata,
+		trash:       trash,
 		index:       index,
 		operation:   operation,
 		synchronize: synchronize,
@@ -305,11 +307,12 @@ func (r *repair) repairIntegrityPhase1(clusterIndexMap map[string]map[string]str
 	r.logger.Info("Start traversing metadata entries for usage alignment cache")
 
 	if err := r.metadata.Cursor(func(folder *common.Folder) (bool, error) {
-		if len(folder.Files) == 0 {
+		files := folder.FilesWithVersions()
+		if len(files) == 0 {
 			return false, nil
 		}
 
-		for _, file := range folder.Files {
+		for _, file := range files {
 			for _, chunk := range file.Chunks {
 				increaseUsageMapFunc(chunk.Hash)
 			}
@@ -325,6 +328,23 @@ func (r *repair) repairIntegrityPhase1(clusterIndexMap map[string]map[string]str
 		return err
 	}
 
+	r.logger.Info("Start traversing trash entries for usage alignment cache")
+
+	if err := r.trash.Cursor(func(entry *common.TrashEntry) error {
+		for _, file := range entry.Files() {
+			for _, chunk := range file.Chunks {
+				increaseUsageMapFunc(chunk.Hash)
+			}
+
+			for _, chunk := range file.Missing {
+				increaseUsageMapFunc(chunk.Hash)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
 	r.logger.Info("Examine usages of metadata entries with data nodes")
 
 	mismatchedUsageMap := make(map[string]map[string]uint16)
@@ -385,13 +405,21 @@ func (r *repair) repairIntegrityPhase1(clusterIndexMap map[string]map[string]str
 	r.logger.Info("Start usage resetting on clusters")
 
 	// Make Chunk Usage Update
-	errCh := make(chan error, len(mismatchedUsageMap))
+	errCh := make(chan error, len(mismatchedUsageMap)*common.MaxShards)
 	wg := &sync.WaitGroup{}
 	for clusterId, usageMap := range mismatchedUsageMap {
-		masterNode := clusterMap[clusterId].Master()
+		cluster := clusterMap[clusterId]
 
-		wg.Add(1)
-		go r.fixUsage(wg, clusterId, masterNode, usageMap, errCh)
+		// every shard of the erasure coded chunk keeps the usage separately
+		nodes := common.NodeList{cluster.Master()}
+		if cluster.ErasureCoded() {
+			nodes = cluster.Nodes
+		}
+
+		for _, node := range nodes {
+			wg.Add(1)
+			go r.fixUsage(wg, clusterId, node, usageMap, errCh)
+		}
 	}
 	wg.Wait()
 	close(errCh)
@@ -449,11 +477,12 @@ func (r *repair) repairIntegrityPhase2(checksumRebuild bool, clusterIndexMap map
 	r.logger.Info("Start traversing metadata entries for integrity check up")
 
 	if err := r.metadata.Cursor(func(folder *common.Folder) (bool, error) {
-		if len(folder.Files) == 0 {
+		files := folder.FilesWithVersions()
+		if len(files) == 0 {
 			return false, nil
 		}
 
-		for _, file := range folder.Files {
+		for _, file := range files {
 			file.Resurrect()
 
 			if len(file.Chunks) == 0 {
@@ -485,13 +514,13 @@ func (r *repair) repairIntegrityPhase2(checksumRebuild bool, clusterIndexMap map
 					continue
 				}
 
-				if cacheFileItem.FileItem.Size != chunk.Size {
+				cluster, has := clusterMap[cacheFileItem.ClusterId]
+				if !has {
 					deletionResult.Missing = append(deletionResult.Missing, chunk.Hash)
 					continue
 				}
 
-				_, has := clusterMap[cacheFileItem.ClusterId]
-				if !has {
+				if cacheFileItem.FileItem.Size != chunkSizeOnNode(cluster, chunk) {
 					deletionResult.Missing = append(deletionResult.Missing, chunk.Hash)
 					continue
 				}
@@ -503,7 +532,23 @@ func (r *repair) repairIntegrityPhase2(checksumRebuild bool, clusterIndexMap map
 					continue
 				}
 
-				masterNode := clusterMap[cacheFileItem.ClusterId].Master()
+				if cluster.ErasureCoded() {
+					data, err := readErasureCodedChunk(cluster, cacheFileItem.ExistsIn, chunk.Hash, chunk.Size)
+					if err != nil {
+						r.logger.Error(
+							fmt.Sprintf("Reading chunk %s from %s is failed, skipping checksum calculation for %s.", chunk.Hash, cacheFileItem.ClusterId, file.Name),
+							zap.String("clusterId", cacheFileItem.ClusterId),
+							zap.String("sha512Hex", chunk.Hash),
+							zap.Error(err),
+						)
+						sha512Failed = true
+						continue
+					}
+					_, _ = sha512Hash.Write(data)
+					continue
+				}
+
+				masterNode := cluster.Master()
 				mdn, err := cluster2.NewDataNode(masterNode.Address)
 				if err != nil {
 					r.logger.Error(
@@ -559,24 +604,46 @@ func (r *repair) repairIntegrityPhase2(checksumRebuild bool, clusterIndexMap map
 		return err
 	}
 
+	r.logger.Info("Start traversing trash entries to keep their chunks")
+
+	// Trash entries are not repaired, their chunks are only kept from the orphan cleanup
+	if err := r.trash.Cursor(func(entry *common.TrashEntry) error {
+		for _, file := range entry.Files() {
+			for _, chunk := range file.Chunks {
+				cacheFileItem, err := r.index.Get(chunk.Hash)
+				if err != nil {
+					if err != os.ErrNotExist {
+						return err
+					}
+					continue
+				}
+				deleteFromIndexMapFunc(cacheFileItem.ClusterId, cacheFileItem.FileItem.Sha512Hex)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
 	r.logger.Info("Start orphan chunk cleanup on clusters")
 
 	// Make Orphan File Chunk Cleanup
 	wg := &sync.WaitGroup{}
 	for clusterId, indexMap := range clusterIndexMap {
-		masterNode := clusterMap[clusterId].Master()
-
 		wg.Add(1)
-		go r.cleanupOrphan(wg, clusterId, masterNode, indexMap)
+		go r.cleanupOrphan(wg, clusterMap[clusterId], indexMap)
 	}
 	wg.Wait()
 
 	return nil
 }
 
-func (r *repair) cleanupOrphan(wg *sync.WaitGroup, clusterId string, masterNode *common.Node, indexMap map[string]string) {
+func (r *repair) cleanupOrphan(wg *sync.WaitGroup, cluster *common.Cluster, indexMap map[string]string) {
 	defer wg.Done()
 
+	clusterId := cluster.Id
+	masterNode := cluster.Master()
+
 	if len(indexMap) == 0 {
 		r.logger.Info(fmt.Sprintf("%s does not have orphan chunks", clusterId))
 		return
@@ -604,6 +671,11 @@ func (r *repair) cleanupOrphan(wg *sync.WaitGroup, clusterId string, masterNode
 		return
 	}
 
+	if cluster.ErasureCoded() {
+		r.cleanupOrphanShards(cluster, clusterSha512HexList)
+		return
+	}
+
 	r.logger.Info(fmt.Sprintf("Creating snapshot for %s...", clusterId))
 
 	if !mdn.SnapshotCreate() {
@@ -644,6 +716,58 @@ func (r *repair) cleanupOrphan(wg *sync.WaitGroup, clusterId string, masterNode
 	r.logger.Info(fmt.Sprintf("Orphan chunks cleanup for %s is completed", clusterId))
 }
 
+// cleanupOrphanShards deletes the orphan chunks from all nodes of the erasure coded cluster. Snapshot is not
+// possible for the erasure coded cluster, so the chunks are deleted without taking it
+func (r *repair) cleanupOrphanShards(cluster *common.Cluster, sha512HexList []string) {
+	r.logger.Info(fmt.Sprintf("Cleaning up orphan chunks in %s...", cluster.Id))
+
+	for _, node := range cluster.Nodes {
+		dn, err := cluster2.NewDataNode(node.Address)
+		if err != nil {
+			r.logger.Error(
+				"Unable to make connection to data node for orphan cleanup",
+				zap.String("clusterId", cluster.Id),
+				zap.String("nodeId", node.Id),
+				zap.String("nodeAddress", node.Address),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		for _, sha512Hex := range sha512HexList {
+			if err := dn.Delete(sha512Hex); err != nil {
+				r.logger.Error(
+					fmt.Sprintf("Deleting orphan chunk %s from %s is failed", sha512Hex, node.Address),
+					zap.String("clusterId", cluster.Id),
+					zap.String("nodeId", node.Id),
+					zap.String("sha512Hex", sha512Hex),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
+	if err := r.synchronize.Cluster(cluster.Id, true, true, true); err != nil {
+		r.logger.Warn("Cluster sync is failed for the completion of orphan cleanup",
+			zap.String("clusterId", cluster.Id),
+			zap.Error(err),
+		)
+	}
+
+	// Recover cluster state for repair
+	_ = r.clusters.UpdateStateWithMaintain(cluster.Id, common.StateReadonly, true, common.TopicRepair)
+
+	r.logger.Info(fmt.Sprintf("Orphan chunks cleanup for %s is completed", cluster.Id))
+}
+
+// chunkSizeOnNode returns the size of the chunk that is kept on the node, erasure coded chunks are kept as shards
+func chunkSizeOnNode(cluster *common.Cluster, chunk *common.DataChunk) uint32 {
+	if cluster.ErasureCoded() {
+		return cluster.Erasure.ShardSize(chunk.Size)
+	}
+	return chunk.Size
+}
+
 func (r *repair) repairChecksum(clusters common.Clusters, rebuildChecksum bool) error {
 	r.logger.Info("Checksum repairing requires cluster synchronisation.")
 
@@ -711,9 +835,11 @@ func (r *repair) repairChecksumCalculation(rebuildChecksum bool, clusterMap map[
 					break
 				}
 
-				if cacheFileItem.FileItem.Size != chunk.Size {
+				cluster, has := clusterMap[cacheFileItem.ClusterId]
+				if !has {
 					r.logger.Error(
-						fmt.Sprintf("File size mismatched, skipping checksum calculation for %s.", file.Name),
+						fmt.Sprintf("Chunk cluster is not exists, skipping checksum calculation for %s.", file.Name),
+						zap.String("clusterId", cacheFileItem.ClusterId),
 						zap.String("filePath", folder.Full),
 						zap.String("fileName", file.Name),
 					)
@@ -721,11 +847,9 @@ func (r *repair) repairChecksumCalculation(rebuildChecksum bool, clusterMap map[
 					break
 				}
 
-				_, has := clusterMap[cacheFileItem.ClusterId]
-				if !has {
+				if cacheFileItem.FileItem.Size != chunkSizeOnNode(cluster, chunk) {
 					r.logger.Error(
-						fmt.Sprintf("Chunk cluster is not exists, skipping checksum calculation for %s.", file.Name),
-						zap.String("clusterId", cacheFileItem.ClusterId),
+						fmt.Sprintf("File size mismatched, skipping checksum calculation for %s.", file.Name),
 						zap.String("filePath", folder.Full),
 						zap.String("fileName", file.Name),
 					)
@@ -733,7 +857,23 @@ func (r *repair) repairChecksumCalculation(rebuildChecksum bool, clusterMap map[
 					break
 				}
 
-				masterNode := clusterMap[cacheFileItem.ClusterId].Master()
+				if cluster.ErasureCoded() {
+					data, err := readErasureCodedChunk(cluster, cacheFileItem.ExistsIn, chunk.Hash, chunk.Size)
+					if err != nil {
+						r.logger.Error(
+							fmt.Sprintf("Reading chunk %s from %s is failed, skipping checksum calculation for %s.", chunk.Hash, cacheFileItem.ClusterId, file.Name),
+							zap.String("clusterId", cacheFileItem.ClusterId),
+							zap.String("sha512Hex", chunk.Hash),
+							zap.Error(err),
+						)
+						sha512Failed = true
+						break
+					}
+					_, _ = sha512Hash.Write(data)
+					continue
+				}
+
+				masterNode := cluster.Master()
 				mdn, err := cluster2.NewDataNode(masterNode.Address)
 				if err != nil {
 					r.logger.Error(
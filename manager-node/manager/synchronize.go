@@ -155,7 +155,12 @@ func (s *synchronize) Cluster(clusterId string, ignoreMaintainMode bool, keepInM
 	)
 
 	cluster.Reservations.CleanUp()
-	cluster.Used, _ = mdn.Used()
+	cluster.Used, cluster.Logical, _ = mdn.Used()
+	if cluster.ErasureCoded() {
+		// every node keeps the shard of the chunk in the shard size
+		cluster.Used *= uint64(cluster.Erasure.Data)
+		cluster.Logical *= uint64(cluster.Erasure.Data)
+	}
 	cluster.Snapshots = container.Snapshots
 
 	_ = s.clusters.ResetStats(cluster)
@@ -168,6 +173,11 @@ func (s *synchronize) Cluster(clusterId string, ignoreMaintainMode bool, keepInM
 	)
 
 	syncTime := time.Now().UTC()
+	if cluster.ErasureCoded() {
+		s.syncShards(cluster, container, syncTime, keepInMaintainMode, waitFullSync)
+		return nil
+	}
+
 	for _, fileItem := range container.FileItems {
 		s.index.QueueUpsert(common.NewCacheFileItem(clusterId, masterNode.Id, fileItem), &syncTime)
 	}
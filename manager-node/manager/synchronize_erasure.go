@@ -0,0 +1,144 @@
+package manager
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/freakmaxi/kertish-dfs/basics/common"
+	cluster2 "github.com/freakmaxi/kertish-dfs/manager-node/cluster"
+	"go.uber.org/zap"
+)
+
+// syncShards indexes the chunks of the erasure coded cluster with all nodes keeping their shards. Nodes can not
+// be synced from the master because every node keeps a different shard, so the lost shards are rebuilt from
+// the others instead
+func (s *synchronize) syncShards(cluster *common.Cluster, masterContainer *common.SyncContainer, syncTime time.Time, keepInMaintainMode bool, waitFullSync bool) {
+	masterNode := cluster.Master()
+
+	nodeFileItems := make(map[string]common.SyncFileItemMap)
+	nodeFileItems[masterNode.Id] = masterContainer.FileItems
+
+	for _, slaveNode := range cluster.Slaves() {
+		sdn, err := cluster2.NewDataNode(slaveNode.Address)
+		if err != nil {
+			s.logger.Error(
+				"Syncing error: shard node is not accessible",
+				zap.String("clusterId", cluster.Id),
+				zap.String("nodeId", slaveNode.Id),
+				zap.String("nodeAddress", slaveNode.Address),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		container, err := sdn.SyncList(nil)
+		if err != nil {
+			s.logger.Error(
+				"Syncing error: shard node didn't response for SyncList",
+				zap.String("clusterId", cluster.Id),
+				zap.String("nodeId", slaveNode.Id),
+				zap.String("nodeAddress", slaveNode.Address),
+				zap.Error(err),
+			)
+			continue
+		}
+		nodeFileItems[slaveNode.Id] = container.FileItems
+	}
+
+	items := make(map[string]*common.CacheFileItem)
+	for _, node := range cluster.Nodes {
+		for sha512Hex, fileItem := range nodeFileItems[node.Id] {
+			item, has := items[sha512Hex]
+			if !has {
+				items[sha512Hex] = common.NewCacheFileItem(cluster.Id, node.Id, fileItem)
+				continue
+			}
+			item.ExistsIn[node.Id] = true
+
+			// usage of the master is the reference
+			if node.Master {
+				item.FileItem = fileItem
+			}
+		}
+	}
+
+	for _, item := range items {
+		s.index.QueueUpsert(item, &syncTime)
+	}
+	s.index.WaitQueueCompletion()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go s.rebuildShards(wg, cluster, items, nodeFileItems)
+	if waitFullSync {
+		wg.Wait()
+	}
+
+	go func(wg *sync.WaitGroup, clusterId string, keepInMaintainMode bool) {
+		wg.Wait()
+		if err := s.clusters.UpdateMaintain(clusterId, keepInMaintainMode, common.TopicNone); err != nil {
+			s.logger.Error(
+				"Cluster hasn't been taken off the maintain mode. Needs manual action!",
+				zap.String("clusterId", clusterId),
+				zap.Error(err),
+			)
+		}
+	}(wg, cluster.Id, keepInMaintainMode)
+
+	s.logger.Info(
+		fmt.Sprintf("Synchronization shard nodes of cluster %s is completed", cluster.Id),
+		zap.String("clusterId", cluster.Id),
+	)
+}
+
+// rebuildShards creates the missing shards on the accessible nodes. Chunks those do not have enough shards
+// are not recoverable and left to the repair
+func (s *synchronize) rebuildShards(wg *sync.WaitGroup, cluster *common.Cluster, items map[string]*common.CacheFileItem, nodeFileItems map[string]common.SyncFileItemMap) {
+	defer wg.Done()
+
+	rebuilt := 0
+	for sha512Hex, item := range items {
+		lackingNodes := make(common.NodeList, 0)
+		for _, node := range cluster.ShardNodes() {
+			if node == nil {
+				continue
+			}
+			if _, accessible := nodeFileItems[node.Id]; !accessible {
+				continue
+			}
+			if item.ExistsIn[node.Id] {
+				continue
+			}
+			lackingNodes = append(lackingNodes, node)
+		}
+
+		if len(lackingNodes) == 0 {
+			continue
+		}
+
+		if err := rebuildChunkShards(cluster, item, lackingNodes); err != nil {
+			s.logger.Error(
+				fmt.Sprintf("Rebuilding shards of %s is failed", sha512Hex),
+				zap.String("clusterId", cluster.Id),
+				zap.String("sha512Hex", sha512Hex),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		for _, node := range lackingNodes {
+			s.index.QueueUpsertChunkNode(sha512Hex, node.Id)
+		}
+		s.index.QueueUpsertUsageInMap(cluster.Id, common.SyncFileItemList{item.FileItem})
+		rebuilt++
+	}
+	s.index.WaitQueueCompletion()
+
+	if rebuilt > 0 {
+		s.logger.Info(
+			fmt.Sprintf("%d chunk(s) shards are rebuilt in cluster %s", rebuilt, cluster.Id),
+			zap.String("clusterId", cluster.Id),
+		)
+	}
+}
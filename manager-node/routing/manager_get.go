@@ -147,6 +147,8 @@ func (m *managerRouter) handleMove(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(503)
 		} else if err == errors.ErrNoSpace {
 			w.WriteHeader(507)
+		} else if err == errors.ErrErasureCoded {
+			w.WriteHeader(400)
 		} else {
 			w.WriteHeader(500)
 			m.logger.Error(
@@ -176,6 +178,8 @@ func (m *managerRouter) handleBalance(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(404)
 		} else if err == errors.ErrNotAvailableForClusterAction {
 			w.WriteHeader(503)
+		} else if err == errors.ErrErasureCoded {
+			w.WriteHeader(400)
 		} else {
 			w.WriteHeader(500)
 			m.logger.Error("Balance request is failed", zap.Strings("clusterIds", clusterIds), zap.Error(err))
@@ -233,11 +237,15 @@ func (m *managerRouter) handleClusters(w http.ResponseWriter, r *http.Request) {
 func (m *managerRouter) handleFind(w http.ResponseWriter, r *http.Request) {
 	sha512Hex := r.Header.Get("X-Options")
 
-	clusterId, addresses, err := m.manager.Find(sha512Hex, common.MTCreate)
+	placement, err := m.manager.Find(sha512Hex, common.MTCreate)
 
 	if err == nil {
-		w.Header().Set("X-Cluster-Id", clusterId)
-		w.Header().Set("X-Address", addresses[0])
+		w.Header().Set("X-Cluster-Id", placement.ClusterId)
+		w.Header().Set("X-Address", placement.Addresses[0])
+		if placement.ErasureCoded() {
+			w.Header().Set("X-Erasure-Coding", placement.Erasure.String())
+			w.Header().Set("X-Addresses", strings.Join(placement.Addresses, ","))
+		}
 		return
 	}
 
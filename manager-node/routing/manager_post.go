@@ -27,6 +27,8 @@ func (m *managerRouter) handlePost(w http.ResponseWriter, r *http.Request) {
 		m.handleCreateSnapshot(w, r)
 	case "reserve":
 		m.handleReserve(w, r)
+	case "rotateKeys":
+		m.handleRotateKeys(w, r)
 	case "readMap", "createMap", "deleteMap":
 		mapType := common.MTRead
 		switch action {
@@ -44,10 +46,20 @@ func (m *managerRouter) handlePost(w http.ResponseWriter, r *http.Request) {
 func (m *managerRouter) handleRegister(w http.ResponseWriter, r *http.Request) {
 	clusterId, addresses := m.describeRegisterOptions(r.Header.Get("X-Options"))
 
+	var erasure *common.ErasureCoding
+	if erasureHeader := r.Header.Get("X-Erasure-Coding"); len(erasureHeader) > 0 {
+		var err error
+		erasure, err = common.ParseErasureCoding(erasureHeader)
+		if err != nil || len(clusterId) > 0 {
+			w.WriteHeader(422)
+			return
+		}
+	}
+
 	var cluster *common.Cluster
 	var err error
 	if len(clusterId) == 0 {
-		cluster, err = m.manager.Register(addresses)
+		cluster, err = m.manager.Register(addresses, erasure)
 	} else {
 		err = m.manager.RegisterNodesTo(clusterId, addresses)
 		if err == nil {
@@ -101,14 +113,82 @@ func (m *managerRouter) handleCreateSnapshot(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+func (m *managerRouter) handleRotateKeys(w http.ResponseWriter, r *http.Request) {
+	clusterIds := strings.Split(r.Header.Get("X-Options"), ",")
+	if len(clusterIds) > 0 && len(clusterIds[0]) == 0 {
+		clusterIds = []string{}
+	}
+
+	err := m.manager.RotateKeys(clusterIds)
+	if err == nil {
+		w.WriteHeader(202)
+		return
+	}
+
+	if err == errors.ErrNotFound {
+		w.WriteHeader(404)
+	} else {
+		w.WriteHeader(400)
+		m.logger.Error("Rotate keys request is failed", zap.Strings("clusterIds", clusterIds), zap.Error(err))
+	}
+
+	e := common.NewError(215, err.Error())
+	if err := json.NewEncoder(w).Encode(e); err != nil {
+		m.logger.Error("Response of rotate keys request is failed", zap.Error(err))
+	}
+}
+
 func (m *managerRouter) handleReserve(w http.ResponseWriter, r *http.Request) {
+	if chunkSizesHeader := r.Header.Get("X-Chunk-Sizes"); len(chunkSizesHeader) > 0 {
+		m.handleReserveChunks(w, chunkSizesHeader)
+		return
+	}
+
 	size, err := strconv.ParseUint(r.Header.Get("X-Size"), 10, 64)
 	if err != nil {
 		w.WriteHeader(422)
 		return
 	}
 
-	reservationMap, err := m.manager.Reserve(size)
+	blockSize := uint64(0)
+	if blockSizeHeader := r.Header.Get("X-Block-Size"); len(blockSizeHeader) > 0 {
+		blockSize, err = strconv.ParseUint(blockSizeHeader, 10, 32)
+		if err != nil {
+			w.WriteHeader(422)
+			return
+		}
+	}
+
+	reservationMap, err := m.manager.Reserve(size, uint32(blockSize))
+	if err == os.ErrInvalid {
+		w.WriteHeader(422)
+		return
+	}
+	m.writeReservation(w, reservationMap, size, err)
+}
+
+func (m *managerRouter) handleReserveChunks(w http.ResponseWriter, chunkSizesHeader string) {
+	size := uint64(0)
+	chunkSizes := make([]uint32, 0)
+	for _, chunkSizeString := range strings.Split(chunkSizesHeader, ",") {
+		chunkSize, err := strconv.ParseUint(strings.TrimSpace(chunkSizeString), 10, 32)
+		if err != nil {
+			w.WriteHeader(422)
+			return
+		}
+		chunkSizes = append(chunkSizes, uint32(chunkSize))
+		size += chunkSize
+	}
+
+	reservationMap, err := m.manager.ReserveChunks(chunkSizes)
+	if err == os.ErrInvalid {
+		w.WriteHeader(422)
+		return
+	}
+	m.writeReservation(w, reservationMap, size, err)
+}
+
+func (m *managerRouter) writeReservation(w http.ResponseWriter, reservationMap *common.ReservationMap, size uint64, err error) {
 	if err == nil {
 		if err := json.NewEncoder(w).Encode(reservationMap); err != nil {
 			m.logger.Error("Response of reserve request is failed", zap.Error(err))
@@ -163,7 +243,7 @@ func (m *managerRouter) handleMap(w http.ResponseWriter, r *http.Request, mapTyp
 
 func (m *managerRouter) validatePostAction(action string) bool {
 	switch action {
-	case "register", "snapshot", "reserve", "readMap", "createMap", "deleteMap":
+	case "register", "snapshot", "reserve", "rotateKeys", "readMap", "createMap", "deleteMap":
 		return true
 	}
 	return false
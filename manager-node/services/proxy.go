@@ -2,8 +2,8 @@ package services
 
 import (
 	"fmt"
-	"net/http"
 
+	"github.com/freakmaxi/kertish-dfs/basics/secure"
 	"github.com/freakmaxi/kertish-dfs/manager-node/routing"
 	"go.uber.org/zap"
 )
@@ -27,7 +27,7 @@ func NewProxy(bindAddr string, manager *routing.Manager, logger *zap.Logger) *Pr
 // Start starts the proxy service
 func (p *Proxy) Start() {
 	p.logger.Info(fmt.Sprintf("Manager Service is running on %s", p.bindAddr))
-	if err := http.ListenAndServe(p.bindAddr, p.manager.Get()); err != nil {
+	if err := secure.ListenAndServe(p.bindAddr, p.manager.Get()); err != nil {
 		p.logger.Error("Manager service is failed", zap.Error(err))
 	}
 }